@@ -0,0 +1,10 @@
+data "ziti_configs" "web" {
+  filter = "name startsWith \"web.\""
+  tags = {
+    app = "web"
+  }
+}
+
+output "web_config_types" {
+  value = { for config in data.ziti_configs.web.configs : config.name => config.config_type_name }
+}
@@ -0,0 +1,7 @@
+data "ziti_edge_router_role_attributes" "regions" {
+  filter = "id startsWith \"region-\""
+}
+
+output "router_regions" {
+  value = data.ziti_edge_router_role_attributes.regions.role_attributes
+}
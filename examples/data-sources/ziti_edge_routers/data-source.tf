@@ -0,0 +1,7 @@
+data "ziti_edge_routers" "public" {
+  role_attributes = ["public"]
+}
+
+output "public_routers" {
+  value = { for router in data.ziti_edge_routers.public.edge_routers : router.name => router.id }
+}
@@ -0,0 +1,13 @@
+data "ziti_identities" "payments" {
+  tags = {
+    team = "payments"
+  }
+}
+
+resource "ziti_service_policy" "payments_dial" {
+  name          = "payments-dial"
+  type          = "Dial"
+  semantic      = "AnyOf"
+  identityroles = [for identity in data.ziti_identities.payments.identities : "@${identity.id}"]
+  serviceroles  = ["#payments"]
+}
@@ -0,0 +1,14 @@
+data "ziti_identity_role_attributes" "teams" {
+  starts_with = "team-"
+}
+
+# One dial policy per team attribute in use.
+resource "ziti_service_policy" "team_dial" {
+  for_each = data.ziti_identity_role_attributes.teams.role_attributes
+
+  name          = "${each.value}-dial"
+  type          = "Dial"
+  semantic      = "AnyOf"
+  identityroles = ["#${each.value}"]
+  serviceroles  = ["#${each.value}-services"]
+}
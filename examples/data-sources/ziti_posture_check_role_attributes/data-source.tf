@@ -0,0 +1,7 @@
+data "ziti_posture_check_role_attributes" "compliance" {
+  contains = "compliance"
+}
+
+output "compliance_posture_roles" {
+  value = data.ziti_posture_check_role_attributes.compliance.role_attributes
+}
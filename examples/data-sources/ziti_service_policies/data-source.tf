@@ -0,0 +1,9 @@
+data "ziti_service_policies" "bind" {
+  filter = "name contains \"bind\""
+  sort   = "name desc"
+  limit  = 10
+}
+
+output "bind_policies" {
+  value = data.ziti_service_policies.bind.service_policies[*].name
+}
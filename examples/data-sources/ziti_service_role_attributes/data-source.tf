@@ -0,0 +1,13 @@
+data "ziti_service_role_attributes" "all" {}
+
+locals {
+  policy_service_roles = ["web", "db"]
+}
+
+# Fail the plan when a policy refers to a service attribute nobody has.
+check "service_roles_exist" {
+  assert {
+    condition     = length(setsubtract(local.policy_service_roles, data.ziti_service_role_attributes.all.role_attributes)) == 0
+    error_message = "Unknown service role attributes: ${join(", ", setsubtract(local.policy_service_roles, data.ziti_service_role_attributes.all.role_attributes))}"
+  }
+}
@@ -0,0 +1,9 @@
+data "ziti_services" "web" {
+  filter          = "name startsWith \"web-\""
+  role_attributes = ["public"]
+  sort            = "name"
+}
+
+output "web_services" {
+  value = data.ziti_services.web.services[*].name
+}
@@ -0,0 +1,39 @@
+resource "ziti_identity" "device" {
+  name = "device"
+  type = "Device"
+}
+
+# Return the pending one-time token of the device, issuing a new one once it
+# has been used or has expired, and hand it to Vault, so the JWT is never
+# written to the state file. The same JWT is returned on every plan and apply
+# until then.
+ephemeral "ziti_enrollment_token" "device" {
+  identity_id = ziti_identity.device.id
+  issue       = true
+  expires_in  = "24h"
+}
+
+# Write-only arguments are only sent when their version changes: bump
+# device_jwt_version to store a JWT issued after the previous one was used or
+# has expired.
+variable "device_jwt_version" {
+  type    = number
+  default = 1
+}
+
+resource "vault_kv_secret_v2" "device_jwt" {
+  mount                = "secret"
+  name                 = "ziti/device"
+  data_json_wo         = jsonencode({ jwt = ephemeral.ziti_enrollment_token.device.jwt })
+  data_json_wo_version = var.device_jwt_version
+}
+
+resource "ziti_edge_router" "router" {
+  name = "router"
+}
+
+# Fetch the pending enrollment JWT of an edge router, e.g. to pass it to a
+# write-only argument of a secret store.
+ephemeral "ziti_enrollment_token" "router" {
+  edge_router_id = ziti_edge_router.router.id
+}
@@ -47,3 +47,39 @@ provider "ziti" {
   identity_file = "/secure/terraform-automation.json"
   hosts         = ["https://<domain1>:<port1>/edge/management/v1", "https://<domain2>:<port2>/edge/management/v1"]
 }
+
+## Option H — username/password with a CA bundle to verify the controller
+## Env equivalent: ZITI_API_CA_FILE
+provider "ziti" {
+  host     = "https://<domain>:<port>/edge/management/v1"
+  username = "ziti_session_username"
+  password = "ziti_session_password"
+  ca_file  = "/secure/ziti-ca.pem"
+}
+
+## Option I — external JWT (ext-jwt) from a workload identity token file
+## Env equivalents: ZITI_API_JWT, ZITI_API_JWT_FILE
+provider "ziti" {
+  host     = "https://<domain>:<port>/edge/management/v1"
+  jwt_file = "/var/run/secrets/tokens/ziti-token"
+}
+
+## Default tags on every resource, leaving tags set by other tooling alone
+provider "ziti" {
+  host     = "https://<domain>:<port>/edge/management/v1"
+  username = "ziti_session_username"
+  password = "ziti_session_password"
+
+  default_tags {
+    tags = {
+      owner       = "platform"
+      cost_center = "cc-42"
+      managed_by  = "terraform"
+    }
+  }
+
+  ignore_tags {
+    keys         = ["last_scanned"]
+    key_prefixes = ["scanner:"]
+  }
+}
@@ -1,2 +1,5 @@
 # auth policy can be imported by specifying the identifier.
 terraform import ziti_auth_policy.test_auth_policy <ID>
+
+# or by its name.
+terraform import ziti_auth_policy.test_auth_policy name=<NAME>
@@ -1,2 +1,5 @@
 # certificate authority can be imported by specifying the identifier.
 terraform import ziti_certificate_authority.test_certificate_authority <ID>
+
+# or by its name.
+terraform import ziti_certificate_authority.test_certificate_authority name=<NAME>
@@ -1,2 +1,5 @@
 # edge router can be imported by specifying the identifier.
 terraform import ziti_edge_router.test_edge_router <ID>
+
+# or by its name.
+terraform import ziti_edge_router.test_edge_router name=<NAME>
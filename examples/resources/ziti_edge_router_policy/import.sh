@@ -1,2 +1,5 @@
 # edge router policy can be imported by specifying the identifier.
 terraform import ziti_edge_router_policy.test1 <ID>
+
+# or by its name.
+terraform import ziti_edge_router_policy.test1 name=<NAME>
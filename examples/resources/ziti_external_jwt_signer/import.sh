@@ -1,2 +1,5 @@
 # external jwt signer can be imported by specifying the identifier.
 terraform import ziti_external_jwt_signer.test_external_jwt_signer <ID>
+
+# or by its name.
+terraform import ziti_external_jwt_signer.test_external_jwt_signer name=<NAME>
@@ -1,2 +1,8 @@
 # host v1 config can be imported by specifying the identifier.
 terraform import ziti_host_v1_config.simple_host <ID>
+
+# or by its name.
+terraform import ziti_host_v1_config.simple_host name=<NAME>
+
+# config_type fails the import unless the config is of that type, given by name or ID.
+terraform import ziti_host_v1_config.simple_host name=<NAME>,config_type=host.v1
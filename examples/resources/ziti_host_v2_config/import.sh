@@ -1,2 +1,8 @@
 # host v2 config can be imported by specifying the identifier.
 terraform import ziti_host_v2_config.simple_host_v2 <ID>
+
+# or by its name.
+terraform import ziti_host_v2_config.simple_host_v2 name=<NAME>
+
+# config_type fails the import unless the config is of that type, given by name or ID.
+terraform import ziti_host_v2_config.simple_host_v2 name=<NAME>,config_type=host.v2
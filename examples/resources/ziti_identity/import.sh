@@ -1,2 +1,5 @@
 # identity can be imported by specifying the identifier.
 terraform import ziti_identity.test1 <ID>
+
+# or by its name.
+terraform import ziti_identity.test1 name=<NAME>
@@ -1,2 +1,5 @@
 # identity_ca can be imported by specifying the identifier.
 terraform import ziti_identity_ca.test_identity_ca <ID>
+
+# or by its name.
+terraform import ziti_identity_ca.test_identity_ca name=<NAME>
@@ -0,0 +1,11 @@
+resource "ziti_identity" "test_device" {
+  name            = "test_device"
+  type            = "Device"
+  role_attributes = ["devices"]
+}
+
+resource "ziti_identity_enrollment" "test_device" {
+  identity_id = ziti_identity.test_device.id
+  key_type    = "EC"
+  key_size    = 384
+}
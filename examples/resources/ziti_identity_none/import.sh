@@ -1,2 +1,5 @@
 # identity_none can be imported by specifying the identifier.
 terraform import ziti_identity_none.test_identity_none <ID>
+
+# or by its name.
+terraform import ziti_identity_none.test_identity_none name=<NAME>
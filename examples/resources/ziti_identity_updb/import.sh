@@ -1,2 +1,5 @@
 # identity updb can be imported by specifying the identifier.
 terraform import ziti_identity_updb.test_identity_updb <ID>
+
+# or by its name.
+terraform import ziti_identity_updb.test_identity_updb name=<NAME>
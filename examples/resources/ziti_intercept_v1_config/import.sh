@@ -1,2 +1,8 @@
 # intercept v1 config can be imported by specifying the identifier.
 terraform import ziti_intercept_v1_config.test_intercept_v1_config <ID>
+
+# or by its name.
+terraform import ziti_intercept_v1_config.test_intercept_v1_config name=<NAME>
+
+# config_type fails the import unless the config is of that type, given by name or ID.
+terraform import ziti_intercept_v1_config.test_intercept_v1_config name=<NAME>,config_type=intercept.v1
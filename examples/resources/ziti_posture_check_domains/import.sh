@@ -1,2 +1,5 @@
 # domain posture check can be imported by specifying the identifier.
 terraform import ziti_posture_check_domains.test_posture_check_domains <ID>
+
+# or by its name.
+terraform import ziti_posture_check_domains.test_posture_check_domains name=<NAME>
@@ -1,2 +1,5 @@
 # mac addresses posture check can be imported by specifying the identifier.
 terraform import ziti_posture_check_mac_addresses.test_posture_check_mac_addresses <ID>
+
+# or by its name.
+terraform import ziti_posture_check_mac_addresses.test_posture_check_mac_addresses name=<NAME>
@@ -1,2 +1,5 @@
 # mfa posture check can be imported by specifying the identifier.
 terraform import ziti_posture_check_mfa.test_posture_check_mfa <ID>
+
+# or by its name.
+terraform import ziti_posture_check_mfa.test_posture_check_mfa name=<NAME>
@@ -1,2 +1,5 @@
 # multi process posture check can be imported by specifying the identifier.
 terraform import ziti_posture_check_multi_process.test_posture_check_multi_process <ID>
+
+# or by its name.
+terraform import ziti_posture_check_multi_process.test_posture_check_multi_process name=<NAME>
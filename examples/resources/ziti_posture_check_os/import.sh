@@ -1,2 +1,5 @@
 # os posture check can be imported by specifying the identifier.
 terraform import ziti_posture_check_os.test_posture_check_os <ID>
+
+# or by its name.
+terraform import ziti_posture_check_os.test_posture_check_os name=<NAME>
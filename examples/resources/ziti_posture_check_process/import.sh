@@ -1,2 +1,5 @@
 # process posture check can be imported by specifying the identifier.
 terraform import ziti_posture_check_process.test_posture_check_process <ID>
+
+# or by its name.
+terraform import ziti_posture_check_process.test_posture_check_process name=<NAME>
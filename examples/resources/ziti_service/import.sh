@@ -1,2 +1,5 @@
 # service can be imported by specifying the identifier.
 terraform import ziti_service.test_service <ID>
+
+# or by its name.
+terraform import ziti_service.test_service name=<NAME>
@@ -1,2 +1,5 @@
 # service edge router policy can be imported by specifying the identifier.
 terraform import ziti_service_edge_router_policy.test_service_er_policy <ID>
+
+# or by its name.
+terraform import ziti_service_edge_router_policy.test_service_er_policy name=<NAME>
@@ -1,2 +1,5 @@
 # service policy can be imported by specifying the identifier.
 terraform import ziti_service_policy.test_service_policy <ID>
+
+# or by its name.
+terraform import ziti_service_policy.test_service_policy name=<NAME>
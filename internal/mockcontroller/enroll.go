@@ -0,0 +1,266 @@
+package mockcontroller
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// EnrollPath is the edge client API endpoint an identity enrolls at.
+const EnrollPath = "/edge/client/v1/enroll"
+
+// enrollmentCA is the CA that signs the certificates of enrolled identities.
+type enrollmentCA struct {
+	key  *ecdsa.PrivateKey
+	cert *x509.Certificate
+}
+
+func newEnrollmentCA() (*enrollmentCA, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "mock-controller-edge-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &enrollmentCA{key: key, cert: cert}, nil
+}
+
+// EnrollmentCA returns the CA that signs the certificates of enrolled
+// identities.
+func (s *Server) EnrollmentCA() *x509.Certificate {
+	return s.ca.cert
+}
+
+// enroll completes the one-time token enrollment of an identity: it signs the
+// certificate request in the body, consumes the enrollment and adds a cert
+// authenticator to the identity. Like the controller, it answers with the
+// certificate and CA bundle as JSON when asked to, and with the certificate
+// as PEM otherwise.
+func (s *Server) enroll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", r.Method+" is not allowed", nil)
+		return
+	}
+	if method := r.URL.Query().Get("method"); method != "ott" {
+		writeError(w, http.StatusBadRequest, "INVALID_ENROLLMENT_METHOD", "unsupported enrollment method: "+method, nil)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "COULD_NOT_PARSE_BODY", err.Error(), nil)
+		return
+	}
+	block, _ := pem.Decode(body)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		writeError(w, http.StatusBadRequest, "COULD_NOT_PARSE_BODY", "the body is not a PEM encoded certificate request", nil)
+		return
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err == nil {
+		err = csr.CheckSignature()
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_CSR", err.Error(), nil)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token := r.URL.Query().Get("token")
+	var identity map[string]interface{}
+	for _, entity := range s.store[Identities].entities {
+		enrollment, _ := entity["enrollment"].(map[string]interface{})
+		if ott, ok := enrollment["ott"].(map[string]interface{}); ok && token != "" && ott["token"] == token && !enrollmentExpired(ott) {
+			identity = entity
+			break
+		}
+	}
+	if identity == nil {
+		writeError(w, http.StatusNotFound, "INVALID_ENROLLMENT_TOKEN", "the enrollment token is invalid or has been used", nil)
+		return
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: identity["id"].(string)},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, s.ca.cert, csr.PublicKey, s.ca.key)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "UNHANDLED", err.Error(), nil)
+		return
+	}
+	fingerprint := sha1.Sum(der)
+	delete(identity["enrollment"].(map[string]interface{}), "ott")
+	identity["authenticators"] = map[string]interface{}{
+		"cert": map[string]interface{}{"id": newID(), "fingerprint": hex.EncodeToString(fingerprint[:])},
+	}
+
+	cert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		ca := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.ca.cert.Raw}))
+		writeJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"cert": cert, "ca": ca}, "meta": map[string]interface{}{}})
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-pem-file")
+	_, _ = io.WriteString(w, cert)
+}
+
+// enrollmentExpired reports whether the expiresAt of a pending enrollment has
+// passed, after which the controller rejects its token.
+func enrollmentExpired(enrollment map[string]interface{}) bool {
+	expiresAt, err := time.Parse(time.RFC3339Nano, fmt.Sprint(enrollment["expiresAt"]))
+	return err == nil && !expiresAt.After(time.Now())
+}
+
+// createEnrollment serves POST /enrollments, which issues a new one-time
+// token enrollment for an identity.
+func (s *Server) createEnrollment(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		IdentityID string `json:"identityId"`
+		Method     string `json:"method"`
+		ExpiresAt  string `json:"expiresAt"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "COULD_NOT_PARSE_BODY", err.Error(), nil)
+		return
+	}
+	if request.Method != "ott" {
+		writeAPIError(w, invalidField("method", "must be ott", request.Method))
+		return
+	}
+	expiresAt, err := time.Parse(time.RFC3339Nano, request.ExpiresAt)
+	if err != nil {
+		writeAPIError(w, invalidField("expiresAt", "is not a date-time", request.ExpiresAt))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	identity, ok := s.store[Identities].entities[request.IdentityID]
+	if !ok {
+		writeAPIError(w, invalidField("identityId", "is not a valid identity", request.IdentityID))
+		return
+	}
+	enrollment, _ := identity["enrollment"].(map[string]interface{})
+	if _, pending := enrollment["ott"]; pending {
+		writeAPIError(w, invalidField("method", "the identity already has a pending ott enrollment", request.Method))
+		return
+	}
+	if enrollment == nil {
+		enrollment = map[string]interface{}{}
+		identity["enrollment"] = enrollment
+	}
+	id, token := newID(), newID()
+	enrollment["ott"] = map[string]interface{}{
+		"id":        id,
+		"token":     token,
+		"jwt":       s.issueJWT(request.IdentityID, token, "ott", expiresAt),
+		"expiresAt": expiresAt.UTC().Format(time.RFC3339Nano),
+	}
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"data": map[string]interface{}{"id": id, "_links": map[string]interface{}{}},
+		"meta": map[string]interface{}{},
+	})
+}
+
+// deleteAuthenticator serves DELETE /authenticators/{id} for the cert
+// authenticators enrollment adds to identities.
+func (s *Server) deleteAuthenticator(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, identity := range s.store[Identities].entities {
+		authenticators, _ := identity["authenticators"].(map[string]interface{})
+		if cert, ok := authenticators["cert"].(map[string]interface{}); ok && cert["id"] == id {
+			delete(authenticators, "cert")
+			writeJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{}, "meta": map[string]interface{}{}})
+			return
+		}
+	}
+	writeNotFound(w)
+}
+
+// refreshEnrollment serves POST /enrollments/{id}/refresh, which replaces the
+// token and JWT of a pending enrollment and moves its expiry.
+func (s *Server) refreshEnrollment(w http.ResponseWriter, r *http.Request, id string) {
+	var request struct {
+		ExpiresAt string `json:"expiresAt"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "COULD_NOT_PARSE_BODY", err.Error(), nil)
+		return
+	}
+	expiresAt, err := time.Parse(time.RFC3339Nano, request.ExpiresAt)
+	if err != nil {
+		writeAPIError(w, invalidField("expiresAt", "is not a date-time", request.ExpiresAt))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for identityID, identity := range s.store[Identities].entities {
+		enrollments, _ := identity["enrollment"].(map[string]interface{})
+		for method, e := range enrollments {
+			if e, ok := e.(map[string]interface{}); ok && e["id"] == id {
+				token := newID()
+				e["token"] = token
+				e["jwt"] = s.issueJWT(identityID, token, method, expiresAt)
+				e["expiresAt"] = expiresAt.UTC().Format(time.RFC3339Nano)
+				writeJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{}, "meta": map[string]interface{}{}})
+				return
+			}
+		}
+	}
+	writeNotFound(w)
+}
+
+// reEnrollEdgeRouter serves POST /edge-routers/{id}/re-enroll, which drops
+// the certificate of an edge router and issues a new enrollment JWT for it.
+func (s *Server) reEnrollEdgeRouter(w http.ResponseWriter, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	router, ok := s.store[EdgeRouters].entities[id]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	token := newID()
+	expiresAt := time.Now().Add(24 * time.Hour)
+	router["enrollmentToken"] = token
+	router["enrollmentJwt"] = s.issueJWT(id, token, "erott", expiresAt)
+	router["enrollmentCreatedAt"] = time.Now().UTC().Format(time.RFC3339Nano)
+	router["enrollmentExpiresAt"] = expiresAt.UTC().Format(time.RFC3339Nano)
+	router["isVerified"] = false
+	delete(router, "fingerprint")
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{}, "meta": map[string]interface{}{}})
+}
@@ -0,0 +1,661 @@
+package mockcontroller
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultLimit and maxLimit bound list pages like the controller does.
+	defaultLimit = 10
+	maxLimit     = 500
+)
+
+// collection holds the entities of one management API endpoint, e.g.
+// "identities", keyed by ID.
+type collection struct {
+	kind     string
+	entities map[string]map[string]interface{}
+	// onCreate sets the fields the controller derives from a new entity, e.g.
+	// the enrollment JWT of an identity. It can reject the entity with an
+	// *apiError.
+	onCreate func(s *Server, entity map[string]interface{}) *apiError
+	// onUpdate carries the server owned fields of old over to a replaced or
+	// patched entity.
+	onUpdate func(s *Server, old, entity map[string]interface{}) *apiError
+}
+
+// apiError is a request rejected by a collection hook.
+type apiError struct {
+	status  int
+	code    string
+	message string
+	cause   *apiErrorCause
+}
+
+func invalidField(field, reason string, value interface{}) *apiError {
+	return &apiError{
+		status:  http.StatusBadRequest,
+		code:    "COULD_NOT_VALIDATE",
+		message: "The supplied request contains an invalid document or no valid accept content were available",
+		cause:   &apiErrorCause{Field: field, Reason: reason, Value: value},
+	}
+}
+
+// Collections served by the fake controller.
+const (
+	AuthPolicies              = "auth-policies"
+	CAs                       = "cas"
+	ConfigTypes               = "config-types"
+	Configs                   = "configs"
+	EdgeRouterPolicies        = "edge-router-policies"
+	EdgeRouters               = "edge-routers"
+	ExternalJWTSigners        = "external-jwt-signers"
+	Identities                = "identities"
+	PostureChecks             = "posture-checks"
+	ServiceEdgeRouterPolicies = "service-edge-router-policies"
+	ServicePolicies           = "service-policies"
+	Services                  = "services"
+)
+
+// IDs of the config types the fake controller is seeded with. They match the
+// IDs a controller gives its built-in config types, which the config
+// resources default config_type_id to.
+const (
+	ConfigTypeHostV1      = "NH5p4FpGR"
+	ConfigTypeHostV2      = "host.v2"
+	ConfigTypeInterceptV1 = "g7cIWbcGg"
+)
+
+// DefaultAuthPolicyID is the ID of the seeded default auth policy.
+const DefaultAuthPolicyID = "default"
+
+func newStore() map[string]*collection {
+	store := map[string]*collection{
+		AuthPolicies:              {kind: "authPolicies"},
+		CAs:                       {kind: "cas", onCreate: createCA, onUpdate: keepFields("fingerprint", "isVerified", "verificationToken")},
+		ConfigTypes:               {kind: "configTypes"},
+		Configs:                   {kind: "configs", onCreate: createConfig, onUpdate: keepFields("configTypeId", "configType")},
+		EdgeRouterPolicies:        {kind: "edgeRouterPolicies"},
+		EdgeRouters:               {kind: "edgeRouters", onCreate: createEdgeRouter, onUpdate: keepFields("enrollmentJwt", "enrollmentToken", "enrollmentExpiresAt", "enrollmentCreatedAt", "isVerified", "isOnline", "fingerprint")},
+		ExternalJWTSigners:        {kind: "externalJwtSigners"},
+		Identities:                {kind: "identities", onCreate: createIdentity, onUpdate: updateIdentity},
+		PostureChecks:             {kind: "postureChecks", onCreate: createPostureCheck, onUpdate: keepFields("typeId")},
+		ServiceEdgeRouterPolicies: {kind: "serviceEdgeRouterPolicies"},
+		ServicePolicies:           {kind: "servicePolicies"},
+		Services:                  {kind: "services", onCreate: createService, onUpdate: updateService},
+	}
+	for _, c := range store {
+		c.entities = map[string]map[string]interface{}{}
+	}
+
+	now := timestamp()
+	for id, name := range map[string]string{ConfigTypeHostV1: "host.v1", ConfigTypeHostV2: "host.v2", ConfigTypeInterceptV1: "intercept.v1"} {
+		store[ConfigTypes].entities[id] = map[string]interface{}{
+			"id": id, "name": name, "schema": map[string]interface{}{}, "tags": map[string]interface{}{},
+			"createdAt": now, "updatedAt": now,
+		}
+	}
+	store[AuthPolicies].entities[DefaultAuthPolicyID] = map[string]interface{}{
+		"id":   DefaultAuthPolicyID,
+		"name": "Default",
+		"primary": map[string]interface{}{
+			"cert":   map[string]interface{}{"allowed": true, "allowExpiredCerts": true},
+			"extJwt": map[string]interface{}{"allowed": true, "allowedSigners": []interface{}{}},
+			"updb": map[string]interface{}{
+				"allowed": true, "lockoutDurationMinutes": 0, "maxAttempts": 5, "minPasswordLength": 5,
+				"requireMixedCase": false, "requireNumberChar": false, "requireSpecialChar": false,
+			},
+		},
+		"secondary": map[string]interface{}{"requireTotp": false},
+		"tags":      map[string]interface{}{},
+		"createdAt": now, "updatedAt": now,
+	}
+	return store
+}
+
+// serveEntities serves the CRUD endpoints of path, which is either a
+// collection, e.g. "identities", or an entity, e.g. "identities/abc".
+func (s *Server) serveEntities(w http.ResponseWriter, r *http.Request, path string) {
+	name, id, _ := strings.Cut(path, "/")
+	if collection, ok := roleAttributeEndpoints[name]; ok && id == "" && r.Method == http.MethodGet {
+		s.mu.Lock()
+		c := s.store[collection]
+		s.mu.Unlock()
+		s.listRoleAttributes(w, r, c)
+		return
+	}
+	switch {
+	case name == "enrollments" && id == "" && r.Method == http.MethodPost:
+		s.createEnrollment(w, r)
+		return
+	case name == "authenticators" && id != "" && r.Method == http.MethodDelete:
+		s.deleteAuthenticator(w, id)
+		return
+	case name == "enrollments" && strings.HasSuffix(id, "/refresh") && r.Method == http.MethodPost:
+		s.refreshEnrollment(w, r, strings.TrimSuffix(id, "/refresh"))
+		return
+	case name == EdgeRouters && strings.HasSuffix(id, "/re-enroll") && r.Method == http.MethodPost:
+		s.reEnrollEdgeRouter(w, strings.TrimSuffix(id, "/re-enroll"))
+		return
+	}
+	s.mu.Lock()
+	c, ok := s.store[name]
+	s.mu.Unlock()
+	if !ok || strings.Contains(id, "/") {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "no such endpoint: "+r.URL.Path, nil)
+		return
+	}
+
+	switch {
+	case id == "" && r.Method == http.MethodGet:
+		s.list(w, r, c)
+	case id == "" && r.Method == http.MethodPost:
+		s.create(w, r, c)
+	case id != "" && r.Method == http.MethodGet:
+		s.detail(w, c, id)
+	case id != "" && (r.Method == http.MethodPut || r.Method == http.MethodPatch):
+		s.update(w, r, c, id)
+	case id != "" && r.Method == http.MethodDelete:
+		s.delete(w, c, id)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", r.Method+" is not allowed on "+r.URL.Path, nil)
+	}
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request, c *collection) {
+	query, limit, offset, ok := listParams(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	matches := make([]map[string]interface{}, 0, len(c.entities))
+	for _, entity := range c.entities {
+		if query.matches(entity) {
+			matches = append(matches, copyEntity(entity))
+		}
+	}
+	s.mu.Unlock()
+	query.sort(matches)
+
+	total := len(matches)
+	writeListPage(w, matches[min(offset, total):min(offset+limit, total)], limit, offset, total)
+}
+
+// listParams parses the filter and pagination of a list request. An invalid
+// request is answered with an error and returns false.
+func listParams(w http.ResponseWriter, r *http.Request) (*query, int, int, bool) {
+	query, err := parseFilter(r.URL.Query().Get("filter"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_FILTER", err.Error(), nil)
+		return nil, 0, 0, false
+	}
+	limit, offset := defaultLimit, 0
+	if query.limit != nil {
+		limit = *query.limit
+	}
+	if query.skip != nil {
+		offset = *query.skip
+	}
+	for param, value := range map[string]*int{"limit": &limit, "offset": &offset} {
+		if raw := r.URL.Query().Get(param); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 0 {
+				writeError(w, http.StatusBadRequest, "INVALID_PAGINATION", "invalid "+param+": "+raw, nil)
+				return nil, 0, 0, false
+			}
+			*value = n
+		}
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	return query, limit, offset, true
+}
+
+// writeListPage writes a page of a list response.
+func writeListPage(w http.ResponseWriter, page interface{}, limit, offset, total int) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": page,
+		"meta": map[string]interface{}{
+			"pagination":       map[string]interface{}{"limit": limit, "offset": offset, "totalCount": total},
+			"filterableFields": []string{},
+		},
+	})
+}
+
+// roleAttributeEndpoints maps the role attribute endpoints to the collection
+// whose role attributes they list.
+var roleAttributeEndpoints = map[string]string{
+	"edge-router-role-attributes":   EdgeRouters,
+	"identity-role-attributes":      Identities,
+	"posture-check-role-attributes": PostureChecks,
+	"service-role-attributes":       Services,
+}
+
+// listRoleAttributes serves a role attribute endpoint: the distinct role
+// attributes of the entities of c. Like the controller, its filter and sort
+// address an attribute as id.
+func (s *Server) listRoleAttributes(w http.ResponseWriter, r *http.Request, c *collection) {
+	query, limit, offset, ok := listParams(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	seen := map[string]bool{}
+	for _, entity := range c.entities {
+		attributes, _ := entity["roleAttributes"].([]interface{})
+		for _, attribute := range attributes {
+			if name, ok := attribute.(string); ok {
+				seen[name] = true
+			}
+		}
+	}
+	s.mu.Unlock()
+	matches := make([]map[string]interface{}, 0, len(seen))
+	for name := range seen {
+		attribute := map[string]interface{}{"id": name}
+		if query.matches(attribute) {
+			matches = append(matches, attribute)
+		}
+	}
+	query.sort(matches)
+
+	total := len(matches)
+	page := []string{}
+	for _, attribute := range matches[min(offset, total):min(offset+limit, total)] {
+		page = append(page, attribute["id"].(string))
+	}
+	writeListPage(w, page, limit, offset, total)
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request, c *collection) {
+	entity, ok := decodeEntity(w, r)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if apiErr := s.validate(c, "", entity); apiErr != nil {
+		writeAPIError(w, apiErr)
+		return
+	}
+	id := newID()
+	now := timestamp()
+	entity["id"] = id
+	entity["createdAt"] = now
+	entity["updatedAt"] = now
+	if _, ok := entity["tags"]; !ok {
+		entity["tags"] = map[string]interface{}{}
+	}
+	if c.onCreate != nil {
+		if apiErr := c.onCreate(s, entity); apiErr != nil {
+			writeAPIError(w, apiErr)
+			return
+		}
+	}
+	c.entities[id] = entity
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"data": map[string]interface{}{"id": id, "_links": map[string]interface{}{}},
+		"meta": map[string]interface{}{},
+	})
+}
+
+func (s *Server) detail(w http.ResponseWriter, c *collection, id string) {
+	s.mu.Lock()
+	entity, ok := c.entities[id]
+	if ok {
+		entity = copyEntity(entity)
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": entity, "meta": map[string]interface{}{}})
+}
+
+// update replaces (PUT) or merges into (PATCH) the entity id.
+func (s *Server) update(w http.ResponseWriter, r *http.Request, c *collection, id string) {
+	body, ok := decodeEntity(w, r)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := c.entities[id]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	entity := body
+	if r.Method == http.MethodPatch {
+		entity = copyEntity(old)
+		for key, value := range body {
+			entity[key] = value
+		}
+	}
+	if apiErr := s.validate(c, id, entity); apiErr != nil {
+		writeAPIError(w, apiErr)
+		return
+	}
+	entity["id"] = id
+	entity["createdAt"] = old["createdAt"]
+	entity["updatedAt"] = timestamp()
+	if _, ok := entity["tags"]; !ok {
+		entity["tags"] = map[string]interface{}{}
+	}
+	if c.onUpdate != nil {
+		if apiErr := c.onUpdate(s, old, entity); apiErr != nil {
+			writeAPIError(w, apiErr)
+			return
+		}
+	}
+	c.entities[id] = entity
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{}, "meta": map[string]interface{}{}})
+}
+
+func (s *Server) delete(w http.ResponseWriter, c *collection, id string) {
+	s.mu.Lock()
+	_, ok := c.entities[id]
+	delete(c.entities, id)
+	s.mu.Unlock()
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{}, "meta": map[string]interface{}{}})
+}
+
+// validate checks the name of entity, which must be set and unique within its
+// collection. id is the ID of an updated entity, empty on create.
+func (s *Server) validate(c *collection, id string, entity map[string]interface{}) *apiError {
+	name, _ := entity["name"].(string)
+	if name == "" {
+		return invalidField("name", "is required", nil)
+	}
+	for otherID, other := range c.entities {
+		if otherID != id && other["name"] == name {
+			return invalidField("name", "duplicate value '"+name+"' in unique index on "+c.kind+" store", name)
+		}
+	}
+	return nil
+}
+
+func decodeEntity(w http.ResponseWriter, r *http.Request) (map[string]interface{}, bool) {
+	var entity map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&entity); err != nil || entity == nil {
+		message := "the body is not a JSON object"
+		if err != nil {
+			message = err.Error()
+		}
+		writeError(w, http.StatusBadRequest, "COULD_NOT_PARSE_BODY", message, nil)
+		return nil, false
+	}
+	return entity, true
+}
+
+func writeAPIError(w http.ResponseWriter, apiErr *apiError) {
+	writeError(w, apiErr.status, apiErr.code, apiErr.message, apiErr.cause)
+}
+
+func writeNotFound(w http.ResponseWriter) {
+	writeError(w, http.StatusNotFound, "NOT_FOUND", "The resource requested was not found or is no longer available", nil)
+}
+
+// keepFields returns an onUpdate hook that carries the named server owned
+// fields over from the old entity.
+func keepFields(fields ...string) func(s *Server, old, entity map[string]interface{}) *apiError {
+	return func(_ *Server, old, entity map[string]interface{}) *apiError {
+		for _, field := range fields {
+			if value, ok := old[field]; ok {
+				entity[field] = value
+			} else {
+				delete(entity, field)
+			}
+		}
+		return nil
+	}
+}
+
+func createCA(_ *Server, entity map[string]interface{}) *apiError {
+	if certPem, _ := entity["certPem"].(string); certPem == "" {
+		return invalidField("certPem", "is required", nil)
+	}
+	entity["fingerprint"] = newID()
+	entity["isVerified"] = false
+	entity["verificationToken"] = newID()
+	return nil
+}
+
+func createConfig(s *Server, entity map[string]interface{}) *apiError {
+	typeID, _ := entity["configTypeId"].(string)
+	configType, ok := s.store[ConfigTypes].entities[typeID]
+	if !ok {
+		return invalidField("configTypeId", "is not a valid config type", typeID)
+	}
+	entity["configType"] = map[string]interface{}{"id": typeID, "name": configType["name"], "entity": "config-types"}
+	if _, ok := entity["data"].(map[string]interface{}); !ok {
+		return invalidField("data", "is required", nil)
+	}
+	return nil
+}
+
+func createEdgeRouter(s *Server, entity map[string]interface{}) *apiError {
+	setDefault(entity, "cost", 0)
+	setDefault(entity, "noTraversal", false)
+	setDefault(entity, "isTunnelerEnabled", false)
+	setDefault(entity, "roleAttributes", []interface{}{})
+	setDefault(entity, "appData", map[string]interface{}{})
+	token := newID()
+	expiresAt := time.Now().Add(24 * time.Hour)
+	entity["enrollmentToken"] = token
+	entity["enrollmentJwt"] = s.issueJWT(entity["id"].(string), token, "erott", expiresAt)
+	entity["enrollmentCreatedAt"] = entity["createdAt"]
+	entity["enrollmentExpiresAt"] = expiresAt.UTC().Format(time.RFC3339Nano)
+	entity["isVerified"] = false
+	entity["isOnline"] = false
+	return nil
+}
+
+func createIdentity(s *Server, entity map[string]interface{}) *apiError {
+	typeName, _ := entity["type"].(string)
+	switch typeName {
+	case "User", "Device", "Default", "Router", "Service":
+	default:
+		return invalidField("type", "must be one of User, Device, Default, Router, Service", typeName)
+	}
+	entity["typeId"] = typeName
+	entity["type"] = map[string]interface{}{"id": typeName, "name": typeName, "entity": "identity-types"}
+	setDefault(entity, "authPolicyId", DefaultAuthPolicyID)
+	setDefault(entity, "isAdmin", false)
+	setDefault(entity, "defaultHostingCost", 0)
+	setDefault(entity, "defaultHostingPrecedence", "default")
+	setDefault(entity, "roleAttributes", []interface{}{})
+	setDefault(entity, "appData", map[string]interface{}{})
+	setDefault(entity, "serviceHostingCosts", map[string]interface{}{})
+	setDefault(entity, "serviceHostingPrecedences", map[string]interface{}{})
+	entity["hasApiSession"] = false
+	entity["disabled"] = false
+	entity["authenticators"] = map[string]interface{}{}
+
+	request, _ := entity["enrollment"].(map[string]interface{})
+	enrollment := map[string]interface{}{}
+	expiresAt := time.Now().Add(24 * time.Hour)
+	newEnrollment := func(method string) map[string]interface{} {
+		token := newID()
+		return map[string]interface{}{
+			"id":        newID(),
+			"token":     token,
+			"jwt":       s.issueJWT(entity["id"].(string), token, method, expiresAt),
+			"expiresAt": expiresAt.UTC().Format(time.RFC3339Nano),
+		}
+	}
+	if ott, _ := request["ott"].(bool); ott {
+		enrollment["ott"] = newEnrollment("ott")
+	}
+	if caID, _ := request["ottca"].(string); caID != "" {
+		if _, ok := s.store[CAs].entities[caID]; !ok {
+			return invalidField("enrollment.ottca", "is not a valid CA", caID)
+		}
+		ottca := newEnrollment("ottca")
+		ottca["caId"] = caID
+		enrollment["ottca"] = ottca
+	}
+	if username, _ := request["updb"].(string); username != "" {
+		updb := newEnrollment("updb")
+		updb["username"] = username
+		enrollment["updb"] = updb
+	}
+	entity["enrollment"] = enrollment
+	return nil
+}
+
+func updateIdentity(s *Server, old, entity map[string]interface{}) *apiError {
+	if typeName, ok := entity["type"].(string); ok {
+		entity["typeId"] = typeName
+		entity["type"] = map[string]interface{}{"id": typeName, "name": typeName, "entity": "identity-types"}
+	}
+	return keepFields("enrollment", "hasApiSession", "disabled", "authenticators")(s, old, entity)
+}
+
+func createPostureCheck(_ *Server, entity map[string]interface{}) *apiError {
+	switch typeID, _ := entity["typeId"].(string); typeID {
+	case "DOMAIN", "MAC", "MFA", "OS", "PROCESS", "PROCESS_MULTI":
+	default:
+		return invalidField("typeId", "must be one of DOMAIN, MAC, MFA, OS, PROCESS, PROCESS_MULTI", typeID)
+	}
+	setDefault(entity, "roleAttributes", []interface{}{})
+	entity["version"] = 1
+	return nil
+}
+
+func createService(_ *Server, entity map[string]interface{}) *apiError {
+	setDefault(entity, "encryptionRequired", true)
+	setDefault(entity, "terminatorStrategy", "smartrouting")
+	setDefault(entity, "maxIdleTimeMillis", 0)
+	setDefault(entity, "configs", []interface{}{})
+	setDefault(entity, "roleAttributes", []interface{}{})
+	return nil
+}
+
+// updateService applies the create defaults again, as the controller does
+// when a PUT leaves a field out, e.g. a zero maxIdleTimeMillis.
+func updateService(s *Server, _, entity map[string]interface{}) *apiError {
+	return createService(s, entity)
+}
+
+func setDefault(entity map[string]interface{}, field string, value interface{}) {
+	if v, ok := entity[field]; !ok || v == nil {
+		entity[field] = value
+	}
+}
+
+func timestamp() string {
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
+
+// copyEntity deep copies an entity so callers cannot alias the store.
+func copyEntity(entity map[string]interface{}) map[string]interface{} {
+	encoded, _ := json.Marshal(entity)
+	var copied map[string]interface{}
+	_ = json.Unmarshal(encoded, &copied)
+	return copied
+}
+
+// Entity returns a copy of the entity id of collection, e.g. Identities.
+func (s *Server) Entity(collection, id string) (map[string]interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.store[collection]
+	if !ok {
+		return nil, false
+	}
+	entity, ok := c.entities[id]
+	if !ok {
+		return nil, false
+	}
+	return copyEntity(entity), true
+}
+
+// EntityIDByName returns the ID of the entity of collection named name.
+func (s *Server) EntityIDByName(collection, name string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.store[collection]
+	if !ok {
+		return "", false
+	}
+	for id, entity := range c.entities {
+		if entity["name"] == name {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// EntityCount returns the number of entities in collection.
+func (s *Server) EntityCount(collection string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.store[collection]
+	if !ok {
+		return 0
+	}
+	return len(c.entities)
+}
+
+// PatchEntity sets fields of the entity id behind the provider's back, to
+// simulate drift. It reports whether the entity exists.
+func (s *Server) PatchEntity(collection, id string, fields map[string]interface{}) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.store[collection]
+	if !ok {
+		return false
+	}
+	entity, ok := c.entities[id]
+	if !ok {
+		return false
+	}
+	for key, value := range copyEntity(fields) {
+		entity[key] = value
+	}
+	entity["updatedAt"] = timestamp()
+	return true
+}
+
+// DeleteEntity deletes the entity id behind the provider's back. It reports
+// whether the entity existed.
+func (s *Server) DeleteEntity(collection, id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.store[collection]
+	if !ok {
+		return false
+	}
+	_, ok = c.entities[id]
+	delete(c.entities, id)
+	return ok
+}
+
+// sortEntities orders entities by creation, then ID, for stable pages.
+func sortEntities(entities []map[string]interface{}) {
+	sort.SliceStable(entities, func(i, j int) bool {
+		ci, _ := entities[i]["createdAt"].(string)
+		cj, _ := entities[j]["createdAt"].(string)
+		if ci != cj {
+			return ci < cj
+		}
+		ii, _ := entities[i]["id"].(string)
+		ij, _ := entities[j]["id"].(string)
+		return ii < ij
+	})
+}
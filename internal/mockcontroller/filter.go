@@ -0,0 +1,590 @@
+package mockcontroller
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// query is a parsed controller filter, e.g.
+// `name contains "web" and anyOf(roleAttributes) = "edge" sort by name limit 5`.
+//
+// It implements the parts of the controller filter language the provider
+// uses: and, or, not, parentheses, the comparison, in, contains, startsWith,
+// endsWith and null operators, anyOf, allOf and isEmpty, and the sort by, skip
+// and limit clauses.
+type query struct {
+	predicate predicate
+	sortBy    []sortField
+	skip      *int
+	limit     *int
+}
+
+type sortField struct {
+	field string
+	desc  bool
+}
+
+type predicate func(entity map[string]interface{}) bool
+
+func (q *query) matches(entity map[string]interface{}) bool {
+	return q.predicate == nil || q.predicate(entity)
+}
+
+// sort orders entities by the sort by clause, or by creation without one.
+func (q *query) sort(entities []map[string]interface{}) {
+	sortEntities(entities)
+	if len(q.sortBy) == 0 {
+		return
+	}
+	sort.SliceStable(entities, func(i, j int) bool {
+		for _, f := range q.sortBy {
+			c := compareValues(lookup(entities[i], f.field), lookup(entities[j], f.field))
+			if c == 0 {
+				continue
+			}
+			if f.desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOp
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// value is the unquoted string or parsed number of a literal.
+	value interface{}
+}
+
+func tokenize(filter string) ([]token, error) {
+	var tokens []token
+	runes := []rune(filter)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"':
+			var b strings.Builder
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated string in filter: %s", filter)
+				}
+				if runes[i] == '\\' && i+1 < len(runes) {
+					n, err := unescape(&b, runes[i+1:])
+					if err != nil {
+						return nil, fmt.Errorf("%v in filter: %s", err, filter)
+					}
+					i += 1 + n
+					continue
+				}
+				if runes[i] == '"' {
+					i++
+					break
+				}
+				b.WriteRune(runes[i])
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenString, text: b.String(), value: b.String()})
+		case r == '(' || r == ')' || r == '[' || r == ']' || r == ',':
+			tokens = append(tokens, token{kind: tokenPunct, text: string(r)})
+			i++
+		case r == '=' || r == '!' || r == '<' || r == '>':
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("unexpected ! in filter: %s", filter)
+			}
+			tokens = append(tokens, token{kind: tokenOp, text: op})
+			i += len(op)
+		case r == '-' || unicode.IsDigit(r):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			n, err := strconv.ParseFloat(string(runes[start:i]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q in filter", string(runes[start:i]))
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:i]), value: n})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:i])})
+		default:
+			return nil, fmt.Errorf("unexpected %q in filter: %s", r, filter)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF}), nil
+}
+
+// unescape writes the character of the escape sequence that follows a
+// backslash to b and returns the length of the sequence. The escapes are those
+// of JSON strings.
+func unescape(b *strings.Builder, escape []rune) (int, error) {
+	switch escape[0] {
+	case 'n':
+		b.WriteRune('\n')
+	case 'r':
+		b.WriteRune('\r')
+	case 't':
+		b.WriteRune('\t')
+	case 'b':
+		b.WriteRune('\b')
+	case 'f':
+		b.WriteRune('\f')
+	case 'u':
+		if len(escape) < 5 {
+			return 0, fmt.Errorf("invalid escape \\%s", string(escape))
+		}
+		code, err := strconv.ParseUint(string(escape[1:5]), 16, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid escape \\%s", string(escape[:5]))
+		}
+		b.WriteRune(rune(code))
+		return 5, nil
+	case '"', '\\', '/':
+		b.WriteRune(escape[0])
+	default:
+		return 0, fmt.Errorf("invalid escape \\%c", escape[0])
+	}
+	return 1, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// keyword reports whether the next token is the case insensitive keyword kw,
+// and consumes it if so.
+func (p *parser) keyword(kw string) bool {
+	t := p.peek()
+	if t.kind == tokenIdent && strings.EqualFold(t.text, kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) punct(s string) bool {
+	t := p.peek()
+	if t.kind == tokenPunct && t.text == s {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(s string) error {
+	if !p.punct(s) {
+		return fmt.Errorf("expected %q, found %q", s, p.peek().text)
+	}
+	return nil
+}
+
+// parseFilter parses a controller filter. An empty filter matches everything.
+func parseFilter(filter string) (*query, error) {
+	tokens, err := tokenize(filter)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	q := &query{}
+	if t := p.peek(); t.kind != tokenEOF && !isClauseKeyword(t) {
+		if q.predicate, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+	}
+	for p.peek().kind != tokenEOF {
+		switch {
+		case p.keyword("sort"):
+			if !p.keyword("by") {
+				return nil, fmt.Errorf("expected \"by\" after \"sort\"")
+			}
+			for {
+				field := p.next()
+				if field.kind != tokenIdent {
+					return nil, fmt.Errorf("expected a sort field, found %q", field.text)
+				}
+				f := sortField{field: field.text}
+				if p.keyword("desc") {
+					f.desc = true
+				} else {
+					p.keyword("asc")
+				}
+				q.sortBy = append(q.sortBy, f)
+				if !p.punct(",") {
+					break
+				}
+			}
+		case p.keyword("skip"):
+			n, err := p.parseCount()
+			if err != nil {
+				return nil, err
+			}
+			q.skip = &n
+		case p.keyword("limit"):
+			if p.keyword("none") {
+				n := maxLimit
+				q.limit = &n
+				continue
+			}
+			n, err := p.parseCount()
+			if err != nil {
+				return nil, err
+			}
+			q.limit = &n
+		default:
+			return nil, fmt.Errorf("unexpected %q in filter: %s", p.peek().text, filter)
+		}
+	}
+	return q, nil
+}
+
+func isClauseKeyword(t token) bool {
+	if t.kind != tokenIdent {
+		return false
+	}
+	for _, kw := range []string{"sort", "skip", "limit"} {
+		if strings.EqualFold(t.text, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parseCount() (int, error) {
+	t := p.next()
+	n, ok := t.value.(float64)
+	if t.kind != tokenNumber || !ok || n < 0 || n != float64(int(n)) {
+		return 0, fmt.Errorf("expected a count, found %q", t.text)
+	}
+	return int(n), nil
+}
+
+func (p *parser) parseOr() (predicate, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(e map[string]interface{}) bool { return l(e) || right(e) }
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (predicate, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(e map[string]interface{}) bool { return l(e) && right(e) }
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (predicate, error) {
+	switch {
+	case p.keyword("not"):
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(e map[string]interface{}) bool { return !inner(e) }, nil
+	case p.punct("("):
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expect(")")
+	case p.keyword("true"):
+		return func(map[string]interface{}) bool { return true }, nil
+	case p.keyword("false"):
+		return func(map[string]interface{}) bool { return false }, nil
+	case p.keyword("isEmpty"):
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		field := p.next()
+		if field.kind != tokenIdent {
+			return nil, fmt.Errorf("expected a field, found %q", field.text)
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return func(e map[string]interface{}) bool {
+			values, _ := lookup(e, field.text).([]interface{})
+			return len(values) == 0
+		}, nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison parses `field op value`, where field may be wrapped in
+// anyOf or allOf to compare the elements of a list.
+func (p *parser) parseComparison() (predicate, error) {
+	set := ""
+	if t := p.peek(); t.kind == tokenIdent && (strings.EqualFold(t.text, "anyOf") || strings.EqualFold(t.text, "allOf")) {
+		set = strings.ToLower(p.next().text)
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+	}
+	field := p.next()
+	if field.kind != tokenIdent {
+		return nil, fmt.Errorf("expected a field, found %q", field.text)
+	}
+	if set != "" {
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	}
+
+	negate := false
+	var op string
+	switch t := p.next(); {
+	case t.kind == tokenOp:
+		op = t.text
+	case t.kind == tokenIdent && strings.EqualFold(t.text, "is"):
+		negate = p.keyword("not")
+		if !p.keyword("null") {
+			return nil, fmt.Errorf("expected \"null\" after \"is\"")
+		}
+		op = "null"
+	case t.kind == tokenIdent && strings.EqualFold(t.text, "not"):
+		negate = true
+		op = strings.ToLower(p.next().text)
+	case t.kind == tokenIdent:
+		op = strings.ToLower(t.text)
+	default:
+		return nil, fmt.Errorf("expected an operator after %q", field.text)
+	}
+
+	var compare func(v interface{}) bool
+	switch op {
+	case "null":
+		compare = func(v interface{}) bool { return v == nil }
+	case "in":
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		compare = func(v interface{}) bool {
+			for _, value := range values {
+				if compareValues(v, value) == 0 {
+					return true
+				}
+			}
+			return false
+		}
+	default:
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if compare, err = comparison(op, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(e map[string]interface{}) bool {
+		v := lookup(e, field.text)
+		var result bool
+		switch set {
+		case "anyof":
+			values, _ := v.([]interface{})
+			for _, element := range values {
+				if compare(element) {
+					result = true
+					break
+				}
+			}
+		case "allof":
+			values, _ := v.([]interface{})
+			result = true
+			for _, element := range values {
+				if !compare(element) {
+					result = false
+					break
+				}
+			}
+		default:
+			result = compare(v)
+		}
+		return result != negate
+	}, nil
+}
+
+func comparison(op string, value interface{}) (func(v interface{}) bool, error) {
+	text, isText := value.(string)
+	switch op {
+	case "=":
+		return func(v interface{}) bool { return compareValues(v, value) == 0 }, nil
+	case "!=":
+		return func(v interface{}) bool { return compareValues(v, value) != 0 }, nil
+	case "<":
+		return func(v interface{}) bool { return v != nil && compareValues(v, value) < 0 }, nil
+	case "<=":
+		return func(v interface{}) bool { return v != nil && compareValues(v, value) <= 0 }, nil
+	case ">":
+		return func(v interface{}) bool { return v != nil && compareValues(v, value) > 0 }, nil
+	case ">=":
+		return func(v interface{}) bool { return v != nil && compareValues(v, value) >= 0 }, nil
+	}
+	if !isText {
+		return nil, fmt.Errorf("%s needs a string value", op)
+	}
+	switch op {
+	case "contains":
+		return stringComparison(func(s string) bool { return strings.Contains(s, text) }), nil
+	case "icontains":
+		return stringComparison(func(s string) bool { return strings.Contains(strings.ToLower(s), strings.ToLower(text)) }), nil
+	case "startswith":
+		return stringComparison(func(s string) bool { return strings.HasPrefix(s, text) }), nil
+	case "endswith":
+		return stringComparison(func(s string) bool { return strings.HasSuffix(s, text) }), nil
+	}
+	return nil, fmt.Errorf("unknown operator %q", op)
+}
+
+func stringComparison(f func(s string) bool) func(v interface{}) bool {
+	return func(v interface{}) bool {
+		s, ok := v.(string)
+		return ok && f(s)
+	}
+}
+
+func (p *parser) parseList() ([]interface{}, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	var values []interface{}
+	if p.punct("]") {
+		return values, nil
+	}
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		if p.punct("]") {
+			return values, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseValue() (interface{}, error) {
+	t := p.next()
+	switch {
+	case t.kind == tokenString || t.kind == tokenNumber:
+		return t.value, nil
+	case t.kind == tokenIdent && strings.EqualFold(t.text, "true"):
+		return true, nil
+	case t.kind == tokenIdent && strings.EqualFold(t.text, "false"):
+		return false, nil
+	case t.kind == tokenIdent && strings.EqualFold(t.text, "null"):
+		return nil, nil
+	}
+	return nil, fmt.Errorf("expected a value, found %q", t.text)
+}
+
+// lookup resolves a dotted field, e.g. "tags.env", in an entity.
+func lookup(entity map[string]interface{}, field string) interface{} {
+	var v interface{} = entity
+	for _, part := range strings.Split(field, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[part]
+	}
+	return v
+}
+
+// compareValues orders two JSON values of the same type; values of different
+// types compare unequal.
+func compareValues(a, b interface{}) int {
+	switch av := a.(type) {
+	case string:
+		if bv, ok := b.(string); ok {
+			return strings.Compare(av, bv)
+		}
+	case float64:
+		if bv, ok := b.(float64); ok {
+			switch {
+			case av < bv:
+				return -1
+			case av > bv:
+				return 1
+			}
+			return 0
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			switch {
+			case av == bv:
+				return 0
+			case !av:
+				return -1
+			}
+			return 1
+		}
+	case nil:
+		if b == nil {
+			return 0
+		}
+		return -1
+	}
+	if b == nil {
+		return 1
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
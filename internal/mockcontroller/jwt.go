@@ -0,0 +1,54 @@
+package mockcontroller
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+// EnrollmentClaims are the claims of an enrollment JWT.
+type EnrollmentClaims struct {
+	Issuer    string `json:"iss"`
+	Subject   string `json:"sub"`
+	Audience  string `json:"aud,omitempty"`
+	ExpiresAt int64  `json:"exp"`
+	ID        string `json:"jti"`
+	// Method is the enrollment method, e.g. "ott" or "erott".
+	Method string `json:"em"`
+	// Controllers are the controller URLs the enrollment may be completed at.
+	Controllers []string `json:"ctrls,omitempty"`
+}
+
+// issueJWT issues an ES256 enrollment JWT for the entity subject, signed with
+// the enrollment signing key of the server.
+func (s *Server) issueJWT(subject, token, method string, expiresAt time.Time) string {
+	claims := EnrollmentClaims{
+		Issuer:      s.URL,
+		Subject:     subject,
+		ExpiresAt:   expiresAt.Unix(),
+		ID:          token,
+		Method:      method,
+		Controllers: []string{s.URL},
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "ES256", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	r, sig, err := ecdsa.Sign(rand.Reader, s.signer, digest[:])
+	if err != nil {
+		return ""
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	sig.FillBytes(signature[32:])
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// EnrollmentSigner returns the public key enrollment JWTs are signed with.
+func (s *Server) EnrollmentSigner() *ecdsa.PublicKey {
+	return &s.signer.PublicKey
+}
@@ -0,0 +1,280 @@
+// Package mockcontroller implements an in-memory fake of the Ziti controller
+// for tests. It serves the authentication, management and cluster endpoints
+// the provider uses, so resources and data sources can be exercised offline.
+package mockcontroller
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.mozilla.org/pkcs7"
+)
+
+const (
+	// ManagementPath is the base path of the edge management API.
+	ManagementPath = "/edge/management/v1"
+	// ClusterMembersPath lists the members of the controller cluster.
+	ClusterMembersPath = "/fabric/v1/cluster/list-members"
+	// CACertsPath serves the controller CA bundle as base64 encoded PKCS#7.
+	CACertsPath = "/.well-known/est/cacerts"
+
+	// DefaultUsername and DefaultPassword are accepted by password
+	// authentication unless WithCredentials is used.
+	DefaultUsername = "admin"
+	DefaultPassword = "admin"
+)
+
+// ClusterMember is a member reported by the cluster list-members endpoint.
+type ClusterMember struct {
+	Address   string `json:"address"`
+	Connected bool   `json:"connected"`
+	ID        string `json:"id"`
+	Leader    bool   `json:"leader"`
+	ReadOnly  bool   `json:"readOnly"`
+	Version   string `json:"version"`
+	Voter     bool   `json:"voter"`
+}
+
+// Server is a fake Ziti controller backed by an in-memory store.
+type Server struct {
+	*httptest.Server
+
+	username string
+	password string
+	extJWT   string
+	tls      bool
+
+	signer *ecdsa.PrivateKey
+	ca     *enrollmentCA
+
+	mu       sync.Mutex
+	sessions map[string]bool
+	store    map[string]*collection
+	members  []ClusterMember
+}
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithCredentials sets the username and password accepted by password
+// authentication.
+func WithCredentials(username, password string) Option {
+	return func(s *Server) {
+		s.username = username
+		s.password = password
+	}
+}
+
+// WithExtJWT accepts jwt as a bearer token for ext-jwt authentication.
+func WithExtJWT(jwt string) Option {
+	return func(s *Server) {
+		s.extJWT = jwt
+	}
+}
+
+// WithTLS serves HTTPS instead of HTTP. The server asks for, but does not
+// require, a client certificate; cert authentication succeeds with any.
+func WithTLS() Option {
+	return func(s *Server) {
+		s.tls = true
+	}
+}
+
+// New starts a fake controller and stops it when the test ends.
+func New(t testing.TB, opts ...Option) *Server {
+	t.Helper()
+	signer, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating the enrollment signing key: %v", err)
+	}
+	ca, err := newEnrollmentCA()
+	if err != nil {
+		t.Fatalf("generating the enrollment CA: %v", err)
+	}
+	s := &Server{
+		username: DefaultUsername,
+		password: DefaultPassword,
+		signer:   signer,
+		ca:       ca,
+		sessions: map[string]bool{},
+		store:    newStore(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.Server = httptest.NewUnstartedServer(http.HandlerFunc(s.serveHTTP))
+	if s.tls {
+		s.Server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+		s.Server.StartTLS()
+	} else {
+		s.Server.Start()
+	}
+	t.Cleanup(s.Close)
+
+	u, _ := url.Parse(s.URL)
+	s.members = []ClusterMember{{Address: "tls:" + u.Host, Connected: true, ID: "ctrl1", Leader: true, Version: "v1.0.0", Voter: true}}
+	return s
+}
+
+// ManagementURL is the host the provider is configured with.
+func (s *Server) ManagementURL() string {
+	return s.URL + ManagementPath
+}
+
+// SetClusterMembers replaces the members reported by the cluster list-members
+// endpoint. A new server reports itself as the only member.
+func (s *Server) SetClusterMembers(members []ClusterMember) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.members = append([]ClusterMember(nil), members...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == CACertsPath:
+		s.serveCACerts(w)
+	case r.URL.Path == EnrollPath:
+		s.enroll(w, r)
+	case r.URL.Path == ManagementPath+"/authenticate":
+		s.authenticate(w, r)
+	case r.URL.Path == ClusterMembersPath:
+		if !s.authorized(w, r) {
+			return
+		}
+		s.mu.Lock()
+		members := append([]ClusterMember(nil), s.members...)
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, map[string]interface{}{"data": members, "meta": map[string]interface{}{}})
+	case strings.HasPrefix(r.URL.Path, ManagementPath+"/"):
+		if !s.authorized(w, r) {
+			return
+		}
+		s.serveEntities(w, r, strings.Trim(strings.TrimPrefix(r.URL.Path, ManagementPath), "/"))
+	default:
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "no such endpoint: "+r.URL.Path, nil)
+	}
+}
+
+// serveCACerts serves the certificate of a TLS server as the controller CA
+// bundle, the way bootstrap_ca fetches it.
+func (s *Server) serveCACerts(w http.ResponseWriter) {
+	if !s.tls {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "the CA bundle is only served over TLS", nil)
+		return
+	}
+	bundle, err := pkcs7.DegenerateCertificate(s.Certificate().Raw)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "UNHANDLED", err.Error(), nil)
+		return
+	}
+	w.Header().Set("Content-Type", "application/pkcs7-mime")
+	_, _ = io.WriteString(w, base64.StdEncoding.EncodeToString(bundle))
+}
+
+func (s *Server) authenticate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", r.Method+" is not allowed", nil)
+		return
+	}
+	ok := false
+	switch r.URL.Query().Get("method") {
+	case "password":
+		var credentials struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&credentials); err != nil {
+			writeError(w, http.StatusBadRequest, "COULD_NOT_PARSE_BODY", err.Error(), nil)
+			return
+		}
+		ok = credentials.Username == s.username && credentials.Password == s.password
+	case "cert":
+		ok = r.TLS != nil && len(r.TLS.PeerCertificates) > 0
+	case "ext-jwt":
+		ok = s.extJWT != "" && r.Header.Get("Authorization") == "Bearer "+s.extJWT
+	}
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "INVALID_AUTH", "The authentication request failed", nil)
+		return
+	}
+
+	token := newID() + newID()
+	s.mu.Lock()
+	s.sessions[token] = true
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": map[string]interface{}{"id": newID(), "token": token},
+		"meta": map[string]interface{}{},
+	})
+}
+
+// authorized reports whether the request carries a session issued by
+// authenticate, and writes a 401 response if it does not.
+func (s *Server) authorized(w http.ResponseWriter, r *http.Request) bool {
+	s.mu.Lock()
+	ok := s.sessions[r.Header.Get("zt-session")]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "The request could not be completed. The session is not authorized or the credentials are invalid", nil)
+	}
+	return ok
+}
+
+// ExpireSessions invalidates every issued session, as a controller restart
+// would.
+func (s *Server) ExpireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions = map[string]bool{}
+}
+
+// apiErrorCause is the cause of a rejected request, e.g. an invalid field.
+type apiErrorCause struct {
+	Field  string      `json:"field,omitempty"`
+	Reason string      `json:"reason,omitempty"`
+	Value  interface{} `json:"value,omitempty"`
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string, cause *apiErrorCause) {
+	apiErr := map[string]interface{}{
+		"code":      code,
+		"message":   message,
+		"requestId": newID(),
+	}
+	if cause != nil {
+		apiErr["cause"] = cause
+	}
+	writeJSON(w, status, map[string]interface{}{"error": apiErr, "meta": map[string]interface{}{}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// newID returns a random ID shaped like the short IDs of the controller.
+func newID() string {
+	const alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("reading random bytes: %v", err))
+	}
+	for i := range b {
+		b[i] = alphabet[int(b[i])%len(alphabet)]
+	}
+	return string(b)
+}
@@ -0,0 +1,373 @@
+package mockcontroller
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// client calls a fake controller with an authenticated session.
+type client struct {
+	t       *testing.T
+	s       *Server
+	session string
+}
+
+func login(t *testing.T, s *Server) *client {
+	t.Helper()
+	c := &client{t: t, s: s}
+	status, body := c.do(http.MethodPost, "/authenticate?method=password", map[string]string{"username": DefaultUsername, "password": DefaultPassword})
+	if status != http.StatusOK {
+		t.Fatalf("authenticate: status %d", status)
+	}
+	c.session = body["data"].(map[string]interface{})["token"].(string)
+	return c
+}
+
+func (c *client) do(method, path string, body interface{}) (int, map[string]interface{}) {
+	c.t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		encoded, _ := json.Marshal(body)
+		reader = bytes.NewReader(encoded)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, _ := http.NewRequest(method, c.s.ManagementURL()+path, reader)
+	req.Header.Set("zt-session", c.session)
+	resp, err := c.s.Client().Do(req)
+	if err != nil {
+		c.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	var decoded map[string]interface{}
+	_ = json.NewDecoder(resp.Body).Decode(&decoded)
+	return resp.StatusCode, decoded
+}
+
+func (c *client) create(path string, body interface{}) string {
+	c.t.Helper()
+	status, created := c.do(http.MethodPost, path, body)
+	if status != http.StatusCreated {
+		c.t.Fatalf("POST %s: status %d: %v", path, status, created)
+	}
+	return created["data"].(map[string]interface{})["id"].(string)
+}
+
+func (c *client) names(filter string) []string {
+	c.t.Helper()
+	status, body := c.do(http.MethodGet, "/services?filter="+url.QueryEscape(filter), nil)
+	if status != http.StatusOK {
+		c.t.Fatalf("list %q: status %d: %v", filter, status, body)
+	}
+	names := []string{}
+	for _, item := range body["data"].([]interface{}) {
+		names = append(names, item.(map[string]interface{})["name"].(string))
+	}
+	return names
+}
+
+func TestAuthentication(t *testing.T) {
+	s := New(t, WithCredentials("user", "secret"), WithExtJWT("oidc-token"))
+	c := &client{t: t, s: s}
+
+	if status, _ := c.do(http.MethodPost, "/authenticate?method=password", map[string]string{"username": "user", "password": "wrong"}); status != http.StatusUnauthorized {
+		t.Errorf("wrong password: got status %d, want 401", status)
+	}
+	if status, _ := c.do(http.MethodGet, "/identities", nil); status != http.StatusUnauthorized {
+		t.Errorf("no session: got status %d, want 401", status)
+	}
+	if status, _ := c.do(http.MethodPost, "/authenticate?method=password", map[string]string{"username": "user", "password": "secret"}); status != http.StatusOK {
+		t.Errorf("password: got status %d, want 200", status)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, s.ManagementURL()+"/authenticate?method=ext-jwt", bytes.NewBufferString("{}"))
+	req.Header.Set("Authorization", "Bearer oidc-token")
+	resp, err := s.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("ext-jwt: got status %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestCRUD(t *testing.T) {
+	s := New(t)
+	c := login(t, s)
+
+	id := c.create("/identities", map[string]interface{}{
+		"name": "alice", "type": "User", "isAdmin": false,
+		"enrollment": map[string]interface{}{"ott": true},
+	})
+	status, detail := c.do(http.MethodGet, "/identities/"+id, nil)
+	if status != http.StatusOK {
+		t.Fatalf("GET identity: status %d", status)
+	}
+	data := detail["data"].(map[string]interface{})
+	if data["type"].(map[string]interface{})["name"] != "User" {
+		t.Errorf("type = %v, want User", data["type"])
+	}
+	jwt, _ := data["enrollment"].(map[string]interface{})["ott"].(map[string]interface{})["jwt"].(string)
+	if jwt == "" {
+		t.Error("the identity has no enrollment JWT")
+	}
+
+	if status, _ := c.do(http.MethodPatch, "/identities/"+id, map[string]interface{}{"name": "alice", "roleAttributes": []string{"dev"}}); status != http.StatusOK {
+		t.Fatalf("PATCH identity: status %d", status)
+	}
+	entity, _ := s.Entity(Identities, id)
+	if entity["type"].(map[string]interface{})["name"] != "User" || len(entity["roleAttributes"].([]interface{})) != 1 {
+		t.Errorf("PATCH did not merge: %v", entity)
+	}
+
+	if status, body := c.do(http.MethodPost, "/identities", map[string]interface{}{"name": "alice", "type": "User"}); status != http.StatusBadRequest {
+		t.Errorf("duplicate name: got status %d, want 400", status)
+	} else if cause := body["error"].(map[string]interface{})["cause"].(map[string]interface{}); cause["field"] != "name" {
+		t.Errorf("duplicate name: cause = %v, want the name field", cause)
+	}
+
+	if status, _ := c.do(http.MethodDelete, "/identities/"+id, nil); status != http.StatusOK {
+		t.Fatalf("DELETE identity: status %d", status)
+	}
+	if status, _ := c.do(http.MethodGet, "/identities/"+id, nil); status != http.StatusNotFound {
+		t.Errorf("GET deleted identity: got status %d, want 404", status)
+	}
+}
+
+func TestOutOfBandChanges(t *testing.T) {
+	s := New(t)
+	c := login(t, s)
+	id := c.create("/services", map[string]interface{}{"name": "web"})
+
+	if !s.PatchEntity(Services, id, map[string]interface{}{"encryptionRequired": false}) {
+		t.Fatal("PatchEntity: the service does not exist")
+	}
+	_, detail := c.do(http.MethodGet, "/services/"+id, nil)
+	if detail["data"].(map[string]interface{})["encryptionRequired"] != false {
+		t.Error("PatchEntity did not change the service")
+	}
+
+	if !s.DeleteEntity(Services, id) {
+		t.Fatal("DeleteEntity: the service does not exist")
+	}
+	if status, _ := c.do(http.MethodGet, "/services/"+id, nil); status != http.StatusNotFound {
+		t.Errorf("GET deleted service: got status %d, want 404", status)
+	}
+}
+
+func TestListFilter(t *testing.T) {
+	s := New(t)
+	c := login(t, s)
+	for _, svc := range []map[string]interface{}{
+		{"name": "web-a", "roleAttributes": []string{"web", "prod"}, "tags": map[string]interface{}{"env": "prod"}},
+		{"name": "web-b", "roleAttributes": []string{"web"}, "tags": map[string]interface{}{"env": "dev"}},
+		{"name": "db \"main\"", "roleAttributes": []string{"db"}},
+		{"name": "tab\there"},
+	} {
+		c.create("/services", svc)
+	}
+
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{`name="web-a"`, []string{"web-a"}},
+		{`name = "db \"main\""`, []string{`db "main"`}},
+		{`name startsWith "web" sort by name desc`, []string{"web-b", "web-a"}},
+		{`name contains "eb" and tags.env = "dev"`, []string{"web-b"}},
+		{`anyOf(roleAttributes) = "prod" or name = "db \"main\""`, []string{"web-a", `db "main"`}},
+		{`name in ["web-b", "nope"]`, []string{"web-b"}},
+		{`name = "tab\there" or name = "\u0074ab\u0009here"`, []string{"tab\there"}},
+		{`not (name startsWith "web") and name != "tab\there"`, []string{`db "main"`}},
+		{`true sort by name limit 1 skip 2`, []string{"web-a"}},
+	}
+	for _, tt := range tests {
+		got := c.names(tt.filter)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.filter, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: got %v, want %v", tt.filter, got, tt.want)
+				break
+			}
+		}
+	}
+
+	if status, _ := c.do(http.MethodGet, "/services?filter="+url.QueryEscape(`name ~ "x"`), nil); status != http.StatusBadRequest {
+		t.Errorf("invalid filter: got status %d, want 400", status)
+	}
+}
+
+func TestPagination(t *testing.T) {
+	s := New(t)
+	c := login(t, s)
+	for _, name := range []string{"a", "b", "c"} {
+		c.create("/services", map[string]interface{}{"name": name})
+	}
+	status, body := c.do(http.MethodGet, "/services?limit=2&offset=2", nil)
+	if status != http.StatusOK {
+		t.Fatalf("list: status %d", status)
+	}
+	if n := len(body["data"].([]interface{})); n != 1 {
+		t.Errorf("got %d services, want 1", n)
+	}
+	pagination := body["meta"].(map[string]interface{})["pagination"].(map[string]interface{})
+	if pagination["totalCount"] != float64(3) {
+		t.Errorf("totalCount = %v, want 3", pagination["totalCount"])
+	}
+}
+
+func TestClusterMembers(t *testing.T) {
+	s := New(t)
+	c := login(t, s)
+	s.SetClusterMembers([]ClusterMember{{ID: "a", Address: "tls:a:443", Leader: true, Connected: true}, {ID: "b", Address: "tls:b:443", Connected: true}})
+
+	req, _ := http.NewRequest(http.MethodGet, s.URL+ClusterMembersPath, nil)
+	req.Header.Set("zt-session", c.session)
+	resp, err := s.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var body struct {
+		Data []ClusterMember `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Data) != 2 || !body.Data[0].Leader {
+		t.Errorf("members = %+v", body.Data)
+	}
+}
+
+func TestRoleAttributes(t *testing.T) {
+	s := New(t)
+	c := login(t, s)
+	c.create("/services", map[string]interface{}{"name": "a", "roleAttributes": []string{"web", "prod"}})
+	c.create("/services", map[string]interface{}{"name": "b", "roleAttributes": []string{"web", "dev"}})
+	c.create("/identities", map[string]interface{}{"name": "c", "type": "User", "isAdmin": false, "roleAttributes": []string{"clients"}})
+
+	for _, tt := range []struct {
+		path string
+		want []string
+	}{
+		{"/service-role-attributes", []string{"dev", "prod", "web"}},
+		{"/service-role-attributes?filter=" + url.QueryEscape(`id contains "e" sort by id desc`), []string{"web", "dev"}},
+		{"/service-role-attributes?limit=1&offset=1", []string{"prod"}},
+		{"/identity-role-attributes", []string{"clients"}},
+		{"/edge-router-role-attributes", []string{}},
+	} {
+		status, body := c.do(http.MethodGet, tt.path, nil)
+		if status != http.StatusOK {
+			t.Fatalf("GET %s: status %d: %v", tt.path, status, body)
+		}
+		got := []string{}
+		for _, attribute := range body["data"].([]interface{}) {
+			got = append(got, attribute.(string))
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("GET %s: got %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestEnroll(t *testing.T) {
+	s := New(t)
+	c := login(t, s)
+	id := c.create("/identities", map[string]interface{}{"name": "device", "type": "Device", "isAdmin": false, "enrollment": map[string]interface{}{"ott": true}})
+	entity, _ := s.Entity(Identities, id)
+	token := entity["enrollment"].(map[string]interface{})["ott"].(map[string]interface{})["token"].(string)
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: pkix.Name{CommonName: "device"}}, key)
+	csr := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+	enroll := func(token string) (int, map[string]interface{}) {
+		req, _ := http.NewRequest(http.MethodPost, s.URL+EnrollPath+"?method=ott&token="+url.QueryEscape(token), bytes.NewReader(csr))
+		req.Header.Set("Content-Type", "application/x-pem-file")
+		req.Header.Set("Accept", "application/json")
+		resp, err := s.Client().Do(req)
+		if err != nil {
+			t.Fatalf("enroll: %v", err)
+		}
+		defer resp.Body.Close()
+		var decoded map[string]interface{}
+		_ = json.NewDecoder(resp.Body).Decode(&decoded)
+		return resp.StatusCode, decoded
+	}
+
+	status, body := enroll(token)
+	if status != http.StatusOK {
+		t.Fatalf("enroll: status %d: %v", status, body)
+	}
+	block, _ := pem.Decode([]byte(body["data"].(map[string]interface{})["cert"].(string)))
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("parsing the enrolled certificate: %v", err)
+	}
+	if err := cert.CheckSignatureFrom(s.EnrollmentCA()); err != nil {
+		t.Errorf("the enrolled certificate is not signed by the enrollment CA: %v", err)
+	}
+	entity, _ = s.Entity(Identities, id)
+	authenticator, ok := entity["authenticators"].(map[string]interface{})["cert"].(map[string]interface{})
+	if !ok {
+		t.Fatal("the identity has no cert authenticator after enrolling")
+	}
+	if status, _ := enroll(token); status != http.StatusNotFound {
+		t.Errorf("enrolling twice with a token: status %d, want %d", status, http.StatusNotFound)
+	}
+
+	// Once the authenticator is removed, a new enrollment can be issued.
+	if status, body := c.do(http.MethodDelete, "/authenticators/"+authenticator["id"].(string), nil); status != http.StatusOK {
+		t.Fatalf("deleting the authenticator: status %d: %v", status, body)
+	}
+	c.create("/enrollments", map[string]interface{}{"identityId": id, "method": "ott", "expiresAt": "2099-01-01T00:00:00.000Z"})
+	entity, _ = s.Entity(Identities, id)
+	if status, body := enroll(entity["enrollment"].(map[string]interface{})["ott"].(map[string]interface{})["token"].(string)); status != http.StatusOK {
+		t.Errorf("enrolling with a new enrollment: status %d: %v", status, body)
+	}
+}
+
+func TestReissueEnrollment(t *testing.T) {
+	s := New(t)
+	c := login(t, s)
+	id := c.create("/identities", map[string]interface{}{"name": "device", "type": "Device", "isAdmin": false, "enrollment": map[string]interface{}{"ott": true}})
+	entity, _ := s.Entity(Identities, id)
+	ott := entity["enrollment"].(map[string]interface{})["ott"].(map[string]interface{})
+	jwt := ott["jwt"]
+	if status, body := c.do(http.MethodPost, "/enrollments/"+ott["id"].(string)+"/refresh", map[string]interface{}{"expiresAt": "2099-01-01T00:00:00.000Z"}); status != http.StatusOK {
+		t.Fatalf("refreshing the enrollment: status %d: %v", status, body)
+	}
+	entity, _ = s.Entity(Identities, id)
+	ott = entity["enrollment"].(map[string]interface{})["ott"].(map[string]interface{})
+	if ott["jwt"] == jwt || ott["expiresAt"] != "2099-01-01T00:00:00Z" {
+		t.Errorf("the refreshed enrollment kept its JWT or expiry: %v", ott)
+	}
+	if status, _ := c.do(http.MethodPost, "/enrollments/unknown/refresh", map[string]interface{}{"expiresAt": "2099-01-01T00:00:00.000Z"}); status != http.StatusNotFound {
+		t.Errorf("refreshing an unknown enrollment: status %d, want %d", status, http.StatusNotFound)
+	}
+
+	routerID := c.create("/edge-routers", map[string]interface{}{"name": "router"})
+	s.PatchEntity(EdgeRouters, routerID, map[string]interface{}{"isVerified": true, "fingerprint": "abc"})
+	router, _ := s.Entity(EdgeRouters, routerID)
+	jwt = router["enrollmentJwt"]
+	if status, body := c.do(http.MethodPost, "/edge-routers/"+routerID+"/re-enroll", nil); status != http.StatusOK {
+		t.Fatalf("re-enrolling the edge router: status %d: %v", status, body)
+	}
+	router, _ = s.Entity(EdgeRouters, routerID)
+	if router["enrollmentJwt"] == jwt || router["isVerified"] != false || router["fingerprint"] != nil {
+		t.Errorf("the re-enrolled edge router was not reset: %v", router)
+	}
+}
@@ -2,14 +2,12 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 
-	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/rs/zerolog/log"
+	"github.com/openziti/edge-api/rest_management_api_client/auth_policy"
+	"github.com/openziti/edge-api/rest_model"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -38,9 +36,6 @@ func (r *authPolicyDataSource) Configure(_ context.Context, req datasource.Confi
 
 	datasourceConfig := req.ProviderData.(*zitiData)
 	r.datasourceConfig = datasourceConfig
-
-	fmt.Printf("Using API Token to create datasource: %s\n", r.datasourceConfig.apiToken)
-	fmt.Printf("Using domain to create datasource: %s\n", r.datasourceConfig.host)
 }
 
 // Metadata returns the datasource type name.
@@ -182,175 +177,17 @@ func (r *authPolicyDataSource) Read(ctx context.Context, req datasource.ReadRequ
 		return
 	}
 
-	filter := ""
-	if state.Name.ValueString() != "" {
-		filter = "filter=name=\"" + state.Name.ValueString() + "\""
-	}
-	if state.ID.ValueString() != "" {
-		filter = "filter=id=\"" + state.ID.ValueString() + "\""
-	}
-
-	authUrl := fmt.Sprintf("%s/auth-policies?%s", r.datasourceConfig.host, filter)
-	cresp, err := ReadZitiResource(authUrl, r.datasourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Reading auth policy", "Could not READ auth policy, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
-	if err != nil {
-		log.Error().Msgf("Error unmarshalling JSON response from Ziti DataSource Response: %v", err)
-		fmt.Println("Error unmarshalling JSON:", err)
+	filter := dataSourceFilter(state.ID, state.Name)
+	detail, ok := lookupDataSourceEntity(ctx, r.datasourceConfig, &resp.Diagnostics, "auth policy", filter, authPolicyList)
+	if !ok {
 		return
 	}
 
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	service := jsonBody["data"].([]interface{})
-
-	if len(service) > 1 {
-		resp.Diagnostics.AddError(
-			"Multiple items returned from API upon filter execution!", "Try to narrow down the filter expression"+filter,
-		)
-	}
-	if len(service) == 0 {
-		resp.Diagnostics.AddError(
-			"No items returned from API upon filter execution!", "Try to relax the filter expression: "+filter,
-		)
-	}
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	data := service[0].(map[string]interface{})
-
-	state.Name = types.StringValue(data["name"].(string))
-	state.ID = types.StringValue(data["id"].(string))
-
-	if primaryData, ok := data["primary"].(map[string]interface{}); ok {
-		attrTypes := PrimaryModel.AttrTypes
-		values := make(map[string]attr.Value)
-
-		if certData, ok := primaryData["cert"].(map[string]interface{}); ok {
-			certValues := make(map[string]attr.Value)
-
-			if v, ok := certData["allowExpiredCerts"].(bool); ok {
-				certValues["allow_expired_certs"] = types.BoolValue(v)
-			}
-			if v, ok := certData["allowed"].(bool); ok {
-				certValues["allowed"] = types.BoolValue(v)
-			}
-
-			certObj, _ := types.ObjectValue(authPolicyCertModel.AttrTypes, certValues)
-			values["cert"] = certObj
-		} else {
-			values["cert"] = types.ObjectNull(authPolicyCertModel.AttrTypes)
-		}
-
-		if extJwtData, ok := primaryData["extJwt"].(map[string]interface{}); ok {
-			extJwtValues := make(map[string]attr.Value)
-
-			if v, ok := extJwtData["allowed"].(bool); ok {
-				extJwtValues["allowed"] = types.BoolValue(v)
-			}
-
-			if signers, ok := extJwtData["allowedSigners"].([]interface{}); ok {
-				signerVals := make([]attr.Value, 0, len(signers))
-				for _, s := range signers {
-					if str, ok := s.(string); ok {
-						signerVals = append(signerVals, types.StringValue(str))
-					}
-				}
-				extJwtValues["allowed_signers"] = types.ListValueMust(types.StringType, signerVals)
-			} else {
-				extJwtValues["allowed_signers"] = types.ListValueMust(types.StringType, []attr.Value{})
-			}
-
-			extJwtObj, _ := types.ObjectValue(authPolicyExtJWTModel.AttrTypes, extJwtValues)
-			values["ext_jwt"] = extJwtObj
-		} else {
-			values["ext_jwt"] = types.ObjectNull(authPolicyExtJWTModel.AttrTypes)
-		}
-
-		if updbData, ok := primaryData["updb"].(map[string]interface{}); ok {
-			updbValues := make(map[string]attr.Value)
-
-			if v, ok := updbData["allowed"].(bool); ok {
-				updbValues["allowed"] = types.BoolValue(v)
-			}
-			if v, ok := updbData["requireMixedCase"].(bool); ok {
-				updbValues["require_mixed_case"] = types.BoolValue(v)
-			}
-			if v, ok := updbData["requireNumberChar"].(bool); ok {
-				updbValues["require_number_char"] = types.BoolValue(v)
-			}
-			if v, ok := updbData["requireSpecialChar"].(bool); ok {
-				updbValues["require_special_char"] = types.BoolValue(v)
-			}
-
-			if v, ok := updbData["lockoutDurationMinutes"].(float64); ok {
-				updbValues["lockout_duration_minutes"] =
-					types.Int64Value(int64(v))
-			}
-			if v, ok := updbData["maxAttempts"].(float64); ok {
-				updbValues["max_attempts"] =
-					types.Int64Value(int64(v))
-			}
-			if v, ok := updbData["minPasswordLength"].(float64); ok {
-				updbValues["min_password_length"] =
-					types.Int64Value(int64(v))
-			}
-
-			updbObj, _ := types.ObjectValue(authPolicyUPDBModel.AttrTypes, updbValues)
-			values["updb"] = updbObj
-		} else {
-			values["updb"] = types.ObjectNull(authPolicyUPDBModel.AttrTypes)
-		}
-
-		primaryObj, _ := types.ObjectValue(attrTypes, values)
-		state.Primary = primaryObj
-
-	} else {
-		state.Primary = types.ObjectNull(PrimaryModel.AttrTypes)
-	}
-
-	if secondaryData, ok := data["secondary"].(map[string]interface{}); ok {
-		jwtSigner := types.StringNull()
-		if v, ok := secondaryData["requireExtJwtSigner"].(string); ok && v != "" {
-			jwtSigner = types.StringValue(v)
-		}
-
-		requireTotp := types.BoolValue(false)
-		if v, ok := secondaryData["requireTotp"].(bool); ok {
-			requireTotp = types.BoolValue(v)
-		}
-
-		values := map[string]attr.Value{
-			"jwt_signer":   jwtSigner,
-			"require_totp": requireTotp,
-		}
-		obj, _ := types.ObjectValue(SecondaryModel.AttrTypes, values)
-		state.Secondary = obj
-
-	} else {
-		state.Secondary = types.ObjectNull(SecondaryModel.AttrTypes)
-	}
-
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
-	}
+	state.ID = types.StringPointerValue(detail.ID)
+	state.Name = types.StringPointerValue(detail.Name)
+	state.Primary = authPolicyPrimaryFromAPI(detail.Primary, &resp.Diagnostics)
+	state.Secondary = authPolicySecondaryFromAPI(detail.Secondary, &resp.Diagnostics)
+	state.Tags = tagsFromAPI(ctx, detail.Tags, &resp.Diagnostics)
 
 	// Set refreshed state
 	diags = resp.State.Set(ctx, &state)
@@ -359,3 +196,15 @@ func (r *authPolicyDataSource) Read(ctx context.Context, req datasource.ReadRequ
 		return
 	}
 }
+
+// authPolicyList lists the auth policies matching filter.
+func authPolicyList(ctx context.Context, client *zitiData, filter string) ([]*rest_model.AuthPolicyDetail, error) {
+	list, err := client.api.AuthPolicy.ListAuthPolicies(&auth_policy.ListAuthPoliciesParams{Filter: optionalFilter(filter), Context: ctx}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if list.Payload == nil {
+		return nil, errMissingData
+	}
+	return withoutNil(list.Payload.Data), nil
+}
@@ -2,14 +2,9 @@ package provider
 
 import (
 	"context"
-	"errors"
-	"encoding/json"
-	"fmt"
-	"net/url"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
-	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
@@ -19,10 +14,8 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/openziti/edge-api/rest_management_api_client/auth_policy"
 	"github.com/openziti/edge-api/rest_model"
-	"github.com/rs/zerolog/log"
-	"github.com/tidwall/gjson"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -34,32 +27,27 @@ var (
 
 // NewAuthPolicyResource is a helper function to simplify the provider implementation.
 func NewAuthPolicyResource() resource.Resource {
-	return &authPolicyResource{}
+	return &authPolicyResource{newZitiResource(zitiResourceSpec[authPolicyResourceModel, *rest_model.AuthPolicyDetail]{
+		typeName: "_auth_policy",
+		label:    "auth policy",
+		create:   authPolicyCreate,
+		read:     authPolicyRead,
+		readData: authPolicyReadData,
+		update:   authPolicyUpdate,
+		delete:   authPolicyDelete,
+		list:     authPolicyList,
+		entityID: func(detail *rest_model.AuthPolicyDetail) string {
+			return types.StringPointerValue(detail.ID).ValueString()
+		},
+		exports: func(detail *rest_model.AuthPolicyDetail) bool {
+			return types.StringPointerValue(detail.ID).ValueString() != "default"
+		},
+	})}
 }
 
 // authPolicyResource is the resource implementation.
 type authPolicyResource struct {
-	resourceConfig *zitiData
-}
-
-// Configure adds the provider configured client to the resource.
-func (r *authPolicyResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	// Add a nil check when handling ProviderData because Terraform
-	// sets that data after it calls the ConfigureProvider RPC.
-	if req.ProviderData == nil {
-		return
-	}
-
-	resourceConfig := req.ProviderData.(*zitiData)
-	r.resourceConfig = resourceConfig
-
-	fmt.Printf("Using API Token to create resource: %s\n", r.resourceConfig.apiToken)
-	fmt.Printf("Using domain to create resource: %s\n", r.resourceConfig.host)
-}
-
-// Metadata returns the resource type name.
-func (r *authPolicyResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
-	resp.TypeName = req.ProviderTypeName + "_auth_policy"
+	zitiResource[authPolicyResourceModel, *rest_model.AuthPolicyDetail]
 }
 
 // authPolicyResourceModel maps the resource schema data.
@@ -69,6 +57,7 @@ type authPolicyResourceModel struct {
 	Primary     types.Object `tfsdk:"primary"`
 	Secondary   types.Object `tfsdk:"secondary"`
 	Tags        types.Map    `tfsdk:"tags"`
+	TagsAll     types.Map    `tfsdk:"tags_all"`
 	LastUpdated types.String `tfsdk:"last_updated"`
 }
 
@@ -203,6 +192,7 @@ func (r *authPolicyResource) Schema(_ context.Context, _ resource.SchemaRequest,
 				Default:             mapdefault.StaticValue(types.MapNull(types.StringType)),
 				MarkdownDescription: "Auth Policy Tags",
 			},
+			"tags_all": tagsAllAttribute(),
 		},
 	}
 }
@@ -246,17 +236,7 @@ var authPolicyUPDBModel = types.ObjectType{
 	},
 }
 
-// Create a new resource.
-func (r *authPolicyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	// Retrieve values from plan
-	var eplan authPolicyResourceModel
-
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func authPolicyCreate(ctx context.Context, client *zitiData, eplan *authPolicyResourceModel, diags *diag.Diagnostics) (string, error) {
 	name := eplan.Name.ValueString()
 	tags := TagsFromAttributes(eplan.Tags.Elements())
 
@@ -365,231 +345,14 @@ func (r *authPolicyResource) Create(ctx context.Context, req resource.CreateRequ
 		Tags:      tags,
 	}
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************create resource payload***********************:\n %s\n", jsonData)
-
-	authUrl := fmt.Sprintf("%s/auth-policies", r.resourceConfig.host)
-	cresp, err := CreateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti POST Response: %s", cresp)
-	log.Info().Msg(msg)
+	created, err := client.api.AuthPolicy.CreateAuthPolicy(&auth_policy.CreateAuthPolicyParams{AuthPolicy: &payload, Context: ctx}, nil)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Creating auth policy", "Could not Create auth policy, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	fmt.Printf("**********************create response************************:\n %s\n", cresp)
-	resourceID := gjson.Get(cresp, "data.id").String()
-
-	// Map response body to schema and populate Computed attribute values
-	eplan.ID = types.StringValue(resourceID)
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
-
-	// Set state to fully populated data
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-}
-
-// Read resource information.
-func (r *authPolicyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	// Get current state
-	var state authPolicyResourceModel
-	tflog.Debug(ctx, "Reading auth policy")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	authUrl := fmt.Sprintf("%s/auth-policies/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := ReadZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		if errors.Is(err, errNotFound) {
-			msg := fmt.Sprintf("Resource not found in backend; removing from state, id: %s", state.ID.ValueString())
-			log.Info().Msg(msg)
-			resp.State.RemoveResource(ctx)
-			return
-		}
-		resp.Diagnostics.AddError(
-			"Error Reading auth policy", "Could not READ auth policy, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Reading auth policy", fmt.Sprintf("Could not READ auth policy, ERROR %v: ", err.Error()),
-		)
-		return
-	}
-
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	data, ok := jsonBody["data"].(map[string]interface{})
-	if !ok {
-		resp.Diagnostics.AddError("Error: ", "'data' is either missing or not a map[string]interface{}")
-		return
-	}
-
-	// Manually assign individual values from the map to the struct fields
-	state.Name = types.StringValue(data["name"].(string))
-
-	if primaryData, ok := data["primary"].(map[string]interface{}); ok {
-		attrTypes := PrimaryModel.AttrTypes
-		values := make(map[string]attr.Value)
-
-		if certData, ok := primaryData["cert"].(map[string]interface{}); ok {
-			certValues := make(map[string]attr.Value)
-
-			if v, ok := certData["allowExpiredCerts"].(bool); ok {
-				certValues["allow_expired_certs"] = types.BoolValue(v)
-			}
-			if v, ok := certData["allowed"].(bool); ok {
-				certValues["allowed"] = types.BoolValue(v)
-			}
-
-			certObj, _ := types.ObjectValue(authPolicyCertModel.AttrTypes, certValues)
-			values["cert"] = certObj
-		} else {
-			values["cert"] = types.ObjectNull(authPolicyCertModel.AttrTypes)
-		}
-
-		if extJwtData, ok := primaryData["extJwt"].(map[string]interface{}); ok {
-			extJwtValues := make(map[string]attr.Value)
-
-			if v, ok := extJwtData["allowed"].(bool); ok {
-				extJwtValues["allowed"] = types.BoolValue(v)
-			}
-
-			if signers, ok := extJwtData["allowedSigners"].([]interface{}); ok {
-				signerVals := make([]attr.Value, 0, len(signers))
-				for _, s := range signers {
-					if str, ok := s.(string); ok {
-						signerVals = append(signerVals, types.StringValue(str))
-					}
-				}
-				extJwtValues["allowed_signers"] = types.ListValueMust(types.StringType, signerVals)
-			} else {
-				extJwtValues["allowed_signers"] = types.ListValueMust(types.StringType, []attr.Value{})
-			}
-
-			extJwtObj, _ := types.ObjectValue(authPolicyExtJWTModel.AttrTypes, extJwtValues)
-			values["ext_jwt"] = extJwtObj
-		} else {
-			values["ext_jwt"] = types.ObjectNull(authPolicyExtJWTModel.AttrTypes)
-		}
-
-		if updbData, ok := primaryData["updb"].(map[string]interface{}); ok {
-			updbValues := make(map[string]attr.Value)
-
-			if v, ok := updbData["allowed"].(bool); ok {
-				updbValues["allowed"] = types.BoolValue(v)
-			}
-			if v, ok := updbData["requireMixedCase"].(bool); ok {
-				updbValues["require_mixed_case"] = types.BoolValue(v)
-			}
-			if v, ok := updbData["requireNumberChar"].(bool); ok {
-				updbValues["require_number_char"] = types.BoolValue(v)
-			}
-			if v, ok := updbData["requireSpecialChar"].(bool); ok {
-				updbValues["require_special_char"] = types.BoolValue(v)
-			}
-
-			if v, ok := updbData["lockoutDurationMinutes"].(float64); ok {
-				updbValues["lockout_duration_minutes"] =
-					types.Int64Value(int64(v))
-			}
-			if v, ok := updbData["maxAttempts"].(float64); ok {
-				updbValues["max_attempts"] =
-					types.Int64Value(int64(v))
-			}
-			if v, ok := updbData["minPasswordLength"].(float64); ok {
-				updbValues["min_password_length"] =
-					types.Int64Value(int64(v))
-			}
-
-			updbObj, _ := types.ObjectValue(authPolicyUPDBModel.AttrTypes, updbValues)
-			values["updb"] = updbObj
-		} else {
-			values["updb"] = types.ObjectNull(authPolicyUPDBModel.AttrTypes)
-		}
-
-		primaryObj, _ := types.ObjectValue(attrTypes, values)
-		state.Primary = primaryObj
-
-	} else {
-		state.Primary = types.ObjectNull(PrimaryModel.AttrTypes)
-	}
-
-	if secondaryData, ok := data["secondary"].(map[string]interface{}); ok {
-		jwtSigner := types.StringNull()
-		if v, ok := secondaryData["requireExtJwtSigner"].(string); ok && v != "" {
-			jwtSigner = types.StringValue(v)
-		}
-
-		requireTotp := types.BoolValue(false)
-		if v, ok := secondaryData["requireTotp"].(bool); ok {
-			requireTotp = types.BoolValue(v)
-		}
-
-		values := map[string]attr.Value{
-			"jwt_signer":   jwtSigner,
-			"require_totp": requireTotp,
-		}
-		obj, _ := types.ObjectValue(SecondaryModel.AttrTypes, values)
-		state.Secondary = obj
-
-	} else {
-		state.Secondary = types.ObjectNull(SecondaryModel.AttrTypes)
-	}
-
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
-	}
-
-	// Set refreshed state
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+		return "", err
 	}
+	return createdID(created.Payload)
 }
 
-// Update updates the resource and sets the updated Terraform state on success.
-func (r *authPolicyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	// Retrieve values from plan
-	var eplan authPolicyResourceModel
-	tflog.Debug(ctx, "Updating auth policy")
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	// Get current state
-	var state authPolicyResourceModel
-	sdiags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(sdiags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func authPolicyUpdate(ctx context.Context, client *zitiData, id string, eplan *authPolicyResourceModel, diags *diag.Diagnostics) error {
 	name := eplan.Name.ValueString()
 	tags := TagsFromAttributes(eplan.Tags.Elements())
 
@@ -691,62 +454,125 @@ func (r *authPolicyResource) Update(ctx context.Context, req resource.UpdateRequ
 		}
 	}
 
-	payload := rest_model.AuthPolicyCreate{
-		Name:      &name,
-		Primary:   &authPolicyPrimary,
-		Secondary: &authPolicySecondary,
-		Tags:      tags,
+	payload := rest_model.AuthPolicyUpdate{
+		AuthPolicyCreate: rest_model.AuthPolicyCreate{
+			Name:      &name,
+			Primary:   &authPolicyPrimary,
+			Secondary: &authPolicySecondary,
+			Tags:      tags,
+		},
 	}
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************update resource payload***********************:\n %s\n", jsonData)
+	_, err := client.api.AuthPolicy.UpdateAuthPolicy(&auth_policy.UpdateAuthPolicyParams{ID: id, AuthPolicy: &payload, Context: ctx}, nil)
+	return err
+}
 
-	authUrl := fmt.Sprintf("%s/auth-policies/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := UpdateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti PATCH Response: %s", cresp)
-	log.Info().Msg(msg)
+func authPolicyRead(ctx context.Context, client *zitiData, id string) (*rest_model.AuthPolicyDetail, error) {
+	detail, err := client.api.AuthPolicy.DetailAuthPolicy(&auth_policy.DetailAuthPolicyParams{ID: id, Context: ctx}, nil)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Updating auth policy", "Could not Update auth policy, unexpected error: "+err.Error(),
-		)
-		return
+		return nil, err
 	}
+	if detail.Payload == nil || detail.Payload.Data == nil {
+		return nil, errMissingData
+	}
+	return detail.Payload.Data, nil
+}
 
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
+func authPolicyDelete(ctx context.Context, client *zitiData, id string) error {
+	_, err := client.api.AuthPolicy.DeleteAuthPolicy(&auth_policy.DeleteAuthPolicyParams{ID: id, Context: ctx}, nil)
+	return err
+}
 
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
+func authPolicyReadData(ctx context.Context, detail *rest_model.AuthPolicyDetail, state *authPolicyResourceModel, diags *diag.Diagnostics) {
+	state.Name = types.StringPointerValue(detail.Name)
+	state.Primary = authPolicyPrimaryFromAPI(detail.Primary, diags)
+	state.Secondary = authPolicySecondaryFromAPI(detail.Secondary, diags)
+	state.Tags = tagsFromAPI(ctx, detail.Tags, diags)
 }
 
-// Delete deletes the resource and removes the Terraform state on success.
-func (r *authPolicyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	// Retrieve values from state
-	var state authPolicyResourceModel
-	tflog.Debug(ctx, "Deleting auth policy")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+// authPolicyPrimaryFromAPI converts the primary authentication settings of a
+// fetched auth policy to the primary attribute.
+func authPolicyPrimaryFromAPI(primaryData *rest_model.AuthPolicyPrimary, diags *diag.Diagnostics) types.Object {
+	if primaryData == nil {
+		return types.ObjectNull(PrimaryModel.AttrTypes)
+	}
+	values := make(map[string]attr.Value)
+
+	if certData := primaryData.Cert; certData != nil {
+		certValues := map[string]attr.Value{
+			"allow_expired_certs": types.BoolPointerValue(certData.AllowExpiredCerts),
+			"allowed":             types.BoolPointerValue(certData.Allowed),
+		}
+
+		certObj, diag := types.ObjectValue(authPolicyCertModel.AttrTypes, certValues)
+		diags.Append(diag...)
+		values["cert"] = certObj
+	} else {
+		values["cert"] = types.ObjectNull(authPolicyCertModel.AttrTypes)
 	}
 
-	authUrl := fmt.Sprintf("%s/auth-policies/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
+	if extJwtData := primaryData.ExtJWT; extJwtData != nil {
+		signerVals := make([]attr.Value, 0, len(extJwtData.AllowedSigners))
+		for _, signer := range extJwtData.AllowedSigners {
+			signerVals = append(signerVals, types.StringValue(signer))
+		}
+		extJwtValues := map[string]attr.Value{
+			"allowed":         types.BoolPointerValue(extJwtData.Allowed),
+			"allowed_signers": types.ListValueMust(types.StringType, signerVals),
+		}
 
-	cresp, err := DeleteZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti Delete Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Deleting auth policy", "Could not DELETE auth policy, unexpected error: "+err.Error(),
-		)
-		return
+		extJwtObj, diag := types.ObjectValue(authPolicyExtJWTModel.AttrTypes, extJwtValues)
+		diags.Append(diag...)
+		values["ext_jwt"] = extJwtObj
+	} else {
+		values["ext_jwt"] = types.ObjectNull(authPolicyExtJWTModel.AttrTypes)
 	}
+
+	if updbData := primaryData.Updb; updbData != nil {
+		updbValues := map[string]attr.Value{
+			"allowed":                  types.BoolPointerValue(updbData.Allowed),
+			"require_mixed_case":       types.BoolPointerValue(updbData.RequireMixedCase),
+			"require_number_char":      types.BoolPointerValue(updbData.RequireNumberChar),
+			"require_special_char":     types.BoolPointerValue(updbData.RequireSpecialChar),
+			"lockout_duration_minutes": types.Int64PointerValue(updbData.LockoutDurationMinutes),
+			"max_attempts":             types.Int64PointerValue(updbData.MaxAttempts),
+			"min_password_length":      types.Int64PointerValue(updbData.MinPasswordLength),
+		}
+
+		updbObj, diag := types.ObjectValue(authPolicyUPDBModel.AttrTypes, updbValues)
+		diags.Append(diag...)
+		values["updb"] = updbObj
+	} else {
+		values["updb"] = types.ObjectNull(authPolicyUPDBModel.AttrTypes)
+	}
+
+	primaryObj, diag := types.ObjectValue(PrimaryModel.AttrTypes, values)
+	diags.Append(diag...)
+	return primaryObj
 }
 
-func (r *authPolicyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+// authPolicySecondaryFromAPI converts the secondary authentication settings of
+// a fetched auth policy to the secondary attribute.
+func authPolicySecondaryFromAPI(secondaryData *rest_model.AuthPolicySecondary, diags *diag.Diagnostics) types.Object {
+	if secondaryData == nil {
+		return types.ObjectNull(SecondaryModel.AttrTypes)
+	}
+
+	jwtSigner := types.StringNull()
+	if v := secondaryData.RequireExtJWTSigner; v != nil && *v != "" {
+		jwtSigner = types.StringValue(*v)
+	}
+
+	requireTotp := types.BoolValue(false)
+	if v := secondaryData.RequireTotp; v != nil {
+		requireTotp = types.BoolValue(*v)
+	}
+
+	values := map[string]attr.Value{
+		"jwt_signer":   jwtSigner,
+		"require_totp": requireTotp,
+	}
+	obj, diag := types.ObjectValue(SecondaryModel.AttrTypes, values)
+	diags.Append(diag...)
+	return obj
 }
@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"testing"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccAuthPolicyResource(t *testing.T) {
+	controller := testAccController(t)
+	const resourceName = "ziti_auth_policy.test"
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_auth_policy" "test" {
+  name = "test-auth-policy"
+  primary = {
+    cert    = { allowed = true }
+    ext_jwt = { allowed = false }
+    updb    = { allowed = true, max_attempts = 3 }
+  }
+  secondary = {}
+}
+`
+	update := testAccProviderConfig(controller, "") + `
+resource "ziti_auth_policy" "test" {
+  name = "test-auth-policy"
+  primary = {
+    cert    = { allowed = true, allow_expired_certs = true }
+    ext_jwt = { allowed = false }
+    updb    = { allowed = true, max_attempts = 3, lockout_duration_minutes = 10 }
+  }
+  secondary = { require_totp = true }
+  tags      = { env = "test" }
+}
+`
+
+	steps := []resource.TestStep{{
+		Config: config,
+		Check: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckEntityExists(controller, mockcontroller.AuthPolicies, resourceName),
+			resource.TestCheckResourceAttr(resourceName, "name", "test-auth-policy"),
+			resource.TestCheckResourceAttr(resourceName, "primary.cert.allowed", "true"),
+			resource.TestCheckResourceAttr(resourceName, "primary.updb.max_attempts", "3"),
+			resource.TestCheckResourceAttr(resourceName, "secondary.require_totp", "false"),
+		),
+	}}
+	steps = append(steps, testAccImportSteps(resourceName)...)
+	steps = append(steps, testAccUpdateStep(resourceName, update,
+		resource.TestCheckResourceAttr(resourceName, "primary.cert.allow_expired_certs", "true"),
+		resource.TestCheckResourceAttr(resourceName, "primary.updb.lockout_duration_minutes", "10"),
+		resource.TestCheckResourceAttr(resourceName, "secondary.require_totp", "true"),
+		resource.TestCheckResourceAttr(resourceName, "tags.env", "test"),
+	))
+	steps = append(steps, testAccOutOfBandSteps(t, controller, mockcontroller.AuthPolicies, "test-auth-policy", resourceName, update,
+		map[string]interface{}{"tags": map[string]interface{}{"env": "prod"}})...)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    steps,
+	})
+}
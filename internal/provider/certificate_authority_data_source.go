@@ -2,14 +2,12 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 
-	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/rs/zerolog/log"
+	"github.com/openziti/edge-api/rest_management_api_client/certificate_authority"
+	"github.com/openziti/edge-api/rest_model"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -38,9 +36,6 @@ func (r *certificateAuthorityDataSource) Configure(_ context.Context, req dataso
 
 	datasourceConfig := req.ProviderData.(*zitiData)
 	r.datasourceConfig = datasourceConfig
-
-	fmt.Printf("Using API Token to create datasource: %s\n", r.datasourceConfig.apiToken)
-	fmt.Printf("Using domain to create datasource: %s\n", r.datasourceConfig.host)
 }
 
 // Metadata returns the datasource type name.
@@ -52,7 +47,7 @@ func (r *certificateAuthorityDataSource) Metadata(_ context.Context, req datasou
 type certificateAuthorityDataSourceModel struct {
 	ID                        types.String `tfsdk:"id"`
 	Name                      types.String `tfsdk:"name"`
-	IdentityRoles             types.List   `tfsdk:"identityroles"`
+	IdentityRoles             types.Set    `tfsdk:"identityroles"`
 	IsAutoCaEnrollmentEnabled types.Bool   `tfsdk:"is_autoca_enrollment_enabled"`
 	IsOttCaEnrollmentEnabled  types.Bool   `tfsdk:"is_ottca_enrollment_enabled"`
 	IsAuthEnabled             types.Bool   `tfsdk:"is_auth_enabled"`
@@ -77,7 +72,7 @@ func (r *certificateAuthorityDataSource) Schema(_ context.Context, _ datasource.
 				Optional:            true,
 				MarkdownDescription: "Name of the Certificate Authority",
 			},
-			"identityroles": schema.ListAttribute{
+			"identityroles": schema.SetAttribute{
 				Computed:            true,
 				ElementType:         types.StringType,
 				MarkdownDescription: "Identity Roles",
@@ -151,129 +146,38 @@ func (r *certificateAuthorityDataSource) Read(ctx context.Context, req datasourc
 		return
 	}
 
-	filter := ""
-	if state.Name.ValueString() != "" {
-		filter = "filter=name=\"" + state.Name.ValueString() + "\""
-	}
-	if state.ID.ValueString() != "" {
-		filter = "filter=id=\"" + state.ID.ValueString() + "\""
-	}
-
-	authUrl := fmt.Sprintf("%s/cas?%s", r.datasourceConfig.host, filter)
-	cresp, err := ReadZitiResource(authUrl, r.datasourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Reading Certificate Authority", "Could not READ CA, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
-	if err != nil {
-		log.Error().Msgf("Error unmarshalling JSON response from Ziti DataSource Response: %v", err)
-		fmt.Println("Error unmarshalling JSON:", err)
-		return
-	}
-
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	service := jsonBody["data"].([]interface{})
-
-	if len(service) > 1 {
-		resp.Diagnostics.AddError(
-			"Multiple items returned from API upon filter execution!", "Try to narrow down the filter expression"+filter,
-		)
-	}
-	if len(service) == 0 {
-		resp.Diagnostics.AddError(
-			"No items returned from API upon filter execution!", "Try to relax the filter expression: "+filter,
-		)
-	}
-	if resp.Diagnostics.HasError() {
+	filter := dataSourceFilter(state.ID, state.Name)
+	detail, ok := lookupDataSourceEntity(ctx, r.datasourceConfig, &resp.Diagnostics, "Certificate Authority", filter, certificateAuthorityList)
+	if !ok {
 		return
 	}
 
-	data := service[0].(map[string]interface{})
-
-	state.Name = types.StringValue(data["name"].(string))
-	state.ID = types.StringValue(data["id"].(string))
-
-	if identityNameFormat, ok := data["identityNameFormat"].(string); ok {
-		state.IdentityNameFormat = types.StringValue(identityNameFormat)
-	}
-
-	if certPem, ok := data["certPem"].(string); ok {
-		state.CertPem = types.StringValue(certPem)
-	}
+	state.ID = types.StringPointerValue(detail.ID)
+	state.Name = types.StringPointerValue(detail.Name)
 
-	if isAuthEnabled, ok := data["isAuthEnabled"].(bool); ok {
-		state.IsAuthEnabled = types.BoolValue(isAuthEnabled)
+	if detail.IdentityNameFormat != nil {
+		state.IdentityNameFormat = types.StringValue(*detail.IdentityNameFormat)
 	}
 
-	if isAutoCaEnrollmentEnabled, ok := data["isAutoCaEnrollmentEnabled"].(bool); ok {
-		state.IsAutoCaEnrollmentEnabled = types.BoolValue(isAutoCaEnrollmentEnabled)
+	if detail.CertPem != nil {
+		state.CertPem = types.StringValue(*detail.CertPem)
 	}
 
-	if isOttCaEnrollmentEnabled, ok := data["isOttCaEnrollmentEnabled"].(bool); ok {
-		state.IsOttCaEnrollmentEnabled = types.BoolValue(isOttCaEnrollmentEnabled)
+	if detail.IsAuthEnabled != nil {
+		state.IsAuthEnabled = types.BoolValue(*detail.IsAuthEnabled)
 	}
 
-	if extIdClaim, ok := data["externalIdClaim"].(map[string]interface{}); ok {
-		attrTypes := ExternalIdClaimModel.AttrTypes
-		values := make(map[string]attr.Value)
-
-		if v, ok := extIdClaim["location"].(string); ok {
-			values["location"] = types.StringValue(v)
-		}
-
-		if v, ok := extIdClaim["matcher"].(string); ok {
-			values["matcher"] = types.StringValue(v)
-		}
-
-		if v, ok := extIdClaim["parser"].(string); ok && v != "" {
-			values["parser"] = types.StringValue(v)
-		}
-
-		if v, ok := extIdClaim["matcherCriteria"].(string); ok && v != "" {
-			values["matchercriteria"] = types.StringValue(v)
-		}
-
-		if v, ok := extIdClaim["parserCriteria"].(string); ok && v != "" {
-			values["parsercriteria"] = types.StringValue(v)
-		}
-
-		if v, ok := extIdClaim["index"].(float64); ok {
-			values["index"] = types.Int64Value(int64(v))
-		}
-
-		obj, _ := types.ObjectValue(attrTypes, values)
-		state.ExternalIdClaim = obj
-
-	} else {
-		state.ExternalIdClaim = types.ObjectNull(ExternalIdClaimModel.AttrTypes)
+	if detail.IsAutoCaEnrollmentEnabled != nil {
+		state.IsAutoCaEnrollmentEnabled = types.BoolValue(*detail.IsAutoCaEnrollmentEnabled)
 	}
 
-	if identityRoles, ok := data["identityRoles"].([]interface{}); ok {
-		identityRoles, diag := types.ListValueFrom(ctx, types.StringType, identityRoles)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.IdentityRoles = identityRoles
-	} else {
-		state.IdentityRoles = types.ListNull(types.StringType)
+	if detail.IsOttCaEnrollmentEnabled != nil {
+		state.IsOttCaEnrollmentEnabled = types.BoolValue(*detail.IsOttCaEnrollmentEnabled)
 	}
 
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
-	}
+	state.ExternalIdClaim = externalIdClaimFromAPI(detail.ExternalIDClaim, &resp.Diagnostics)
+	state.IdentityRoles = stringSetFromAPI(ctx, detail.IdentityRoles, &resp.Diagnostics)
+	state.Tags = tagsFromAPI(ctx, detail.Tags, &resp.Diagnostics)
 
 	// Set refreshed state
 	diags = resp.State.Set(ctx, &state)
@@ -282,3 +186,15 @@ func (r *certificateAuthorityDataSource) Read(ctx context.Context, req datasourc
 		return
 	}
 }
+
+// certificateAuthorityList lists the CAs matching filter.
+func certificateAuthorityList(ctx context.Context, client *zitiData, filter string) ([]*rest_model.CaDetail, error) {
+	list, err := client.api.CertificateAuthority.ListCas(&certificate_authority.ListCasParams{Filter: optionalFilter(filter), Context: ctx}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if list.Payload == nil {
+		return nil, errMissingData
+	}
+	return withoutNil(list.Payload.Data), nil
+}
@@ -2,74 +2,58 @@ package provider
 
 import (
 	"context"
-	"errors"
-	"encoding/json"
-	"fmt"
-	"net/url"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
-	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
-	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/openziti/edge-api/rest_management_api_client/certificate_authority"
 	"github.com/openziti/edge-api/rest_model"
-	"github.com/rs/zerolog/log"
-	"github.com/tidwall/gjson"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource                = &certificateAuthorityResource{}
-	_ resource.ResourceWithConfigure   = &certificateAuthorityResource{}
-	_ resource.ResourceWithImportState = &certificateAuthorityResource{}
+	_ resource.Resource                 = &certificateAuthorityResource{}
+	_ resource.ResourceWithConfigure    = &certificateAuthorityResource{}
+	_ resource.ResourceWithImportState  = &certificateAuthorityResource{}
+	_ resource.ResourceWithUpgradeState = &certificateAuthorityResource{}
 )
 
 // NewCertificateAuthorityResource is a helper function to simplify the provider implementation.
 func NewCertificateAuthorityResource() resource.Resource {
-	return &certificateAuthorityResource{}
+	return &certificateAuthorityResource{newZitiResource(zitiResourceSpec[certificateAuthorityResourceModel, *rest_model.CaDetail]{
+		typeName: "_certificate_authority",
+		label:    "certificate authority",
+		create:   certificateAuthorityCreate,
+		read:     certificateAuthorityRead,
+		readData: certificateAuthorityReadData,
+		update:   certificateAuthorityUpdate,
+		delete:   certificateAuthorityDelete,
+		list:     certificateAuthorityList,
+		entityID: func(detail *rest_model.CaDetail) string { return types.StringPointerValue(detail.ID).ValueString() },
+	})}
 }
 
 // certificateAuthorityResource is the resource implementation.
 type certificateAuthorityResource struct {
-	resourceConfig *zitiData
-}
-
-// Configure adds the provider configured client to the resource.
-func (r *certificateAuthorityResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	// Add a nil check when handling ProviderData because Terraform
-	// sets that data after it calls the ConfigureProvider RPC.
-	if req.ProviderData == nil {
-		return
-	}
-
-	resourceConfig := req.ProviderData.(*zitiData)
-	r.resourceConfig = resourceConfig
-
-	fmt.Printf("Using API Token to create resource: %s\n", r.resourceConfig.apiToken)
-	fmt.Printf("Using domain to create resource: %s\n", r.resourceConfig.host)
-}
-
-// Metadata returns the resource type name.
-func (r *certificateAuthorityResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
-	resp.TypeName = req.ProviderTypeName + "_certificate_authority"
+	zitiResource[certificateAuthorityResourceModel, *rest_model.CaDetail]
 }
 
 // certificateAuthorityResourceModel maps the resource schema data.
 type certificateAuthorityResourceModel struct {
 	ID                        types.String `tfsdk:"id"`
 	Name                      types.String `tfsdk:"name"`
-	IdentityRoles             types.List   `tfsdk:"identityroles"`
+	IdentityRoles             types.Set    `tfsdk:"identityroles"`
 	IsAutoCaEnrollmentEnabled types.Bool   `tfsdk:"is_autoca_enrollment_enabled"`
 	IsOttCaEnrollmentEnabled  types.Bool   `tfsdk:"is_ottca_enrollment_enabled"`
 	IsAuthEnabled             types.Bool   `tfsdk:"is_auth_enabled"`
@@ -77,6 +61,7 @@ type certificateAuthorityResourceModel struct {
 	CertPem                   types.String `tfsdk:"cert_pem"`
 	ExternalIdClaim           types.Object `tfsdk:"external_id_claim"`
 	Tags                      types.Map    `tfsdk:"tags"`
+	TagsAll                   types.Map    `tfsdk:"tags_all"`
 	LastUpdated               types.String `tfsdk:"last_updated"`
 }
 
@@ -91,9 +76,19 @@ var ExternalIdClaimModel = types.ObjectType{
 	},
 }
 
+// UpgradeState migrates the state of schema version 0, whose roles were lists.
+func (r *certificateAuthorityResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	var resp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &resp)
+	return map[int64]resource.StateUpgrader{
+		0: roleSetsUpgrader(resp.Schema, "identityroles"),
+	}
+}
+
 // Schema defines the schema for the resource.
 func (r *certificateAuthorityResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version:             1,
 		MarkdownDescription: "Ziti Certificate Authority Resource",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -111,11 +106,11 @@ func (r *certificateAuthorityResource) Schema(_ context.Context, _ resource.Sche
 				Required:            true,
 				MarkdownDescription: "Name of the Certificate Authority",
 			},
-			"identityroles": schema.ListAttribute{
+			"identityroles": schema.SetAttribute{
 				ElementType:         types.StringType,
 				Optional:            true,
 				Computed:            true,
-				Default:             listdefault.StaticValue(types.ListNull(types.StringType)),
+				Default:             setdefault.StaticValue(types.SetNull(types.StringType)),
 				MarkdownDescription: "Identity Roles",
 			},
 			"is_autoca_enrollment_enabled": schema.BoolAttribute{
@@ -199,21 +194,12 @@ func (r *certificateAuthorityResource) Schema(_ context.Context, _ resource.Sche
 				Default:             mapdefault.StaticValue(types.MapNull(types.StringType)),
 				MarkdownDescription: "Certificate Authority Tags",
 			},
+			"tags_all": tagsAllAttribute(),
 		},
 	}
 }
 
-// Create a new resource.
-func (r *certificateAuthorityResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	// Retrieve values from plan
-	var eplan certificateAuthorityResourceModel
-
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func certificateAuthorityCreate(ctx context.Context, client *zitiData, eplan *certificateAuthorityResourceModel, diags *diag.Diagnostics) (string, error) {
 	name := eplan.Name.ValueString()
 	tags := TagsFromAttributes(eplan.Tags.Elements())
 	isAuthEnabled := eplan.IsAuthEnabled.ValueBool()
@@ -248,185 +234,14 @@ func (r *certificateAuthorityResource) Create(ctx context.Context, req resource.
 		Tags:                      tags,
 	}
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************create resource payload***********************:\n %s\n", jsonData)
-
-	authUrl := fmt.Sprintf("%s/cas", r.resourceConfig.host)
-	cresp, err := CreateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti POST Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Creating certificate authority", "Could not Create CA, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	fmt.Printf("**********************create response************************:\n %s\n", cresp)
-	resourceID := gjson.Get(cresp, "data.id").String()
-
-	// Map response body to schema and populate Computed attribute values
-	eplan.ID = types.StringValue(resourceID)
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
-
-	// Set state to fully populated data
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-}
-
-// Read resource information.
-func (r *certificateAuthorityResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	// Get current state
-	var state certificateAuthorityResourceModel
-	tflog.Debug(ctx, "Reading Certificate Authority")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	authUrl := fmt.Sprintf("%s/cas/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := ReadZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
+	created, err := client.api.CertificateAuthority.CreateCa(&certificate_authority.CreateCaParams{Ca: &payload, Context: ctx}, nil)
 	if err != nil {
-		if errors.Is(err, errNotFound) {
-			msg := fmt.Sprintf("Resource not found in backend; removing from state, id: %s", state.ID.ValueString())
-			log.Info().Msg(msg)
-			resp.State.RemoveResource(ctx)
-			return
-		}
-		resp.Diagnostics.AddError(
-			"Error Reading Certificate Authority", "Could not READ CA, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Reading Certificate Authority", fmt.Sprintf("Could not READ CA, ERROR %v: ", err.Error()),
-		)
-		return
-	}
-
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	data, ok := jsonBody["data"].(map[string]interface{})
-	if !ok {
-		resp.Diagnostics.AddError("Error: ", "'data' is either missing or not a map[string]interface{}")
-		return
-	}
-
-	// Manually assign individual values from the map to the struct fields
-	state.Name = types.StringValue(data["name"].(string))
-
-	if identityNameFormat, ok := data["identityNameFormat"].(string); ok {
-		state.IdentityNameFormat = types.StringValue(identityNameFormat)
-	}
-
-	if certPem, ok := data["certPem"].(string); ok {
-		state.CertPem = types.StringValue(certPem)
-	}
-
-	if isAuthEnabled, ok := data["isAuthEnabled"].(bool); ok {
-		state.IsAuthEnabled = types.BoolValue(isAuthEnabled)
-	}
-
-	if isAutoCaEnrollmentEnabled, ok := data["isAutoCaEnrollmentEnabled"].(bool); ok {
-		state.IsAutoCaEnrollmentEnabled = types.BoolValue(isAutoCaEnrollmentEnabled)
-	}
-
-	if isOttCaEnrollmentEnabled, ok := data["isOttCaEnrollmentEnabled"].(bool); ok {
-		state.IsOttCaEnrollmentEnabled = types.BoolValue(isOttCaEnrollmentEnabled)
-	}
-
-	if extIdClaim, ok := data["externalIdClaim"].(map[string]interface{}); ok {
-		attrTypes := ExternalIdClaimModel.AttrTypes
-		values := make(map[string]attr.Value)
-
-		if v, ok := extIdClaim["location"].(string); ok {
-			values["location"] = types.StringValue(v)
-		}
-
-		if v, ok := extIdClaim["matcher"].(string); ok {
-			values["matcher"] = types.StringValue(v)
-		}
-
-		if v, ok := extIdClaim["parser"].(string); ok && v != "" {
-			values["parser"] = types.StringValue(v)
-		}
-
-		if v, ok := extIdClaim["matcherCriteria"].(string); ok && v != "" {
-			values["matchercriteria"] = types.StringValue(v)
-		}
-
-		if v, ok := extIdClaim["parserCriteria"].(string); ok && v != "" {
-			values["parsercriteria"] = types.StringValue(v)
-		}
-
-		if v, ok := extIdClaim["index"].(float64); ok {
-			values["index"] = types.Int64Value(int64(v))
-		}
-
-		obj, _ := types.ObjectValue(attrTypes, values)
-		state.ExternalIdClaim = obj
-
-	} else {
-		state.ExternalIdClaim = types.ObjectNull(ExternalIdClaimModel.AttrTypes)
-	}
-
-	if identityRoles, ok := data["identityRoles"].([]interface{}); ok {
-		identityRoles, diag := types.ListValueFrom(ctx, types.StringType, identityRoles)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.IdentityRoles = identityRoles
-	} else {
-		state.IdentityRoles = types.ListNull(types.StringType)
-	}
-
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
-	}
-
-	// Set refreshed state
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+		return "", err
 	}
+	return createdID(created.Payload)
 }
 
-// Update updates the resource and sets the updated Terraform state on success.
-func (r *certificateAuthorityResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	// Retrieve values from plan
-	var eplan certificateAuthorityResourceModel
-	tflog.Debug(ctx, "Updating Certificate Authority")
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	// Get current state
-	var state certificateAuthorityResourceModel
-	sdiags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(sdiags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func certificateAuthorityUpdate(ctx context.Context, client *zitiData, id string, eplan *certificateAuthorityResourceModel, diags *diag.Diagnostics) error {
 	name := eplan.Name.ValueString()
 	tags := TagsFromAttributes(eplan.Tags.Elements())
 
@@ -460,55 +275,71 @@ func (r *certificateAuthorityResource) Update(ctx context.Context, req resource.
 		Tags:                      tags,
 	}
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************update resource payload***********************:\n %s\n", jsonData)
+	_, err := client.api.CertificateAuthority.UpdateCa(&certificate_authority.UpdateCaParams{ID: id, Ca: &payload, Context: ctx}, nil)
+	return err
+}
 
-	authUrl := fmt.Sprintf("%s/cas/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := UpdateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti PATCH Response: %s", cresp)
-	log.Info().Msg(msg)
+func certificateAuthorityRead(ctx context.Context, client *zitiData, id string) (*rest_model.CaDetail, error) {
+	detail, err := client.api.CertificateAuthority.DetailCa(&certificate_authority.DetailCaParams{ID: id, Context: ctx}, nil)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Updating Certificate Authority", "Could not Update CA, unexpected error: "+err.Error(),
-		)
-		return
+		return nil, err
+	}
+	if detail.Payload == nil || detail.Payload.Data == nil {
+		return nil, errMissingData
 	}
+	return detail.Payload.Data, nil
+}
+
+func certificateAuthorityDelete(ctx context.Context, client *zitiData, id string) error {
+	_, err := client.api.CertificateAuthority.DeleteCa(&certificate_authority.DeleteCaParams{ID: id, Context: ctx}, nil)
+	return err
+}
 
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
+func certificateAuthorityReadData(ctx context.Context, detail *rest_model.CaDetail, state *certificateAuthorityResourceModel, diags *diag.Diagnostics) {
+	state.Name = types.StringPointerValue(detail.Name)
 
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+	if detail.IdentityNameFormat != nil {
+		state.IdentityNameFormat = types.StringValue(*detail.IdentityNameFormat)
 	}
-}
 
-// Delete deletes the resource and removes the Terraform state on success.
-func (r *certificateAuthorityResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	// Retrieve values from state
-	var state certificateAuthorityResourceModel
-	tflog.Debug(ctx, "Deleting Certificate Authority")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+	if detail.CertPem != nil {
+		state.CertPem = types.StringValue(*detail.CertPem)
 	}
 
-	authUrl := fmt.Sprintf("%s/cas/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
+	if detail.IsAuthEnabled != nil {
+		state.IsAuthEnabled = types.BoolValue(*detail.IsAuthEnabled)
+	}
 
-	cresp, err := DeleteZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti Delete Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Deleting Certificate Authority", "Could not DELETE Certificate Authority, unexpected error: "+err.Error(),
-		)
-		return
+	if detail.IsAutoCaEnrollmentEnabled != nil {
+		state.IsAutoCaEnrollmentEnabled = types.BoolValue(*detail.IsAutoCaEnrollmentEnabled)
+	}
+
+	if detail.IsOttCaEnrollmentEnabled != nil {
+		state.IsOttCaEnrollmentEnabled = types.BoolValue(*detail.IsOttCaEnrollmentEnabled)
 	}
+
+	state.ExternalIdClaim = externalIdClaimFromAPI(detail.ExternalIDClaim, diags)
+
+	state.IdentityRoles = stringSetFromAPI(ctx, detail.IdentityRoles, diags)
+	state.Tags = tagsFromAPI(ctx, detail.Tags, diags)
 }
 
-func (r *certificateAuthorityResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+// externalIdClaimFromAPI converts the external id claim of a fetched CA to the
+// external_id_claim attribute.
+func externalIdClaimFromAPI(extIdClaim *rest_model.ExternalIDClaim, diags *diag.Diagnostics) types.Object {
+	if extIdClaim == nil {
+		return types.ObjectNull(ExternalIdClaimModel.AttrTypes)
+	}
+	values := map[string]attr.Value{
+		"location":        types.StringPointerValue(extIdClaim.Location),
+		"matcher":         types.StringPointerValue(extIdClaim.Matcher),
+		"parser":          nonEmptyStringValue(extIdClaim.Parser),
+		"matchercriteria": types.StringPointerValue(extIdClaim.MatcherCriteria),
+		"parsercriteria":  types.StringPointerValue(extIdClaim.ParserCriteria),
+		"index":           types.Int64PointerValue(extIdClaim.Index),
+	}
+
+	obj, diag := types.ObjectValue(ExternalIdClaimModel.AttrTypes, values)
+	diags.Append(diag...)
+	return obj
 }
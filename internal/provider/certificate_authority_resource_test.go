@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"testing"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+// testCertPem is a PEM certificate argument, escaped for a quoted HCL string.
+// The fake controller does not parse it.
+const testCertPem = `-----BEGIN CERTIFICATE-----\nMIIBszCCAVmgAwIBAgIUTEST\n-----END CERTIFICATE-----\n`
+
+func TestAccCertificateAuthorityResource(t *testing.T) {
+	controller := testAccController(t)
+	const resourceName = "ziti_certificate_authority.test"
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_certificate_authority" "test" {
+  name                        = "test-ca"
+  cert_pem                    = "` + testCertPem + `"
+  identityroles               = ["#devices", "#clients"]
+  is_auth_enabled             = true
+  is_ottca_enrollment_enabled = true
+}
+`
+	update := testAccProviderConfig(controller, "") + `
+resource "ziti_certificate_authority" "test" {
+  name                         = "test-ca"
+  cert_pem                     = "` + testCertPem + `"
+  identityroles                = ["#devices", "#clients"]
+  is_auth_enabled              = true
+  is_ottca_enrollment_enabled  = true
+  is_autoca_enrollment_enabled = true
+  identity_name_format         = "[caName]-[requestedName]"
+  external_id_claim = {
+    location        = "SAN_EMAIL"
+    matcher         = "SUFFIX"
+    matchercriteria = "@example.com"
+    parsercriteria  = ""
+  }
+  tags = { env = "test" }
+}
+`
+
+	steps := []resource.TestStep{{
+		Config: config,
+		Check: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckEntityExists(controller, mockcontroller.CAs, resourceName),
+			resource.TestCheckResourceAttr(resourceName, "name", "test-ca"),
+			resource.TestCheckTypeSetElemAttr(resourceName, "identityroles.*", "#devices"),
+			resource.TestCheckTypeSetElemAttr(resourceName, "identityroles.*", "#clients"),
+			resource.TestCheckResourceAttr(resourceName, "identity_name_format", "[caName]-[commonName]"),
+		),
+	}}
+	steps = append(steps, testAccImportSteps(resourceName)...)
+	steps = append(steps, testAccUpdateStep(resourceName, update,
+		resource.TestCheckResourceAttr(resourceName, "is_autoca_enrollment_enabled", "true"),
+		resource.TestCheckResourceAttr(resourceName, "identity_name_format", "[caName]-[requestedName]"),
+		resource.TestCheckResourceAttr(resourceName, "external_id_claim.location", "SAN_EMAIL"),
+		resource.TestCheckResourceAttr(resourceName, "external_id_claim.matchercriteria", "@example.com"),
+		resource.TestCheckResourceAttr(resourceName, "tags.env", "test"),
+	))
+	steps = append(steps, testAccOutOfBandSteps(t, controller, mockcontroller.CAs, "test-ca", resourceName, update,
+		map[string]interface{}{"isAuthEnabled": false})...)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    steps,
+	})
+}
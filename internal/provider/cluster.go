@@ -0,0 +1,203 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+type clusterMember struct {
+	Address   string `json:"address"`
+	Connected bool   `json:"connected"`
+	ID        string `json:"id"`
+	Leader    bool   `json:"leader"`
+	ReadOnly  bool   `json:"readOnly"`
+	Version   string `json:"version"`
+	Voter     bool   `json:"voter"`
+}
+
+// fetchClusterMembers calls /fabric/v1/cluster/list-members and returns the member list.
+func fetchClusterMembers(httpClient *http.Client, activeHost, token string) ([]clusterMember, error) {
+	u, err := url.Parse(activeHost)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host URL: %w", err)
+	}
+	clusterURL := fmt.Sprintf("%s://%s/fabric/v1/cluster/list-members", u.Scheme, u.Host)
+	req, err := http.NewRequest("GET", clusterURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build cluster request: %w", err)
+	}
+	req.Header.Set("zt-session", token)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cluster request failed: %w", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	defer resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error reading cluster response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cluster list-members status %d: %s", resp.StatusCode, string(body))
+	}
+	var result struct {
+		Data []clusterMember `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("error parsing cluster response: %w", err)
+	}
+	return result.Data, nil
+}
+
+// memberToHost converts a cluster member tls address (e.g. "tls:HOST:PORT") to
+// an https URL preserving the base path of the original host.
+func memberToHost(address, originalHost string) (string, error) {
+	addr := strings.TrimPrefix(address, "tls:")
+	u, err := url.Parse(originalHost)
+	if err != nil {
+		return "", fmt.Errorf("invalid original host: %w", err)
+	}
+	return fmt.Sprintf("https://%s%s", addr, u.Path), nil
+}
+
+// clusterRouter keeps the member list of an HA controller cluster for the
+// lifetime of the provider, so that requests can follow the leader when
+// leadership moves and fail over when a controller becomes unreachable.
+type clusterRouter struct {
+	httpClient *http.Client
+	// seeds are the configured controllers, used when no discovered member answers.
+	seeds []string
+
+	mu      sync.Mutex
+	members []clusterMember
+}
+
+func newClusterRouter(httpClient *http.Client, seeds []string) *clusterRouter {
+	return &clusterRouter{httpClient: httpClient, seeds: seeds}
+}
+
+// refresh re-reads the member list from the controller at host.
+func (r *clusterRouter) refresh(host, token string) error {
+	members, err := fetchClusterMembers(r.httpClient, host, token)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.members = members
+	r.mu.Unlock()
+	return nil
+}
+
+// candidates returns the controllers worth trying, in order of preference: the
+// leader, the other connected members, then the configured seeds. exclude is
+// left out so that a failed controller is not retried straight away.
+func (r *clusterRouter) candidates(ctx context.Context, baseHost, exclude string) []string {
+	r.mu.Lock()
+	members := append([]clusterMember(nil), r.members...)
+	r.mu.Unlock()
+
+	// Sort so the leader is tried first.
+	leaderFirst := make([]clusterMember, 0, len(members))
+	for _, m := range members {
+		if m.Leader {
+			leaderFirst = append([]clusterMember{m}, leaderFirst...)
+		} else {
+			leaderFirst = append(leaderFirst, m)
+		}
+	}
+
+	var hosts []string
+	seen := map[string]bool{exclude: true}
+	for _, m := range leaderFirst {
+		if !m.Connected {
+			continue
+		}
+		memberHost, err := memberToHost(m.Address, baseHost)
+		if err != nil {
+			tflog.SubsystemWarn(ctx, logSubsystemCluster, "Skipping cluster member with bad address", map[string]any{"member": m.ID, "address": m.Address, "error": err.Error()})
+			continue
+		}
+		if !seen[memberHost] {
+			seen[memberHost] = true
+			hosts = append(hosts, memberHost)
+		}
+	}
+	for _, h := range r.seeds {
+		if !seen[h] {
+			seen[h] = true
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
+}
+
+// leaderHost returns the URL of the current leader, or "" when it is unknown.
+func (r *clusterRouter) leaderHost(baseHost string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range r.members {
+		if m.Leader && m.Connected {
+			if h, err := memberToHost(m.Address, baseHost); err == nil {
+				return h
+			}
+		}
+	}
+	return ""
+}
+
+// connect picks a new controller after failedHost stopped answering or refused
+// a write. It authenticates against the first reachable candidate and refreshes
+// the member list from it. Reads may stay on any connected member; writes move
+// on to the leader that member reports.
+func (r *clusterRouter) connect(ctx context.Context, authenticate func(string) (string, error), baseHost, failedHost string, write bool) (string, string, error) {
+	ctx = newLogSubsystem(ctx, logSubsystemCluster)
+	var errs []string
+	for _, h := range r.candidates(ctx, baseHost, failedHost) {
+		token, err := authenticate(h)
+		if err != nil {
+			tflog.SubsystemWarn(ctx, logSubsystemCluster, "Failover to Ziti controller failed", map[string]any{"host": h, "error": err.Error()})
+			errs = append(errs, fmt.Sprintf("%s: %v", h, err))
+			continue
+		}
+		if err := r.refresh(h, token); err != nil {
+			tflog.SubsystemWarn(ctx, logSubsystemCluster, "Could not fetch cluster members", map[string]any{"host": h, "error": err.Error()})
+		}
+		if leader := r.leaderHost(baseHost); write && leader != "" && leader != h && leader != failedHost {
+			if leaderToken, err := authenticate(leader); err == nil {
+				tflog.SubsystemInfo(ctx, logSubsystemCluster, "Failing over to cluster leader", map[string]any{"host": leader})
+				return leader, leaderToken, nil
+			}
+		}
+		tflog.SubsystemInfo(ctx, logSubsystemCluster, "Failing over to Ziti controller", map[string]any{"host": h})
+		return h, token, nil
+	}
+	if len(errs) == 0 {
+		return "", "", errors.New("no other Ziti controller is known")
+	}
+	return "", "", fmt.Errorf("no Ziti controller reachable: %s", strings.Join(errs, "; "))
+}
+
+// notLeaderErrorCodes are the error codes of a controller that cannot apply
+// a write because it is not the cluster leader, or the cluster has none, e.g.
+// while an election runs.
+var notLeaderErrorCodes = map[string]bool{
+	"CLUSTER_HAS_NO_LEADER": true,
+	"NOT_LEADER":            true,
+}
+
+// isNotLeaderResponse reports whether the controller refused a request because
+// it is not, or no longer knows, the cluster leader.
+func isNotLeaderResponse(resp *http.Response, body []byte) bool {
+	if resp == nil || resp.StatusCode < http.StatusBadRequest {
+		return false
+	}
+	return notLeaderErrorCodes[zitiAPIErrorFromBody(resp.StatusCode, body).Code]
+}
@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIsNotLeaderResponse(t *testing.T) {
+	t.Parallel()
+	for body, want := range map[string]bool{
+		`{"error": {"code": "CLUSTER_HAS_NO_LEADER", "message": "cluster has no leader"}}`:    true,
+		`{"error": {"code": "NOT_LEADER", "message": "node is not the leader"}}`:              true,
+		`{"error": {"code": "COULD_NOT_VALIDATE", "message": "name: not leader-compatible"}}`: false,
+		`no leader`: false,
+	} {
+		resp := &http.Response{StatusCode: http.StatusServiceUnavailable}
+		if got := isNotLeaderResponse(resp, []byte(body)); got != want {
+			t.Errorf("isNotLeaderResponse(%s) = %t, want %t", body, got, want)
+		}
+	}
+}
+
+// newFailoverClient returns a client of a two controller cluster whose active
+// controller, the first, drops every connection after reading the request.
+// The requests that reach the second controller are counted in replayed.
+func newFailoverClient(t *testing.T, authenticate func(host string) (string, error), replayed *atomic.Int32) *zitiData {
+	t.Helper()
+	var dropped atomic.Int32
+	failing := newDroppingServer(t, &dropped)
+	standby := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/edge/management/v1/services" {
+			replayed.Add(1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data": {}}`))
+	}))
+	t.Cleanup(standby.Close)
+
+	client := newHTTPClient(nil, httpClientOptions{requestTimeout: 5 * time.Second})
+	host := failing.URL + "/edge/management/v1"
+	return &zitiData{
+		host:         host,
+		activeHost:   host,
+		apiToken:     "failing",
+		httpClient:   client,
+		authenticate: authenticate,
+		router:       newClusterRouter(client.StandardClient(), []string{host, standby.URL + "/edge/management/v1"}),
+	}
+}
+
+func TestFailoverReplaysOnlyIdempotentRequests(t *testing.T) {
+	t.Parallel()
+	var replayed atomic.Int32
+	d := newFailoverClient(t, func(string) (string, error) { return "standby", nil }, &replayed)
+
+	if _, _, err := d.send(context.Background(), http.MethodPost, d.host+"/services", nil, []byte(`{}`)); err == nil {
+		t.Error("a create that failed after it was sent succeeded")
+	}
+	if got := replayed.Load(); got != 0 {
+		t.Errorf("the create was replayed %d times on the standby controller", got)
+	}
+	if host, token := d.session(); token != "standby" || host == d.host {
+		t.Errorf("the session is still on %s after the failover", host)
+	}
+
+	d.activeHost, d.apiToken = d.host, "failing"
+	resp, _, err := d.send(context.Background(), http.MethodGet, d.host+"/services", nil, nil)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("a read was not replayed on the standby controller: %v", err)
+	}
+	if got := replayed.Load(); got != 1 {
+		t.Errorf("the read reached the standby controller %d times, want 1", got)
+	}
+}
+
+func TestFailoverDoesNotBlockSession(t *testing.T) {
+	t.Parallel()
+	var replayed atomic.Int32
+	authenticating := make(chan struct{})
+	release := make(chan struct{})
+	d := newFailoverClient(t, func(string) (string, error) {
+		close(authenticating)
+		<-release
+		return "standby", nil
+	}, &replayed)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _, _ = d.send(context.Background(), http.MethodGet, d.host+"/services", nil, nil)
+	}()
+	<-authenticating
+	sessionRead := make(chan struct{})
+	go func() {
+		d.session()
+		close(sessionRead)
+	}()
+	select {
+	case <-sessionRead:
+	case <-time.After(2 * time.Second):
+		t.Error("the session is locked while the failover authenticates")
+	}
+	close(release)
+	<-done
+}
@@ -2,70 +2,413 @@ package provider
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"errors"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 
+	httptransport "github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/strfmt"
+	"github.com/hashicorp/go-cleanhttp"
 	"github.com/hashicorp/go-retryablehttp"
-	"github.com/rs/zerolog/log"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/openziti/edge-api/rest_management_api_client"
+	"golang.org/x/time/rate"
 )
 
 var errNotFound = errors.New("requested resource was not found")
 
-func doRequest(method, url, sessionToken string, body []byte) (string, error) {
+// httpClientOptions configures the HTTP client shared by every provider request.
+type httpClientOptions struct {
+	maxRetries     int
+	retryWaitMin   time.Duration
+	retryWaitMax   time.Duration
+	requestTimeout time.Duration
+
+	// maxConcurrentRequests caps the requests in flight against the controller;
+	// zero means unlimited.
+	maxConcurrentRequests int
+	// requestsPerSecond caps the request rate against the controller; zero
+	// means unlimited.
+	requestsPerSecond float64
+}
+
+// newHTTPClient builds the pooled, retrying HTTP client used for every call to
+// the controller. Connections are kept alive and reused across resources.
+func newHTTPClient(tlsCfg *tls.Config, opts httpClientOptions) *retryablehttp.Client {
+	transport := cleanhttp.DefaultPooledTransport()
+	transport.TLSClientConfig = tlsCfg
+
 	httpClient := retryablehttp.NewClient()
-	httpClient.HTTPClient.Transport = &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	httpClient.HTTPClient = &http.Client{
+		Transport: newLimitedTransport(transport, opts.maxConcurrentRequests, opts.requestsPerSecond),
+		Timeout:   opts.requestTimeout,
+	}
+	httpClient.RetryMax = opts.maxRetries
+	httpClient.RetryWaitMin = opts.retryWaitMin
+	httpClient.RetryWaitMax = opts.retryWaitMax
+	httpClient.CheckRetry = zitiRetryPolicy
+	// DefaultBackoff waits for the Retry-After header on 429 and 503 responses
+	// and backs off exponentially between retryWaitMin and retryWaitMax otherwise.
+	httpClient.Backoff = retryablehttp.DefaultBackoff
+	// Hand the last response back once retries are exhausted so that the
+	// controller's error body reaches the caller.
+	httpClient.ErrorHandler = retryablehttp.PassthroughErrorHandler
+	httpClient.Logger = nil
+	return httpClient
+}
+
+// limitedTransport throttles every request attempt sent to the controller,
+// including retries and authentication calls, regardless of how many resources
+// Terraform runs in parallel.
+type limitedTransport struct {
+	next    http.RoundTripper
+	slots   chan struct{}
+	limiter *rate.Limiter
+}
+
+func newLimitedTransport(next http.RoundTripper, maxConcurrent int, requestsPerSecond float64) http.RoundTripper {
+	if maxConcurrent <= 0 && requestsPerSecond <= 0 {
+		return next
+	}
+	t := &limitedTransport{next: next}
+	if maxConcurrent > 0 {
+		t.slots = make(chan struct{}, maxConcurrent)
+	}
+	if requestsPerSecond > 0 {
+		burst := int(requestsPerSecond)
+		if burst < 1 {
+			burst = 1
+		}
+		t.limiter = rate.NewLimiter(rate.Limit(requestsPerSecond), burst)
 	}
+	return t
+}
 
-	req, _ := retryablehttp.NewRequest(method, url, bytes.NewBuffer(body))
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("zt-session", sessionToken)
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if t.limiter != nil {
+		if err := t.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if t.slots == nil {
+		return t.next.RoundTrip(req)
+	}
 
-	resp, err := httpClient.Do(req)
+	select {
+	case t.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release := sync.OnceFunc(func() { <-t.slots })
+	resp, err := t.next.RoundTrip(req)
 	if err != nil {
-		log.Error().Msgf("Request failed on url: %s, ERR: %v", url, err)
-		return "", err
+		release()
+		return nil, err
 	}
+	// Hold the slot until the response body has been consumed.
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releasingBody frees a concurrency slot once the response body is closed.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
 
-	if resp.StatusCode == http.StatusNotFound {
-		return "", errNotFound
+// zitiRetryPolicy retries connection errors and responses that signal a
+// transient controller condition. Requests that are not idempotent, such as
+// creates, may already have been applied when the connection fails after they
+// were sent, or when a gateway times out; they are only retried when the
+// request never left, or the controller turned it away with 429 or 503.
+// Other 5xx responses are never retried.
+func zitiRetryPolicy(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	if ctx.Err() != nil {
+		return false, ctx.Err()
 	}
+	if err != nil {
+		if !isIdempotentMethod(requestMethod(err)) && !requestNotSent(err) {
+			return false, nil
+		}
+		return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true, nil
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return resp.Request == nil || isIdempotentMethod(resp.Request.Method), nil
+	}
+	return false, nil
+}
 
-	body, err = io.ReadAll(resp.Body)
-	defer resp.Body.Close()
+// isIdempotentMethod reports whether a request with method can be replayed
+// without changing its outcome.
+func isIdempotentMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// requestMethod returns the method of the request that failed with err, as
+// recorded by http.Client in its *url.Error, or "" if it is unknown.
+func requestMethod(err error) string {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Op
+	}
+	return ""
+}
+
+// requestNotSent reports whether err proves that the request never reached
+// the controller: the host could not be resolved or the connection was not
+// established.
+func requestNotSent(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// canReplay reports whether a request that failed with err can be sent again,
+// e.g. to another controller of an HA cluster.
+func canReplay(method string, err error) bool {
+	return isIdempotentMethod(method) || requestNotSent(err)
+}
+
+// sessionToken returns the zt-session token currently in use.
+func (d *zitiData) sessionToken() string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.apiToken
+}
+
+// session returns the controller requests are currently sent to and the
+// zt-session token that is valid there.
+func (d *zitiData) session() (string, string) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.activeHost, d.apiToken
+}
+
+// reauthenticate replaces an expired session token by re-running the auth flow
+// selected in Configure. staleToken is the token the caller was rejected with;
+// when another request has already refreshed it, the new session is returned
+// without authenticating again, so parallel resources share a single re-auth.
+func (d *zitiData) reauthenticate(staleToken string) (string, string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.apiToken != staleToken {
+		return d.activeHost, d.apiToken, nil
+	}
+	if d.authenticate == nil {
+		return "", "", errors.New("session expired and no authentication method is configured")
+	}
+	token, err := d.authenticate(d.activeHost)
 	if err != nil {
-		log.Error().Msgf("Error Reading Ziti Resource Response: %v", err)
-		return "", err
+		return "", "", fmt.Errorf("re-authentication failed: %w", err)
 	}
+	d.apiToken = token
+	return d.activeHost, token, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode != http.StatusCreated {
-			log.Printf("Unexpected status code: %d %s", resp.StatusCode, resp.Status)
-			return string(body), errors.New(string(body))
+// failover moves the session away from failedHost to another member of the HA
+// cluster, or to its leader when write is true. reachable reports whether
+// failedHost still answered, in which case its view of the cluster is read
+// first. When another request has already failed over, the new session is
+// returned as is.
+//
+// The cluster is contacted without holding mu, so requests on the current
+// session are not blocked while the failover authenticates; the new session
+// is swapped in once it is ready.
+func (d *zitiData) failover(ctx context.Context, failedHost string, write, reachable bool) (string, string, error) {
+	d.failoverMu.Lock()
+	defer d.failoverMu.Unlock()
+	activeHost, staleToken := d.session()
+	if activeHost != failedHost {
+		return activeHost, staleToken, nil
+	}
+	if reachable {
+		if err := d.router.refresh(failedHost, staleToken); err != nil {
+			tflog.SubsystemWarn(ctx, logSubsystemCluster, "Could not fetch cluster members", map[string]any{"host": failedHost, "error": err.Error()})
 		}
 	}
+	host, token, err := d.router.connect(ctx, d.authenticate, d.host, failedHost, write)
+	if err != nil {
+		return "", "", fmt.Errorf("failover from %s failed: %w", failedHost, err)
+	}
 
-	return string(body), nil
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.activeHost != failedHost {
+		// The session was replaced meanwhile, e.g. by a re-authentication
+		// against a controller that answered again.
+		return d.activeHost, d.apiToken, nil
+	}
+	d.activeHost = host
+	d.apiToken = token
+	return host, token, nil
 }
 
-func CreateZitiResource(requestURL string, sessionToken string, payloadData []byte) (string, error) {
-	return doRequest(http.MethodPost, requestURL, sessionToken, payloadData)
+// routeURL rewrites a request URL built from d.host to target activeHost.
+// URLs outside the management API, e.g. /.well-known/est/cacerts, are built
+// with controllerURL and keep their path on activeHost.
+func (d *zitiData) routeURL(requestURL, activeHost string) string {
+	if activeHost == "" || activeHost == d.host {
+		return requestURL
+	}
+	if strings.HasPrefix(requestURL, d.host) {
+		return activeHost + strings.TrimPrefix(requestURL, d.host)
+	}
+	if origin := controllerURL(d.host, ""); origin != "" && strings.HasPrefix(requestURL, origin+"/") {
+		return controllerURL(activeHost, strings.TrimPrefix(requestURL, origin))
+	}
+	return requestURL
 }
 
-func ReadZitiResource(requestURL string, sessionToken string) (string, error) {
-	return doRequest(http.MethodGet, requestURL, sessionToken, nil)
+// controllerURL returns the URL of path on the controller at host, e.g.
+// /edge/client/v1.
+func controllerURL(host, p string) string {
+	u, err := url.Parse(host)
+	if err != nil || u.Host == "" {
+		return p
+	}
+	return u.Scheme + "://" + u.Host + p
 }
 
-func UpdateZitiResource(requestURL string, sessionToken string, payloadData []byte) (string, error) {
-	return doRequest(http.MethodPut, requestURL, sessionToken, payloadData)
+func (d *zitiData) sendRequest(ctx context.Context, method, url, sessionToken string, header http.Header, body []byte) (*http.Response, []byte, error) {
+	req, err := retryablehttp.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("zt-session", sessionToken)
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		tflog.SubsystemError(ctx, logSubsystemClient, "Request failed", map[string]any{"method": method, "url": url, "error": err.Error()})
+		return nil, nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	defer resp.Body.Close()
+	if err != nil {
+		tflog.SubsystemError(ctx, logSubsystemClient, "Error reading Ziti response", map[string]any{"method": method, "url": url, "error": err.Error()})
+		return nil, nil, err
+	}
+	tflog.SubsystemDebug(ctx, logSubsystemClient, "Ziti API request", map[string]any{"method": method, "url": url, "status": resp.StatusCode})
+	return resp, respBody, nil
 }
 
-func PatchZitiResource(requestURL string, sessionToken string, payloadData []byte) (string, error) {
-	return doRequest(http.MethodPatch, requestURL, sessionToken, payloadData)
+// send sends a request with the current session to the active controller. It
+// fails over to another controller of an HA cluster and re-authenticates an
+// expired session as needed, so callers only see the final response.
+func (d *zitiData) send(ctx context.Context, method, url string, header http.Header, body []byte) (*http.Response, []byte, error) {
+	host, token := d.session()
+	ctx = newLogSubsystem(ctx, logSubsystemClient, token)
+	resp, respBody, err := d.sendRequest(ctx, method, d.routeURL(url, host), token, header, body)
+
+	// In an HA cluster, move to another controller when this one is unreachable
+	// or refuses a write because it is not the leader, and replay the request.
+	// A request that failed after it may have reached the controller is only
+	// replayed when it is idempotent; a write is reported instead, as it may
+	// already have been applied. Later requests use the new controller.
+	if d.router != nil && ctx.Err() == nil && (err != nil || isNotLeaderResponse(resp, respBody)) {
+		tflog.SubsystemWarn(ctx, logSubsystemClient, "Ziti controller unavailable, failing over", map[string]any{"host": host, "method": method, "url": url})
+		failedErr := err
+		host, token, err = d.failover(ctx, host, method != http.MethodGet, err == nil)
+		if err != nil {
+			if failedErr != nil {
+				return nil, nil, fmt.Errorf("%w; %v", failedErr, err)
+			}
+			return nil, nil, err
+		}
+		if failedErr != nil && !canReplay(method, failedErr) {
+			return nil, nil, fmt.Errorf("%w; the request was not replayed on %s as it may already have been applied", failedErr, host)
+		}
+		ctx = tflog.SubsystemMaskLogStrings(ctx, logSubsystemClient, token)
+		resp, respBody, err = d.sendRequest(ctx, method, d.routeURL(url, host), token, header, body)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The controller answers 401 once the API session has expired or been
+	// removed; authenticate again and replay the request once.
+	if resp.StatusCode == http.StatusUnauthorized {
+		tflog.SubsystemInfo(ctx, logSubsystemClient, "Ziti session rejected, re-authenticating", map[string]any{"method": method, "url": url})
+		host, token, err = d.reauthenticate(token)
+		if err != nil {
+			return nil, nil, err
+		}
+		ctx = tflog.SubsystemMaskLogStrings(ctx, logSubsystemClient, token)
+		resp, respBody, err = d.sendRequest(ctx, method, d.routeURL(url, host), token, header, body)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return resp, respBody, nil
 }
 
-func DeleteZitiResource(requestURL string, sessionToken string) (string, error) {
-	return doRequest(http.MethodDelete, requestURL, sessionToken, nil)
+// sessionTransport is the http.RoundTripper of the typed management API client.
+type sessionTransport struct {
+	client *zitiData
+}
+
+// RoundTrip lets the typed management API client send its requests through
+// send, so they share the session, failover and re-authentication handling.
+func (t *sessionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resp, respBody, err := t.client.send(req.Context(), req.Method, req.URL.String(), req.Header, body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	return resp, nil
+}
+
+// newManagementClient builds the typed management API client for the
+// controller at d.host. Its requests are sent through d.send.
+func newManagementClient(d *zitiData) (*rest_management_api_client.ZitiEdgeManagement, error) {
+	hostURL, err := url.Parse(d.host)
+	if err != nil {
+		return nil, err
+	}
+	transport := httptransport.NewWithClient(hostURL.Host, hostURL.Path, []string{hostURL.Scheme}, &http.Client{Transport: &sessionTransport{client: d}})
+	// The runtime dumps every request, session token included, to stderr when
+	// DEBUG is set in the environment.
+	transport.SetDebug(false)
+	return rest_management_api_client.New(transport, strfmt.Default), nil
 }
@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// newDroppingServer accepts every request and closes the connection without
+// answering, as a controller that fails after applying a request would.
+func newDroppingServer(t *testing.T, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRetryPolicyDoesNotReplayWrites(t *testing.T) {
+	t.Parallel()
+	client := newHTTPClient(nil, httpClientOptions{maxRetries: 2, retryWaitMin: time.Millisecond, retryWaitMax: time.Millisecond, requestTimeout: 5 * time.Second})
+	for method, want := range map[string]int32{
+		http.MethodPost:   1,
+		http.MethodPatch:  1,
+		http.MethodGet:    3,
+		http.MethodDelete: 3,
+	} {
+		var requests atomic.Int32
+		server := newDroppingServer(t, &requests)
+		req, _ := http.NewRequest(method, server.URL+"/edge/management/v1/services", nil)
+		if resp, err := client.StandardClient().Do(req); err == nil {
+			resp.Body.Close()
+			t.Fatalf("%s: the dropped connection did not fail the request", method)
+		}
+		if got := requests.Load(); got != want {
+			t.Errorf("%s: the controller received %d requests, want %d", method, got, want)
+		}
+	}
+}
+
+func TestRetryPolicyRetriesUnsentWrites(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	var attempts atomic.Int32
+	client := newHTTPClient(nil, httpClientOptions{maxRetries: 2, retryWaitMin: time.Millisecond, retryWaitMax: time.Millisecond, requestTimeout: 5 * time.Second})
+	client.RequestLogHook = func(_ retryablehttp.Logger, _ *http.Request, _ int) { attempts.Add(1) }
+	req, _ := http.NewRequest(http.MethodPost, url+"/edge/management/v1/services", nil)
+	if resp, err := client.StandardClient().Do(req); err == nil {
+		resp.Body.Close()
+		t.Fatal("a request to a closed port succeeded")
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("a refused POST was attempted %d times, want 3", got)
+	}
+}
@@ -0,0 +1,147 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/openziti/edge-api/rest_management_api_client/config"
+	"github.com/openziti/edge-api/rest_model"
+)
+
+// configList lists the configs matching filter.
+func configList(ctx context.Context, client *zitiData, filter string) ([]*rest_model.ConfigDetail, error) {
+	list, err := client.api.Config.ListConfigs(&config.ListConfigsParams{Filter: optionalFilter(filter), Context: ctx}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if list.Payload == nil {
+		return nil, errMissingData
+	}
+	return withoutNil(list.Payload.Data), nil
+}
+
+// configPage lists a page of the configs matching filter.
+func configPage(ctx context.Context, client *zitiData, filter string, offset, limit int64) ([]*rest_model.ConfigDetail, *rest_model.Pagination, error) {
+	list, err := client.api.Config.ListConfigs(&config.ListConfigsParams{Filter: optionalFilter(filter), Offset: &offset, Limit: &limit, Context: ctx}, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if list.Payload == nil {
+		return nil, nil, errMissingData
+	}
+	return withoutNil(list.Payload.Data), paginationOf(list.Payload.Meta), nil
+}
+
+// checkConfigType returns an error unless configType is the ID or name of the
+// config type of a fetched config. It lets an import ID such as
+// "name=web,config_type=host.v1" assert the type of the imported config.
+func checkConfigType(detail *rest_model.ConfigDetail, configType string) error {
+	typeID := types.StringPointerValue(detail.ConfigTypeID).ValueString()
+	typeName := ""
+	if detail.ConfigType != nil {
+		typeName = detail.ConfigType.Name
+	}
+	if configType == typeID || configType == typeName {
+		return nil
+	}
+	return fmt.Errorf("config %s is of type %s (%s), not %s", types.StringPointerValue(detail.Name).ValueString(), typeName, typeID, configType)
+}
+
+// decodeConfigData decodes the data of a fetched config into dto, e.g. a
+// *HostConfigDTO. Data that does not have the shape of dto is reported to diags
+// as an error of the configType config and returns false.
+func decodeConfigData(detail *rest_model.ConfigDetail, configType string, dto interface{}, diags *diag.Diagnostics) bool {
+	err := errMissingData
+	if detail.Data != nil {
+		var encoded []byte
+		encoded, err = json.Marshal(detail.Data)
+		if err == nil {
+			err = json.Unmarshal(encoded, dto)
+		}
+	}
+	if err != nil {
+		diags.AddError(
+			"Invalid Config Data", "Could not decode the data of "+configType+" config "+types.StringPointerValue(detail.ID).ValueString()+": "+err.Error(),
+		)
+		return false
+	}
+	return true
+}
+
+// configsDataSourceItem maps a config of the ziti_configs data source. Its data
+// is kept as JSON since every config type has a different shape.
+type configsDataSourceItem struct {
+	ID             types.String `tfsdk:"id"`
+	Name           types.String `tfsdk:"name"`
+	ConfigTypeID   types.String `tfsdk:"config_type_id"`
+	ConfigTypeName types.String `tfsdk:"config_type_name"`
+	Data           types.String `tfsdk:"data"`
+	Tags           types.Map    `tfsdk:"tags"`
+}
+
+// configsDataSourceItemState copies a config fetched by the ziti_configs data
+// source into an item.
+func configsDataSourceItemState(ctx context.Context, detail *rest_model.ConfigDetail, item *configsDataSourceItem, diags *diag.Diagnostics) {
+	item.ID = types.StringPointerValue(detail.ID)
+	item.Name = types.StringPointerValue(detail.Name)
+	item.ConfigTypeID = types.StringPointerValue(detail.ConfigTypeID)
+	item.ConfigTypeName = types.StringNull()
+	if detail.ConfigType != nil {
+		item.ConfigTypeName = types.StringValue(detail.ConfigType.Name)
+	}
+	item.Data = types.StringNull()
+	if detail.Data != nil {
+		encoded, err := json.Marshal(detail.Data)
+		if err != nil {
+			diags.AddError(
+				"Invalid Config Data", "Could not encode the data of config "+item.ID.ValueString()+": "+err.Error(),
+			)
+			return
+		}
+		item.Data = types.StringValue(string(encoded))
+	}
+	item.Tags = tagsFromAPI(ctx, detail.Tags, diags)
+}
+
+// NewConfigsDataSource is a helper function to simplify the provider implementation.
+func NewConfigsDataSource() datasource.DataSource {
+	return &zitiListDataSource[*rest_model.ConfigDetail, configsDataSourceItem]{spec: zitiListDataSourceSpec[*rest_model.ConfigDetail, configsDataSourceItem]{
+		typeName: "_configs",
+		label:    "configs",
+		items:    "configs",
+		itemAttributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Identifier",
+			},
+			"name": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Name of the config",
+			},
+			"config_type_id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "ID of the config type",
+			},
+			"config_type_name": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Name of the config type, e.g. `host.v1`",
+			},
+			"data": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Data of the config, encoded as JSON. Use `jsondecode` to read it.",
+			},
+			"tags": schema.MapAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Config Tags",
+			},
+		},
+		page: configPage,
+		item: configsDataSourceItemState,
+	}}
+}
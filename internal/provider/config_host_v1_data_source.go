@@ -2,13 +2,10 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/rs/zerolog/log"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -37,9 +34,6 @@ func (r *hostV1ConfigDataSource) Configure(_ context.Context, req datasource.Con
 
 	datasourceConfig := req.ProviderData.(*zitiData)
 	r.datasourceConfig = datasourceConfig
-
-	fmt.Printf("Using API Token to create datasource: %s\n", r.datasourceConfig.apiToken)
-	fmt.Printf("Using domain to create datasource: %s\n", r.datasourceConfig.host)
 }
 
 // Metadata returns the datasource type name.
@@ -318,78 +312,23 @@ func (r *hostV1ConfigDataSource) Read(ctx context.Context, req datasource.ReadRe
 		return
 	}
 
-	filter := ""
-	if state.Name.ValueString() != "" {
-		filter = "filter=name=\"" + state.Name.ValueString() + "\""
-	}
-	if state.ID.ValueString() != "" {
-		filter = "filter=id=\"" + state.ID.ValueString() + "\""
-	}
-
-	authUrl := fmt.Sprintf("%s/configs?%s", r.datasourceConfig.host, filter)
-	cresp, err := ReadZitiResource(authUrl, r.datasourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Reading configs", "Could not READ configs, unexpected error: "+err.Error(),
-		)
+	filter := dataSourceFilter(state.ID, state.Name)
+	detail, ok := lookupDataSourceEntity(ctx, r.datasourceConfig, &resp.Diagnostics, "configs", filter, configList)
+	if !ok {
 		return
 	}
 
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
-	if err != nil {
-		log.Error().Msgf("Error unmarshalling JSON response from Ziti DataSource Response: %v", err)
-		fmt.Println("Error unmarshalling JSON:", err)
-		return
-	}
-
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	_config := jsonBody["data"].([]interface{})
-	if len(_config) > 1 {
-		resp.Diagnostics.AddError(
-			"Multiple items returned from API upon filter execution!", "Try to narrow down the filter expression"+filter,
-		)
-	}
-	if len(_config) == 0 {
-		resp.Diagnostics.AddError(
-			"No items returned from API upon filter execution!", "Try to relax the filter expression: "+filter,
-		)
-	}
-	if resp.Diagnostics.HasError() {
+	var hostConfigDto HostConfigDTO
+	if !decodeConfigData(detail, "host.v1", &hostConfigDto, &resp.Diagnostics) {
 		return
 	}
-
-	data := _config[0].(map[string]interface{})
-	datasourceData := data["data"].(map[string]interface{})
-
-	var hostConfigDto HostConfigDTO
-	GenericFromObject(datasourceData, &hostConfigDto)
 	resourceState := hostConfigDto.ConvertToZitiResourceModel(ctx)
 	newState := ResourceModelToDataSourceModel(resourceState)
 
-	// Manually assign individual values from the map to the struct fields
-	state.Name = types.StringValue(data["name"].(string))
-	state.ID = types.StringValue(data["id"].(string))
-	state.ConfigTypeId = types.StringValue(data["configTypeId"].(string))
-
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
-	}
-
-	newState.ID = state.ID
-	newState.Name = state.Name
-	newState.ConfigTypeId = state.ConfigTypeId
-	newState.Tags = state.Tags
+	newState.ID = types.StringPointerValue(detail.ID)
+	newState.Name = types.StringPointerValue(detail.Name)
+	newState.ConfigTypeId = types.StringPointerValue(detail.ConfigTypeID)
+	newState.Tags = tagsFromAPI(ctx, detail.Tags, &resp.Diagnostics)
 	state = newState
 
 	// Set refreshed state
@@ -2,20 +2,14 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
-	"fmt"
-	"net/url"
 	"reflect"
 	"regexp"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/int32validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
-	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32default"
@@ -28,9 +22,8 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/openziti/edge-api/rest_management_api_client/config"
 	"github.com/openziti/edge-api/rest_model"
-	"github.com/rs/zerolog/log"
-	"github.com/tidwall/gjson"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -42,12 +35,26 @@ var (
 
 // NewHostV1ConfigResource is a helper function to simplify the provider implementation.
 func NewHostV1ConfigResource() resource.Resource {
-	return &hostV1ConfigResource{}
+	return &hostV1ConfigResource{newZitiResource(zitiResourceSpec[hostV1ConfigResourceModel, *rest_model.ConfigDetail]{
+		typeName: "_host_v1_config",
+		label:    "configs",
+		create:   hostV1ConfigCreate,
+		read:     hostV1ConfigRead,
+		readData: hostV1ConfigReadData,
+		update:   hostV1ConfigUpdate,
+		delete:   hostV1ConfigDelete,
+		list:     configList,
+		entityID: func(detail *rest_model.ConfigDetail) string { return types.StringPointerValue(detail.ID).ValueString() },
+		importChecks: map[string]func(*rest_model.ConfigDetail, string) error{
+			"config_type": checkConfigType,
+		},
+		exports: func(detail *rest_model.ConfigDetail) bool { return checkConfigType(detail, "host.v1") == nil },
+	})}
 }
 
 // hostV1ConfigResource is the resource implementation.
 type hostV1ConfigResource struct {
-	resourceConfig *zitiData
+	zitiResource[hostV1ConfigResourceModel, *rest_model.ConfigDetail]
 }
 
 var ListenOptionsModel = types.ObjectType{
@@ -106,26 +113,6 @@ var ForwardAddressTranslationModel = types.ObjectType{
 	},
 }
 
-// Configure adds the provider configured client to the resource.
-func (r *hostV1ConfigResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	// Add a nil check when handling ProviderData because Terraform
-	// sets that data after it calls the ConfigureProvider RPC.
-	if req.ProviderData == nil {
-		return
-	}
-
-	resourceConfig := req.ProviderData.(*zitiData)
-	r.resourceConfig = resourceConfig
-
-	fmt.Printf("Using API Token to create resource: %s\n", r.resourceConfig.apiToken)
-	fmt.Printf("Using domain to create resource: %s\n", r.resourceConfig.host)
-}
-
-// Metadata returns the resource type name.
-func (r *hostV1ConfigResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
-	resp.TypeName = req.ProviderTypeName + "_host_v1_config"
-}
-
 // hostV1ConfigResourceModel maps the resource schema data.
 type hostV1ConfigResourceModel struct {
 	ID                         types.String `tfsdk:"id"`
@@ -147,6 +134,7 @@ type hostV1ConfigResourceModel struct {
 	PortChecks                 types.List   `tfsdk:"port_checks"`
 	HTTPChecks                 types.List   `tfsdk:"http_checks"`
 	Tags                       types.Map    `tfsdk:"tags"`
+	TagsAll                    types.Map    `tfsdk:"tags_all"`
 	LastUpdated                types.String `tfsdk:"last_updated"`
 }
 
@@ -459,6 +447,7 @@ func (r *hostV1ConfigResource) Schema(_ context.Context, _ resource.SchemaReques
 				Default:             mapdefault.StaticValue(types.MapNull(types.StringType)),
 				MarkdownDescription: "Config Tags",
 			},
+			"tags_all": tagsAllAttribute(),
 		},
 	}
 }
@@ -812,29 +801,16 @@ func (r *hostV1ConfigResourceModel) ToHostConfigDTO(ctx context.Context) HostCon
 	return hostConfigDto
 }
 
-// Create a new resource.
-func (r *hostV1ConfigResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	// Retrieve values from plan
-	var eplan hostV1ConfigResourceModel
-
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func hostV1ConfigCreate(ctx context.Context, client *zitiData, eplan *hostV1ConfigResourceModel, diags *diag.Diagnostics) (string, error) {
 	requestObject, err := JsonStructToObject(ctx, eplan.ToHostConfigDTO(ctx), true, true)
 	if err != nil {
-		resp.Diagnostics.AddError(
+		diags.AddError(
 			"Error marshalling Ziti Config from API",
 			"Could not create Ziti Config "+eplan.ID.ValueString()+": "+err.Error(),
 		)
-		return
+		return "", nil
 	}
 
-	jsonObj, _ := json.Marshal(requestObject)
-	tflog.Debug(ctx, string(jsonObj))
-
 	name := eplan.Name.ValueString()
 	configTypeId := eplan.ConfigTypeId.ValueString()
 	tags := TagsFromAttributes(eplan.Tags.Elements())
@@ -846,142 +822,23 @@ func (r *hostV1ConfigResource) Create(ctx context.Context, req resource.CreateRe
 		Tags:         tags,
 	}
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************create resource payload***********************:\n %s\n", jsonData)
-
-	authUrl := fmt.Sprintf("%s/configs", r.resourceConfig.host)
-	cresp, err := CreateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti POST Response: %s", cresp)
-	log.Info().Msg(msg)
+	created, err := client.api.Config.CreateConfig(&config.CreateConfigParams{Config: &payload, Context: ctx}, nil)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Creating configs", "Could not Create configs, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	fmt.Printf("**********************create response************************:\n %s\n", cresp)
-	resourceID := gjson.Get(cresp, "data.id").String()
-
-	// Map response body to schema and populate Computed attribute values
-	eplan.ID = types.StringValue(resourceID)
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
-
-	// Set state to fully populated data
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+		return "", err
 	}
+	return createdID(created.Payload)
 }
 
-// Read resource information.
-func (r *hostV1ConfigResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	// Get current state
-	var state hostV1ConfigResourceModel
-	tflog.Debug(ctx, "Reading Host Config")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	authUrl := fmt.Sprintf("%s/configs/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := ReadZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		if errors.Is(err, errNotFound) {
-			msg := fmt.Sprintf("Resource not found in backend; removing from state, id: %s", state.ID.ValueString())
-			log.Info().Msg(msg)
-			resp.State.RemoveResource(ctx)
-			return
-		}
-		resp.Diagnostics.AddError(
-			"Error Reading configs", "Could not READ configs, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
-	if err != nil {
-		log.Error().Msgf("Error unmarshalling JSON response from Ziti Resource Response: %v", err)
-		fmt.Println("Error unmarshalling JSON:", err)
-		return
-	}
-
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	data := jsonBody["data"].(map[string]interface{})
-	resourceData := data["data"].(map[string]interface{})
-
-	var hostConfigDto HostConfigDTO
-	GenericFromObject(resourceData, &hostConfigDto)
-	newState := hostConfigDto.ConvertToZitiResourceModel(ctx)
-	fmt.Printf("**********************newState************************:\n %+v\n", newState)
-
-	// Manually assign individual values from the map to the struct fields
-	state.Name = types.StringValue(data["name"].(string))
-
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
-	}
-
-	newState.ID = state.ID
-	newState.Name = state.Name
-	newState.ConfigTypeId = state.ConfigTypeId
-	newState.Tags = state.Tags
-	newState.LastUpdated = state.LastUpdated
-	state = newState
-
-	// Set refreshed state
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-}
-
-// Update updates the resource and sets the updated Terraform state on success.
-func (r *hostV1ConfigResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	// Retrieve values from plan
-	var eplan hostV1ConfigResourceModel
-	tflog.Debug(ctx, "Updating Host Config")
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	// Get current state
-	var state hostV1ConfigResourceModel
-	sdiags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(sdiags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func hostV1ConfigUpdate(ctx context.Context, client *zitiData, id string, eplan *hostV1ConfigResourceModel, diags *diag.Diagnostics) error {
 	requestObject, err := JsonStructToObject(ctx, eplan.ToHostConfigDTO(ctx), true, true)
 	if err != nil {
-		resp.Diagnostics.AddError(
+		diags.AddError(
 			"Error marshalling Ziti Config from API",
 			"Could not create Ziti Config "+eplan.ID.ValueString()+": "+err.Error(),
 		)
-		return
+		return nil
 	}
 
-	jsonObj, _ := json.Marshal(requestObject)
-	tflog.Debug(ctx, string(jsonObj))
-
 	name := eplan.Name.ValueString()
 	tags := TagsFromAttributes(eplan.Tags.Elements())
 
@@ -991,55 +848,40 @@ func (r *hostV1ConfigResource) Update(ctx context.Context, req resource.UpdateRe
 		Tags: tags,
 	}
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************update resource payload***********************:\n %s\n", jsonData)
+	_, err = client.api.Config.UpdateConfig(&config.UpdateConfigParams{ID: id, Config: &payload, Context: ctx}, nil)
+	return err
+}
 
-	authUrl := fmt.Sprintf("%s/configs/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := UpdateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti PUT Response: %s", cresp)
-	log.Info().Msg(msg)
+func hostV1ConfigRead(ctx context.Context, client *zitiData, id string) (*rest_model.ConfigDetail, error) {
+	detail, err := client.api.Config.DetailConfig(&config.DetailConfigParams{ID: id, Context: ctx}, nil)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Updating configs", "Could not Update configs, unexpected error: "+err.Error(),
-		)
-		return
+		return nil, err
 	}
-
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
-
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+	if detail.Payload == nil || detail.Payload.Data == nil {
+		return nil, errMissingData
 	}
+	return detail.Payload.Data, nil
 }
 
-// Delete deletes the resource and removes the Terraform state on success.
-func (r *hostV1ConfigResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	// Retrieve values from state
-	var state hostV1ConfigResourceModel
-	tflog.Debug(ctx, "Deleting Host Config")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	authUrl := fmt.Sprintf("%s/configs/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
+func hostV1ConfigDelete(ctx context.Context, client *zitiData, id string) error {
+	_, err := client.api.Config.DeleteConfig(&config.DeleteConfigParams{ID: id, Context: ctx}, nil)
+	return err
+}
 
-	cresp, err := DeleteZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti Delete Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Deleting configs", "Could not DELETE configs, unexpected error: "+err.Error(),
-		)
+func hostV1ConfigReadData(ctx context.Context, detail *rest_model.ConfigDetail, state *hostV1ConfigResourceModel, diags *diag.Diagnostics) {
+	var hostConfigDto HostConfigDTO
+	if !decodeConfigData(detail, "host.v1", &hostConfigDto, diags) {
 		return
 	}
-}
+	newState := hostConfigDto.ConvertToZitiResourceModel(ctx)
 
-func (r *hostV1ConfigResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	state.Name = types.StringPointerValue(detail.Name)
+
+	newState.ID = state.ID
+	newState.Name = state.Name
+	newState.ConfigTypeId = types.StringPointerValue(detail.ConfigTypeID)
+	newState.Tags = tagsFromAPI(ctx, detail.Tags, diags)
+	newState.TagsAll = state.TagsAll
+	newState.LastUpdated = state.LastUpdated
+	*state = newState
 }
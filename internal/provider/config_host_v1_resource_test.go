@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"testing"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccHostV1ConfigResource(t *testing.T) {
+	controller := testAccController(t)
+	const resourceName = "ziti_host_v1_config.test"
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_host_v1_config" "test" {
+  name     = "test.host.v1"
+  address  = "localhost"
+  port     = 5432
+  protocol = "tcp"
+}
+`
+	update := testAccProviderConfig(controller, "") + `
+resource "ziti_host_v1_config" "test" {
+  name                     = "test.host.v1"
+  address                  = "localhost"
+  port                     = 5433
+  protocol                 = "tcp"
+  allowed_source_addresses = ["192.168.1.1"]
+  listen_options = {
+    connect_timeout = "10s"
+    precedence      = "required"
+  }
+  port_checks = [{
+    address  = "localhost:5433"
+    interval = "5s"
+    timeout  = "10s"
+    actions  = [{ trigger = "fail", duration = "10s", action = "mark unhealthy" }]
+  }]
+}
+`
+
+	steps := []resource.TestStep{{
+		Config: config,
+		Check: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckEntityExists(controller, mockcontroller.Configs, resourceName),
+			resource.TestCheckResourceAttr(resourceName, "name", "test.host.v1"),
+			resource.TestCheckResourceAttr(resourceName, "config_type_id", mockcontroller.ConfigTypeHostV1),
+			resource.TestCheckResourceAttr(resourceName, "port", "5432"),
+		),
+	}}
+	steps = append(steps, testAccImportSteps(resourceName)...)
+	steps = append(steps, testAccUpdateStep(resourceName, update,
+		resource.TestCheckResourceAttr(resourceName, "port", "5433"),
+		resource.TestCheckResourceAttr(resourceName, "allowed_source_addresses.0", "192.168.1.1"),
+		resource.TestCheckResourceAttr(resourceName, "listen_options.precedence", "required"),
+		resource.TestCheckResourceAttr(resourceName, "port_checks.0.address", "localhost:5433"),
+	))
+	steps = append(steps, testAccOutOfBandSteps(t, controller, mockcontroller.Configs, "test.host.v1", resourceName, update,
+		map[string]interface{}{"data": map[string]interface{}{"address": "example.com", "port": 5433, "protocol": "tcp"}})...)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    steps,
+	})
+}
@@ -2,15 +2,10 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 
-	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/rs/zerolog/log"
-	"github.com/tidwall/gjson"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -39,9 +34,6 @@ func (r *hostV2ConfigDataSource) Configure(_ context.Context, req datasource.Con
 
 	datasourceConfig := req.ProviderData.(*zitiData)
 	r.datasourceConfig = datasourceConfig
-
-	fmt.Printf("Using API Token to create datasource: %s\n", r.datasourceConfig.apiToken)
-	fmt.Printf("Using domain to create datasource: %s\n", r.datasourceConfig.host)
 }
 
 // Metadata returns the datasource type name.
@@ -288,107 +280,24 @@ func (r *hostV2ConfigDataSource) Read(ctx context.Context, req datasource.ReadRe
 		return
 	}
 
-	filter := ""
-	if state.Name.ValueString() != "" {
-		filter = "filter=name=\"" + state.Name.ValueString() + "\""
-	}
-	if state.ID.ValueString() != "" {
-		filter = "filter=id=\"" + state.ID.ValueString() + "\""
-	}
-
-	authUrl := fmt.Sprintf("%s/configs?%s", r.datasourceConfig.host, filter)
-	cresp, err := ReadZitiResource(authUrl, r.datasourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Reading configs", "Could not READ configs, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
-	if err != nil {
-		log.Error().Msgf("Error unmarshalling JSON response from Ziti DataSource Response: %v", err)
-		fmt.Println("Error unmarshalling JSON:", err)
-		return
-	}
-
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	_config := jsonBody["data"].([]interface{})
-	if len(_config) > 1 {
-		resp.Diagnostics.AddError(
-			"Multiple items returned from API upon filter execution!", "Try to narrow down the filter expression"+filter,
-		)
-	}
-	if len(_config) == 0 {
-		resp.Diagnostics.AddError(
-			"No items returned from API upon filter execution!", "Try to relax the filter expression: "+filter,
-		)
-	}
-	if resp.Diagnostics.HasError() {
+	filter := dataSourceFilter(state.ID, state.Name)
+	detail, ok := lookupDataSourceEntity(ctx, r.datasourceConfig, &resp.Diagnostics, "configs", filter, configList)
+	if !ok {
 		return
 	}
 
-	data := _config[0].(map[string]interface{})
-	datasourceData := gjson.Get(cresp, "data.0.data")
-	if !datasourceData.Exists() {
-		resp.Diagnostics.AddError("Missing data", "The config response had no 'data' object.")
+	terminators, ok := hostV2TerminatorsFromAPI(ctx, detail, &resp.Diagnostics)
+	if !ok {
 		return
 	}
-
-	terminatorsJson := datasourceData.Get("terminators")
-	if terminatorsJson.Exists() && terminatorsJson.IsArray() {
-		resultList := make([]attr.Value, 0)
-		for _, term := range terminatorsJson.Array() {
-			// Unmarshal 1 terminator into HostConfigDTO
-			var dto HostConfigDTO
-			if err := json.Unmarshal([]byte(term.Raw), &dto); err != nil {
-				resp.Diagnostics.AddError(
-					"Failed to Unmarshal Terminator",
-					err.Error(),
-				)
-				return
-			}
-
-			// Convert HostConfigDTO → Terraform Object
-			tfModel := dto.ConvertToZitiResourceModel2(ctx)
-
-			// Convert tfModel struct to Terraform Object value
-			objVal, diag := tfModel.ToTerraformObject(ctx)
-			resp.Diagnostics.Append(diag...)
-			if resp.Diagnostics.HasError() {
-				return
-			}
-			resultList = append(resultList, objVal)
-		}
-
-		// Assign list to state
-		terminatorList, diag := types.ListValue(hostV2ConfigModelType(), resultList)
-		resp.Diagnostics.Append(diag...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		state.Terminators = terminatorList
+	if terminators != nil {
+		state.Terminators = *terminators
 	}
 
-	// Manually assign individual values from the map to the struct fields
-	state.Name = types.StringValue(data["name"].(string))
-	state.ID = types.StringValue(data["id"].(string))
-	state.ConfigTypeId = types.StringValue(data["configTypeId"].(string))
-
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
-	}
+	state.ID = types.StringPointerValue(detail.ID)
+	state.Name = types.StringPointerValue(detail.Name)
+	state.ConfigTypeId = types.StringPointerValue(detail.ConfigTypeID)
+	state.Tags = tagsFromAPI(ctx, detail.Tags, &resp.Diagnostics)
 
 	// Set refreshed state
 	diags = resp.State.Set(ctx, &state)
@@ -2,19 +2,13 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
-	"fmt"
-	"net/url"
 	"regexp"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/int32validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
-	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int32default"
@@ -27,9 +21,8 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/openziti/edge-api/rest_management_api_client/config"
 	"github.com/openziti/edge-api/rest_model"
-	"github.com/rs/zerolog/log"
-	"github.com/tidwall/gjson"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -41,32 +34,26 @@ var (
 
 // NewHostV2ConfigResource is a helper function to simplify the provider implementation.
 func NewHostV2ConfigResource() resource.Resource {
-	return &hostV2ConfigResource{}
+	return &hostV2ConfigResource{newZitiResource(zitiResourceSpec[hostV2ConfigResourceModel, *rest_model.ConfigDetail]{
+		typeName: "_host_v2_config",
+		label:    "configs",
+		create:   hostV2ConfigCreate,
+		read:     hostV2ConfigRead,
+		readData: hostV2ConfigReadData,
+		update:   hostV2ConfigUpdate,
+		delete:   hostV2ConfigDelete,
+		list:     configList,
+		entityID: func(detail *rest_model.ConfigDetail) string { return types.StringPointerValue(detail.ID).ValueString() },
+		importChecks: map[string]func(*rest_model.ConfigDetail, string) error{
+			"config_type": checkConfigType,
+		},
+		exports: func(detail *rest_model.ConfigDetail) bool { return checkConfigType(detail, "host.v2") == nil },
+	})}
 }
 
 // hostV2ConfigResource is the resource implementation.
 type hostV2ConfigResource struct {
-	resourceConfig *zitiData
-}
-
-// Configure adds the provider configured client to the resource.
-func (r *hostV2ConfigResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	// Add a nil check when handling ProviderData because Terraform
-	// sets that data after it calls the ConfigureProvider RPC.
-	if req.ProviderData == nil {
-		return
-	}
-
-	resourceConfig := req.ProviderData.(*zitiData)
-	r.resourceConfig = resourceConfig
-
-	fmt.Printf("Using API Token to create resource: %s\n", r.resourceConfig.apiToken)
-	fmt.Printf("Using domain to create resource: %s\n", r.resourceConfig.host)
-}
-
-// Metadata returns the resource type name.
-func (r *hostV2ConfigResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
-	resp.TypeName = req.ProviderTypeName + "_host_v2_config"
+	zitiResource[hostV2ConfigResourceModel, *rest_model.ConfigDetail]
 }
 
 var HostConfigModel = types.ObjectType{
@@ -100,6 +87,7 @@ type hostV2ConfigResourceModel struct {
 	ConfigTypeId types.String `tfsdk:"config_type_id"`
 	Terminators  types.List   `tfsdk:"terminators"`
 	Tags         types.Map    `tfsdk:"tags"`
+	TagsAll      types.Map    `tfsdk:"tags_all"`
 	LastUpdated  types.String `tfsdk:"last_updated"`
 }
 
@@ -420,6 +408,7 @@ func (r *hostV2ConfigResource) Schema(_ context.Context, _ resource.SchemaReques
 				Default:             mapdefault.StaticValue(types.MapNull(types.StringType)),
 				MarkdownDescription: "Config Tags",
 			},
+			"tags_all": tagsAllAttribute(),
 		},
 	}
 }
@@ -567,16 +556,7 @@ func (dto *HostConfigDTO) ConvertToZitiResourceModel2(ctx context.Context) hostV
 	return res
 }
 
-func (r *hostV2ConfigResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	// Retrieve values from plan
-	var eplan hostV2ConfigResourceModel
-
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func hostV2ConfigCreate(ctx context.Context, client *zitiData, eplan *hostV2ConfigResourceModel, diags *diag.Diagnostics) (string, error) {
 	var terminators []HostConfigDTO
 	if !eplan.Terminators.IsNull() && !eplan.Terminators.IsUnknown() {
 		for _, v := range eplan.Terminators.Elements() {
@@ -650,11 +630,11 @@ func (r *hostV2ConfigResource) Create(ctx context.Context, req resource.CreateRe
 	wrapper := TerminatorsListDTO{Terminators: terminators}
 	requestObject, err := JsonStructToObject2(ctx, wrapper, true, true)
 	if err != nil {
-		resp.Diagnostics.AddError(
+		diags.AddError(
 			"Error marshalling Ziti Config from API",
 			"Could not create Ziti Config "+eplan.ID.ValueString()+": "+err.Error(),
 		)
-		return
+		return "", nil
 	}
 
 	name := eplan.Name.ValueString()
@@ -668,154 +648,14 @@ func (r *hostV2ConfigResource) Create(ctx context.Context, req resource.CreateRe
 		Tags:         tags,
 	}
 
-	// Convert payload to JSON for API request
-	jsonData, _ := json.Marshal(payload)
-	tflog.Debug(ctx, fmt.Sprintf("Final API request payload:\n%s", jsonData))
-
-	authUrl := fmt.Sprintf("%s/configs", r.resourceConfig.host)
-	cresp, err := CreateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti POST Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Creating configs", "Could not Create configs, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	resourceID := gjson.Get(cresp, "data.id").String()
-
-	// Map response body to schema and populate Computed attribute values
-	eplan.ID = types.StringValue(resourceID)
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
-
-	// Set state to fully populated data
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-}
-
-// Read resource information.
-func (r *hostV2ConfigResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	// Get current state
-	var state hostV2ConfigResourceModel
-	tflog.Debug(ctx, "Reading Host Config")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	authUrl := fmt.Sprintf("%s/configs/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := ReadZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		if errors.Is(err, errNotFound) {
-			msg := fmt.Sprintf("Resource not found in backend; removing from state, id: %s", state.ID.ValueString())
-			log.Info().Msg(msg)
-			resp.State.RemoveResource(ctx)
-			return
-		}
-		resp.Diagnostics.AddError(
-			"Error Reading configs", "Could not READ configs, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
+	created, err := client.api.Config.CreateConfig(&config.CreateConfigParams{Config: &payload, Context: ctx}, nil)
 	if err != nil {
-		log.Error().Msgf("Error unmarshalling JSON response from Ziti Resource Response: %v", err)
-		fmt.Println("Error unmarshalling JSON:", err)
-		return
-	}
-
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	data := jsonBody["data"].(map[string]interface{})
-
-	resourceData := gjson.Get(cresp, "data.data")
-	if !resourceData.Exists() {
-		resp.Diagnostics.AddError("Missing data", "The config response had no 'data' object.")
-		return
-	}
-
-	terminatorsJson := resourceData.Get("terminators")
-	if terminatorsJson.Exists() && terminatorsJson.IsArray() {
-		resultList := make([]attr.Value, 0)
-		for _, term := range terminatorsJson.Array() {
-			// Unmarshal terminator into HostConfigDTO
-			var dto HostConfigDTO
-			if err := json.Unmarshal([]byte(term.Raw), &dto); err != nil {
-				resp.Diagnostics.AddError(
-					"Failed to Unmarshal Terminator",
-					err.Error(),
-				)
-				return
-			}
-
-			// Convert HostConfigDTO to Terraform Object
-			tfModel := dto.ConvertToZitiResourceModel2(ctx)
-
-			// Convert tfModel struct to Terraform Object value
-			objVal, diag := tfModel.ToTerraformObject(ctx)
-			resp.Diagnostics.Append(diag...)
-			if resp.Diagnostics.HasError() {
-				return
-			}
-			resultList = append(resultList, objVal)
-		}
-
-		// Assign list to state
-		terminatorList, diag := types.ListValue(hostV2ConfigModelType(), resultList)
-		resp.Diagnostics.Append(diag...)
-		if resp.Diagnostics.HasError() {
-			return
-		}
-		state.Terminators = terminatorList
-	}
-
-	// Manually assign individual values from the map to the struct fields
-	state.Name = types.StringValue(data["name"].(string))
-
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
-	}
-
-	// Set refreshed state
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+		return "", err
 	}
+	return createdID(created.Payload)
 }
 
-// Update updates the resource and sets the updated Terraform state on success.
-func (r *hostV2ConfigResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	// Retrieve values from plan
-	var eplan hostV2ConfigResourceModel
-	tflog.Debug(ctx, "Updating Host Config")
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	// Get current state
-	var state hostV2ConfigResourceModel
-	sdiags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(sdiags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func hostV2ConfigUpdate(ctx context.Context, client *zitiData, id string, eplan *hostV2ConfigResourceModel, diags *diag.Diagnostics) error {
 	var terminators []HostConfigDTO
 	if !eplan.Terminators.IsNull() && !eplan.Terminators.IsUnknown() {
 		for _, v := range eplan.Terminators.Elements() {
@@ -889,16 +729,13 @@ func (r *hostV2ConfigResource) Update(ctx context.Context, req resource.UpdateRe
 	wrapper := TerminatorsListDTO{Terminators: terminators}
 	requestObject, err := JsonStructToObject2(ctx, wrapper, true, true)
 	if err != nil {
-		resp.Diagnostics.AddError(
+		diags.AddError(
 			"Error marshalling Ziti Config from API",
 			"Could not create Ziti Config "+eplan.ID.ValueString()+": "+err.Error(),
 		)
-		return
+		return nil
 	}
 
-	jsonObj, _ := json.Marshal(requestObject)
-	tflog.Debug(ctx, string(jsonObj))
-
 	name := eplan.Name.ValueString()
 	tags := TagsFromAttributes(eplan.Tags.Elements())
 
@@ -908,55 +745,72 @@ func (r *hostV2ConfigResource) Update(ctx context.Context, req resource.UpdateRe
 		Tags: tags,
 	}
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************update resource payload***********************:\n %s\n", jsonData)
+	_, err = client.api.Config.UpdateConfig(&config.UpdateConfigParams{ID: id, Config: &payload, Context: ctx}, nil)
+	return err
+}
 
-	authUrl := fmt.Sprintf("%s/configs/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := UpdateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti PUT Response: %s", cresp)
-	log.Info().Msg(msg)
+func hostV2ConfigRead(ctx context.Context, client *zitiData, id string) (*rest_model.ConfigDetail, error) {
+	detail, err := client.api.Config.DetailConfig(&config.DetailConfigParams{ID: id, Context: ctx}, nil)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Updating configs", "Could not Update configs, unexpected error: "+err.Error(),
-		)
-		return
+		return nil, err
+	}
+	if detail.Payload == nil || detail.Payload.Data == nil {
+		return nil, errMissingData
 	}
+	return detail.Payload.Data, nil
+}
 
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
+func hostV2ConfigDelete(ctx context.Context, client *zitiData, id string) error {
+	_, err := client.api.Config.DeleteConfig(&config.DeleteConfigParams{ID: id, Context: ctx}, nil)
+	return err
+}
 
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
+func hostV2ConfigReadData(ctx context.Context, detail *rest_model.ConfigDetail, state *hostV2ConfigResourceModel, diags *diag.Diagnostics) {
+	terminators, ok := hostV2TerminatorsFromAPI(ctx, detail, diags)
+	if !ok {
 		return
 	}
+	if terminators != nil {
+		state.Terminators = *terminators
+	}
+
+	state.Name = types.StringPointerValue(detail.Name)
+	state.ConfigTypeId = types.StringPointerValue(detail.ConfigTypeID)
+	state.Tags = tagsFromAPI(ctx, detail.Tags, diags)
 }
 
-// Delete deletes the resource and removes the Terraform state on success.
-func (r *hostV2ConfigResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	// Retrieve values from state
-	var state hostV2ConfigResourceModel
-	tflog.Debug(ctx, "Deleting Host Config")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+// hostV2TerminatorsFromAPI decodes the terminators of a fetched host.v2 config
+// to the terminators attribute. It returns nil when the config has no
+// terminators, and false when its data could not be decoded.
+func hostV2TerminatorsFromAPI(ctx context.Context, detail *rest_model.ConfigDetail, diags *diag.Diagnostics) (*types.List, bool) {
+	var data struct {
+		Terminators *[]HostConfigDTO `json:"terminators"`
+	}
+	if !decodeConfigData(detail, "host.v2", &data, diags) {
+		return nil, false
+	}
+	if data.Terminators == nil {
+		return nil, true
 	}
 
-	authUrl := fmt.Sprintf("%s/configs/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
+	resultList := make([]attr.Value, 0, len(*data.Terminators))
+	for _, dto := range *data.Terminators {
+		// Convert HostConfigDTO to Terraform Object
+		tfModel := dto.ConvertToZitiResourceModel2(ctx)
 
-	cresp, err := DeleteZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti Delete Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Deleting configs", "Could not DELETE configs, unexpected error: "+err.Error(),
-		)
-		return
+		// Convert tfModel struct to Terraform Object value
+		objVal, diag := tfModel.ToTerraformObject(ctx)
+		diags.Append(diag...)
+		if diags.HasError() {
+			return nil, false
+		}
+		resultList = append(resultList, objVal)
 	}
-}
 
-func (r *hostV2ConfigResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	terminatorList, diag := types.ListValue(hostV2ConfigModelType(), resultList)
+	diags.Append(diag...)
+	if diags.HasError() {
+		return nil, false
+	}
+	return &terminatorList, true
 }
@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"testing"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccHostV2ConfigResource(t *testing.T) {
+	controller := testAccController(t)
+	const resourceName = "ziti_host_v2_config.test"
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_host_v2_config" "test" {
+  name        = "test.host.v2"
+  terminators = [{ address = "localhost", port = 5432, protocol = "tcp" }]
+}
+`
+	update := testAccProviderConfig(controller, "") + `
+resource "ziti_host_v2_config" "test" {
+  name = "test.host.v2"
+  terminators = [
+    { address = "localhost", port = 5432, protocol = "tcp" },
+    { forward_address = true, allowed_addresses = ["10.0.0.0/24"], port = 5432, protocol = "udp" },
+  ]
+}
+`
+
+	steps := []resource.TestStep{{
+		Config: config,
+		Check: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckEntityExists(controller, mockcontroller.Configs, resourceName),
+			resource.TestCheckResourceAttr(resourceName, "name", "test.host.v2"),
+			resource.TestCheckResourceAttr(resourceName, "config_type_id", mockcontroller.ConfigTypeHostV2),
+			resource.TestCheckResourceAttr(resourceName, "terminators.#", "1"),
+			resource.TestCheckResourceAttr(resourceName, "terminators.0.port", "5432"),
+		),
+	}}
+	steps = append(steps, testAccImportSteps(resourceName)...)
+	steps = append(steps, testAccUpdateStep(resourceName, update,
+		resource.TestCheckResourceAttr(resourceName, "terminators.#", "2"),
+		resource.TestCheckResourceAttr(resourceName, "terminators.1.forward_address", "true"),
+		resource.TestCheckResourceAttr(resourceName, "terminators.1.allowed_addresses.0", "10.0.0.0/24"),
+	))
+	steps = append(steps, testAccOutOfBandSteps(t, controller, mockcontroller.Configs, "test.host.v2", resourceName, update,
+		map[string]interface{}{"data": map[string]interface{}{"terminators": []interface{}{
+			map[string]interface{}{"address": "localhost", "port": 8080, "protocol": "tcp"},
+		}}})...)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    steps,
+	})
+}
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"testing"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccInterceptV1ConfigResource(t *testing.T) {
+	controller := testAccController(t)
+	const resourceName = "ziti_intercept_v1_config.test"
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_intercept_v1_config" "test" {
+  name        = "test.intercept.v1"
+  addresses   = ["test.ziti"]
+  protocols   = ["tcp"]
+  port_ranges = [{ low = 443, high = 443 }]
+}
+`
+	update := testAccProviderConfig(controller, "") + `
+resource "ziti_intercept_v1_config" "test" {
+  name        = "test.intercept.v1"
+  addresses   = ["test.ziti", "*.test.ziti"]
+  protocols   = ["tcp", "udp"]
+  port_ranges = [{ low = 80, high = 443 }]
+  dial_options = {
+    connect_timeout_seconds = 10
+    identity                = "$dst_hostname"
+  }
+  source_ip = "10.10.10.10"
+}
+`
+
+	steps := []resource.TestStep{{
+		Config: config,
+		Check: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckEntityExists(controller, mockcontroller.Configs, resourceName),
+			resource.TestCheckResourceAttr(resourceName, "name", "test.intercept.v1"),
+			resource.TestCheckResourceAttr(resourceName, "config_type_id", mockcontroller.ConfigTypeInterceptV1),
+			resource.TestCheckResourceAttr(resourceName, "addresses.0", "test.ziti"),
+		),
+	}}
+	steps = append(steps, testAccImportSteps(resourceName)...)
+	steps = append(steps, testAccUpdateStep(resourceName, update,
+		resource.TestCheckResourceAttr(resourceName, "addresses.1", "*.test.ziti"),
+		resource.TestCheckResourceAttr(resourceName, "protocols.#", "2"),
+		resource.TestCheckResourceAttr(resourceName, "port_ranges.0.low", "80"),
+		resource.TestCheckResourceAttr(resourceName, "dial_options.identity", "$dst_hostname"),
+		resource.TestCheckResourceAttr(resourceName, "source_ip", "10.10.10.10"),
+	))
+	steps = append(steps, testAccOutOfBandSteps(t, controller, mockcontroller.Configs, "test.intercept.v1", resourceName, update,
+		map[string]interface{}{"data": map[string]interface{}{
+			"addresses":  []interface{}{"other.ziti"},
+			"protocols":  []interface{}{"tcp", "udp"},
+			"portRanges": []interface{}{map[string]interface{}{"low": 80, "high": 443}},
+			"sourceIp":   "10.10.10.10",
+		}})...)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    steps,
+	})
+}
@@ -2,13 +2,13 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/rs/zerolog/log"
+	"github.com/openziti/edge-api/rest_management_api_client/edge_router"
+	"github.com/openziti/edge-api/rest_model"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -37,9 +37,6 @@ func (r *edgeRouterDataSource) Configure(_ context.Context, req datasource.Confi
 
 	datasourceConfig := req.ProviderData.(*zitiData)
 	r.datasourceConfig = datasourceConfig
-
-	fmt.Printf("Using API Token to create datasource: %s\n", r.datasourceConfig.apiToken)
-	fmt.Printf("Using domain to create datasource: %s\n", r.datasourceConfig.host)
 }
 
 // Metadata returns the datasource type name.
@@ -115,101 +112,80 @@ func (r *edgeRouterDataSource) Read(ctx context.Context, req datasource.ReadRequ
 		return
 	}
 
-	filter := ""
-	if state.Name.ValueString() != "" {
-		filter = "filter=name=\"" + state.Name.ValueString() + "\""
-	}
-	if state.ID.ValueString() != "" {
-		filter = "filter=id=\"" + state.ID.ValueString() + "\""
-	}
-
-	authUrl := fmt.Sprintf("%s/edge-routers?%s", r.datasourceConfig.host, filter)
-	cresp, err := ReadZitiResource(authUrl, r.datasourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Reading edge-routers", "Could not READ edge-routers, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
-	if err != nil {
-		log.Error().Msgf("Error unmarshalling JSON response from Ziti DataSource Response: %v", err)
-		fmt.Println("Error unmarshalling JSON:", err)
+	filter := dataSourceFilter(state.ID, state.Name)
+	detail, ok := lookupDataSourceEntity(ctx, r.datasourceConfig, &resp.Diagnostics, "edge-routers", filter, edgeRouterList)
+	if !ok {
 		return
 	}
 
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	edgeRouter := jsonBody["data"].([]interface{})
+	edgeRouterDataSourceState(ctx, detail, &state, &resp.Diagnostics)
 
-	if len(edgeRouter) > 1 {
-		resp.Diagnostics.AddError(
-			"Multiple items returned from API upon filter execution!", "Try to narrow down the filter expression"+filter,
-		)
-	}
-	if len(edgeRouter) == 0 {
-		resp.Diagnostics.AddError(
-			"No items returned from API upon filter execution!", "Try to relax the filter expression: "+filter,
-		)
-	}
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+}
 
-	data := edgeRouter[0].(map[string]interface{})
-
-	state.Name = types.StringValue(data["name"].(string))
-	state.ID = types.StringValue(data["id"].(string))
+// edgeRouterDataSourceState copies an edge router fetched by the data source into state.
+func edgeRouterDataSourceState(ctx context.Context, detail *rest_model.EdgeRouterDetail, state *edgeRouterDataSourceModel, diags *diag.Diagnostics) {
+	state.ID = types.StringPointerValue(detail.ID)
+	state.Name = types.StringPointerValue(detail.Name)
 
-	if cost, ok := data["Cost"].(int64); ok {
-		state.Cost = types.Int64Value(int64(cost))
+	if detail.Cost != nil {
+		state.Cost = types.Int64Value(*detail.Cost)
 	}
 
-	if isTunnelerEnabled, ok := data["isTunnelerEnabled"].(bool); ok {
-		state.IsTunnelerEnabled = types.BoolValue(isTunnelerEnabled)
+	if detail.IsTunnelerEnabled != nil {
+		state.IsTunnelerEnabled = types.BoolValue(*detail.IsTunnelerEnabled)
 	}
 
-	if noTraversal, ok := data["noTraversal"].(bool); ok {
-		state.NoTraversal = types.BoolValue(noTraversal)
+	if detail.NoTraversal != nil {
+		state.NoTraversal = types.BoolValue(*detail.NoTraversal)
 	}
 
-	if roleAttributes, ok := data["roleAttributes"].([]interface{}); ok {
-		roleAttributes, diag := types.SetValueFrom(ctx, types.StringType, roleAttributes)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.RoleAttributes = roleAttributes
-	} else {
-		state.RoleAttributes = types.SetNull(types.StringType)
-	}
+	state.RoleAttributes = roleAttributesFromAPI(ctx, detail.RoleAttributes, diags)
+	state.Tags = tagsFromAPI(ctx, detail.Tags, diags)
 
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
+	if detail.AppData != nil {
+		state.AppData = tagsFromAPI(ctx, detail.AppData, diags)
 	}
+}
 
-	if appData, ok := data["appData"].(map[string]interface{}); ok {
-		if len(appData) != 0 {
-			appData, diag := types.MapValueFrom(ctx, types.StringType, appData)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.AppData = appData
-		} else {
-			state.AppData = types.MapNull(types.StringType)
-		}
+// edgeRouterList lists the edge routers matching filter.
+func edgeRouterList(ctx context.Context, client *zitiData, filter string) ([]*rest_model.EdgeRouterDetail, error) {
+	list, err := client.api.EdgeRouter.ListEdgeRouters(&edge_router.ListEdgeRoutersParams{Filter: optionalFilter(filter), Context: ctx}, nil)
+	if err != nil {
+		return nil, err
 	}
+	if list.Payload == nil {
+		return nil, errMissingData
+	}
+	return withoutNil(list.Payload.Data), nil
+}
 
-	// Set refreshed state
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+// edgeRouterPage lists a page of the edge routers matching filter.
+func edgeRouterPage(ctx context.Context, client *zitiData, filter string, offset, limit int64) ([]*rest_model.EdgeRouterDetail, *rest_model.Pagination, error) {
+	list, err := client.api.EdgeRouter.ListEdgeRouters(&edge_router.ListEdgeRoutersParams{Filter: optionalFilter(filter), Offset: &offset, Limit: &limit, Context: ctx}, nil)
+	if err != nil {
+		return nil, nil, err
 	}
+	if list.Payload == nil {
+		return nil, nil, errMissingData
+	}
+	return withoutNil(list.Payload.Data), paginationOf(list.Payload.Meta), nil
+}
+
+// NewEdgeRoutersDataSource is a helper function to simplify the provider implementation.
+func NewEdgeRoutersDataSource() datasource.DataSource {
+	return &zitiListDataSource[*rest_model.EdgeRouterDetail, edgeRouterDataSourceModel]{spec: zitiListDataSourceSpec[*rest_model.EdgeRouterDetail, edgeRouterDataSourceModel]{
+		typeName:       "_edge_routers",
+		label:          "edge routers",
+		items:          "edge_routers",
+		itemAttributes: listItemAttributes(dataSourceAttributes(NewEdgeRouterDataSource())),
+		roleAttributes: true,
+		page:           edgeRouterPage,
+		item:           edgeRouterDataSourceState,
+	}}
 }
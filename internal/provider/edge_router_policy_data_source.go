@@ -2,13 +2,12 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/rs/zerolog/log"
+	"github.com/openziti/edge-api/rest_management_api_client/edge_router_policy"
+	"github.com/openziti/edge-api/rest_model"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -37,9 +36,6 @@ func (r *edgeRouterPolicyDataSource) Configure(_ context.Context, req datasource
 
 	datasourceConfig := req.ProviderData.(*zitiData)
 	r.datasourceConfig = datasourceConfig
-
-	fmt.Printf("Using API Token for datasource: %s\n", r.datasourceConfig.apiToken)
-	fmt.Printf("Using domain for datasource: %s\n", r.datasourceConfig.host)
 }
 
 // Metadata returns the datasource type name.
@@ -51,8 +47,8 @@ func (r *edgeRouterPolicyDataSource) Metadata(_ context.Context, req datasource.
 type edgeRouterPolicyDataSourceModel struct {
 	ID              types.String `tfsdk:"id"`
 	Name            types.String `tfsdk:"name"`
-	EdgeRouterRoles types.List   `tfsdk:"edgerouterroles"`
-	IdentityRoles   types.List   `tfsdk:"identityroles"`
+	EdgeRouterRoles types.Set    `tfsdk:"edgerouterroles"`
+	IdentityRoles   types.Set    `tfsdk:"identityroles"`
 	Semantic        types.String `tfsdk:"semantic"`
 	Tags            types.Map    `tfsdk:"tags"`
 }
@@ -76,12 +72,12 @@ func (r *edgeRouterPolicyDataSource) Schema(_ context.Context, _ datasource.Sche
 				Computed:            true,
 				MarkdownDescription: "Semantic Value",
 			},
-			"edgerouterroles": schema.ListAttribute{
+			"edgerouterroles": schema.SetAttribute{
 				ElementType:         types.StringType,
 				Computed:            true,
 				MarkdownDescription: "Edge Router Roles",
 			},
-			"identityroles": schema.ListAttribute{
+			"identityroles": schema.SetAttribute{
 				ElementType:         types.StringType,
 				Computed:            true,
 				MarkdownDescription: "Identity Roles",
@@ -103,86 +99,22 @@ func (r *edgeRouterPolicyDataSource) Read(ctx context.Context, req datasource.Re
 		return
 	}
 
-	filter := ""
-	if state.Name.ValueString() != "" {
-		filter = "filter=name=\"" + state.Name.ValueString() + "\""
-	}
-	if state.ID.ValueString() != "" {
-		filter = "filter=id=\"" + state.ID.ValueString() + "\""
-	}
-
-	authUrl := fmt.Sprintf("%s/edge-router-policies?%s", r.datasourceConfig.host, filter)
-	cresp, err := ReadZitiResource(authUrl, r.datasourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Reading ERP", "Could not READ ERP, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
-	if err != nil {
-		log.Error().Msgf("Error unmarshalling JSON response from Ziti Resource Response: %v", err)
-		fmt.Println("Error unmarshalling JSON:", err)
-		return
-	}
-
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	edgeRouterPolicies := jsonBody["data"].([]interface{})
-
-	if len(edgeRouterPolicies) > 1 {
-		resp.Diagnostics.AddError(
-			"Multiple items returned from API upon filter execution!", "Try to narrow down the filter expression"+filter,
-		)
-	}
-	if len(edgeRouterPolicies) == 0 {
-		resp.Diagnostics.AddError(
-			"No items returned from API upon filter execution!", "Try to relax the filter expression: "+filter,
-		)
-	}
-	if resp.Diagnostics.HasError() {
+	filter := dataSourceFilter(state.ID, state.Name)
+	detail, ok := lookupDataSourceEntity(ctx, r.datasourceConfig, &resp.Diagnostics, "ERP", filter, edgeRouterPolicyList)
+	if !ok {
 		return
 	}
 
-	data := edgeRouterPolicies[0].(map[string]interface{})
-
-	state.Name = types.StringValue(data["name"].(string))
-	state.ID = types.StringValue(data["id"].(string))
-
-	if semanticValue, ok := data["semantic"].(string); ok {
-		state.Semantic = types.StringValue(semanticValue)
-	}
-
-	if identityRoles, ok := data["identityRoles"].([]interface{}); ok {
-		identityRoles, diag := types.ListValueFrom(ctx, types.StringType, identityRoles)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.IdentityRoles = identityRoles
-	} else {
-		state.IdentityRoles = types.ListNull(types.StringType)
-	}
+	state.ID = types.StringPointerValue(detail.ID)
+	state.Name = types.StringPointerValue(detail.Name)
 
-	if edgeRouterRoles, ok := data["edgeRouterRoles"].([]interface{}); ok {
-		edgeRouterRoles, diag := types.ListValueFrom(ctx, types.StringType, edgeRouterRoles)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.EdgeRouterRoles = edgeRouterRoles
-	} else {
-		state.EdgeRouterRoles = types.ListNull(types.StringType)
+	if detail.Semantic != nil {
+		state.Semantic = types.StringValue(string(*detail.Semantic))
 	}
 
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
-	}
+	state.IdentityRoles = stringSetFromAPI(ctx, detail.IdentityRoles, &resp.Diagnostics)
+	state.EdgeRouterRoles = stringSetFromAPI(ctx, detail.EdgeRouterRoles, &resp.Diagnostics)
+	state.Tags = tagsFromAPI(ctx, detail.Tags, &resp.Diagnostics)
 
 	// Set refreshed state
 	diags = resp.State.Set(ctx, &state)
@@ -191,3 +123,15 @@ func (r *edgeRouterPolicyDataSource) Read(ctx context.Context, req datasource.Re
 		return
 	}
 }
+
+// edgeRouterPolicyList lists the edge router policies matching filter.
+func edgeRouterPolicyList(ctx context.Context, client *zitiData, filter string) ([]*rest_model.EdgeRouterPolicyDetail, error) {
+	list, err := client.api.EdgeRouterPolicy.ListEdgeRouterPolicies(&edge_router_policy.ListEdgeRouterPoliciesParams{Filter: optionalFilter(filter), Context: ctx}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if list.Payload == nil {
+		return nil, errMissingData
+	}
+	return withoutNil(list.Payload.Data), nil
+}
@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"testing"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccEdgeRouterPolicyResource(t *testing.T) {
+	controller := testAccController(t)
+	const resourceName = "ziti_edge_router_policy.test"
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_edge_router_policy" "test" {
+  name            = "test-edge-router-policy"
+  semantic        = "AnyOf"
+  edgerouterroles = ["#public"]
+  identityroles   = ["#servers", "#clients"]
+}
+`
+	update := testAccProviderConfig(controller, "") + `
+resource "ziti_edge_router_policy" "test" {
+  name            = "test-edge-router-policy"
+  semantic        = "AllOf"
+  edgerouterroles = ["#public", "#private"]
+  identityroles   = ["#servers", "#clients"]
+  tags            = { env = "test" }
+}
+`
+
+	steps := []resource.TestStep{{
+		Config: config,
+		Check: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckEntityExists(controller, mockcontroller.EdgeRouterPolicies, resourceName),
+			resource.TestCheckResourceAttr(resourceName, "name", "test-edge-router-policy"),
+			resource.TestCheckTypeSetElemAttr(resourceName, "identityroles.*", "#servers"),
+			resource.TestCheckTypeSetElemAttr(resourceName, "identityroles.*", "#clients"),
+		),
+	}}
+	steps = append(steps, testAccImportSteps(resourceName)...)
+	steps = append(steps, testAccUpdateStep(resourceName, update,
+		resource.TestCheckResourceAttr(resourceName, "semantic", "AllOf"),
+		resource.TestCheckResourceAttr(resourceName, "edgerouterroles.#", "2"),
+		resource.TestCheckResourceAttr(resourceName, "tags.env", "test"),
+	))
+	steps = append(steps, testAccOutOfBandSteps(t, controller, mockcontroller.EdgeRouterPolicies, "test-edge-router-policy", resourceName, update,
+		map[string]interface{}{"identityRoles": []interface{}{"#all"}})...)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    steps,
+	})
+}
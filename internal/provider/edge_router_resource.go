@@ -2,14 +2,10 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
-	"fmt"
-	"net/url"
-	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
-	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
@@ -20,10 +16,8 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/openziti/edge-api/rest_management_api_client/edge_router"
 	"github.com/openziti/edge-api/rest_model"
-	"github.com/rs/zerolog/log"
-	"github.com/tidwall/gjson"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -35,50 +29,45 @@ var (
 
 // NewEdgeRouterResource is a helper function to simplify the provider implementation.
 func NewEdgeRouterResource() resource.Resource {
-	return &edgeRouterResource{}
+	return &edgeRouterResource{newZitiResource(zitiResourceSpec[edgeRouterResourceModel, *rest_model.EdgeRouterDetail]{
+		typeName:      "_edge_router",
+		label:         "edge-routers",
+		create:        edgeRouterCreate,
+		read:          edgeRouterRead,
+		readData:      edgeRouterReadData,
+		update:        edgeRouterUpdate,
+		delete:        edgeRouterDelete,
+		enrollmentJwt: edgeRouterEnrollmentJwt,
+		list:          edgeRouterList,
+		entityID: func(detail *rest_model.EdgeRouterDetail) string {
+			return types.StringPointerValue(detail.ID).ValueString()
+		},
+	})}
 }
 
 // edgeRouterResource is the resource implementation.
 type edgeRouterResource struct {
-	resourceConfig *zitiData
-}
-
-// Configure adds the provider configured client to the resource.
-func (r *edgeRouterResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	// Add a nil check when handling ProviderData because Terraform
-	// sets that data after it calls the ConfigureProvider RPC.
-	if req.ProviderData == nil {
-		return
-	}
-
-	resourceConfig := req.ProviderData.(*zitiData)
-	r.resourceConfig = resourceConfig
-
-	fmt.Printf("Using API Token to create resource: %s\n", r.resourceConfig.apiToken)
-	fmt.Printf("Using domain to create resource: %s\n", r.resourceConfig.host)
-}
-
-// Metadata returns the resource type name.
-func (r *edgeRouterResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
-	resp.TypeName = req.ProviderTypeName + "_edge_router"
+	zitiResource[edgeRouterResourceModel, *rest_model.EdgeRouterDetail]
 }
 
 // edgeRouterResourceModel maps the resource schema data.
 type edgeRouterResourceModel struct {
-	ID                types.String `tfsdk:"id"`
-	Name              types.String `tfsdk:"name"`
-	Cost              types.Int64  `tfsdk:"cost"`
-	RoleAttributes    types.Set    `tfsdk:"role_attributes"`
-	IsTunnelerEnabled types.Bool   `tfsdk:"is_tunnelerenabled"`
-	NoTraversal       types.Bool   `tfsdk:"no_traversal"`
-	Tags              types.Map    `tfsdk:"tags"`
-	AppData           types.Map    `tfsdk:"app_data"`
-	LastUpdated       types.String `tfsdk:"last_updated"`
-	EnrollmentJwt     types.String `tfsdk:"enrollment_token"`
+	ID                types.String   `tfsdk:"id"`
+	Name              types.String   `tfsdk:"name"`
+	Cost              types.Int64    `tfsdk:"cost"`
+	RoleAttributes    types.Set      `tfsdk:"role_attributes"`
+	IsTunnelerEnabled types.Bool     `tfsdk:"is_tunnelerenabled"`
+	NoTraversal       types.Bool     `tfsdk:"no_traversal"`
+	Tags              types.Map      `tfsdk:"tags"`
+	TagsAll           types.Map      `tfsdk:"tags_all"`
+	AppData           types.Map      `tfsdk:"app_data"`
+	LastUpdated       types.String   `tfsdk:"last_updated"`
+	EnrollmentJwt     types.String   `tfsdk:"enrollment_token"`
+	Timeouts          timeouts.Value `tfsdk:"timeouts"`
 }
 
 // Schema defines the schema for the resource.
-func (r *edgeRouterResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *edgeRouterResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		MarkdownDescription: "Ziti Edge Router Resource",
 		Attributes: map[string]schema.Attribute{
@@ -132,6 +121,7 @@ func (r *edgeRouterResource) Schema(_ context.Context, _ resource.SchemaRequest,
 				Default:             mapdefault.StaticValue(types.MapNull(types.StringType)),
 				MarkdownDescription: "Edge Router Tags",
 			},
+			"tags_all": tagsAllAttribute(),
 			"app_data": schema.MapAttribute{
 				Computed:            true,
 				ElementType:         types.StringType,
@@ -149,20 +139,15 @@ func (r *edgeRouterResource) Schema(_ context.Context, _ resource.SchemaRequest,
 				MarkdownDescription: "The JWT token for one-time enrollment (OTT).",
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+			}),
+		},
 	}
 }
 
-// Create a new resource.
-func (r *edgeRouterResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	// Retrieve values from plan
-	var eplan edgeRouterResourceModel
-
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func edgeRouterCreate(ctx context.Context, client *zitiData, eplan *edgeRouterResourceModel, diags *diag.Diagnostics) (string, error) {
 	name := eplan.Name.ValueString()
 	cost_ := eplan.Cost.ValueInt64()
 	tags := TagsFromAttributes(eplan.Tags.Elements())
@@ -187,181 +172,14 @@ func (r *edgeRouterResource) Create(ctx context.Context, req resource.CreateRequ
 		AppData:           appData,
 	}
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************create resource payload***********************:\n %s\n", jsonData)
-
-	authUrl := fmt.Sprintf("%s/edge-routers", r.resourceConfig.host)
-	cresp, err := CreateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti POST Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Creating edge-routers", "Could not Create edge-routers, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	fmt.Printf("**********************create response************************:\n %s\n", cresp)
-	resourceID := gjson.Get(cresp, "data.id").String()
-
-	// Map response body to schema and populate Computed attribute values
-	eplan.ID = types.StringValue(resourceID)
-
-	// Poll router endpoint to get JWT
-	maxRetries := 10
-	var jwtToken string
-
-	for i := 0; i < maxRetries; i++ {
-		time.Sleep(5 * time.Second) // wait between retries
-
-		jwtUrl := fmt.Sprintf("%s/edge-routers/%s", r.resourceConfig.host, resourceID)
-		respBody, err := ReadZitiResource(jwtUrl, r.resourceConfig.apiToken)
-		if err != nil {
-			continue
-		}
-
-		// Use gjson to extract the JWT
-		jwt := gjson.Get(respBody, "data.enrollmentJwt").String()
-		if jwt != "" {
-			jwtToken = jwt
-			break
-		}
-	}
-
-	if jwtToken == "" {
-		resp.Diagnostics.AddError("Error Fetching JWT", "Timeout while waiting for JWT to be available")
-		return
-	}
-
-	eplan.EnrollmentJwt = types.StringValue(jwtToken)
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
-
-	// Set state to fully populated data
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-}
-
-// Read resource information.
-func (r *edgeRouterResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	// Get current state
-	var state edgeRouterResourceModel
-	tflog.Debug(ctx, "Reading Edge Router")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	authUrl := fmt.Sprintf("%s/edge-routers/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := ReadZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		if errors.Is(err, errNotFound) {
-			msg := fmt.Sprintf("Resource not found in backend; removing from state, id: %s", state.ID.ValueString())
-			log.Info().Msg(msg)
-			resp.State.RemoveResource(ctx)
-			return
-		}
-		resp.Diagnostics.AddError(
-			"Error Reading edge-routers", "Could not READ edge-routers, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
+	created, err := client.api.EdgeRouter.CreateEdgeRouter(&edge_router.CreateEdgeRouterParams{EdgeRouter: &payload, Context: ctx}, nil)
 	if err != nil {
-		// Handle error
-		resp.Diagnostics.AddError(
-			"Error Reading edge router", fmt.Sprintf("Could not READ edge router, ERROR %v: ", err.Error()),
-		)
-		return
-	}
-
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	data, ok := jsonBody["data"].(map[string]interface{})
-	if !ok {
-		resp.Diagnostics.AddError("Error: ", "'data' is either missing or not a map[string]interface{}")
-		return
-	}
-
-	// Manually assign individual values from the map to the struct fields
-	state.Name = types.StringValue(data["name"].(string))
-
-	if cost, ok := data["Cost"].(int64); ok {
-		state.Cost = types.Int64Value(int64(cost))
-	}
-
-	if isTunnelerEnabled, ok := data["isTunnelerEnabled"].(bool); ok {
-		state.IsTunnelerEnabled = types.BoolValue(isTunnelerEnabled)
-	}
-
-	if noTraversal, ok := data["noTraversal"].(bool); ok {
-		state.NoTraversal = types.BoolValue(noTraversal)
-	}
-
-	if roleAttributes, ok := data["roleAttributes"].([]interface{}); ok {
-		roleAttributes, diag := types.SetValueFrom(ctx, types.StringType, roleAttributes)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.RoleAttributes = roleAttributes
-	} else {
-		state.RoleAttributes = types.SetNull(types.StringType)
-	}
-
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
-	}
-
-	if appData, ok := data["appData"].(map[string]interface{}); ok {
-		if len(appData) != 0 {
-			appData, diag := types.MapValueFrom(ctx, types.StringType, appData)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.AppData = appData
-		} else {
-			state.AppData = types.MapNull(types.StringType)
-		}
-	}
-
-	// Set refreshed state
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+		return "", err
 	}
+	return createdID(created.Payload)
 }
 
-// Update updates the resource and sets the updated Terraform state on success.
-func (r *edgeRouterResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	// Retrieve values from plan
-	var eplan edgeRouterResourceModel
-	tflog.Debug(ctx, "Updating Edge Router")
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	// Get current state
-	var state edgeRouterResourceModel
-	sdiags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(sdiags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func edgeRouterUpdate(ctx context.Context, client *zitiData, id string, eplan *edgeRouterResourceModel, diags *diag.Diagnostics) error {
 	name := eplan.Name.ValueString()
 	cost_ := eplan.Cost.ValueInt64()
 	tags := TagsFromAttributes(eplan.Tags.Elements())
@@ -386,56 +204,53 @@ func (r *edgeRouterResource) Update(ctx context.Context, req resource.UpdateRequ
 		AppData:           appData,
 	}
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************update resource payload***********************:\n %s\n", jsonData)
+	_, err := client.api.EdgeRouter.UpdateEdgeRouter(&edge_router.UpdateEdgeRouterParams{ID: id, EdgeRouter: &payload, Context: ctx}, nil)
+	return err
+}
 
-	authUrl := fmt.Sprintf("%s/edge-routers/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := UpdateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti PUT Response: %s", cresp)
-	log.Info().Msg(msg)
+func edgeRouterRead(ctx context.Context, client *zitiData, id string) (*rest_model.EdgeRouterDetail, error) {
+	detail, err := client.api.EdgeRouter.DetailEdgeRouter(&edge_router.DetailEdgeRouterParams{ID: id, Context: ctx}, nil)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Updating edge-routers", "Could not Update edge-routers, unexpected error: "+err.Error(),
-		)
-		return
+		return nil, err
+	}
+	if detail.Payload == nil || detail.Payload.Data == nil {
+		return nil, errMissingData
 	}
+	return detail.Payload.Data, nil
+}
+
+func edgeRouterDelete(ctx context.Context, client *zitiData, id string) error {
+	_, err := client.api.EdgeRouter.DeleteEdgeRouter(&edge_router.DeleteEdgeRouterParams{ID: id, Context: ctx}, nil)
+	return err
+}
 
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
-	eplan.EnrollmentJwt = state.EnrollmentJwt
+func edgeRouterReadData(ctx context.Context, detail *rest_model.EdgeRouterDetail, state *edgeRouterResourceModel, diags *diag.Diagnostics) {
+	state.Name = types.StringPointerValue(detail.Name)
 
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+	if detail.Cost != nil {
+		state.Cost = types.Int64Value(*detail.Cost)
 	}
-}
 
-// Delete deletes the resource and removes the Terraform state on success.
-func (r *edgeRouterResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	// Retrieve values from state
-	var state edgeRouterResourceModel
-	tflog.Debug(ctx, "Deleting Edge Router")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+	if detail.IsTunnelerEnabled != nil {
+		state.IsTunnelerEnabled = types.BoolValue(*detail.IsTunnelerEnabled)
 	}
 
-	authUrl := fmt.Sprintf("%s/edge-routers/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
+	if detail.NoTraversal != nil {
+		state.NoTraversal = types.BoolValue(*detail.NoTraversal)
+	}
 
-	cresp, err := DeleteZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti Delete Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Deleting edge-routers", "Could not DELETE edge-routers, unexpected error: "+err.Error(),
-		)
-		return
+	state.RoleAttributes = roleAttributesFromAPI(ctx, detail.RoleAttributes, diags)
+
+	if detail.AppData != nil {
+		state.AppData = tagsFromAPI(ctx, detail.AppData, diags)
 	}
+
+	state.Tags = tagsFromAPI(ctx, detail.Tags, diags)
 }
 
-func (r *edgeRouterResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+func edgeRouterEnrollmentJwt(detail *rest_model.EdgeRouterDetail) string {
+	if detail.EnrollmentJWT == nil {
+		return ""
+	}
+	return *detail.EnrollmentJWT
 }
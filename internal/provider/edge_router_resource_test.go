@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"testing"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccEdgeRouterResource(t *testing.T) {
+	controller := testAccController(t)
+	const resourceName = "ziti_edge_router.test"
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_edge_router" "test" {
+  name            = "test-edge-router"
+  role_attributes = ["public"]
+
+  timeouts {
+    create = "30s"
+  }
+}
+`
+	update := testAccProviderConfig(controller, "") + `
+resource "ziti_edge_router" "test" {
+  name               = "test-edge-router"
+  role_attributes    = ["public", "eu"]
+  cost               = 10
+  is_tunnelerenabled = true
+  app_data           = { region = "eu" }
+}
+`
+
+	steps := []resource.TestStep{{
+		Config: config,
+		Check: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckEntityExists(controller, mockcontroller.EdgeRouters, resourceName),
+			resource.TestCheckResourceAttr(resourceName, "name", "test-edge-router"),
+			resource.TestCheckResourceAttr(resourceName, "role_attributes.#", "1"),
+			resource.TestCheckResourceAttrSet(resourceName, "enrollment_token"),
+			resource.TestCheckResourceAttr(resourceName, "timeouts.create", "30s"),
+		),
+	}}
+	steps = append(steps, testAccImportSteps(resourceName)...)
+	steps = append(steps, testAccUpdateStep(resourceName, update,
+		resource.TestCheckResourceAttr(resourceName, "role_attributes.#", "2"),
+		resource.TestCheckResourceAttr(resourceName, "cost", "10"),
+		resource.TestCheckResourceAttr(resourceName, "is_tunnelerenabled", "true"),
+		resource.TestCheckResourceAttr(resourceName, "app_data.region", "eu"),
+		resource.TestCheckResourceAttrSet(resourceName, "enrollment_token"),
+	))
+	steps = append(steps, testAccOutOfBandSteps(t, controller, mockcontroller.EdgeRouters, "test-edge-router", resourceName, update,
+		map[string]interface{}{"cost": 100})...)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    steps,
+	})
+}
@@ -0,0 +1,343 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/hashicorp/terraform-plugin-framework-validators/ephemeralvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/openziti/edge-api/rest_management_api_client/edge_router"
+	"github.com/openziti/edge-api/rest_management_api_client/enrollment"
+	"github.com/openziti/edge-api/rest_management_api_client/identity"
+	"github.com/openziti/edge-api/rest_model"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ ephemeral.EphemeralResource                     = &enrollmentTokenEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithConfigure        = &enrollmentTokenEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithConfigValidators = &enrollmentTokenEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithValidateConfig   = &enrollmentTokenEphemeralResource{}
+)
+
+// defaultIssuedEnrollmentLifetime is how long an enrollment issued for an
+// identity stays valid when expires_in is not set.
+const defaultIssuedEnrollmentLifetime = 24 * time.Hour
+
+// identityEnrollmentMethods are the enrollment methods of an identity, in the
+// order a pending enrollment is picked when method is not set.
+var identityEnrollmentMethods = []string{"ott", "ottca", "updb"}
+
+// NewEnrollmentTokenEphemeralResource is a helper function to simplify the provider implementation.
+func NewEnrollmentTokenEphemeralResource() ephemeral.EphemeralResource {
+	return &enrollmentTokenEphemeralResource{}
+}
+
+// enrollmentTokenEphemeralResource returns the enrollment JWT of an identity
+// or edge router without storing it in state, so it can be passed on to a
+// secret store or cloud-init instead of the enrollment_token attributes.
+//
+// Terraform opens ephemeral resources on every plan and apply, so Open never
+// invalidates a usable enrollment: it only issues one when the identity has
+// none that is pending and unexpired, and never re-enrolls an edge router.
+type enrollmentTokenEphemeralResource struct {
+	resourceConfig *zitiData
+}
+
+// enrollmentTokenEphemeralResourceModel maps the ephemeral resource schema data.
+type enrollmentTokenEphemeralResourceModel struct {
+	IdentityID   types.String `tfsdk:"identity_id"`
+	EdgeRouterID types.String `tfsdk:"edge_router_id"`
+	Method       types.String `tfsdk:"method"`
+	Issue        types.Bool   `tfsdk:"issue"`
+	ExpiresIn    types.String `tfsdk:"expires_in"`
+	JWT          types.String `tfsdk:"jwt"`
+	ExpiresAt    types.String `tfsdk:"expires_at"`
+}
+
+// pendingEnrollment is an enrollment that has not been used yet.
+type pendingEnrollment struct {
+	id        string
+	method    string
+	jwt       string
+	token     string
+	expiresAt strfmt.DateTime
+}
+
+// expired reports whether the enrollment can no longer be used.
+func (e *pendingEnrollment) expired() bool {
+	expiresAt := time.Time(e.expiresAt)
+	return !expiresAt.IsZero() && !expiresAt.After(time.Now())
+}
+
+// Configure adds the provider configured client to the ephemeral resource.
+func (r *enrollmentTokenEphemeralResource) Configure(_ context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	r.resourceConfig = req.ProviderData.(*zitiData)
+}
+
+// Metadata returns the ephemeral resource type name.
+func (r *enrollmentTokenEphemeralResource) Metadata(_ context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_enrollment_token"
+}
+
+// Schema defines the schema for the ephemeral resource.
+func (r *enrollmentTokenEphemeralResource) Schema(_ context.Context, _ ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Returns the pending enrollment JWT of an identity or edge router without storing it in state. " +
+			"With `issue`, an identity whose enrollment has been used or has expired gets a new one; a usable enrollment is never replaced, " +
+			"so the same JWT is returned on every plan and apply until it is used.",
+		Attributes: map[string]schema.Attribute{
+			"identity_id": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "ID of the identity to return the enrollment JWT of. Exactly one of `identity_id` and `edge_router_id` must be set.",
+			},
+			"edge_router_id": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "ID of the edge router to return the enrollment JWT of.",
+			},
+			"method": schema.StringAttribute{
+				Optional: true,
+				Computed: true,
+				MarkdownDescription: "Enrollment method of the identity: `ott`, `ottca` or `updb`. Defaults to the method of the pending enrollment, or `ott` when a new enrollment is issued. " +
+					"It is `erott` for edge routers.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(identityEnrollmentMethods...),
+				},
+			},
+			"issue": schema.BoolAttribute{
+				Optional: true,
+				MarkdownDescription: "Issue an enrollment when the identity has no usable one: an expired enrollment is refreshed, and an `ott` enrollment is created when there is none, e.g. because it has been used. " +
+					"Not supported for edge routers, as re-enrolling one drops its certificate. Defaults to `false`.",
+			},
+			"expires_in": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "How long an enrollment issued for an identity stays valid, as a Go duration (e.g. `1h`). Defaults to `24h`.",
+			},
+			"jwt": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "The enrollment JWT.",
+			},
+			"expires_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Expiry time of the enrollment JWT, in RFC 3339 format.",
+			},
+		},
+	}
+}
+
+// ConfigValidators requires either an identity or an edge router.
+func (r *enrollmentTokenEphemeralResource) ConfigValidators(_ context.Context) []ephemeral.ConfigValidator {
+	return []ephemeral.ConfigValidator{
+		ephemeralvalidator.ExactlyOneOf(path.MatchRoot("identity_id"), path.MatchRoot("edge_router_id")),
+		ephemeralvalidator.Conflicting(path.MatchRoot("edge_router_id"), path.MatchRoot("method")),
+		ephemeralvalidator.Conflicting(path.MatchRoot("edge_router_id"), path.MatchRoot("expires_in")),
+		ephemeralvalidator.Conflicting(path.MatchRoot("edge_router_id"), path.MatchRoot("issue")),
+	}
+}
+
+// ValidateConfig checks that expires_in is a positive duration.
+func (r *enrollmentTokenEphemeralResource) ValidateConfig(ctx context.Context, req ephemeral.ValidateConfigRequest, resp *ephemeral.ValidateConfigResponse) {
+	var expiresIn types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("expires_in"), &expiresIn)...)
+	if resp.Diagnostics.HasError() || expiresIn.IsNull() || expiresIn.IsUnknown() {
+		return
+	}
+	if _, ok := parseLifetime(expiresIn); !ok {
+		addInvalidLifetimeError(&resp.Diagnostics, expiresIn)
+	}
+}
+
+// Open fetches the enrollment JWT, issuing it first when issue is set and
+// the identity has no usable enrollment.
+func (r *enrollmentTokenEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var config enrollmentTokenEphemeralResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var pending *pendingEnrollment
+	if !config.EdgeRouterID.IsNull() {
+		pending = r.edgeRouterEnrollment(ctx, config.EdgeRouterID.ValueString(), &resp.Diagnostics)
+	} else {
+		pending = r.identityEnrollment(ctx, config, &resp.Diagnostics)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	config.Method = types.StringValue(pending.method)
+	config.JWT = types.StringValue(pending.jwt)
+	config.ExpiresAt = types.StringValue(time.Time(pending.expiresAt).UTC().Format(time.RFC3339))
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &config)...)
+}
+
+// identityEnrollment returns the pending enrollment of the identity. When it
+// has none that is usable and issue is set, an expired one is refreshed or an
+// ott enrollment is created.
+func (r *enrollmentTokenEphemeralResource) identityEnrollment(ctx context.Context, config enrollmentTokenEphemeralResourceModel, diags *diag.Diagnostics) *pendingEnrollment {
+	identityID := config.IdentityID.ValueString()
+	method := config.Method.ValueString()
+	pending, err := pendingIdentityEnrollment(ctx, r.resourceConfig, identityID, method)
+	if err != nil {
+		addZitiErrorDiagnostic(ctx, diags, nil, "Error Reading Enrollment", "Could not READ identity "+identityID+", unexpected error: ", zitiAPIErrorFrom(err))
+		return nil
+	}
+	if pending != nil && !pending.expired() {
+		return pending
+	}
+
+	if !config.Issue.ValueBool() {
+		if pending != nil {
+			diags.AddError("Error Reading Enrollment",
+				fmt.Sprintf("The %s enrollment of identity %s expired at %s; set issue = true to renew it.", pending.method, identityID, pending.expiresAt))
+			return nil
+		}
+		kind := "a pending"
+		if method != "" {
+			kind = "a pending " + method
+		}
+		diags.AddError("Error Reading Enrollment",
+			fmt.Sprintf("Identity %s has no %s enrollment, e.g. because it has been used; set issue = true to issue a new one.", identityID, kind))
+		return nil
+	}
+
+	lifetime, ok := parseLifetime(config.ExpiresIn)
+	if !ok {
+		addInvalidLifetimeError(diags, config.ExpiresIn)
+		return nil
+	}
+	expiresAt := strfmt.DateTime(time.Now().Add(lifetime))
+	switch {
+	case pending != nil:
+		tflog.Info(ctx, "Refreshing the expired enrollment of identity", map[string]any{"identity_id": identityID, "method": pending.method})
+		method = pending.method
+		_, err = r.resourceConfig.api.Enrollment.RefreshEnrollment(&enrollment.RefreshEnrollmentParams{
+			ID:      pending.id,
+			Refresh: &rest_model.EnrollmentRefresh{ExpiresAt: &expiresAt},
+			Context: ctx,
+		}, nil)
+	case method == "" || method == "ott":
+		tflog.Info(ctx, "Identity has no pending enrollment; issuing an OTT enrollment", map[string]any{"identity_id": identityID})
+		method = "ott"
+		createMethod := rest_model.EnrollmentCreateMethodOtt
+		_, err = r.resourceConfig.api.Enrollment.CreateEnrollment(&enrollment.CreateEnrollmentParams{
+			Enrollment: &rest_model.EnrollmentCreate{
+				IdentityID: &identityID,
+				Method:     &createMethod,
+				ExpiresAt:  &expiresAt,
+			},
+			Context: ctx,
+		}, nil)
+	default:
+		diags.AddAttributeError(path.Root("method"), "Error Issuing Enrollment",
+			fmt.Sprintf("Identity %s has no pending %s enrollment to refresh; only ott enrollments can be issued.", identityID, method))
+		return nil
+	}
+	if err != nil {
+		addZitiErrorDiagnostic(ctx, diags, nil, "Error Issuing Enrollment", "Could not issue an enrollment for identity "+identityID+": ", zitiAPIErrorFrom(err))
+		return nil
+	}
+
+	pending, err = pendingIdentityEnrollment(ctx, r.resourceConfig, identityID, method)
+	if err == nil && pending == nil {
+		err = errors.New("the identity has no pending enrollment after issuing it")
+	}
+	if err != nil {
+		addZitiErrorDiagnostic(ctx, diags, nil, "Error Issuing Enrollment", "Could not READ identity "+identityID+": ", zitiAPIErrorFrom(err))
+		return nil
+	}
+	return pending
+}
+
+// edgeRouterEnrollment returns the pending enrollment of the edge router.
+func (r *enrollmentTokenEphemeralResource) edgeRouterEnrollment(ctx context.Context, routerID string, diags *diag.Diagnostics) *pendingEnrollment {
+	detail, err := r.resourceConfig.api.EdgeRouter.DetailEdgeRouter(&edge_router.DetailEdgeRouterParams{ID: routerID, Context: ctx}, nil)
+	if err == nil && (detail.Payload == nil || detail.Payload.Data == nil) {
+		err = errMissingData
+	}
+	if err != nil {
+		addZitiErrorDiagnostic(ctx, diags, nil, "Error Reading Enrollment", "Could not READ edge router "+routerID+", unexpected error: ", zitiAPIErrorFrom(err))
+		return nil
+	}
+	data := detail.Payload.Data
+	if data.EnrollmentJWT == nil || *data.EnrollmentJWT == "" {
+		diags.AddError("Error Reading Enrollment",
+			fmt.Sprintf("Edge router %s has no pending enrollment, as it has enrolled.", routerID))
+		return nil
+	}
+	pending := &pendingEnrollment{method: "erott", jwt: *data.EnrollmentJWT}
+	if data.EnrollmentExpiresAt != nil {
+		pending.expiresAt = *data.EnrollmentExpiresAt
+	}
+	return pending
+}
+
+// pendingIdentityEnrollment returns the pending enrollment of an identity
+// with the given method, or the first pending one if method is "". It
+// returns nil if there is none.
+func pendingIdentityEnrollment(ctx context.Context, client *zitiData, identityID, method string) (*pendingEnrollment, error) {
+	detail, err := client.api.Identity.DetailIdentity(&identity.DetailIdentityParams{ID: identityID, Context: ctx}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if detail.Payload == nil || detail.Payload.Data == nil {
+		return nil, errMissingData
+	}
+	e := detail.Payload.Data.Enrollment
+	if e == nil {
+		return nil, nil
+	}
+	pending := map[string]*pendingEnrollment{}
+	if e.Ott != nil && e.Ott.JWT != "" {
+		pending["ott"] = &pendingEnrollment{id: e.Ott.ID, method: "ott", jwt: e.Ott.JWT, token: e.Ott.Token, expiresAt: e.Ott.ExpiresAt}
+	}
+	if e.Ottca != nil && e.Ottca.JWT != "" {
+		pending["ottca"] = &pendingEnrollment{id: e.Ottca.ID, method: "ottca", jwt: e.Ottca.JWT, token: e.Ottca.Token, expiresAt: e.Ottca.ExpiresAt}
+	}
+	if e.Updb != nil && e.Updb.JWT != "" {
+		pending["updb"] = &pendingEnrollment{id: e.Updb.ID, method: "updb", jwt: e.Updb.JWT, token: e.Updb.Token, expiresAt: e.Updb.ExpiresAt}
+	}
+	if method != "" {
+		return pending[method], nil
+	}
+	for _, m := range identityEnrollmentMethods {
+		if p, ok := pending[m]; ok {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+// parseLifetime parses expires_in, which defaults to
+// defaultIssuedEnrollmentLifetime. It reports false if expires_in is not a
+// positive duration.
+func parseLifetime(expiresIn types.String) (time.Duration, bool) {
+	if expiresIn.IsNull() {
+		return defaultIssuedEnrollmentLifetime, true
+	}
+	lifetime, err := time.ParseDuration(expiresIn.ValueString())
+	return lifetime, err == nil && lifetime > 0
+}
+
+func addInvalidLifetimeError(diags *diag.Diagnostics, expiresIn types.String) {
+	diags.AddAttributeError(path.Root("expires_in"), "Invalid Expiry",
+		fmt.Sprintf("Expected a positive duration such as \"24h\", got %q.", expiresIn.ValueString()))
+}
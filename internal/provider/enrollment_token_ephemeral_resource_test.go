@@ -0,0 +1,285 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/echoprovider"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/openziti/edge-api/rest_management_api_client/edge_router"
+	"github.com/openziti/edge-api/rest_management_api_client/identity"
+	"github.com/openziti/edge-api/rest_model"
+)
+
+func TestEnrollmentTokenIdentity(t *testing.T) {
+	t.Parallel()
+	controller := mockcontroller.New(t)
+	client := testProviderData(t, controller)
+	identityID := createTestIdentity(t, client, "device", &rest_model.IdentityCreateEnrollment{Ott: true})
+	jwt := pendingJWT(controller, identityID, "ott")
+
+	// The pending JWT is returned as is, even with issue set, so opening the
+	// ephemeral resource on every plan and apply does not replace it.
+	token := openEnrollmentToken(t, client, fmt.Sprintf(`{"identity_id": %q}`, identityID))
+	if token.JWT.ValueString() != jwt {
+		t.Errorf("jwt = %q, want the pending enrollment of the identity", token.JWT.ValueString())
+	}
+	if token.Method.ValueString() != "ott" || token.ExpiresAt.ValueString() == "" {
+		t.Errorf("method = %s, expires_at = %s, want ott and an expiry", token.Method, token.ExpiresAt)
+	}
+	for i := 0; i < 2; i++ {
+		if again := openEnrollmentToken(t, client, fmt.Sprintf(`{"identity_id": %q, "issue": true}`, identityID)); again.JWT != token.JWT {
+			t.Error("a pending enrollment was replaced")
+		}
+	}
+
+	// An expired enrollment is only refreshed with issue set.
+	setEnrollmentExpiry(t, controller, identityID, "ott", time.Now().Add(-time.Minute))
+	expectOpenError(t, client, fmt.Sprintf(`{"identity_id": %q}`, identityID), "Error Reading Enrollment")
+	refreshed := openEnrollmentToken(t, client, fmt.Sprintf(`{"identity_id": %q, "issue": true, "expires_in": "1h"}`, identityID))
+	if refreshed.JWT == token.JWT || refreshed.JWT.ValueString() != pendingJWT(controller, identityID, "ott") {
+		t.Errorf("the expired enrollment was not refreshed: %v", refreshed)
+	}
+	expiresAt, err := time.Parse(time.RFC3339, refreshed.ExpiresAt.ValueString())
+	if err != nil || time.Until(expiresAt) > time.Hour || time.Until(expiresAt) < 50*time.Minute {
+		t.Errorf("expires_at = %s, want about an hour from now", refreshed.ExpiresAt)
+	}
+
+	// Once the enrollment is used, a new one must be issued.
+	controller.PatchEntity(mockcontroller.Identities, identityID, map[string]interface{}{"enrollment": map[string]interface{}{}})
+	expectOpenError(t, client, fmt.Sprintf(`{"identity_id": %q}`, identityID), "Error Reading Enrollment")
+	expectOpenError(t, client, fmt.Sprintf(`{"identity_id": %q, "method": "updb", "issue": true}`, identityID), "Error Issuing Enrollment")
+	issued := openEnrollmentToken(t, client, fmt.Sprintf(`{"identity_id": %q, "issue": true}`, identityID))
+	if issued.Method.ValueString() != "ott" || issued.JWT.ValueString() != pendingJWT(controller, identityID, "ott") {
+		t.Errorf("the issued enrollment is not the pending ott enrollment of the identity: %v", issued)
+	}
+	if again := openEnrollmentToken(t, client, fmt.Sprintf(`{"identity_id": %q, "issue": true}`, identityID)); again.JWT != issued.JWT {
+		t.Error("the issued enrollment was replaced when opened again")
+	}
+}
+
+func TestEnrollmentTokenUpdbIdentity(t *testing.T) {
+	t.Parallel()
+	controller := mockcontroller.New(t)
+	client := testProviderData(t, controller)
+	identityID := createTestIdentity(t, client, "user", &rest_model.IdentityCreateEnrollment{Updb: "user"})
+
+	token := openEnrollmentToken(t, client, fmt.Sprintf(`{"identity_id": %q}`, identityID))
+	if token.Method.ValueString() != "updb" || token.JWT.ValueString() != pendingJWT(controller, identityID, "updb") {
+		t.Errorf("method = %s, jwt = %s, want the updb enrollment of the identity", token.Method, token.JWT)
+	}
+	expectOpenError(t, client, fmt.Sprintf(`{"identity_id": %q, "method": "ott"}`, identityID), "Error Reading Enrollment")
+
+	setEnrollmentExpiry(t, controller, identityID, "updb", time.Now().Add(-time.Minute))
+	refreshed := openEnrollmentToken(t, client, fmt.Sprintf(`{"identity_id": %q, "method": "updb", "issue": true}`, identityID))
+	if refreshed.Method.ValueString() != "updb" || refreshed.JWT == token.JWT || refreshed.JWT.ValueString() != pendingJWT(controller, identityID, "updb") {
+		t.Errorf("the updb enrollment was not refreshed: %v", refreshed)
+	}
+}
+
+func TestEnrollmentTokenEdgeRouter(t *testing.T) {
+	t.Parallel()
+	controller := mockcontroller.New(t)
+	client := testProviderData(t, controller)
+	routerID := createTestEdgeRouter(t, client, "router")
+	router, _ := controller.Entity(mockcontroller.EdgeRouters, routerID)
+
+	token := openEnrollmentToken(t, client, fmt.Sprintf(`{"edge_router_id": %q}`, routerID))
+	if token.JWT.ValueString() != router["enrollmentJwt"] || token.Method.ValueString() != "erott" {
+		t.Errorf("jwt = %s, method = %s, want the enrollment JWT of the edge router and erott", token.JWT, token.Method)
+	}
+
+	// An enrolled router has no JWT, and is never re-enrolled.
+	controller.PatchEntity(mockcontroller.EdgeRouters, routerID, map[string]interface{}{"enrollmentJwt": nil, "isVerified": true})
+	expectOpenError(t, client, fmt.Sprintf(`{"edge_router_id": %q}`, routerID), "Error Reading Enrollment")
+	if entity, _ := controller.Entity(mockcontroller.EdgeRouters, routerID); entity["isVerified"] != true {
+		t.Error("the edge router was re-enrolled")
+	}
+}
+
+// Terraform opens the ephemeral resource on every plan and apply; the
+// enrollment of the identity must survive them.
+func TestAccEnrollmentTokenKeepsEnrollment(t *testing.T) {
+	controller := testAccController(t)
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_identity" "test" {
+  name = "device"
+  type = "Device"
+}
+
+ephemeral "ziti_enrollment_token" "test" {
+  identity_id = ziti_identity.test.id
+  issue       = true
+}
+
+provider "echo" {
+  data = ephemeral.ziti_enrollment_token.test
+}
+
+resource "echo" "test" {}
+`
+	check := resource.ComposeAggregateTestCheckFunc(
+		resource.TestCheckResourceAttrPair("echo.test", "data.jwt", "ziti_identity.test", "enrollment_token"),
+		resource.TestCheckResourceAttr("echo.test", "data.method", "ott"),
+		resource.TestCheckResourceAttrWith("ziti_identity.test", "enrollment_token", func(jwt string) error {
+			id := testAccEntityID(t, controller, mockcontroller.Identities, "device")
+			if pendingJWT(controller, id, "ott") != jwt {
+				return errors.New("the enrollment of the identity was replaced")
+			}
+			return nil
+		}),
+	)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: map[string]func() (tfprotov6.ProviderServer, error){
+			"ziti": testAccProtoV6ProviderFactories["ziti"],
+			"echo": echoprovider.NewProviderServer(),
+		},
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{tfversion.SkipBelow(tfversion.Version1_10_0)},
+		Steps: []resource.TestStep{
+			{Config: config, Check: check},
+			{Config: config, Check: check},
+		},
+	})
+}
+
+func TestAccEnrollmentTokenValidation(t *testing.T) {
+	controller := testAccController(t)
+	var steps []resource.TestStep
+	for _, tc := range []struct {
+		arguments []string
+		want      string
+	}{
+		{nil, "Invalid Attribute Combination"},
+		{[]string{`identity_id = "a"`, `edge_router_id = "b"`}, "Invalid Attribute Combination"},
+		{[]string{`edge_router_id = "b"`, `method = "ott"`}, "Invalid Attribute Combination"},
+		{[]string{`edge_router_id = "b"`, `expires_in = "1h"`}, "Invalid Attribute Combination"},
+		{[]string{`edge_router_id = "b"`, `issue = true`}, "Invalid Attribute Combination"},
+		{[]string{`identity_id = "a"`, `expires_in = "-1h"`}, "Invalid Expiry"},
+		{[]string{`identity_id = "a"`, `method = "erott"`}, "Invalid Attribute Value Match"},
+	} {
+		steps = append(steps, resource.TestStep{
+			Config: testAccProviderConfig(controller, "") + `
+ephemeral "ziti_enrollment_token" "test" {
+  ` + strings.Join(tc.arguments, "\n  ") + `
+}
+`,
+			ExpectError: regexp.MustCompile(tc.want),
+		})
+	}
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks:   []tfversion.TerraformVersionCheck{tfversion.SkipBelow(tfversion.Version1_10_0)},
+		Steps:                    steps,
+	})
+}
+
+// openEnrollmentToken opens a ziti_enrollment_token with config, written in
+// Terraform's JSON syntax, and returns its result.
+func openEnrollmentToken(t *testing.T, client *zitiData, config string) enrollmentTokenEphemeralResourceModel {
+	t.Helper()
+	result, diagnostics := openEnrollmentTokenDiagnostics(t, client, config)
+	if diagnostics.HasError() {
+		t.Fatalf("opening %s: %v", config, diagnostics)
+	}
+	return result
+}
+
+// expectOpenError checks that opening a ziti_enrollment_token fails with an
+// error diagnostic with the given summary.
+func expectOpenError(t *testing.T, client *zitiData, config, summary string) {
+	t.Helper()
+	_, diagnostics := openEnrollmentTokenDiagnostics(t, client, config)
+	for _, d := range diagnostics.Errors() {
+		if d.Summary() == summary {
+			return
+		}
+	}
+	t.Errorf("%s: got diagnostics %v, want an error %q", config, diagnostics, summary)
+}
+
+func openEnrollmentTokenDiagnostics(t *testing.T, client *zitiData, config string) (enrollmentTokenEphemeralResourceModel, diag.Diagnostics) {
+	t.Helper()
+	ctx := context.Background()
+	r := &enrollmentTokenEphemeralResource{resourceConfig: client}
+	var schemaResp ephemeral.SchemaResponse
+	r.Schema(ctx, ephemeral.SchemaRequest{}, &schemaResp)
+	typ := schemaResp.Schema.Type()
+	resp := ephemeral.OpenResponse{
+		Result: tfsdk.EphemeralResultData{Schema: schemaResp.Schema, Raw: tftypes.NewValue(typ.TerraformType(ctx), nil)},
+	}
+	r.Open(ctx, ephemeral.OpenRequest{
+		Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: testValue(t, typ, config)},
+	}, &resp)
+	var result enrollmentTokenEphemeralResourceModel
+	if !resp.Diagnostics.HasError() {
+		resp.Diagnostics.Append(resp.Result.Get(ctx, &result)...)
+	}
+	return result, resp.Diagnostics
+}
+
+// createTestIdentity creates a Device identity with enrollment and returns its
+// id.
+func createTestIdentity(t *testing.T, client *zitiData, name string, enrollment *rest_model.IdentityCreateEnrollment) string {
+	t.Helper()
+	isAdmin := false
+	typ := rest_model.IdentityTypeDevice
+	created, err := client.api.Identity.CreateIdentity(&identity.CreateIdentityParams{
+		Identity: &rest_model.IdentityCreate{Name: &name, IsAdmin: &isAdmin, Type: &typ, Enrollment: enrollment},
+		Context:  context.Background(),
+	}, nil)
+	if err != nil {
+		t.Fatalf("creating the identity %s: %v", name, err)
+	}
+	return created.Payload.Data.ID
+}
+
+// createTestEdgeRouter creates an edge router and returns its id.
+func createTestEdgeRouter(t *testing.T, client *zitiData, name string) string {
+	t.Helper()
+	created, err := client.api.EdgeRouter.CreateEdgeRouter(&edge_router.CreateEdgeRouterParams{
+		EdgeRouter: &rest_model.EdgeRouterCreate{Name: &name},
+		Context:    context.Background(),
+	}, nil)
+	if err != nil {
+		t.Fatalf("creating the edge router %s: %v", name, err)
+	}
+	return created.Payload.Data.ID
+}
+
+// pendingJWT returns the JWT of the pending enrollment of an identity the
+// controller stores for method.
+func pendingJWT(controller *mockcontroller.Server, identityID, method string) string {
+	entity, _ := controller.Entity(mockcontroller.Identities, identityID)
+	enrollment, _ := entity["enrollment"].(map[string]interface{})[method].(map[string]interface{})
+	jwt, _ := enrollment["jwt"].(string)
+	return jwt
+}
+
+// setEnrollmentExpiry sets the expiry of the pending enrollment of an identity
+// the controller stores for method.
+func setEnrollmentExpiry(t *testing.T, controller *mockcontroller.Server, identityID, method string, expiresAt time.Time) {
+	t.Helper()
+	entity, _ := controller.Entity(mockcontroller.Identities, identityID)
+	enrollments, _ := entity["enrollment"].(map[string]interface{})
+	enrollment, ok := enrollments[method].(map[string]interface{})
+	if !ok {
+		t.Fatalf("identity %s has no pending %s enrollment", identityID, method)
+	}
+	enrollment["expiresAt"] = expiresAt.UTC().Format(time.RFC3339Nano)
+	controller.PatchEntity(mockcontroller.Identities, identityID, map[string]interface{}{"enrollment": enrollments})
+}
@@ -0,0 +1,190 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/runtime"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/iancoleman/strcase"
+	"github.com/openziti/edge-api/rest_model"
+)
+
+// ZitiAPIError is a non-2xx response of the Ziti controller.
+type ZitiAPIError struct {
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+	// Code is the controller error code, e.g. COULD_NOT_VALIDATE.
+	Code string
+	// Message is the human readable error message.
+	Message string
+	// Cause describes what caused the error, if the controller reported it.
+	Cause *ZitiAPIErrorCause
+	// RequestID identifies the request in the controller logs.
+	RequestID string
+	// Body is the raw response body, kept for responses that are not a
+	// controller error envelope.
+	Body string
+}
+
+// ZitiAPIErrorCause is the cause of a ZitiAPIError. Field is set when a single
+// field of the request was rejected.
+type ZitiAPIErrorCause struct {
+	Field   string
+	Reason  string
+	Value   interface{}
+	Code    string
+	Message string
+}
+
+func zitiAPIErrorFromEnvelope(statusCode int, envelope *rest_model.APIErrorEnvelope) *ZitiAPIError {
+	apiErr := &ZitiAPIError{StatusCode: statusCode}
+	if envelope == nil || envelope.Error == nil {
+		return apiErr
+	}
+	apiErr.Code = envelope.Error.Code
+	apiErr.Message = envelope.Error.Message
+	apiErr.RequestID = envelope.Error.RequestID
+
+	if cause := envelope.Error.Cause; cause != nil {
+		apiErr.Cause = &ZitiAPIErrorCause{
+			Field:   cause.Field,
+			Reason:  cause.Reason,
+			Value:   cause.Value,
+			Code:    cause.Code,
+			Message: cause.Message,
+		}
+	}
+	if apiErr.Cause == nil && envelope.Error.CauseMessage != "" {
+		apiErr.Cause = &ZitiAPIErrorCause{Message: envelope.Error.CauseMessage}
+	}
+	return apiErr
+}
+
+// zitiAPIErrorFromBody converts a non-2xx response sent outside the typed
+// management API client to a *ZitiAPIError.
+func zitiAPIErrorFromBody(statusCode int, body []byte) *ZitiAPIError {
+	var envelope rest_model.APIErrorEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil || envelope.Error == nil {
+		return &ZitiAPIError{StatusCode: statusCode, Body: string(body)}
+	}
+	return zitiAPIErrorFromEnvelope(statusCode, &envelope)
+}
+
+// apiErrorResponse is implemented by the error responses of the typed
+// management API client, e.g. *service.DetailServiceNotFound.
+type apiErrorResponse interface {
+	error
+	GetPayload() *rest_model.APIErrorEnvelope
+}
+
+// apiErrorResponseStatus extracts the status from the message of an
+// apiErrorResponse, e.g. "[GET /services/{id}][404] detailServiceNotFound".
+var apiErrorResponseStatus = regexp.MustCompile(`\]\[(\d{3})\]`)
+
+// zitiAPIErrorFrom converts an error of the typed management API client to a
+// *ZitiAPIError. Other errors, such as transport errors, are returned as is.
+func zitiAPIErrorFrom(err error) error {
+	var errResponse apiErrorResponse
+	if errors.As(err, &errResponse) {
+		statusCode := 0
+		if m := apiErrorResponseStatus.FindStringSubmatch(errResponse.Error()); m != nil {
+			statusCode, _ = strconv.Atoi(m[1])
+		}
+		return zitiAPIErrorFromEnvelope(statusCode, errResponse.GetPayload())
+	}
+
+	var runtimeErr *runtime.APIError
+	if errors.As(err, &runtimeErr) {
+		return &ZitiAPIError{StatusCode: runtimeErr.Code, Message: "unexpected response to " + runtimeErr.OperationName}
+	}
+	return err
+}
+
+func (e *ZitiAPIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Code != "" {
+		fmt.Fprintf(&b, " (%s)", e.Code)
+	}
+	switch {
+	case e.Message != "":
+		fmt.Fprintf(&b, ": %s", e.Message)
+	case e.Code == "" && strings.TrimSpace(e.Body) != "":
+		fmt.Fprintf(&b, ": %s", strings.TrimSpace(e.Body))
+	}
+	if cause := e.Cause.String(); cause != "" {
+		fmt.Fprintf(&b, "; cause: %s", cause)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, " [request id: %s]", e.RequestID)
+	}
+	return b.String()
+}
+
+// Is reports a 404 response as errNotFound.
+func (e *ZitiAPIError) Is(target error) bool {
+	return target == errNotFound && e.StatusCode == http.StatusNotFound
+}
+
+func (c *ZitiAPIErrorCause) String() string {
+	if c == nil {
+		return ""
+	}
+	if c.Field != "" {
+		msg := fmt.Sprintf("%s %s", c.Field, c.Reason)
+		if c.Value != nil {
+			msg += fmt.Sprintf(" (value: %v)", c.Value)
+		}
+		return msg
+	}
+	if c.Message != "" {
+		return c.Message
+	}
+	return c.Code
+}
+
+// schemaTypeResolver is satisfied by resource and data source schemas.
+type schemaTypeResolver interface {
+	TypeAtPath(context.Context, path.Path) (attr.Type, diag.Diagnostics)
+}
+
+// addZitiErrorDiagnostic reports err as an error diagnostic. When the
+// controller rejected a single field of the request, the diagnostic is
+// attached to the matching schema attribute so Terraform points at it.
+func addZitiErrorDiagnostic(ctx context.Context, diags *diag.Diagnostics, schema schemaTypeResolver, summary, detail string, err error) {
+	var apiErr *ZitiAPIError
+	if errors.As(err, &apiErr) && apiErr.Cause != nil && apiErr.Cause.Field != "" && schema != nil {
+		if attrPath, ok := attributePathForField(ctx, schema, apiErr.Cause.Field); ok {
+			diags.AddAttributeError(attrPath, summary, detail+err.Error())
+			return
+		}
+	}
+	diags.AddError(summary, detail+err.Error())
+}
+
+// attributePathForField maps a controller field name, e.g. "roleAttributes" or
+// "data.address", to the schema attribute it was set from. Leading segments
+// that have no attribute of their own, like the "data" wrapper of configs, are
+// dropped.
+func attributePathForField(ctx context.Context, schema schemaTypeResolver, field string) (path.Path, bool) {
+	segments := strings.Split(field, ".")
+	for start := range segments {
+		attrPath := path.Root(strcase.ToSnake(segments[start]))
+		for _, segment := range segments[start+1:] {
+			attrPath = attrPath.AtName(strcase.ToSnake(segment))
+		}
+		if _, diags := schema.TypeAtPath(ctx, attrPath); !diags.HasError() {
+			return attrPath, true
+		}
+	}
+	return path.Empty(), false
+}
@@ -0,0 +1,465 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/defaults"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// exportable is implemented by the resources the exporter can write.
+type exportable interface {
+	// exportIDs lists the IDs of the entities the exporter writes as the
+	// resource type.
+	exportIDs(ctx context.Context) ([]string, error)
+}
+
+// exportIDs lists every entity selected by exportFilter and exports, a page at
+// a time.
+func (r *zitiResource[M, D]) exportIDs(ctx context.Context) ([]string, error) {
+	var ids []string
+	for offset := int64(0); ; offset += listPageSize {
+		filter, err := newFilter().Expression(r.spec.exportFilter).SortBy("id").Page(listPageSize, offset).Build()
+		if err != nil {
+			return nil, err
+		}
+		page, err := r.spec.list(ctx, r.resourceConfig, filter)
+		if err != nil {
+			return nil, fmt.Errorf("listing %s: %w", r.spec.label, zitiAPIErrorFrom(err))
+		}
+		for _, detail := range page {
+			if r.spec.exports == nil || r.spec.exports(detail) {
+				ids = append(ids, r.spec.entityID(detail))
+			}
+		}
+		if len(page) < listPageSize {
+			return ids, nil
+		}
+	}
+}
+
+// exportedResource is an entity read through the resource type it is exported
+// as.
+type exportedResource struct {
+	typeName string
+	label    string
+	id       string
+	schema   schema.Schema
+	state    tftypes.Value
+}
+
+// Export writes a ziti_* resource and an import block for every entity of the
+// controller the ZITI_API_* environment variables point at. Entity IDs that
+// other exported resources refer to, e.g. the configs of a service or an @id
+// role, are written as references to the exported resource.
+func Export(ctx context.Context, w io.Writer, version string) error {
+	p := &zitiProvider{version: version}
+	client, err := configureExport(ctx, p, nil)
+	if err != nil {
+		return err
+	}
+	return exportResources(ctx, w, p, client)
+}
+
+// configureExport configures p with the provider arguments in config; unset
+// arguments are read from the environment, as in a provider block.
+func configureExport(ctx context.Context, p *zitiProvider, config map[string]tftypes.Value) (*zitiData, error) {
+	var schemaResp provider.SchemaResponse
+	p.Schema(ctx, provider.SchemaRequest{}, &schemaResp)
+	objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	values := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, attributeType := range objectType.AttributeTypes {
+		values[name] = tftypes.NewValue(attributeType, nil)
+		if value, ok := config[name]; ok {
+			values[name] = value
+		}
+	}
+
+	var resp provider.ConfigureResponse
+	p.Configure(ctx, provider.ConfigureRequest{
+		Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, values)},
+	}, &resp)
+	if err := diagnosticsError(resp.Diagnostics); err != nil {
+		return nil, err
+	}
+	return resp.ResourceData.(*zitiData), nil
+}
+
+// exportResources reads every exportable entity through client and writes it
+// to w.
+func exportResources(ctx context.Context, w io.Writer, p *zitiProvider, client *zitiData) error {
+	var exported []exportedResource
+	references := map[string]hcl.Traversal{}
+	for _, newResource := range p.Resources(ctx) {
+		r := newResource()
+		exporter, ok := r.(exportable)
+		if !ok {
+			continue
+		}
+		if configurable, ok := r.(resource.ResourceWithConfigure); ok {
+			configurable.Configure(ctx, resource.ConfigureRequest{ProviderData: client}, &resource.ConfigureResponse{})
+		}
+		var metadataResp resource.MetadataResponse
+		r.Metadata(ctx, resource.MetadataRequest{ProviderTypeName: "ziti"}, &metadataResp)
+		var schemaResp resource.SchemaResponse
+		r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+		ids, err := exporter.exportIDs(ctx)
+		if err != nil {
+			return err
+		}
+		labels := map[string]bool{}
+		var ofType []exportedResource
+		for _, id := range ids {
+			state, err := exportRead(ctx, r, schemaResp.Schema, id)
+			if err != nil {
+				return fmt.Errorf("reading %s %s: %w", metadataResp.TypeName, id, err)
+			}
+			if state.IsNull() {
+				continue
+			}
+			var name string
+			if attributes := map[string]tftypes.Value{}; state.As(&attributes) == nil {
+				_ = attributes["name"].As(&name)
+			}
+			label := uniqueLabel(resourceLabel(name, id), labels)
+			ofType = append(ofType, exportedResource{
+				typeName: metadataResp.TypeName,
+				label:    label,
+				id:       id,
+				schema:   schemaResp.Schema,
+				state:    state,
+			})
+			references[id] = hcl.Traversal{
+				hcl.TraverseRoot{Name: metadataResp.TypeName},
+				hcl.TraverseAttr{Name: label},
+				hcl.TraverseAttr{Name: "id"},
+			}
+		}
+		sort.Slice(ofType, func(i, j int) bool { return ofType[i].label < ofType[j].label })
+		exported = append(exported, ofType...)
+	}
+
+	file := hclwrite.NewEmptyFile()
+	body := file.Body()
+	for i, e := range exported {
+		if i > 0 {
+			body.AppendNewline()
+		}
+		writeExportedResource(ctx, body, e, references)
+	}
+	_, err := w.Write(hclwrite.Format(file.Bytes()))
+	return err
+}
+
+// exportRead reads the entity id through r, like a refresh after import. The
+// returned state is null when the entity does not exist.
+func exportRead(ctx context.Context, r resource.Resource, s schema.Schema, id string) (tftypes.Value, error) {
+	objectType := s.Type().TerraformType(ctx).(tftypes.Object)
+	values := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, attributeType := range objectType.AttributeTypes {
+		values[name] = tftypes.NewValue(attributeType, nil)
+	}
+	values["id"] = tftypes.NewValue(tftypes.String, id)
+	state := tfsdk.State{Schema: s, Raw: tftypes.NewValue(objectType, values)}
+
+	resp := resource.ReadResponse{State: state}
+	r.Read(ctx, resource.ReadRequest{State: state}, &resp)
+	if err := diagnosticsError(resp.Diagnostics); err != nil {
+		return tftypes.Value{}, err
+	}
+	return resp.State.Raw, nil
+}
+
+// writeExportedResource appends the import and resource blocks of e to body.
+func writeExportedResource(ctx context.Context, body *hclwrite.Body, e exportedResource, references map[string]hcl.Traversal) {
+	importBlock := body.AppendNewBlock("import", nil).Body()
+	importBlock.SetAttributeTraversal("to", hcl.Traversal{hcl.TraverseRoot{Name: e.typeName}, hcl.TraverseAttr{Name: e.label}})
+	importBlock.SetAttributeValue("id", cty.StringVal(e.id))
+	body.AppendNewline()
+
+	resourceBlock := body.AppendNewBlock("resource", []string{e.typeName, e.label}).Body()
+	var attributes map[string]tftypes.Value
+	_ = e.state.As(&attributes)
+	for _, name := range attributeNames(e.schema.Attributes) {
+		if name == "id" || name == "last_updated" {
+			continue
+		}
+		a := e.schema.Attributes[name]
+		value := attributes[name]
+		if (a.IsSensitive() || value.IsNull()) && a.IsRequired() {
+			resourceBlock.AppendUnstructuredTokens(hclwrite.Tokens{{
+				Type:  hclsyntax.TokenComment,
+				Bytes: []byte("# " + name + " is required but is not returned by the controller\n"),
+			}})
+			continue
+		}
+		if a.IsSensitive() || !exportsAttribute(ctx, a, value) {
+			continue
+		}
+		resourceBlock.SetAttributeRaw(name, exportTokens(ctx, a, value, references))
+	}
+}
+
+// exportsAttribute reports whether an attribute value is written: it is set in
+// configuration and differs from its default.
+func exportsAttribute(ctx context.Context, a schema.Attribute, value tftypes.Value) bool {
+	if value.IsNull() || !value.IsKnown() || (a.IsComputed() && !a.IsOptional() && !a.IsRequired()) {
+		return false
+	}
+	defaultValue, ok := attributeDefault(ctx, a)
+	if !ok {
+		return true
+	}
+	terraformDefault, err := defaultValue.ToTerraformValue(ctx)
+	return err != nil || !terraformDefault.Equal(value)
+}
+
+// attributeDefault returns the static default of an attribute.
+func attributeDefault(ctx context.Context, a schema.Attribute) (attr.Value, bool) {
+	switch a := a.(type) {
+	case schema.StringAttribute:
+		if a.Default != nil {
+			var resp defaults.StringResponse
+			a.Default.DefaultString(ctx, defaults.StringRequest{}, &resp)
+			return resp.PlanValue, true
+		}
+	case schema.BoolAttribute:
+		if a.Default != nil {
+			var resp defaults.BoolResponse
+			a.Default.DefaultBool(ctx, defaults.BoolRequest{}, &resp)
+			return resp.PlanValue, true
+		}
+	case schema.Int32Attribute:
+		if a.Default != nil {
+			var resp defaults.Int32Response
+			a.Default.DefaultInt32(ctx, defaults.Int32Request{}, &resp)
+			return resp.PlanValue, true
+		}
+	case schema.Int64Attribute:
+		if a.Default != nil {
+			var resp defaults.Int64Response
+			a.Default.DefaultInt64(ctx, defaults.Int64Request{}, &resp)
+			return resp.PlanValue, true
+		}
+	case schema.ListAttribute:
+		if a.Default != nil {
+			var resp defaults.ListResponse
+			a.Default.DefaultList(ctx, defaults.ListRequest{}, &resp)
+			return resp.PlanValue, true
+		}
+	case schema.SetAttribute:
+		if a.Default != nil {
+			var resp defaults.SetResponse
+			a.Default.DefaultSet(ctx, defaults.SetRequest{}, &resp)
+			return resp.PlanValue, true
+		}
+	case schema.MapAttribute:
+		if a.Default != nil {
+			var resp defaults.MapResponse
+			a.Default.DefaultMap(ctx, defaults.MapRequest{}, &resp)
+			return resp.PlanValue, true
+		}
+	}
+	return nil, false
+}
+
+// nestedAttributes returns the attributes of the objects of a nested
+// attribute, or nil for any other attribute.
+func nestedAttributes(a schema.Attribute) map[string]schema.Attribute {
+	switch a := a.(type) {
+	case schema.SingleNestedAttribute:
+		return a.Attributes
+	case schema.ListNestedAttribute:
+		return a.NestedObject.Attributes
+	case schema.SetNestedAttribute:
+		return a.NestedObject.Attributes
+	}
+	return nil
+}
+
+// exportTokens returns the HCL expression of an attribute value.
+func exportTokens(ctx context.Context, a schema.Attribute, value tftypes.Value, references map[string]hcl.Traversal) hclwrite.Tokens {
+	attributes := nestedAttributes(a)
+	if attributes == nil {
+		return valueTokens(value, references)
+	}
+	if value.Type().Is(tftypes.Object{}) {
+		return objectTokens(ctx, attributes, value, references)
+	}
+	var elements []tftypes.Value
+	_ = value.As(&elements)
+	tokens := make([]hclwrite.Tokens, 0, len(elements))
+	for _, element := range elements {
+		tokens = append(tokens, objectTokens(ctx, attributes, element, references))
+	}
+	return hclwrite.TokensForTuple(tokens)
+}
+
+// objectTokens returns the HCL object of a nested attribute object, without
+// the attributes exportsAttribute skips.
+func objectTokens(ctx context.Context, attributes map[string]schema.Attribute, value tftypes.Value, references map[string]hcl.Traversal) hclwrite.Tokens {
+	var values map[string]tftypes.Value
+	_ = value.As(&values)
+	var tokens []hclwrite.ObjectAttrTokens
+	for _, name := range attributeNames(attributes) {
+		if !exportsAttribute(ctx, attributes[name], values[name]) {
+			continue
+		}
+		tokens = append(tokens, hclwrite.ObjectAttrTokens{
+			Name:  hclwrite.TokensForIdentifier(name),
+			Value: exportTokens(ctx, attributes[name], values[name], references),
+		})
+	}
+	return hclwrite.TokensForObject(tokens)
+}
+
+// valueTokens returns the HCL expression of a value. A string that is the ID
+// of an exported entity, or an @ role of one, is written as a reference to
+// the resource of the entity.
+func valueTokens(value tftypes.Value, references map[string]hcl.Traversal) hclwrite.Tokens {
+	if value.IsNull() {
+		return hclwrite.TokensForValue(cty.NullVal(cty.DynamicPseudoType))
+	}
+	switch {
+	case value.Type().Is(tftypes.String):
+		var s string
+		_ = value.As(&s)
+		if reference, ok := references[s]; ok {
+			return hclwrite.TokensForTraversal(reference)
+		}
+		if reference, ok := references[strings.TrimPrefix(s, "@")]; ok && strings.HasPrefix(s, "@") {
+			return roleReferenceTokens(reference)
+		}
+		return hclwrite.TokensForValue(cty.StringVal(s))
+	case value.Type().Is(tftypes.Number):
+		n := new(big.Float)
+		_ = value.As(&n)
+		return hclwrite.TokensForValue(cty.NumberVal(n))
+	case value.Type().Is(tftypes.Bool):
+		var b bool
+		_ = value.As(&b)
+		return hclwrite.TokensForValue(cty.BoolVal(b))
+	case value.Type().Is(tftypes.List{}), value.Type().Is(tftypes.Set{}), value.Type().Is(tftypes.Tuple{}):
+		var elements []tftypes.Value
+		_ = value.As(&elements)
+		tokens := make([]hclwrite.Tokens, 0, len(elements))
+		for _, element := range elements {
+			tokens = append(tokens, valueTokens(element, references))
+		}
+		if value.Type().Is(tftypes.Set{}) {
+			sort.Slice(tokens, func(i, j int) bool { return bytes.Compare(tokens[i].Bytes(), tokens[j].Bytes()) < 0 })
+		}
+		return hclwrite.TokensForTuple(tokens)
+	default:
+		var elements map[string]tftypes.Value
+		_ = value.As(&elements)
+		keys := make([]string, 0, len(elements))
+		for key, element := range elements {
+			if !element.IsNull() {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		tokens := make([]hclwrite.ObjectAttrTokens, 0, len(keys))
+		for _, key := range keys {
+			name := hclwrite.TokensForValue(cty.StringVal(key))
+			if hclsyntax.ValidIdentifier(key) {
+				name = hclwrite.TokensForIdentifier(key)
+			}
+			tokens = append(tokens, hclwrite.ObjectAttrTokens{Name: name, Value: valueTokens(elements[key], references)})
+		}
+		return hclwrite.TokensForObject(tokens)
+	}
+}
+
+// roleReferenceTokens returns the template "@${reference}" of an @ role.
+func roleReferenceTokens(reference hcl.Traversal) hclwrite.Tokens {
+	tokens := hclwrite.Tokens{
+		{Type: hclsyntax.TokenOQuote, Bytes: []byte(`"`)},
+		{Type: hclsyntax.TokenQuotedLit, Bytes: []byte("@")},
+		{Type: hclsyntax.TokenTemplateInterp, Bytes: []byte("${")},
+	}
+	tokens = append(tokens, hclwrite.TokensForTraversal(reference)...)
+	return append(tokens,
+		&hclwrite.Token{Type: hclsyntax.TokenTemplateSeqEnd, Bytes: []byte("}")},
+		&hclwrite.Token{Type: hclsyntax.TokenCQuote, Bytes: []byte(`"`)},
+	)
+}
+
+// attributeNames returns the names of attributes, name first and the others
+// sorted.
+func attributeNames(attributes map[string]schema.Attribute) []string {
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if (names[i] == "name") != (names[j] == "name") {
+			return names[i] == "name"
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// resourceLabel turns the name of an entity into a resource name: lower case
+// letters, digits, underscores and dashes, starting with a letter or an
+// underscore. An entity without a name is labelled by its ID.
+func resourceLabel(name, id string) string {
+	if name == "" {
+		name = id
+	}
+	var label strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			label.WriteRune(r)
+		case !strings.HasSuffix(label.String(), "_"):
+			label.WriteByte('_')
+		}
+	}
+	s := strings.Trim(label.String(), "_")
+	if s == "" || !(s[0] >= 'a' && s[0] <= 'z') {
+		s = "_" + s
+	}
+	return s
+}
+
+// uniqueLabel returns label, or label with a numeric suffix when taken.
+func uniqueLabel(label string, taken map[string]bool) string {
+	unique := label
+	for i := 2; taken[unique]; i++ {
+		unique = fmt.Sprintf("%s_%d", label, i)
+	}
+	taken[unique] = true
+	return unique
+}
+
+// diagnosticsError returns the error diagnostics of diags as an error.
+func diagnosticsError(diags diag.Diagnostics) error {
+	if !diags.HasError() {
+		return nil
+	}
+	var messages []string
+	for _, d := range diags.Errors() {
+		messages = append(messages, d.Summary()+": "+d.Detail())
+	}
+	return fmt.Errorf("%s", strings.Join(messages, "; "))
+}
@@ -0,0 +1,155 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestAccExport(t *testing.T) {
+	controller := testAccController(t)
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_host_v1_config" "web" {
+  name     = "web.host.v1"
+  address  = "localhost"
+  port     = 8080
+  protocol = "tcp"
+}
+
+resource "ziti_service" "web" {
+  name    = "web"
+  configs = [ziti_host_v1_config.web.id]
+}
+
+resource "ziti_identity" "client" {
+  name            = "Web Client"
+  role_attributes = ["clients"]
+}
+
+resource "ziti_identity_updb" "operator" {
+  name          = "operator"
+  updb_username = "operator"
+}
+
+resource "ziti_service_policy" "web_dial" {
+  name          = "web-dial"
+  semantic      = "AnyOf"
+  type          = "Dial"
+  identityroles = ["@${ziti_identity.client.id}", "#clients"]
+  serviceroles  = ["@${ziti_service.web.id}"]
+}
+
+resource "ziti_posture_check_domains" "corp" {
+  name    = "corp-domain"
+  domains = ["corp.example"]
+}
+`
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{{
+			Config: config,
+			Check: func(s *terraform.State) error {
+				return checkExport(controller, s.RootModule().Resources["ziti_identity.client"].Primary.ID)
+			},
+		}},
+	})
+}
+
+// checkExport exports the entities of controller and checks the configuration
+// generated for them. clientID is the id of the identity named Web Client.
+func checkExport(controller *mockcontroller.Server, clientID string) error {
+	p := &zitiProvider{version: "test"}
+	data, err := configureExport(context.Background(), p, map[string]tftypes.Value{
+		"host":     tftypes.NewValue(tftypes.String, controller.ManagementURL()),
+		"username": tftypes.NewValue(tftypes.String, mockcontroller.DefaultUsername),
+		"password": tftypes.NewValue(tftypes.String, mockcontroller.DefaultPassword),
+	})
+	if err != nil {
+		return fmt.Errorf("configuring the exporter: %w", err)
+	}
+	var out bytes.Buffer
+	if err := exportResources(context.Background(), &out, p, data); err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	exported := out.String()
+
+	file, diags := hclsyntax.ParseConfig(out.Bytes(), "export.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		return fmt.Errorf("the export is not valid HCL: %s\n%s", diags, exported)
+	}
+	blocks := map[string]int{}
+	for _, block := range file.Body.(*hclsyntax.Body).Blocks {
+		key := block.Type
+		if len(block.Labels) > 0 {
+			key += " " + block.Labels[0]
+		}
+		blocks[key]++
+	}
+	var errs []error
+	for key, want := range map[string]int{
+		"import":                              6,
+		"resource ziti_host_v1_config":        1,
+		"resource ziti_service":               1,
+		"resource ziti_identity":              1,
+		"resource ziti_identity_updb":         1,
+		"resource ziti_service_policy":        1,
+		"resource ziti_posture_check_domains": 1,
+		"resource ziti_auth_policy":           0,
+	} {
+		if blocks[key] != want {
+			errs = append(errs, fmt.Errorf("got %d %s blocks, want %d", blocks[key], key, want))
+		}
+	}
+
+	for _, want := range []string{
+		`resource "ziti_identity" "web_client" {`,
+		"to = ziti_identity.web_client",
+		fmt.Sprintf("id = %q", clientID),
+		"configs = [ziti_host_v1_config.web_host_v1.id]",
+		`identityroles = ["#clients", "@${ziti_identity.web_client.id}"]`,
+		`serviceroles  = ["@${ziti_service.web.id}"]`,
+		"# updb_username is required but is not returned by the controller",
+	} {
+		if !strings.Contains(exported, want) {
+			errs = append(errs, fmt.Errorf("the export does not contain %q", want))
+		}
+	}
+	for _, unwanted := range []string{"enrollment_token", "last_updated", "is_admin"} {
+		if strings.Contains(exported, unwanted) {
+			errs = append(errs, fmt.Errorf("the export contains %s", unwanted))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%w\n%s", errors.Join(errs...), exported)
+	}
+	return nil
+}
+
+func TestResourceLabel(t *testing.T) {
+	t.Parallel()
+	taken := map[string]bool{}
+	for _, tt := range []struct{ name, id, want string }{
+		{"Web Client", "a1", "web_client"},
+		{"web.client", "a2", "web_client_2"},
+		{"db-01", "a3", "db-01"},
+		{"1st", "a4", "_1st"},
+		{"", "xY9", "xy9"},
+		{"@@@", "a5", "_"},
+	} {
+		if got := uniqueLabel(resourceLabel(tt.name, tt.id), taken); got != tt.want {
+			t.Errorf("label of %q = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
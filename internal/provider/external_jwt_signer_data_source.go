@@ -2,13 +2,12 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/rs/zerolog/log"
+	"github.com/openziti/edge-api/rest_management_api_client/external_jwt_signer"
+	"github.com/openziti/edge-api/rest_model"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -37,9 +36,6 @@ func (r *jwtSignerDataSource) Configure(_ context.Context, req datasource.Config
 
 	datasourceConfig := req.ProviderData.(*zitiData)
 	r.datasourceConfig = datasourceConfig
-
-	fmt.Printf("Using API Token to create datasource: %s\n", r.datasourceConfig.apiToken)
-	fmt.Printf("Using domain to create datasource: %s\n", r.datasourceConfig.host)
 }
 
 // Metadata returns the datasource type name.
@@ -153,118 +149,61 @@ func (r *jwtSignerDataSource) Read(ctx context.Context, req datasource.ReadReque
 		return
 	}
 
-	filter := ""
-	if state.Name.ValueString() != "" {
-		filter = "filter=name=\"" + state.Name.ValueString() + "\""
-	}
-	if state.ID.ValueString() != "" {
-		filter = "filter=id=\"" + state.ID.ValueString() + "\""
-	}
-
-	authUrl := fmt.Sprintf("%s/external-jwt-signers?%s", r.datasourceConfig.host, filter)
-	cresp, err := ReadZitiResource(authUrl, r.datasourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Reading external jwt signer", "Could not READ external jwt signer, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
-	if err != nil {
-		log.Error().Msgf("Error unmarshalling JSON response from Ziti DataSource Response: %v", err)
-		fmt.Println("Error unmarshalling JSON:", err)
-		return
-	}
-
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	service := jsonBody["data"].([]interface{})
-
-	if len(service) > 1 {
-		resp.Diagnostics.AddError(
-			"Multiple items returned from API upon filter execution!", "Try to narrow down the filter expression"+filter,
-		)
-	}
-	if len(service) == 0 {
-		resp.Diagnostics.AddError(
-			"No items returned from API upon filter execution!", "Try to relax the filter expression: "+filter,
-		)
-	}
-	if resp.Diagnostics.HasError() {
+	filter := dataSourceFilter(state.ID, state.Name)
+	detail, ok := lookupDataSourceEntity(ctx, r.datasourceConfig, &resp.Diagnostics, "external jwt signer", filter, jwtSignerList)
+	if !ok {
 		return
 	}
 
-	data := service[0].(map[string]interface{})
+	state.ID = types.StringPointerValue(detail.ID)
+	state.Name = types.StringPointerValue(detail.Name)
 
-	state.Name = types.StringValue(data["name"].(string))
-	state.ID = types.StringValue(data["id"].(string))
-
-	if issuer, ok := data["issuer"].(string); ok {
-		state.Issuer = types.StringValue(issuer)
+	if detail.Issuer != nil {
+		state.Issuer = types.StringValue(*detail.Issuer)
 	}
 
-	if audience, ok := data["audience"].(string); ok {
-		state.Audience = types.StringValue(audience)
+	if detail.Audience != nil {
+		state.Audience = types.StringValue(*detail.Audience)
 	}
 
-	if claimsProperty, ok := data["claimsProperty"].(string); ok {
-		state.ClaimsProperty = types.StringValue(claimsProperty)
+	if detail.ClaimsProperty != nil {
+		state.ClaimsProperty = types.StringValue(*detail.ClaimsProperty)
 	}
 
-	if clientID, ok := data["clientID"].(string); ok {
-		state.ClientID = types.StringValue(clientID)
+	if detail.ClientID != nil {
+		state.ClientID = types.StringValue(*detail.ClientID)
 	}
 
-	if externalAuthURL, ok := data["externalAuthUrl"].(string); ok {
-		state.ExternalAuthURL = types.StringValue(externalAuthURL)
+	if detail.ExternalAuthURL != nil {
+		state.ExternalAuthURL = types.StringValue(*detail.ExternalAuthURL)
 	}
 
-	if targetToken, ok := data["targetToken"].(string); ok {
-		state.TargetToken = types.StringValue(targetToken)
+	if detail.TargetToken != nil {
+		state.TargetToken = types.StringValue(string(*detail.TargetToken))
 	}
 
-	if jwksEndpoint, ok := data["jwksEndpoint"].(string); ok {
-		state.JwksEndpoint = types.StringValue(jwksEndpoint)
+	if detail.JwksEndpoint != nil {
+		state.JwksEndpoint = types.StringValue(detail.JwksEndpoint.String())
 	}
 
-	if certPem, ok := data["certPem"].(string); ok {
-		state.CertPem = types.StringValue(certPem)
+	if detail.CertPem != nil {
+		state.CertPem = types.StringValue(*detail.CertPem)
 	}
 
-	if kid, ok := data["kid"].(string); ok && kid != "" {
-		state.Kid = types.StringValue(kid)
+	if detail.Kid != nil && *detail.Kid != "" {
+		state.Kid = types.StringValue(*detail.Kid)
 	}
 
-	if useExternalId, ok := data["useExternalId"].(bool); ok {
-		state.UseExternalId = types.BoolValue(useExternalId)
+	if detail.UseExternalID != nil {
+		state.UseExternalId = types.BoolValue(*detail.UseExternalID)
 	}
 
-	if enabled, ok := data["enabled"].(bool); ok {
-		state.Enabled = types.BoolValue(enabled)
+	if detail.Enabled != nil {
+		state.Enabled = types.BoolValue(*detail.Enabled)
 	}
 
-	if scopes, ok := data["scopes"].([]interface{}); ok {
-		scopes, diag := types.ListValueFrom(ctx, types.StringType, scopes)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.Scopes = scopes
-	} else {
-		state.Scopes = types.ListNull(types.StringType)
-	}
-
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
-	}
+	state.Scopes = stringListFromAPI(ctx, detail.Scopes, &resp.Diagnostics)
+	state.Tags = tagsFromAPI(ctx, detail.Tags, &resp.Diagnostics)
 
 	// Set refreshed state
 	diags = resp.State.Set(ctx, &state)
@@ -273,3 +212,15 @@ func (r *jwtSignerDataSource) Read(ctx context.Context, req datasource.ReadReque
 		return
 	}
 }
+
+// jwtSignerList lists the external JWT signers matching filter.
+func jwtSignerList(ctx context.Context, client *zitiData, filter string) ([]*rest_model.ExternalJWTSignerDetail, error) {
+	list, err := client.api.ExternalJWTSigner.ListExternalJWTSigners(&external_jwt_signer.ListExternalJWTSignersParams{Filter: optionalFilter(filter), Context: ctx}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if list.Payload == nil {
+		return nil, errMissingData
+	}
+	return withoutNil(list.Payload.Data), nil
+}
@@ -2,13 +2,10 @@ package provider
 
 import (
 	"context"
-	"errors"
-	"encoding/json"
-	"fmt"
-	"net/url"
-	"time"
 
+	"github.com/go-openapi/strfmt"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -20,10 +17,8 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/openziti/edge-api/rest_management_api_client/external_jwt_signer"
 	"github.com/openziti/edge-api/rest_model"
-	"github.com/rs/zerolog/log"
-	"github.com/tidwall/gjson"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -35,32 +30,24 @@ var (
 
 // NewJwtSignerResource is a helper function to simplify the provider implementation.
 func NewJwtSignerResource() resource.Resource {
-	return &jwtSignerResource{}
+	return &jwtSignerResource{newZitiResource(zitiResourceSpec[jwtSignerResourceModel, *rest_model.ExternalJWTSignerDetail]{
+		typeName: "_external_jwt_signer",
+		label:    "external jwt signer",
+		create:   jwtSignerCreate,
+		read:     jwtSignerRead,
+		readData: jwtSignerReadData,
+		update:   jwtSignerUpdate,
+		delete:   jwtSignerDelete,
+		list:     jwtSignerList,
+		entityID: func(detail *rest_model.ExternalJWTSignerDetail) string {
+			return types.StringPointerValue(detail.ID).ValueString()
+		},
+	})}
 }
 
 // jwtSignerResource is the resource implementation.
 type jwtSignerResource struct {
-	resourceConfig *zitiData
-}
-
-// Configure adds the provider configured client to the resource.
-func (r *jwtSignerResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	// Add a nil check when handling ProviderData because Terraform
-	// sets that data after it calls the ConfigureProvider RPC.
-	if req.ProviderData == nil {
-		return
-	}
-
-	resourceConfig := req.ProviderData.(*zitiData)
-	r.resourceConfig = resourceConfig
-
-	fmt.Printf("Using API Token to create resource: %s\n", r.resourceConfig.apiToken)
-	fmt.Printf("Using domain to create resource: %s\n", r.resourceConfig.host)
-}
-
-// Metadata returns the resource type name.
-func (r *jwtSignerResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
-	resp.TypeName = req.ProviderTypeName + "_external_jwt_signer"
+	zitiResource[jwtSignerResourceModel, *rest_model.ExternalJWTSignerDetail]
 }
 
 // jwtSignerResourceModel maps the resource schema data.
@@ -80,26 +67,10 @@ type jwtSignerResourceModel struct {
 	Kid             types.String `tfsdk:"kid"`
 	Enabled         types.Bool   `tfsdk:"enabled"`
 	Tags            types.Map    `tfsdk:"tags"`
+	TagsAll         types.Map    `tfsdk:"tags_all"`
 	LastUpdated     types.String `tfsdk:"last_updated"`
 }
 
-type jwtSignerPayload struct {
-	Name            *string          `json:"name"`
-	Issuer          *string          `json:"issuer"`
-	Audience        *string          `json:"audience"`
-	ClaimsProperty  *string          `json:"claimsProperty,omitempty"`
-	UseExternalId   *bool            `json:"useExternalId,omitempty"`
-	ClientID        *string          `json:"clientId,omitempty"`
-	ExternalAuthURL *string          `json:"externalAuthUrl,omitempty"`
-	Scopes          []string         `json:"scopes"`
-	TargetToken     *string          `json:"targetToken,omitempty"`
-	JwksEndpoint    *string          `json:"jwksEndpoint,omitempty"`
-	CertPem         *string          `json:"certPem,omitempty"`
-	Kid             *string          `json:"kid,omitempty"`
-	Enabled         *bool            `json:"enabled"`
-	Tags            *rest_model.Tags `json:"tags,omitempty"`
-}
-
 // Schema defines the schema for the resource.
 func (r *jwtSignerResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
@@ -206,21 +177,12 @@ func (r *jwtSignerResource) Schema(_ context.Context, _ resource.SchemaRequest,
 				Default:             mapdefault.StaticValue(types.MapNull(types.StringType)),
 				MarkdownDescription: "External jwt signer Tags",
 			},
+			"tags_all": tagsAllAttribute(),
 		},
 	}
 }
 
-// Create a new resource.
-func (r *jwtSignerResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	// Retrieve values from plan
-	var eplan jwtSignerResourceModel
-
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func jwtSignerCreate(ctx context.Context, client *zitiData, eplan *jwtSignerResourceModel, diags *diag.Diagnostics) (string, error) {
 	name := eplan.Name.ValueString()
 	tags := TagsFromAttributes(eplan.Tags.Elements())
 
@@ -229,14 +191,14 @@ func (r *jwtSignerResource) Create(ctx context.Context, req resource.CreateReque
 	claimsProperty := eplan.ClaimsProperty.ValueString()
 	clientID := eplan.ClientID.ValueString()
 	externalAuthURL := eplan.ExternalAuthURL.ValueString()
-	targetToken := eplan.TargetToken.ValueString()
+	targetToken := rest_model.TargetToken(eplan.TargetToken.ValueString())
 	kid := eplan.Kid.ValueString()
 	useExternalId := eplan.UseExternalId.ValueBool()
 	enabled := eplan.Enabled.ValueBool()
 
-	var jwksEndpoint *string
+	var jwksEndpoint *strfmt.URI
 	if !eplan.JwksEndpoint.IsNull() && !eplan.JwksEndpoint.IsUnknown() {
-		v := eplan.JwksEndpoint.ValueString()
+		v := strfmt.URI(eplan.JwksEndpoint.ValueString())
 		jwksEndpoint = &v
 	}
 
@@ -253,12 +215,12 @@ func (r *jwtSignerResource) Create(ctx context.Context, req resource.CreateReque
 		}
 	}
 
-	payload := jwtSignerPayload{
+	payload := rest_model.ExternalJWTSignerCreate{
 		Name:            &name,
 		Issuer:          &issuer,
 		Audience:        &audience,
 		ClaimsProperty:  &claimsProperty,
-		UseExternalId:   &useExternalId,
+		UseExternalID:   &useExternalId,
 		ClientID:        &clientID,
 		ExternalAuthURL: &externalAuthURL,
 		Scopes:          scopes,
@@ -270,174 +232,14 @@ func (r *jwtSignerResource) Create(ctx context.Context, req resource.CreateReque
 		Tags:            tags,
 	}
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************create resource payload***********************:\n %s\n", jsonData)
-
-	authUrl := fmt.Sprintf("%s/external-jwt-signers", r.resourceConfig.host)
-	cresp, err := CreateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti POST Response: %s", cresp)
-	log.Info().Msg(msg)
+	created, err := client.api.ExternalJWTSigner.CreateExternalJWTSigner(&external_jwt_signer.CreateExternalJWTSignerParams{ExternalJWTSigner: &payload, Context: ctx}, nil)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Creating external jwt signer", "Could not Create external jwt signer, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	fmt.Printf("**********************create response************************:\n %s\n", cresp)
-	resourceID := gjson.Get(cresp, "data.id").String()
-
-	// Map response body to schema and populate Computed attribute values
-	eplan.ID = types.StringValue(resourceID)
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
-
-	// Set state to fully populated data
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+		return "", err
 	}
+	return createdID(created.Payload)
 }
 
-// Read resource information.
-func (r *jwtSignerResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	// Get current state
-	var state jwtSignerResourceModel
-	tflog.Debug(ctx, "Reading external jwt signer")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	authUrl := fmt.Sprintf("%s/external-jwt-signers/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := ReadZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		if errors.Is(err, errNotFound) {
-			msg := fmt.Sprintf("Resource not found in backend; removing from state, id: %s", state.ID.ValueString())
-			log.Info().Msg(msg)
-			resp.State.RemoveResource(ctx)
-			return
-		}
-		resp.Diagnostics.AddError(
-			"Error Reading external jwt signer", "Could not READ external jwt signer, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Reading external jwt signer", fmt.Sprintf("Could not READ external jwt signer, ERROR %v: ", err.Error()),
-		)
-		return
-	}
-
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	data, ok := jsonBody["data"].(map[string]interface{})
-	if !ok {
-		resp.Diagnostics.AddError("Error: ", "'data' is either missing or not a map[string]interface{}")
-		return
-	}
-
-	// Manually assign individual values from the map to the struct fields
-	state.Name = types.StringValue(data["name"].(string))
-
-	if issuer, ok := data["issuer"].(string); ok {
-		state.Issuer = types.StringValue(issuer)
-	}
-
-	if audience, ok := data["audience"].(string); ok {
-		state.Audience = types.StringValue(audience)
-	}
-
-	if claimsProperty, ok := data["claimsProperty"].(string); ok {
-		state.ClaimsProperty = types.StringValue(claimsProperty)
-	}
-
-	if clientID, ok := data["clientID"].(string); ok {
-		state.ClientID = types.StringValue(clientID)
-	}
-
-	if externalAuthURL, ok := data["externalAuthUrl"].(string); ok {
-		state.ExternalAuthURL = types.StringValue(externalAuthURL)
-	}
-
-	if targetToken, ok := data["targetToken"].(string); ok {
-		state.TargetToken = types.StringValue(targetToken)
-	}
-
-	if jwksEndpoint, ok := data["jwksEndpoint"].(string); ok {
-		state.JwksEndpoint = types.StringValue(jwksEndpoint)
-	}
-
-	if certPem, ok := data["certPem"].(string); ok {
-		state.CertPem = types.StringValue(certPem)
-	}
-
-	if kid, ok := data["kid"].(string); ok && kid != "" {
-		state.Kid = types.StringValue(kid)
-	}
-
-	if useExternalId, ok := data["useExternalId"].(bool); ok {
-		state.UseExternalId = types.BoolValue(useExternalId)
-	}
-
-	if enabled, ok := data["enabled"].(bool); ok {
-		state.Enabled = types.BoolValue(enabled)
-	}
-
-	if scopes, ok := data["scopes"].([]interface{}); ok {
-		scopes, diag := types.ListValueFrom(ctx, types.StringType, scopes)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.Scopes = scopes
-	} else {
-		state.Scopes = types.ListNull(types.StringType)
-	}
-
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
-	}
-
-	// Set refreshed state
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-}
-
-// Update updates the resource and sets the updated Terraform state on success.
-func (r *jwtSignerResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	// Retrieve values from plan
-	var eplan jwtSignerResourceModel
-	tflog.Debug(ctx, "Updating external jwt signer")
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	// Get current state
-	var state jwtSignerResourceModel
-	sdiags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(sdiags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func jwtSignerUpdate(ctx context.Context, client *zitiData, id string, eplan *jwtSignerResourceModel, diags *diag.Diagnostics) error {
 	name := eplan.Name.ValueString()
 	tags := TagsFromAttributes(eplan.Tags.Elements())
 
@@ -446,14 +248,14 @@ func (r *jwtSignerResource) Update(ctx context.Context, req resource.UpdateReque
 	claimsProperty := eplan.ClaimsProperty.ValueString()
 	clientID := eplan.ClientID.ValueString()
 	externalAuthURL := eplan.ExternalAuthURL.ValueString()
-	targetToken := eplan.TargetToken.ValueString()
+	targetToken := rest_model.TargetToken(eplan.TargetToken.ValueString())
 	kid := eplan.Kid.ValueString()
 	useExternalId := eplan.UseExternalId.ValueBool()
 	enabled := eplan.Enabled.ValueBool()
 
-	var jwksEndpoint *string
+	var jwksEndpoint *strfmt.URI
 	if !eplan.JwksEndpoint.IsNull() && !eplan.JwksEndpoint.IsUnknown() {
-		v := eplan.JwksEndpoint.ValueString()
+		v := strfmt.URI(eplan.JwksEndpoint.ValueString())
 		jwksEndpoint = &v
 	}
 
@@ -470,12 +272,12 @@ func (r *jwtSignerResource) Update(ctx context.Context, req resource.UpdateReque
 		}
 	}
 
-	payload := jwtSignerPayload{
+	payload := rest_model.ExternalJWTSignerUpdate{
 		Name:            &name,
 		Issuer:          &issuer,
 		Audience:        &audience,
 		ClaimsProperty:  &claimsProperty,
-		UseExternalId:   &useExternalId,
+		UseExternalID:   &useExternalId,
 		ClientID:        &clientID,
 		ExternalAuthURL: &externalAuthURL,
 		Scopes:          scopes,
@@ -487,55 +289,68 @@ func (r *jwtSignerResource) Update(ctx context.Context, req resource.UpdateReque
 		Tags:            tags,
 	}
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************update resource payload***********************:\n %s\n", jsonData)
+	_, err := client.api.ExternalJWTSigner.UpdateExternalJWTSigner(&external_jwt_signer.UpdateExternalJWTSignerParams{ID: id, ExternalJWTSigner: &payload, Context: ctx}, nil)
+	return err
+}
 
-	authUrl := fmt.Sprintf("%s/external-jwt-signers/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := UpdateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti PATCH Response: %s", cresp)
-	log.Info().Msg(msg)
+func jwtSignerRead(ctx context.Context, client *zitiData, id string) (*rest_model.ExternalJWTSignerDetail, error) {
+	detail, err := client.api.ExternalJWTSigner.DetailExternalJWTSigner(&external_jwt_signer.DetailExternalJWTSignerParams{ID: id, Context: ctx}, nil)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Updating external jwt signer", "Could not Update external jwt signer, unexpected error: "+err.Error(),
-		)
-		return
+		return nil, err
+	}
+	if detail.Payload == nil || detail.Payload.Data == nil {
+		return nil, errMissingData
 	}
+	return detail.Payload.Data, nil
+}
+
+func jwtSignerDelete(ctx context.Context, client *zitiData, id string) error {
+	_, err := client.api.ExternalJWTSigner.DeleteExternalJWTSigner(&external_jwt_signer.DeleteExternalJWTSignerParams{ID: id, Context: ctx}, nil)
+	return err
+}
 
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
+func jwtSignerReadData(ctx context.Context, detail *rest_model.ExternalJWTSignerDetail, state *jwtSignerResourceModel, diags *diag.Diagnostics) {
+	state.Name = types.StringPointerValue(detail.Name)
 
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+	if detail.Issuer != nil {
+		state.Issuer = types.StringValue(*detail.Issuer)
 	}
-}
 
-// Delete deletes the resource and removes the Terraform state on success.
-func (r *jwtSignerResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	// Retrieve values from state
-	var state jwtSignerResourceModel
-	tflog.Debug(ctx, "Deleting external jwt signer")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+	if detail.Audience != nil {
+		state.Audience = types.StringValue(*detail.Audience)
 	}
 
-	authUrl := fmt.Sprintf("%s/external-jwt-signers/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
+	if detail.ClaimsProperty != nil {
+		state.ClaimsProperty = types.StringValue(*detail.ClaimsProperty)
+	}
 
-	cresp, err := DeleteZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti Delete Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Deleting external jwt signer", "Could not DELETE external jwt signer, unexpected error: "+err.Error(),
-		)
-		return
+	state.ClientID = nonEmptyStringValue(detail.ClientID)
+	state.ExternalAuthURL = nonEmptyStringValue(detail.ExternalAuthURL)
+
+	if detail.TargetToken != nil {
+		state.TargetToken = types.StringValue(string(*detail.TargetToken))
+	}
+
+	if detail.JwksEndpoint != nil {
+		state.JwksEndpoint = types.StringValue(detail.JwksEndpoint.String())
+	}
+
+	if detail.CertPem != nil {
+		state.CertPem = types.StringValue(*detail.CertPem)
+	}
+
+	if detail.Kid != nil && *detail.Kid != "" {
+		state.Kid = types.StringValue(*detail.Kid)
+	}
+
+	if detail.UseExternalID != nil {
+		state.UseExternalId = types.BoolValue(*detail.UseExternalID)
+	}
+
+	if detail.Enabled != nil {
+		state.Enabled = types.BoolValue(*detail.Enabled)
 	}
-}
 
-func (r *jwtSignerResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	state.Scopes = stringListFromAPI(ctx, detail.Scopes, diags)
+	state.Tags = tagsFromAPI(ctx, detail.Tags, diags)
 }
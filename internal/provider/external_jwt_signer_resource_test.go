@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"testing"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccExternalJwtSignerResource(t *testing.T) {
+	controller := testAccController(t)
+	const resourceName = "ziti_external_jwt_signer.test"
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_external_jwt_signer" "test" {
+  name          = "test-jwt-signer"
+  issuer        = "https://idp.example.com"
+  audience      = "ziti"
+  jwks_endpoint = "https://idp.example.com/.well-known/jwks.json"
+  enabled       = true
+}
+`
+	update := testAccProviderConfig(controller, "") + `
+resource "ziti_external_jwt_signer" "test" {
+  name              = "test-jwt-signer"
+  issuer            = "https://idp.example.com"
+  audience          = "ziti"
+  jwks_endpoint     = "https://idp.example.com/.well-known/jwks.json"
+  enabled           = true
+  claims_property   = "email"
+  use_external_id   = true
+  client_id         = "ziti-client"
+  external_auth_url = "https://idp.example.com/authorize"
+  scopes            = ["openid", "email"]
+}
+`
+
+	steps := []resource.TestStep{{
+		Config: config,
+		Check: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckEntityExists(controller, mockcontroller.ExternalJWTSigners, resourceName),
+			resource.TestCheckResourceAttr(resourceName, "name", "test-jwt-signer"),
+			resource.TestCheckResourceAttr(resourceName, "claims_property", "sub"),
+			resource.TestCheckResourceAttr(resourceName, "enabled", "true"),
+		),
+	}}
+	steps = append(steps, testAccImportSteps(resourceName)...)
+	steps = append(steps, testAccUpdateStep(resourceName, update,
+		resource.TestCheckResourceAttr(resourceName, "claims_property", "email"),
+		resource.TestCheckResourceAttr(resourceName, "use_external_id", "true"),
+		resource.TestCheckResourceAttr(resourceName, "scopes.1", "email"),
+	))
+	steps = append(steps, testAccOutOfBandSteps(t, controller, mockcontroller.ExternalJWTSigners, "test-jwt-signer", resourceName, update,
+		map[string]interface{}{"enabled": false})...)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    steps,
+	})
+}
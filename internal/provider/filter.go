@@ -0,0 +1,190 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// filterFieldPattern matches a field a filter can compare, e.g. "name" or
+// "tags.team". Any other field would have to be spliced into the filter
+// unquoted, so it is rejected.
+var filterFieldPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+// tagKeyPattern matches a tag key a filter can compare.
+var tagKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// filterBuilder builds a controller filter expression from predicates that
+// are all required to match, e.g.
+//
+//	newFilter().Equals("name", name).Tag("team", "payments").SortBy("name")
+//
+// Values are quoted and escaped per the controller's filter grammar, so a
+// name holding quotes or operators can not change the meaning of the filter.
+// The filter is sent as a query parameter, which the edge API client
+// URL-encodes.
+type filterBuilder struct {
+	predicates []string
+	sortBy     string
+	limit      int64
+	skip       int64
+	err        error
+}
+
+// newFilter returns an empty filter, which matches every entity.
+func newFilter() *filterBuilder {
+	return &filterBuilder{}
+}
+
+// Equals requires field to be value.
+func (b *filterBuilder) Equals(field, value string) *filterBuilder {
+	return b.compare(field, "=", value)
+}
+
+// Contains requires field to contain value.
+func (b *filterBuilder) Contains(field, value string) *filterBuilder {
+	return b.compare(field, "contains", value)
+}
+
+// StartsWith requires field to start with value.
+func (b *filterBuilder) StartsWith(field, value string) *filterBuilder {
+	return b.compare(field, "startsWith", value)
+}
+
+// In requires field to be one of values. No value matches no entity.
+func (b *filterBuilder) In(field string, values ...string) *filterBuilder {
+	if !b.field(field) {
+		return b
+	}
+	if len(values) == 0 {
+		return b.add("false")
+	}
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = quoteFilterString(value)
+	}
+	return b.add(field + " in [" + strings.Join(quoted, ", ") + "]")
+}
+
+// AnyOf requires the list field, e.g. "roleAttributes", to hold value.
+func (b *filterBuilder) AnyOf(field, value string) *filterBuilder {
+	if !b.field(field) {
+		return b
+	}
+	return b.add("anyOf(" + field + ") = " + quoteFilterString(value))
+}
+
+// Tag requires the tag key to be value.
+func (b *filterBuilder) Tag(key, value string) *filterBuilder {
+	if !tagKeyPattern.MatchString(key) {
+		b.fail(fmt.Errorf("the tag key %q can not be matched by a filter; use letters, digits and underscores", key))
+		return b
+	}
+	return b.compare("tags."+key, "=", value)
+}
+
+// Expression requires a filter expression written by the user to match. It is
+// kept in parentheses so it can not change the other predicates.
+func (b *filterBuilder) Expression(expression string) *filterBuilder {
+	expression = strings.TrimSpace(expression)
+	if expression == "" {
+		return b
+	}
+	return b.add("(" + expression + ")")
+}
+
+// SortBy orders the entities, e.g. by "name desc".
+func (b *filterBuilder) SortBy(sortBy string) *filterBuilder {
+	b.sortBy = sortBy
+	return b
+}
+
+// Page selects limit entities after skipping skip of them.
+func (b *filterBuilder) Page(limit, skip int64) *filterBuilder {
+	b.limit = limit
+	b.skip = skip
+	return b
+}
+
+// Build returns the filter expression, which is empty when the filter has
+// neither predicates nor clauses.
+func (b *filterBuilder) Build() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+	filter := strings.Join(b.predicates, " and ")
+	if b.sortBy == "" && b.limit == 0 && b.skip == 0 {
+		return filter, nil
+	}
+	// The sort by, limit and skip clauses have to follow a predicate.
+	if filter == "" {
+		filter = "true"
+	}
+	if b.sortBy != "" {
+		filter += " sort by " + b.sortBy
+	}
+	if b.limit != 0 {
+		filter += " limit " + strconv.FormatInt(b.limit, 10)
+	}
+	if b.skip != 0 {
+		filter += " skip " + strconv.FormatInt(b.skip, 10)
+	}
+	return filter, nil
+}
+
+func (b *filterBuilder) compare(field, operator, value string) *filterBuilder {
+	if !b.field(field) {
+		return b
+	}
+	return b.add(field + " " + operator + " " + quoteFilterString(value))
+}
+
+func (b *filterBuilder) field(field string) bool {
+	if !filterFieldPattern.MatchString(field) {
+		b.fail(fmt.Errorf("%q is not a filter field", field))
+		return false
+	}
+	return true
+}
+
+func (b *filterBuilder) add(predicate string) *filterBuilder {
+	b.predicates = append(b.predicates, predicate)
+	return b
+}
+
+func (b *filterBuilder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// quoteFilterString quotes s as a filter string literal. The controller's
+// grammar takes JSON style escapes inside double quotes.
+func quoteFilterString(s string) string {
+	var q strings.Builder
+	q.Grow(len(s) + 2)
+	q.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			q.WriteByte('\\')
+			q.WriteRune(r)
+		case '\n':
+			q.WriteString(`\n`)
+		case '\r':
+			q.WriteString(`\r`)
+		case '\t':
+			q.WriteString(`\t`)
+		default:
+			if unicode.IsControl(r) {
+				fmt.Fprintf(&q, `\u%04x`, r)
+				continue
+			}
+			q.WriteRune(r)
+		}
+	}
+	q.WriteByte('"')
+	return q.String()
+}
@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestFilterBuilder(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name   string
+		filter *filterBuilder
+		want   string
+	}{
+		{"empty", newFilter(), ""},
+		{"equals", newFilter().Equals("name", "web"), `name = "web"`},
+		{"escapes", newFilter().Equals("name", `say "hi" \ bye`), `name = "say \"hi\" \\ bye"`},
+		{"control characters", newFilter().Equals("name", "a\tb\nc\x01"), `name = "a\tb\nc\u0001"`},
+		{"injection", newFilter().Equals("name", `x" or true or name = "y`), `name = "x\" or true or name = \"y"`},
+		{"url characters", newFilter().Equals("name", "a&b #1?c=d"), `name = "a&b #1?c=d"`},
+		{"contains", newFilter().Contains("name", "eb"), `name contains "eb"`},
+		{"starts with", newFilter().StartsWith("name", "web-"), `name startsWith "web-"`},
+		{"in", newFilter().In("id", "a", `b"`), `id in ["a", "b\""]`},
+		{"in nothing", newFilter().In("id"), "false"},
+		{"any of", newFilter().AnyOf("roleAttributes", "web"), `anyOf(roleAttributes) = "web"`},
+		{"tag", newFilter().Tag("team", "payments"), `tags.team = "payments"`},
+		{"expression", newFilter().Expression(`name = "a" or name = "b"`).Tag("env", "dev"), `(name = "a" or name = "b") and tags.env = "dev"`},
+		{"blank expression", newFilter().Expression("  "), ""},
+		{"sort", newFilter().SortBy("name desc"), "true sort by name desc"},
+		{"page", newFilter().Equals("name", "a").SortBy("id").Page(500, 1000), `name = "a" sort by id limit 500 skip 1000`},
+	} {
+		got, err := tc.filter.Build()
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.name, got, tc.want)
+		}
+	}
+
+	for name, filter := range map[string]*filterBuilder{
+		"tag key":  newFilter().Tag(`team = "x" or tags.a`, "b"),
+		"field":    newFilter().Equals("name or true", "b"),
+		"in field": newFilter().In("(id)", "b"),
+	} {
+		if got, err := filter.Build(); err == nil {
+			t.Errorf("%s: got %s, want an error", name, got)
+		}
+	}
+}
+
+func TestAccDataSourceLookupByAwkwardName(t *testing.T) {
+	controller := testAccController(t)
+	const name = `web "prod" & #1 \ a=b`
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_service" "awkward" {
+  name = "web \"prod\" & #1 \\ a=b"
+  tags = { owner = "a&b \"c\"" }
+}
+
+resource "ziti_service" "web" {
+  name = "web"
+}
+
+data "ziti_service" "awkward" {
+  name       = "web \"prod\" & #1 \\ a=b"
+  depends_on = [ziti_service.awkward, ziti_service.web]
+}
+
+data "ziti_services" "owned" {
+  tags       = { owner = "a&b \"c\"" }
+  depends_on = [ziti_service.awkward, ziti_service.web]
+}
+`
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("ziti_service.awkward", "name", name),
+					resource.TestCheckResourceAttrPair("data.ziti_service.awkward", "id", "ziti_service.awkward", "id"),
+					resource.TestCheckResourceAttr("data.ziti_service.awkward", "name", name),
+					resource.TestCheckResourceAttr("data.ziti_services.owned", "services.#", "1"),
+					resource.TestCheckResourceAttrPair("data.ziti_services.owned", "services.0.id", "ziti_service.awkward", "id"),
+				),
+			},
+			{
+				ResourceName:            "ziti_service.awkward",
+				ImportState:             true,
+				ImportStateId:           "name=" + name,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"last_updated"},
+			},
+		},
+	})
+}
@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"testing"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccIdentityCaResource(t *testing.T) {
+	controller := testAccController(t)
+	const resourceName = "ziti_identity_ca.test"
+	ca := testAccProviderConfig(controller, "") + `
+resource "ziti_certificate_authority" "test" {
+  name                        = "test-ca"
+  cert_pem                    = "` + testCertPem + `"
+  is_ottca_enrollment_enabled = true
+}
+`
+	config := ca + `
+resource "ziti_identity_ca" "test" {
+  name  = "test-identity-ca"
+  ottca = ziti_certificate_authority.test.id
+}
+`
+	update := ca + `
+resource "ziti_identity_ca" "test" {
+  name                  = "test-identity-ca"
+  ottca                 = ziti_certificate_authority.test.id
+  role_attributes       = ["devices"]
+  service_hosting_costs = { web = 5 }
+}
+`
+
+	steps := []resource.TestStep{{
+		Config: config,
+		Check: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckEntityExists(controller, mockcontroller.Identities, resourceName),
+			resource.TestCheckResourceAttr(resourceName, "name", "test-identity-ca"),
+			resource.TestCheckResourceAttrPair(resourceName, "ottca", "ziti_certificate_authority.test", "id"),
+			resource.TestCheckResourceAttrSet(resourceName, "enrollment_token"),
+		),
+	}}
+	steps = append(steps, testAccImportSteps(resourceName)...)
+	steps = append(steps, testAccUpdateStep(resourceName, update,
+		resource.TestCheckResourceAttr(resourceName, "role_attributes.#", "1"),
+		resource.TestCheckResourceAttr(resourceName, "service_hosting_costs.web", "5"),
+		resource.TestCheckResourceAttrSet(resourceName, "enrollment_token"),
+	))
+	steps = append(steps, testAccOutOfBandSteps(t, controller, mockcontroller.Identities, "test-identity-ca", resourceName, update,
+		map[string]interface{}{"serviceHostingCosts": map[string]interface{}{"web": 50}})...)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    steps,
+	})
+}
@@ -2,13 +2,13 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/rs/zerolog/log"
+	"github.com/openziti/edge-api/rest_management_api_client/identity"
+	"github.com/openziti/edge-api/rest_model"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -37,9 +37,6 @@ func (r *identityDataSource) Configure(_ context.Context, req datasource.Configu
 
 	datasourceConfig := req.ProviderData.(*zitiData)
 	r.datasourceConfig = datasourceConfig
-
-	fmt.Printf("Using API Token to create datasource: %s\n", r.datasourceConfig.apiToken)
-	fmt.Printf("Using domain to create datasource: %s\n", r.datasourceConfig.host)
 }
 
 // Metadata returns the datasource type name.
@@ -142,134 +139,101 @@ func (r *identityDataSource) Read(ctx context.Context, req datasource.ReadReques
 		return
 	}
 
-	filter := ""
-	if state.Name.ValueString() != "" {
-		filter = "filter=name=\"" + state.Name.ValueString() + "\""
-	}
-	if state.ID.ValueString() != "" {
-		filter = "filter=id=\"" + state.ID.ValueString() + "\""
-	}
-
-	authUrl := fmt.Sprintf("%s/identities?%s", r.datasourceConfig.host, filter)
-	cresp, err := ReadZitiResource(authUrl, r.datasourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Reading Identity", "Could not READ Identity, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
-	if err != nil {
-		log.Error().Msgf("Error unmarshalling JSON response from Ziti DataSource Response: %v", err)
-		fmt.Println("Error unmarshalling JSON:", err)
+	filter := dataSourceFilter(state.ID, state.Name)
+	detail, ok := lookupDataSourceEntity(ctx, r.datasourceConfig, &resp.Diagnostics, "Identity", filter, identityList)
+	if !ok {
 		return
 	}
 
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	identity := jsonBody["data"].([]interface{})
+	identityDataSourceState(ctx, detail, &state, &resp.Diagnostics)
 
-	if len(identity) > 1 {
-		resp.Diagnostics.AddError(
-			"Multiple items returned from API upon filter execution!", "Try to narrow down the filter expression"+filter,
-		)
-	}
-	if len(identity) == 0 {
-		resp.Diagnostics.AddError(
-			"No items returned from API upon filter execution!", "Try to relax the filter expression: "+filter,
-		)
-	}
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+}
 
-	data := identity[0].(map[string]interface{})
-
-	state.Name = types.StringValue(data["name"].(string))
-	state.ID = types.StringValue(data["id"].(string))
+// identityDataSourceState copies an identity fetched by the data source into state.
+func identityDataSourceState(ctx context.Context, detail *rest_model.IdentityDetail, state *identityDataSourceModel, diags *diag.Diagnostics) {
+	state.ID = types.StringPointerValue(detail.ID)
+	state.Name = types.StringPointerValue(detail.Name)
 
-	if appData, ok := data["appData"].(map[string]interface{}); ok {
-		if len(appData) != 0 {
-			appData, diag := types.MapValueFrom(ctx, types.StringType, appData)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.AppData = appData
-		} else {
-			state.AppData = types.MapNull(types.StringType)
-		}
+	if detail.AppData != nil {
+		state.AppData = tagsFromAPI(ctx, detail.AppData, diags)
 	}
 
-	if authPolicyID, ok := data["authPolicyId"].(string); ok {
-		state.AuthPolicyID = types.StringValue(authPolicyID)
+	if detail.AuthPolicyID != nil {
+		state.AuthPolicyID = types.StringValue(*detail.AuthPolicyID)
 	}
 
-	if defaultHostingCost, ok := data["defaultHostingCost"].(float64); ok {
-		state.DefaultHostingCost = types.Int64Value(int64(defaultHostingCost))
+	if detail.DefaultHostingCost != nil {
+		state.DefaultHostingCost = types.Int64Value(int64(*detail.DefaultHostingCost))
 	}
 
-	if defaultHostingPrecedence, ok := data["defaultHostingPrecedence"].(string); ok {
-		state.DefaultHostingPrecedence = types.StringValue(defaultHostingPrecedence)
+	if detail.DefaultHostingPrecedence != "" {
+		state.DefaultHostingPrecedence = types.StringValue(string(detail.DefaultHostingPrecedence))
 	}
 
-	if externalID, ok := data["externalId"].(string); ok {
-		state.ExternalID = types.StringValue(externalID)
+	if detail.ExternalID != nil {
+		state.ExternalID = types.StringValue(*detail.ExternalID)
 	}
 
-	if isAdmin, ok := data["isAdmin"].(bool); ok {
-		state.IsAdmin = types.BoolValue(isAdmin)
+	if detail.IsAdmin != nil {
+		state.IsAdmin = types.BoolValue(*detail.IsAdmin)
 	}
 
-	if roleAttributes, ok := data["roleAttributes"].([]interface{}); ok {
-		roleAttributes, diag := types.SetValueFrom(ctx, types.StringType, roleAttributes)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.RoleAttributes = roleAttributes
-	} else {
-		state.RoleAttributes = types.SetNull(types.StringType)
-	}
+	state.RoleAttributes = roleAttributesFromAPI(ctx, detail.RoleAttributes, diags)
 
-	if serviceHostingCosts, ok := data["serviceHostingCosts"].(map[string]interface{}); ok {
-		if len(serviceHostingCosts) > 0 {
-			serviceHostingCosts, diag := types.MapValueFrom(ctx, types.Int64Type, serviceHostingCosts)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.ServiceHostingCosts = serviceHostingCosts
-		} else {
-			state.ServiceHostingCosts = types.MapNull(types.Int64Type)
-		}
+	if detail.ServiceHostingCosts != nil {
+		state.ServiceHostingCosts = serviceHostingCostsFromAPI(ctx, detail.ServiceHostingCosts, diags)
 	}
 
-	if serviceHostingPrecedence, ok := data["serviceHostingPrecedences"].(map[string]interface{}); ok {
-		if len(serviceHostingPrecedence) > 0 {
-			serviceHostingPrecedence, diag := types.MapValueFrom(ctx, types.StringType, serviceHostingPrecedence)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.ServiceHostingPrecedence = serviceHostingPrecedence
-		} else {
-			state.ServiceHostingPrecedence = types.MapNull(types.StringType)
-		}
+	if detail.ServiceHostingPrecedences != nil {
+		state.ServiceHostingPrecedence = serviceHostingPrecedencesFromAPI(ctx, detail.ServiceHostingPrecedences, diags)
 	}
 
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
+	state.Tags = tagsFromAPI(ctx, detail.Tags, diags)
+
+	if detail.Type != nil {
+		state.Type = types.StringValue(detail.Type.Name)
 	}
+}
 
-	_type := data["type"].(map[string]interface{})
-	if typeValue, ok := _type["name"].(string); ok {
-		state.Type = types.StringValue(typeValue)
+// identityList lists the identities matching filter.
+func identityList(ctx context.Context, client *zitiData, filter string) ([]*rest_model.IdentityDetail, error) {
+	list, err := client.api.Identity.ListIdentities(&identity.ListIdentitiesParams{Filter: optionalFilter(filter), Context: ctx}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if list.Payload == nil {
+		return nil, errMissingData
 	}
+	return withoutNil(list.Payload.Data), nil
+}
 
-	// Set refreshed state
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+// identityPage lists a page of the identities matching filter.
+func identityPage(ctx context.Context, client *zitiData, filter string, offset, limit int64) ([]*rest_model.IdentityDetail, *rest_model.Pagination, error) {
+	list, err := client.api.Identity.ListIdentities(&identity.ListIdentitiesParams{Filter: optionalFilter(filter), Offset: &offset, Limit: &limit, Context: ctx}, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if list.Payload == nil {
+		return nil, nil, errMissingData
 	}
+	return withoutNil(list.Payload.Data), paginationOf(list.Payload.Meta), nil
+}
+
+// NewIdentitiesDataSource is a helper function to simplify the provider implementation.
+func NewIdentitiesDataSource() datasource.DataSource {
+	return &zitiListDataSource[*rest_model.IdentityDetail, identityDataSourceModel]{spec: zitiListDataSourceSpec[*rest_model.IdentityDetail, identityDataSourceModel]{
+		typeName:       "_identities",
+		label:          "identities",
+		items:          "identities",
+		itemAttributes: listItemAttributes(dataSourceAttributes(NewIdentityDataSource())),
+		roleAttributes: true,
+		page:           identityPage,
+		item:           identityDataSourceState,
+	}}
 }
@@ -0,0 +1,569 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/openziti/edge-api/rest_management_api_client/authenticator"
+	"github.com/openziti/edge-api/rest_management_api_client/enrollment"
+	"github.com/openziti/edge-api/rest_management_api_client/identity"
+	"github.com/openziti/edge-api/rest_model"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                   = &identityEnrollmentResource{}
+	_ resource.ResourceWithConfigure      = &identityEnrollmentResource{}
+	_ resource.ResourceWithModifyPlan     = &identityEnrollmentResource{}
+	_ resource.ResourceWithValidateConfig = &identityEnrollmentResource{}
+)
+
+const (
+	// clientAPIPath is the base path of the edge client API, which identities
+	// enroll at and connect to.
+	clientAPIPath = "/edge/client/v1"
+	// enrollmentTokenLifetime is how long a one-time token enrollment issued
+	// to re-enroll an identity stays valid; it is used right away.
+	enrollmentTokenLifetime = 10 * time.Minute
+	// invalidEnrollmentTokenCode is the error code the controller answers an
+	// enrollment with a used or expired token with.
+	invalidEnrollmentTokenCode = "INVALID_ENROLLMENT_TOKEN"
+)
+
+var (
+	// enrollmentKeySizes are the key sizes allowed for each key_type.
+	enrollmentKeySizes = map[string][]int64{
+		"EC":  {256, 384, 521},
+		"RSA": {2048, 3072, 4096},
+	}
+	// defaultEnrollmentKeySizes are the key sizes used when key_size is not set.
+	defaultEnrollmentKeySizes = map[string]int64{"EC": 256, "RSA": 4096}
+)
+
+// NewIdentityEnrollmentResource is a helper function to simplify the provider implementation.
+func NewIdentityEnrollmentResource() resource.Resource {
+	return &identityEnrollmentResource{}
+}
+
+// identityEnrollmentResource enrolls an identity with a key pair generated by
+// the provider, so its identity JSON can be used without running ziti edge
+// enroll.
+type identityEnrollmentResource struct {
+	resourceConfig *zitiData
+}
+
+// identityEnrollmentResourceModel maps the resource schema data.
+type identityEnrollmentResourceModel struct {
+	ID           types.String `tfsdk:"id"`
+	IdentityID   types.String `tfsdk:"identity_id"`
+	KeyType      types.String `tfsdk:"key_type"`
+	KeySize      types.Int64  `tfsdk:"key_size"`
+	Certificate  types.String `tfsdk:"certificate"`
+	CA           types.String `tfsdk:"ca"`
+	IdentityJSON types.String `tfsdk:"identity_json"`
+}
+
+// identityFile is the identity JSON written by ziti edge enroll, as read by
+// parsePemFromZitiIdentity.
+type identityFile struct {
+	ZtAPI string         `json:"ztAPI"`
+	ID    identityFileID `json:"id"`
+}
+
+type identityFileID struct {
+	Key  string `json:"key"`
+	Cert string `json:"cert"`
+	CA   string `json:"ca"`
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *identityEnrollmentResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	r.resourceConfig = req.ProviderData.(*zitiData)
+}
+
+// Metadata returns the resource type name.
+func (r *identityEnrollmentResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_identity_enrollment"
+}
+
+// Schema defines the schema for the resource.
+func (r *identityEnrollmentResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Enrolls a Ziti identity by one-time token (OTT) with a key pair generated by the provider, and returns its identity JSON. " +
+			"The pending OTT enrollment of the identity is used; an expired one is refreshed, and a new one is issued when there is none, e.g. when the resource is replaced. " +
+			"Destroying the resource deletes the certificate authenticator of the identity, which revokes the identity JSON. " +
+			"The private key is stored in state, and cannot be recovered from the controller: the enrollment cannot be imported, and replacing the resource, " +
+			"e.g. after the state was lost, re-enrolls the identity with a new key.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "ID of the certificate authenticator the enrollment created",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"identity_id": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "ID of the identity to enroll.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"key_type": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("EC"),
+				MarkdownDescription: "Type of the generated key: `EC` (default) or `RSA`.",
+				Validators: []validator.String{
+					stringvalidator.OneOf("EC", "RSA"),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"key_size": schema.Int64Attribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Size of the generated key in bits: the curve size `256` (default), `384` or `521` for `EC`, and `4096` (default), `2048` or `3072` for `RSA`.",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"certificate": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "PEM encoded client certificate the controller issued.",
+			},
+			"ca": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "PEM encoded CA bundle of the controller.",
+			},
+			"identity_json": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "Identity JSON with the `id.cert`, `id.key` and `id.ca` of the enrolled identity, as written by `ziti edge enroll`. It can be passed to the `identity_json` argument of the provider or to a Ziti SDK.",
+			},
+		},
+	}
+}
+
+// ValidateConfig checks that key_size is allowed for key_type.
+func (r *identityEnrollmentResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config identityEnrollmentResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() || config.KeySize.IsNull() || config.KeySize.IsUnknown() || config.KeyType.IsUnknown() {
+		return
+	}
+	keyType := config.KeyType.ValueString()
+	if keyType == "" {
+		keyType = "EC"
+	}
+	sizes, ok := enrollmentKeySizes[keyType]
+	if !ok {
+		return
+	}
+	for _, size := range sizes {
+		if config.KeySize.ValueInt64() == size {
+			return
+		}
+	}
+	resp.Diagnostics.AddAttributeError(path.Root("key_size"), "Invalid Key Size",
+		fmt.Sprintf("%s keys can not be %d bits; use one of %v.", keyType, config.KeySize.ValueInt64(), sizes))
+}
+
+// ModifyPlan plans the default key_size of the planned key_type.
+func (r *identityEnrollmentResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	var keySize types.Int64
+	var keyType types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("key_size"), &keySize)...)
+	resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("key_type"), &keyType)...)
+	if resp.Diagnostics.HasError() || !keySize.IsNull() || keyType.IsUnknown() {
+		return
+	}
+	if size, ok := defaultEnrollmentKeySizes[keyType.ValueString()]; ok {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("key_size"), size)...)
+	}
+}
+
+// Create enrolls the identity.
+func (r *identityEnrollmentResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan identityEnrollmentResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	identityID := plan.IdentityID.ValueString()
+
+	token, err := r.enrollmentToken(ctx, identityID)
+	if err != nil {
+		addZitiErrorDiagnostic(ctx, &resp.Diagnostics, nil, "Error Enrolling Identity", "Could not get an enrollment token for identity "+identityID+": ", zitiAPIErrorFrom(err))
+		return
+	}
+
+	key, err := generateEnrollmentKey(plan.KeyType.ValueString(), int(plan.KeySize.ValueInt64()))
+	if err != nil {
+		resp.Diagnostics.AddError("Error Enrolling Identity", "Could not generate the key: "+err.Error())
+		return
+	}
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: pkix.Name{CommonName: identityID}}, key)
+	if err != nil {
+		resp.Diagnostics.AddError("Error Enrolling Identity", "Could not create the certificate request: "+err.Error())
+		return
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		resp.Diagnostics.AddError("Error Enrolling Identity", "Could not encode the key: "+err.Error())
+		return
+	}
+
+	cert, ca, err := r.enroll(ctx, token, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER}))
+	if err != nil {
+		resp.Diagnostics.AddError("Error Enrolling Identity", "Could not enroll identity "+identityID+": "+err.Error())
+		return
+	}
+
+	identityJSON, err := json.Marshal(identityFile{
+		ZtAPI: controllerURL(r.resourceConfig.host, clientAPIPath),
+		ID: identityFileID{
+			Key:  "pem:" + string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})),
+			Cert: "pem:" + cert,
+			CA:   "pem:" + ca,
+		},
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Error Enrolling Identity", "Could not encode the identity JSON: "+err.Error())
+		return
+	}
+
+	// The one-time token is spent and the key exists nowhere else, so the
+	// enrollment is saved before anything else can fail. Its id is read on the
+	// next refresh if the authenticator cannot be read now.
+	plan.ID = types.StringValue("")
+	plan.Certificate = types.StringValue(cert)
+	plan.CA = types.StringValue(ca)
+	plan.IdentityJSON = types.StringValue(string(identityJSON))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	authenticatorID, err := enrolledAuthenticatorID(ctx, r.resourceConfig, identityID, cert)
+	if err == nil && authenticatorID == "" {
+		err = errors.New("the identity has no certificate authenticator for the issued certificate")
+	}
+	if err != nil {
+		resp.Diagnostics.AddWarning("Enrolled Identity Not Read",
+			"Identity "+identityID+" was enrolled and the enrollment is kept in state, but its certificate authenticator could not be read: "+
+				zitiAPIErrorFrom(err).Error()+". It is read again on the next refresh.")
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), authenticatorID)...)
+}
+
+// Read removes the enrollment from state once the identity or its
+// certificate authenticator is gone, e.g. after a re-enrollment. An enrollment
+// saved without an id gets the id of the authenticator of its certificate.
+func (r *identityEnrollmentResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state identityEnrollmentResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	identityID := state.IdentityID.ValueString()
+	var authenticatorID string
+	var err error
+	if state.ID.ValueString() == "" {
+		authenticatorID, err = enrolledAuthenticatorID(ctx, r.resourceConfig, identityID, state.Certificate.ValueString())
+	} else {
+		authenticatorID, err = certAuthenticatorID(ctx, r.resourceConfig, identityID)
+	}
+	if err != nil && !errors.Is(zitiAPIErrorFrom(err), errNotFound) {
+		resp.Diagnostics.AddError(
+			"Error Reading Identity Enrollment", "Could not READ identity "+identityID+", unexpected error: "+zitiAPIErrorFrom(err).Error(),
+		)
+		return
+	}
+	if authenticatorID == "" || (state.ID.ValueString() != "" && authenticatorID != state.ID.ValueString()) {
+		tflog.Info(ctx, "Enrollment no longer in backend; removing from state", map[string]any{"id": state.ID.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), authenticatorID)...)
+}
+
+// Update is never called with a change, as every argument requires the
+// enrollment to be replaced.
+func (r *identityEnrollmentResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan identityEnrollmentResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// Delete deletes the certificate authenticator of the enrollment.
+func (r *identityEnrollmentResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state identityEnrollmentResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	id := state.ID.ValueString()
+	if id == "" {
+		var err error
+		id, err = enrolledAuthenticatorID(ctx, r.resourceConfig, state.IdentityID.ValueString(), state.Certificate.ValueString())
+		if err != nil && !errors.Is(zitiAPIErrorFrom(err), errNotFound) {
+			resp.Diagnostics.AddError(
+				"Error Deleting Identity Enrollment", "Could not READ identity "+state.IdentityID.ValueString()+", unexpected error: "+zitiAPIErrorFrom(err).Error(),
+			)
+			return
+		}
+		if id == "" {
+			tflog.Info(ctx, "Authenticator already deleted in backend", map[string]any{"identity_id": state.IdentityID.ValueString()})
+			return
+		}
+	}
+
+	_, err := r.resourceConfig.api.Authenticator.DeleteAuthenticator(&authenticator.DeleteAuthenticatorParams{ID: id, Context: ctx}, nil)
+	if err != nil {
+		err = zitiAPIErrorFrom(err)
+		if errors.Is(err, errNotFound) {
+			tflog.Info(ctx, "Authenticator already deleted in backend", map[string]any{"id": id})
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Deleting Identity Enrollment", "Could not DELETE authenticator "+id+", unexpected error: "+err.Error(),
+		)
+	}
+}
+
+// enrollmentToken returns the token of the pending one-time token enrollment
+// of an identity. An expired enrollment is refreshed, and a new one is issued
+// if there is none, as the controller rejects an expired token.
+func (r *identityEnrollmentResource) enrollmentToken(ctx context.Context, identityID string) (string, error) {
+	pending, err := pendingIdentityEnrollment(ctx, r.resourceConfig, identityID, "ott")
+	if err != nil {
+		return "", err
+	}
+	if pending != nil && pending.token != "" && !pending.expired() {
+		return pending.token, nil
+	}
+
+	expiresAt := strfmt.DateTime(time.Now().Add(enrollmentTokenLifetime))
+	if pending != nil {
+		tflog.Info(ctx, "Refreshing the expired OTT enrollment of identity", map[string]any{"identity_id": identityID})
+		_, err = r.resourceConfig.api.Enrollment.RefreshEnrollment(&enrollment.RefreshEnrollmentParams{
+			ID:      pending.id,
+			Refresh: &rest_model.EnrollmentRefresh{ExpiresAt: &expiresAt},
+			Context: ctx,
+		}, nil)
+	} else {
+		tflog.Info(ctx, "Identity has no pending OTT enrollment; issuing one", map[string]any{"identity_id": identityID})
+		method := rest_model.EnrollmentCreateMethodOtt
+		_, err = r.resourceConfig.api.Enrollment.CreateEnrollment(&enrollment.CreateEnrollmentParams{
+			Enrollment: &rest_model.EnrollmentCreate{
+				IdentityID: &identityID,
+				Method:     &method,
+				ExpiresAt:  &expiresAt,
+			},
+			Context: ctx,
+		}, nil)
+	}
+	if err != nil {
+		return "", err
+	}
+	pending, err = pendingIdentityEnrollment(ctx, r.resourceConfig, identityID, "ott")
+	if err == nil && (pending == nil || pending.token == "") {
+		err = errors.New("the issued enrollment has no token")
+	}
+	if err != nil {
+		return "", err
+	}
+	return pending.token, nil
+}
+
+// certAuthenticatorID returns the ID of the certificate authenticator of an
+// identity, or "" if it has none.
+func certAuthenticatorID(ctx context.Context, client *zitiData, identityID string) (string, error) {
+	cert, err := certAuthenticator(ctx, client, identityID)
+	if err != nil || cert == nil {
+		return "", err
+	}
+	return cert.ID, nil
+}
+
+// enrolledAuthenticatorID returns the ID of the certificate authenticator of
+// an identity if it is the one of the PEM encoded certificate, or "".
+func enrolledAuthenticatorID(ctx context.Context, client *zitiData, identityID, certificate string) (string, error) {
+	cert, err := certAuthenticator(ctx, client, identityID)
+	if err != nil || cert == nil {
+		return "", err
+	}
+	fingerprint := certificateFingerprint(certificate)
+	if fingerprint == "" || !strings.EqualFold(strings.ReplaceAll(cert.Fingerprint, ":", ""), fingerprint) {
+		return "", nil
+	}
+	return cert.ID, nil
+}
+
+// certAuthenticator returns the certificate authenticator of an identity, or
+// nil if it has none.
+func certAuthenticator(ctx context.Context, client *zitiData, identityID string) (*rest_model.IdentityAuthenticatorsCert, error) {
+	detail, err := client.api.Identity.DetailIdentity(&identity.DetailIdentityParams{ID: identityID, Context: ctx}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if detail.Payload == nil || detail.Payload.Data == nil {
+		return nil, errMissingData
+	}
+	if a := detail.Payload.Data.Authenticators; a != nil && a.Cert != nil && a.Cert.ID != "" {
+		return a.Cert, nil
+	}
+	return nil, nil
+}
+
+// certificateFingerprint returns the SHA-1 fingerprint the controller keeps
+// for a PEM encoded certificate, or "" if it is not one.
+func certificateFingerprint(certificate string) string {
+	block, _ := pem.Decode([]byte(certificate))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return ""
+	}
+	sum := sha1.Sum(block.Bytes)
+	return hex.EncodeToString(sum[:])
+}
+
+// enroll sends the certificate request csr to the enroll endpoint of the
+// controller requests are currently sent to and returns the issued certificate and the CA bundle, both PEM
+// encoded. The token authenticates the request, so it is sent without the
+// session of the provider, and never replayed: a one-time token the
+// controller may have accepted cannot be used again.
+func (r *identityEnrollmentResource) enroll(ctx context.Context, token string, csr []byte) (string, string, error) {
+	activeHost, _ := r.resourceConfig.session()
+	enrollURL := controllerURL(activeHost, clientAPIPath+"/enroll") + "?" + url.Values{"method": {"ott"}, "token": {token}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, enrollURL, bytes.NewReader(csr))
+	if err != nil {
+		return "", "", err
+	}
+	req.Header = http.Header{"Content-Type": {"application/x-pem-file"}, "Accept": {"application/json"}}
+
+	resp, err := r.resourceConfig.httpClient.HTTPClient.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("%w; the request was not retried as the controller may have used the one-time token", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", fmt.Errorf("could not read the enrollment response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		apiErr := zitiAPIErrorFromBody(resp.StatusCode, body)
+		if apiErr.Code == invalidEnrollmentTokenCode {
+			return "", "", fmt.Errorf("the one-time token of the identity was already used or has expired: %w", apiErr)
+		}
+		return "", "", apiErr
+	}
+
+	// Controllers that do not answer in JSON return the certificate as PEM.
+	var cert, ca string
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		var envelope rest_model.EnrollmentCertsEnvelope
+		if err := json.Unmarshal(body, &envelope); err != nil {
+			return "", "", fmt.Errorf("could not decode the enrollment response: %w", err)
+		}
+		if envelope.Data == nil {
+			return "", "", errMissingData
+		}
+		cert, ca = envelope.Data.Cert, envelope.Data.Ca
+	} else {
+		cert = string(body)
+	}
+	if block, _ := pem.Decode([]byte(cert)); block == nil || block.Type != "CERTIFICATE" {
+		return "", "", errors.New("the controller returned no PEM encoded certificate")
+	}
+
+	if ca == "" {
+		ca, err = r.controllerCA(ctx)
+		if err != nil {
+			return "", "", fmt.Errorf("could not fetch the CA bundle: %w", err)
+		}
+	}
+	return cert, ca, nil
+}
+
+// controllerCA fetches the CA bundle of the controller from
+// /.well-known/est/cacerts, over the verified connection of the provider. It
+// is sent with send, so it follows a failover like any other request.
+func (r *identityEnrollmentResource) controllerCA(ctx context.Context) (string, error) {
+	resp, body, err := r.resourceConfig.send(ctx, http.MethodGet, controllerURL(r.resourceConfig.host, "/.well-known/est/cacerts"), http.Header{"Accept": {"application/pkcs7-mime"}}, nil)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", zitiAPIErrorFromBody(resp.StatusCode, body)
+	}
+	certificates, err := parseCABundle(body)
+	if err != nil {
+		return "", err
+	}
+	var bundle strings.Builder
+	for _, cert := range certificates {
+		_ = pem.Encode(&bundle, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	}
+	return bundle.String(), nil
+}
+
+// generateEnrollmentKey generates an EC key on the curve of size bits, or an
+// RSA key of size bits.
+func generateEnrollmentKey(keyType string, size int) (crypto.Signer, error) {
+	switch keyType {
+	case "EC":
+		curves := map[int]elliptic.Curve{256: elliptic.P256(), 384: elliptic.P384(), 521: elliptic.P521()}
+		curve, ok := curves[size]
+		if !ok {
+			return nil, fmt.Errorf("unsupported EC key size %d", size)
+		}
+		return ecdsa.GenerateKey(curve, rand.Reader)
+	case "RSA":
+		return rsa.GenerateKey(rand.Reader, size)
+	}
+	return nil, fmt.Errorf("unsupported key type %q", keyType)
+}
@@ -0,0 +1,359 @@
+package provider
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/openziti/edge-api/rest_model"
+	"github.com/tidwall/gjson"
+)
+
+func TestAccIdentityEnrollmentResource(t *testing.T) {
+	controller := testAccController(t)
+	const resourceName = "ziti_identity_enrollment.test"
+	identity := testAccProviderConfig(controller, "") + `
+resource "ziti_identity" "test" {
+  name = "device"
+  type = "Device"
+}
+`
+	config := identity + `
+resource "ziti_identity_enrollment" "test" {
+  identity_id = ziti_identity.test.id
+}
+`
+	rsa2048 := identity + `
+resource "ziti_identity_enrollment" "test" {
+  identity_id = ziti_identity.test.id
+  key_type    = "RSA"
+  key_size    = 2048
+}
+`
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet(resourceName, "id"),
+					resource.TestCheckResourceAttr(resourceName, "key_type", "EC"),
+					resource.TestCheckResourceAttr(resourceName, "key_size", "256"),
+					resource.TestCheckResourceAttrSet(resourceName, "certificate"),
+					resource.TestCheckResourceAttrSet(resourceName, "ca"),
+					resource.TestCheckResourceAttrWith(resourceName, "identity_json", func(identityJSON string) error {
+						if got, want := gjson.Get(identityJSON, "ztAPI").String(), controller.URL+clientAPIPath; got != want {
+							return fmt.Errorf("ztAPI = %q, want %q", got, want)
+						}
+						key, err := checkIdentityJSON(controller, identityJSON)
+						if err != nil {
+							return err
+						}
+						if curve, ok := key.(*ecdsa.PublicKey); !ok || curve.Curve.Params().BitSize != 256 {
+							return fmt.Errorf("the enrolled key is %T, want a P-256 EC key", key)
+						}
+						return nil
+					}),
+					func(*terraform.State) error {
+						entity, _ := controller.Entity(mockcontroller.Identities, testAccEntityID(t, controller, mockcontroller.Identities, "device"))
+						if _, pending := entity["enrollment"].(map[string]interface{})["ott"]; pending {
+							return errors.New("the OTT enrollment is still pending after enrolling")
+						}
+						return nil
+					},
+				),
+			},
+			// A change of the key replaces the enrollment; a new OTT enrollment
+			// is issued for it.
+			{
+				Config: rsa2048,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{plancheck.ExpectResourceAction(resourceName, plancheck.ResourceActionReplace)},
+				},
+				Check: resource.TestCheckResourceAttrWith(resourceName, "identity_json", func(identityJSON string) error {
+					key, err := checkIdentityJSON(controller, identityJSON)
+					if err != nil {
+						return err
+					}
+					if rsaKey, ok := key.(*rsa.PublicKey); !ok || rsaKey.N.BitLen() != 2048 {
+						return fmt.Errorf("the enrolled key is %T, want a 2048 bit RSA key", key)
+					}
+					return nil
+				}),
+			},
+			// Removing the authenticator out of band revokes the enrollment.
+			{
+				PreConfig: func() {
+					id := testAccEntityID(t, controller, mockcontroller.Identities, "device")
+					controller.PatchEntity(mockcontroller.Identities, id, map[string]interface{}{"authenticators": map[string]interface{}{}})
+				},
+				Config: rsa2048,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{plancheck.ExpectResourceAction(resourceName, plancheck.ResourceActionCreate)},
+				},
+			},
+		},
+	})
+}
+
+func TestIdentityEnrollmentKeySize(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	r := &identityEnrollmentResource{}
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+	for config, valid := range map[string]bool{
+		`{"identity_id": "x", "key_size": 384}`:                     true,
+		`{"identity_id": "x", "key_type": "RSA", "key_size": 3072}`: true,
+		`{"identity_id": "x", "key_size": 2048}`:                    false,
+		`{"identity_id": "x", "key_type": "RSA", "key_size": 256}`:  false,
+	} {
+		var resp fwresource.ValidateConfigResponse
+		r.ValidateConfig(ctx, fwresource.ValidateConfigRequest{
+			Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: testValue(t, schemaResp.Schema.Type(), config)},
+		}, &resp)
+		if got := !resp.Diagnostics.HasError(); got != valid {
+			t.Errorf("%s: valid = %t, want %t: %v", config, got, valid, resp.Diagnostics)
+		}
+	}
+}
+
+// An expired OTT enrollment is refreshed rather than sent to the controller,
+// which rejects its token.
+func TestIdentityEnrollmentRefreshesExpiredToken(t *testing.T) {
+	t.Parallel()
+	controller := mockcontroller.New(t)
+	client := testProviderData(t, controller)
+	identityID := createTestIdentity(t, client, "device", &rest_model.IdentityCreateEnrollment{Ott: true})
+	setEnrollmentExpiry(t, controller, identityID, "ott", time.Now().Add(-time.Minute))
+
+	resp := createTestEnrollment(t, client, identityID)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("enrolling with an expired OTT enrollment: %v", resp.Diagnostics)
+	}
+	var identityJSON string
+	resp.Diagnostics.Append(resp.State.GetAttribute(context.Background(), path.Root("identity_json"), &identityJSON)...)
+	if _, err := checkIdentityJSON(controller, identityJSON); err != nil {
+		t.Error(err)
+	}
+	if jwt := pendingJWT(controller, identityID, "ott"); jwt != "" {
+		t.Error("the refreshed OTT enrollment is still pending after enrolling")
+	}
+}
+
+// An enrollment whose authenticator cannot be read right after enrolling is
+// kept in state with its key, and gets its id on the next refresh.
+func TestIdentityEnrollmentKeptWhenAuthenticatorNotRead(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	controller := mockcontroller.New(t)
+	client := testProviderData(t, controller)
+	identityID := createTestIdentity(t, client, "device", &rest_model.IdentityCreateEnrollment{Ott: true})
+
+	var enrolled atomic.Bool
+	next := client.httpClient.HTTPClient.Transport
+	client.httpClient.HTTPClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if enrolled.Load() && req.Method == http.MethodGet && strings.HasSuffix(req.URL.Path, "/identities/"+identityID) {
+			return &http.Response{
+				StatusCode: http.StatusInternalServerError,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"error": {"code": "UNHANDLED", "message": "unavailable"}}`)),
+				Request:    req,
+			}, nil
+		}
+		resp, err := next.RoundTrip(req)
+		if strings.HasSuffix(req.URL.Path, mockcontroller.EnrollPath) {
+			enrolled.Store(true)
+		}
+		return resp, err
+	})
+	resp := createTestEnrollment(t, client, identityID)
+	client.httpClient.HTTPClient.Transport = next
+	if resp.Diagnostics.HasError() || resp.Diagnostics.WarningsCount() != 1 {
+		t.Fatalf("got %v, want a warning that the authenticator could not be read", resp.Diagnostics)
+	}
+	var state identityEnrollmentResourceModel
+	resp.Diagnostics.Append(resp.State.Get(ctx, &state)...)
+	if state.ID.ValueString() != "" {
+		t.Errorf("id = %q, want it left empty", state.ID.ValueString())
+	}
+	if _, err := checkIdentityJSON(controller, state.IdentityJSON.ValueString()); err != nil {
+		t.Error(err)
+	}
+
+	r := &identityEnrollmentResource{resourceConfig: client}
+	readResp := &fwresource.ReadResponse{State: resp.State}
+	r.Read(ctx, fwresource.ReadRequest{State: resp.State}, readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("reading the enrollment: %v", readResp.Diagnostics)
+	}
+	var id string
+	readResp.Diagnostics.Append(readResp.State.GetAttribute(ctx, path.Root("id"), &id)...)
+	if want, _ := certAuthenticatorID(ctx, client, identityID); id == "" || id != want {
+		t.Errorf("id = %q after a refresh, want the authenticator %q", id, want)
+	}
+}
+
+// After a failover, the enroll and CA requests go to the controller the
+// provider is connected to, while ztAPI keeps the configured host.
+func TestIdentityEnrollmentUsesActiveHost(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	controller := mockcontroller.New(t)
+	client := testProviderData(t, controller)
+	identityID := createTestIdentity(t, client, "device", &rest_model.IdentityCreateEnrollment{Ott: true})
+
+	// Nothing listens on the configured host, as if it were down.
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+	client.host = unreachable.URL + mockcontroller.ManagementPath
+	api, err := newManagementClient(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.api = api
+
+	resp := createTestEnrollment(t, client, identityID)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("enrolling through the active host: %v", resp.Diagnostics)
+	}
+	var identityJSON string
+	resp.Diagnostics.Append(resp.State.GetAttribute(ctx, path.Root("identity_json"), &identityJSON)...)
+	if got, want := gjson.Get(identityJSON, "ztAPI").String(), unreachable.URL+clientAPIPath; got != want {
+		t.Errorf("ztAPI = %q, want the configured host %q", got, want)
+	}
+	if _, err := checkIdentityJSON(controller, identityJSON); err != nil {
+		t.Error(err)
+	}
+
+	// The mock controller serves the CA bundle over TLS only, so only where the
+	// request is sent is checked.
+	var caURL string
+	next := client.httpClient.HTTPClient.Transport
+	client.httpClient.HTTPClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == mockcontroller.CACertsPath {
+			caURL = req.URL.String()
+		}
+		return next.RoundTrip(req)
+	})
+	_, _ = (&identityEnrollmentResource{resourceConfig: client}).controllerCA(ctx)
+	if want := controller.URL + mockcontroller.CACertsPath; caURL != want {
+		t.Errorf("the CA bundle was fetched from %q, want %q", caURL, want)
+	}
+}
+
+// roundTripFunc is an http.RoundTripper that calls itself.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// createTestEnrollment runs Create of the enrollment resource for an identity
+// with the default key.
+func createTestEnrollment(t *testing.T, client *zitiData, identityID string) *fwresource.CreateResponse {
+	t.Helper()
+	ctx := context.Background()
+	r := &identityEnrollmentResource{resourceConfig: client}
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+	typ := schemaResp.Schema.Type()
+	resp := &fwresource.CreateResponse{
+		State: tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(typ.TerraformType(ctx), nil)},
+	}
+	r.Create(ctx, fwresource.CreateRequest{
+		Plan: tfsdk.Plan{Schema: schemaResp.Schema, Raw: testValue(t, typ, fmt.Sprintf(`{"identity_id": %q, "key_type": "EC", "key_size": 256}`, identityID))},
+	}, resp)
+	return resp
+}
+
+// checkIdentityJSON checks that an identity JSON holds a key pair whose
+// certificate the controller issued, and returns its public key.
+func checkIdentityJSON(controller *mockcontroller.Server, identityJSON string) (interface{}, error) {
+	certPEM, keyPEM, caPEM, err := parsePemFromZitiIdentity(identityJSON)
+	if err != nil {
+		return nil, fmt.Errorf("parsing the identity JSON: %w", err)
+	}
+	if _, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM)); err != nil {
+		return nil, fmt.Errorf("the key does not match the certificate: %w", err)
+	}
+	block, _ := pem.Decode([]byte(certPEM))
+	if block == nil {
+		return nil, errors.New("the certificate is not PEM encoded")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing the certificate: %w", err)
+	}
+	if err := cert.CheckSignatureFrom(controller.EnrollmentCA()); err != nil {
+		return nil, fmt.Errorf("the certificate is not signed by the controller: %w", err)
+	}
+	if block, _ := pem.Decode([]byte(caPEM)); block == nil || string(block.Bytes) != string(controller.EnrollmentCA().Raw) {
+		return nil, errors.New("id.ca is not the CA of the controller")
+	}
+	return cert.PublicKey, nil
+}
+
+// The enroll request carries the one-time token instead of the session, and is
+// sent once even when it fails.
+func TestIdentityEnrollmentEnrollSentOnce(t *testing.T) {
+	t.Parallel()
+	for status, body := range map[int]string{
+		http.StatusNotFound:     `{"error": {"code": "INVALID_ENROLLMENT_TOKEN", "message": "the enrollment token is invalid or has been used"}}`,
+		http.StatusUnauthorized: `{"error": {"code": "UNAUTHORIZED", "message": "unauthorized"}}`,
+		http.StatusBadGateway:   `bad gateway`,
+	} {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			if r.Header.Get("zt-session") != "" {
+				t.Errorf("the enroll request carries the session %q", r.Header.Get("zt-session"))
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			_, _ = io.WriteString(w, body)
+		}))
+		r := &identityEnrollmentResource{resourceConfig: &zitiData{
+			host:         server.URL + "/edge/management/v1",
+			activeHost:   server.URL + "/edge/management/v1",
+			apiToken:     "session",
+			httpClient:   newHTTPClient(nil, httpClientOptions{maxRetries: 2, requestTimeout: 5 * time.Second}),
+			authenticate: func(string) (string, error) { return "renewed", nil },
+		}}
+		_, _, err := r.enroll(context.Background(), "token", []byte("csr"))
+		server.Close()
+
+		var apiErr *ZitiAPIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != status {
+			t.Errorf("%d: got %v, want the error of the controller", status, err)
+		}
+		if got := requests.Load(); got != 1 {
+			t.Errorf("%d: the enroll request was sent %d times", status, got)
+		}
+		if spent := strings.Contains(fmt.Sprint(err), "already used"); spent != (status == http.StatusNotFound) {
+			t.Errorf("%d: got %v, want a used token reported as such", status, err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"testing"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccIdentityNoneResource(t *testing.T) {
+	controller := testAccController(t)
+	const resourceName = "ziti_identity_none.test"
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_identity_none" "test" {
+  name            = "test-identity-none"
+  type            = "Device"
+  role_attributes = ["servers"]
+}
+`
+	update := testAccProviderConfig(controller, "") + `
+resource "ziti_identity_none" "test" {
+  name            = "test-identity-none"
+  type            = "Device"
+  role_attributes = ["servers"]
+  external_id     = "device-42"
+  app_data        = { owner = "ops" }
+}
+`
+
+	steps := []resource.TestStep{{
+		Config: config,
+		Check: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckEntityExists(controller, mockcontroller.Identities, resourceName),
+			resource.TestCheckResourceAttr(resourceName, "name", "test-identity-none"),
+			resource.TestCheckResourceAttr(resourceName, "type", "Device"),
+			resource.TestCheckResourceAttr(resourceName, "is_admin", "false"),
+		),
+	}}
+	steps = append(steps, testAccImportSteps(resourceName)...)
+	steps = append(steps, testAccUpdateStep(resourceName, update,
+		resource.TestCheckResourceAttr(resourceName, "external_id", "device-42"),
+		resource.TestCheckResourceAttr(resourceName, "app_data.owner", "ops"),
+	))
+	steps = append(steps, testAccOutOfBandSteps(t, controller, mockcontroller.Identities, "test-identity-none", resourceName, update,
+		map[string]interface{}{"roleAttributes": []interface{}{"servers", "extra"}})...)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    steps,
+	})
+}
@@ -2,15 +2,11 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
-	"fmt"
-	"net/url"
-	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
-	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
@@ -22,10 +18,8 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/openziti/edge-api/rest_management_api_client/identity"
 	"github.com/openziti/edge-api/rest_model"
-	"github.com/rs/zerolog/log"
-	"github.com/tidwall/gjson"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -37,55 +31,51 @@ var (
 
 // NewIdentityResource is a helper function to simplify the provider implementation.
 func NewIdentityResource() resource.Resource {
-	return &identityResource{}
+	return &identityResource{newZitiResource(zitiResourceSpec[identityResourceModel, *rest_model.IdentityDetail]{
+		typeName:      "_identity",
+		label:         "Identity",
+		create:        identityCreate,
+		read:          identityRead,
+		readData:      identityReadData,
+		update:        identityUpdate,
+		delete:        identityDelete,
+		enrollmentJwt: identityEnrollmentJwt,
+		list:          identityList,
+		entityID: func(detail *rest_model.IdentityDetail) string {
+			return types.StringPointerValue(detail.ID).ValueString()
+		},
+		exports: func(detail *rest_model.IdentityDetail) bool { return identityExportType(detail) == "_identity" },
+	})}
 }
 
 // identityResource is the resource implementation.
 type identityResource struct {
-	resourceConfig *zitiData
-}
-
-// Configure adds the provider configured client to the resource.
-func (r *identityResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	// Add a nil check when handling ProviderData because Terraform
-	// sets that data after it calls the ConfigureProvider RPC.
-	if req.ProviderData == nil {
-		return
-	}
-
-	resourceConfig := req.ProviderData.(*zitiData)
-	r.resourceConfig = resourceConfig
-
-	fmt.Printf("Using API Token to create resource: %s\n", r.resourceConfig.apiToken)
-	fmt.Printf("Using domain to create resource: %s\n", r.resourceConfig.host)
-}
-
-// Metadata returns the resource type name.
-func (r *identityResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
-	resp.TypeName = req.ProviderTypeName + "_identity"
+	zitiResource[identityResourceModel, *rest_model.IdentityDetail]
 }
 
 // identityResourceModel maps the resource schema data.
 type identityResourceModel struct {
-	ID                       types.String `tfsdk:"id"`
-	Name                     types.String `tfsdk:"name"`
-	RoleAttributes           types.Set    `tfsdk:"role_attributes"`
-	AuthPolicyID             types.String `tfsdk:"auth_policy_id"`
-	ExternalID               types.String `tfsdk:"external_id"`
-	IsAdmin                  types.Bool   `tfsdk:"is_admin"`
-	DefaultHostingCost       types.Int64  `tfsdk:"default_hosting_cost"`
-	DefaultHostingPrecedence types.String `tfsdk:"default_hosting_precedence"`
-	ServiceHostingCosts      types.Map    `tfsdk:"service_hosting_costs"`
-	ServiceHostingPrecedence types.Map    `tfsdk:"service_hosting_precedence"`
-	Tags                     types.Map    `tfsdk:"tags"`
-	AppData                  types.Map    `tfsdk:"app_data"`
-	Type                     types.String `tfsdk:"type"`
-	LastUpdated              types.String `tfsdk:"last_updated"`
-	EnrollmentJwt            types.String `tfsdk:"enrollment_token"`
+	ID                       types.String   `tfsdk:"id"`
+	Name                     types.String   `tfsdk:"name"`
+	RoleAttributes           types.Set      `tfsdk:"role_attributes"`
+	AuthPolicyID             types.String   `tfsdk:"auth_policy_id"`
+	ExternalID               types.String   `tfsdk:"external_id"`
+	IsAdmin                  types.Bool     `tfsdk:"is_admin"`
+	DefaultHostingCost       types.Int64    `tfsdk:"default_hosting_cost"`
+	DefaultHostingPrecedence types.String   `tfsdk:"default_hosting_precedence"`
+	ServiceHostingCosts      types.Map      `tfsdk:"service_hosting_costs"`
+	ServiceHostingPrecedence types.Map      `tfsdk:"service_hosting_precedence"`
+	Tags                     types.Map      `tfsdk:"tags"`
+	TagsAll                  types.Map      `tfsdk:"tags_all"`
+	AppData                  types.Map      `tfsdk:"app_data"`
+	Type                     types.String   `tfsdk:"type"`
+	LastUpdated              types.String   `tfsdk:"last_updated"`
+	EnrollmentJwt            types.String   `tfsdk:"enrollment_token"`
+	Timeouts                 timeouts.Value `tfsdk:"timeouts"`
 }
 
 // Schema defines the schema for the resource.
-func (r *identityResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *identityResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		MarkdownDescription: "Ziti Identity Resource, ott type enrollment",
 		Attributes: map[string]schema.Attribute{
@@ -166,6 +156,7 @@ func (r *identityResource) Schema(_ context.Context, _ resource.SchemaRequest, r
 				Default:             mapdefault.StaticValue(types.MapNull(types.StringType)),
 				MarkdownDescription: "Identity Tags",
 			},
+			"tags_all": tagsAllAttribute(),
 			"app_data": schema.MapAttribute{
 				Computed:            true,
 				ElementType:         types.StringType,
@@ -192,19 +183,15 @@ func (r *identityResource) Schema(_ context.Context, _ resource.SchemaRequest, r
 				MarkdownDescription: "The JWT token for one-time identity enrollment (OTT).",
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+			}),
+		},
 	}
 }
 
-// Create a new resource.
-func (r *identityResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	// Retrieve values from plan
-	var eplan identityResourceModel
-
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
+func identityCreate(ctx context.Context, client *zitiData, eplan *identityResourceModel, diags *diag.Diagnostics) (string, error) {
 	var roleAttributes rest_model.Attributes
 	for _, value := range eplan.RoleAttributes.Elements() {
 		if roleAttribute, ok := value.(types.String); ok {
@@ -221,7 +208,6 @@ func (r *identityResource) Create(ctx context.Context, req resource.CreateReques
 	authPolicyId := eplan.AuthPolicyID.ValueString()
 	defaultHostingCost := rest_model.TerminatorCost(eplan.DefaultHostingCost.ValueInt64())
 	defaultHostingPrecedence := rest_model.TerminatorPrecedence(eplan.DefaultHostingPrecedence.ValueString())
-	externalId := eplan.ExternalID.ValueString()
 	isAdmin := eplan.IsAdmin.ValueBool()
 
 	serviceHostingCosts := make(rest_model.TerminatorCostMap)
@@ -244,7 +230,7 @@ func (r *identityResource) Create(ctx context.Context, req resource.CreateReques
 		AuthPolicyID:              &authPolicyId,
 		DefaultHostingCost:        &defaultHostingCost,
 		DefaultHostingPrecedence:  defaultHostingPrecedence,
-		ExternalID:                &externalId,
+		ExternalID:                eplan.ExternalID.ValueStringPointer(),
 		IsAdmin:                   &isAdmin,
 		Name:                      &name,
 		RoleAttributes:            &roleAttributes,
@@ -255,206 +241,14 @@ func (r *identityResource) Create(ctx context.Context, req resource.CreateReques
 		Type:                      &type_,
 	}
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************create resource payload***********************:\n %s\n", jsonData)
-
-	authUrl := fmt.Sprintf("%s/identities", r.resourceConfig.host)
-	cresp, err := CreateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti POST Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Creating Identity", "Could not Create Identity, unexpected error: "+err.Error(),
-		)
-		return
-	}
-	fmt.Printf("**********************create response************************:\n %s\n", cresp)
-
-	resourceID := gjson.Get(cresp, "data.id").String()
-
-	// Map response body to schema and populate Computed attribute values
-	eplan.ID = types.StringValue(resourceID)
-
-	// Poll identity endpoint to get JWT
-	maxRetries := 10
-	var jwtToken string
-
-	for i := 0; i < maxRetries; i++ {
-		time.Sleep(5 * time.Second) // wait between retries
-
-		jwtUrl := fmt.Sprintf("%s/identities/%s", r.resourceConfig.host, resourceID)
-		respBody, err := ReadZitiResource(jwtUrl, r.resourceConfig.apiToken)
-		if err != nil {
-			continue
-		}
-
-		// Use gjson to extract the JWT
-		jwt := gjson.Get(respBody, "data.enrollment.ott.jwt").String()
-		if jwt != "" {
-			jwtToken = jwt
-			break
-		}
-	}
-
-	if jwtToken == "" {
-		resp.Diagnostics.AddError("Error Fetching JWT", "Timeout while waiting for JWT to be available")
-		return
-	}
-
-	eplan.EnrollmentJwt = types.StringValue(jwtToken)
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
-
-	// Set state to fully populated data
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-}
-
-// Read resource information.
-func (r *identityResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	// Get current state
-	var state identityResourceModel
-	tflog.Debug(ctx, "Reading Identity")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	authUrl := fmt.Sprintf("%s/identities/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := ReadZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
+	created, err := client.api.Identity.CreateIdentity(&identity.CreateIdentityParams{Identity: &payload, Context: ctx}, nil)
 	if err != nil {
-		if errors.Is(err, errNotFound) {
-			msg := fmt.Sprintf("Resource not found in backend; removing from state, id: %s", state.ID.ValueString())
-			log.Info().Msg(msg)
-			resp.State.RemoveResource(ctx)
-			return
-		}
-		resp.Diagnostics.AddError(
-			"Error Reading Identity", "Could not READ Identity, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
-	if err != nil {
-		// Handle error
-		resp.Diagnostics.AddError(
-			"Error Reading Identity", fmt.Sprintf("Could not READ Identity, ERROR %v: ", err.Error()),
-		)
-		return
-	}
-
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	data, ok := jsonBody["data"].(map[string]interface{})
-	if !ok {
-		resp.Diagnostics.AddError("Error: ", "'data' is either missing or not a map[string]interface{}")
-		return
-	}
-
-	// Manually assign individual values from the map to the struct fields
-	state.Name = types.StringValue(data["name"].(string))
-
-	if appData, ok := data["appData"].(map[string]interface{}); ok {
-		if len(appData) != 0 {
-			appData, diag := types.MapValueFrom(ctx, types.StringType, appData)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.AppData = appData
-		} else {
-			state.AppData = types.MapNull(types.StringType)
-		}
-	}
-
-	if authPolicyID, ok := data["authPolicyId"].(string); ok {
-		state.AuthPolicyID = types.StringValue(authPolicyID)
-	}
-
-	if defaultHostingCost, ok := data["defaultHostingCost"].(float64); ok {
-		state.DefaultHostingCost = types.Int64Value(int64(defaultHostingCost))
-	}
-
-	if defaultHostingPrecedence, ok := data["defaultHostingPrecedence"].(string); ok {
-		state.DefaultHostingPrecedence = types.StringValue(defaultHostingPrecedence)
-	}
-
-	if externalID, ok := data["externalId"].(string); ok {
-		state.ExternalID = types.StringValue(externalID)
-	}
-
-	if isAdmin, ok := data["isAdmin"].(bool); ok {
-		state.IsAdmin = types.BoolValue(isAdmin)
-	}
-
-	if roleAttributes, ok := data["roleAttributes"].([]interface{}); ok {
-		roleAttributes, diag := types.SetValueFrom(ctx, types.StringType, roleAttributes)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.RoleAttributes = roleAttributes
-	} else {
-		state.RoleAttributes = types.SetNull(types.StringType)
-	}
-
-	if serviceHostingCosts, ok := data["serviceHostingCosts"].(map[string]interface{}); ok {
-		if len(serviceHostingCosts) > 0 {
-			serviceHostingCosts, diag := types.MapValueFrom(ctx, types.Int64Type, serviceHostingCosts)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.ServiceHostingCosts = serviceHostingCosts
-		} else {
-			state.ServiceHostingCosts = types.MapNull(types.Int64Type)
-		}
-	}
-
-	if serviceHostingPrecedence, ok := data["serviceHostingPrecedences"].(map[string]interface{}); ok {
-		if len(serviceHostingPrecedence) > 0 {
-			serviceHostingPrecedence, diag := types.MapValueFrom(ctx, types.StringType, serviceHostingPrecedence)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.ServiceHostingPrecedence = serviceHostingPrecedence
-		} else {
-			state.ServiceHostingPrecedence = types.MapNull(types.StringType)
-		}
-	}
-
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
-	}
-
-	_type := data["type"].(map[string]interface{})
-	if typeValue, ok := _type["name"].(string); ok {
-		state.Type = types.StringValue(typeValue)
-	}
-
-	// Set refreshed state
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+		return "", err
 	}
+	return createdID(created.Payload)
 }
 
-// Update updates the resource and sets the updated Terraform state on success.
-func (r *identityResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	// Retrieve values from plan
-	var eplan identityResourceModel
-	tflog.Debug(ctx, "Updating Identity")
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func identityUpdate(ctx context.Context, client *zitiData, id string, eplan *identityResourceModel, diags *diag.Diagnostics) error {
 	var roleAttributes rest_model.Attributes
 	for _, value := range eplan.RoleAttributes.Elements() {
 		if roleAttribute, ok := value.(types.String); ok {
@@ -468,7 +262,6 @@ func (r *identityResource) Update(ctx context.Context, req resource.UpdateReques
 	authPolicyId := eplan.AuthPolicyID.ValueString()
 	defaultHostingCost := rest_model.TerminatorCost(eplan.DefaultHostingCost.ValueInt64())
 	defaultHostingPrecedence := rest_model.TerminatorPrecedence(eplan.DefaultHostingPrecedence.ValueString())
-	externalId := eplan.ExternalID.ValueString()
 	isAdmin := eplan.IsAdmin.ValueBool()
 
 	serviceHostingCosts := make(rest_model.TerminatorCostMap)
@@ -486,12 +279,12 @@ func (r *identityResource) Update(ctx context.Context, req resource.UpdateReques
 	}
 	type_ := rest_model.IdentityType(eplan.Type.ValueString())
 
-	payload := rest_model.IdentityCreate{
+	payload := rest_model.IdentityUpdate{
 		AppData:                   appData,
 		AuthPolicyID:              &authPolicyId,
 		DefaultHostingCost:        &defaultHostingCost,
 		DefaultHostingPrecedence:  defaultHostingPrecedence,
-		ExternalID:                &externalId,
+		ExternalID:                eplan.ExternalID.ValueStringPointer(),
 		IsAdmin:                   &isAdmin,
 		Name:                      &name,
 		RoleAttributes:            &roleAttributes,
@@ -501,60 +294,127 @@ func (r *identityResource) Update(ctx context.Context, req resource.UpdateReques
 		Type:                      &type_,
 	}
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************update resource payload***********************:\n %s\n", jsonData)
+	_, err := client.api.Identity.UpdateIdentity(&identity.UpdateIdentityParams{ID: id, Identity: &payload, Context: ctx}, nil)
+	return err
+}
 
-	authUrl := fmt.Sprintf("%s/identities/%s", r.resourceConfig.host, url.QueryEscape(eplan.ID.ValueString()))
-	cresp, err := UpdateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti PUT Response: %s", cresp)
-	log.Info().Msg(msg)
+func identityRead(ctx context.Context, client *zitiData, id string) (*rest_model.IdentityDetail, error) {
+	detail, err := client.api.Identity.DetailIdentity(&identity.DetailIdentityParams{ID: id, Context: ctx}, nil)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Updating Identity", "Could not Update Identity, unexpected error: "+err.Error(),
-		)
-		return
+		return nil, err
+	}
+	if detail.Payload == nil || detail.Payload.Data == nil {
+		return nil, errMissingData
+	}
+	return detail.Payload.Data, nil
+}
+
+func identityDelete(ctx context.Context, client *zitiData, id string) error {
+	_, err := client.api.Identity.DeleteIdentity(&identity.DeleteIdentityParams{ID: id, Context: ctx}, nil)
+	return err
+}
+
+func identityReadData(ctx context.Context, detail *rest_model.IdentityDetail, state *identityResourceModel, diags *diag.Diagnostics) {
+	state.Name = types.StringPointerValue(detail.Name)
+
+	if detail.AppData != nil {
+		state.AppData = tagsFromAPI(ctx, detail.AppData, diags)
 	}
 
-	// Map response body to schema and populate Computed attribute values
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
+	if detail.AuthPolicyID != nil {
+		state.AuthPolicyID = types.StringValue(*detail.AuthPolicyID)
+	}
 
-	var state identityResourceModel
-	_ = req.State.Get(ctx, &state)
-	eplan.EnrollmentJwt = state.EnrollmentJwt
+	if detail.DefaultHostingCost != nil {
+		state.DefaultHostingCost = types.Int64Value(int64(*detail.DefaultHostingCost))
+	}
 
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+	if detail.DefaultHostingPrecedence != "" {
+		state.DefaultHostingPrecedence = types.StringValue(string(detail.DefaultHostingPrecedence))
 	}
+
+	state.ExternalID = nonEmptyStringValue(detail.ExternalID)
+
+	if detail.IsAdmin != nil {
+		state.IsAdmin = types.BoolValue(*detail.IsAdmin)
+	}
+
+	state.RoleAttributes = roleAttributesFromAPI(ctx, detail.RoleAttributes, diags)
+
+	if detail.ServiceHostingCosts != nil {
+		state.ServiceHostingCosts = serviceHostingCostsFromAPI(ctx, detail.ServiceHostingCosts, diags)
+	}
+
+	if detail.ServiceHostingPrecedences != nil {
+		state.ServiceHostingPrecedence = serviceHostingPrecedencesFromAPI(ctx, detail.ServiceHostingPrecedences, diags)
+	}
+
+	if detail.Type != nil {
+		state.Type = types.StringValue(detail.Type.Name)
+	}
+
+	state.Tags = tagsFromAPI(ctx, detail.Tags, diags)
 }
 
-// Delete deletes the resource and removes the Terraform state on success.
-func (r *identityResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	// Retrieve values from state
-	var state identityResourceModel
-	tflog.Debug(ctx, "Deleting Identity")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+func identityEnrollmentJwt(detail *rest_model.IdentityDetail) string {
+	if detail.Enrollment == nil || detail.Enrollment.Ott == nil {
+		return ""
 	}
+	return detail.Enrollment.Ott.JWT
+}
 
-	authUrl := fmt.Sprintf("%s/identities/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
+// identityExportType returns the resource type the exporter writes an identity
+// as, e.g. "_identity_updb", or "" for the identities the controller manages:
+// the default admin and the identities of routers.
+func identityExportType(detail *rest_model.IdentityDetail) string {
+	if detail.IsDefaultAdmin != nil && *detail.IsDefaultAdmin {
+		return ""
+	}
+	if detail.Type != nil && detail.Type.Name == "Router" {
+		return ""
+	}
+	enrollment, authenticators := detail.Enrollment, detail.Authenticators
+	switch {
+	case enrollment != nil && enrollment.Updb != nil, authenticators != nil && authenticators.Updb != nil:
+		return "_identity_updb"
+	case enrollment != nil && enrollment.Ottca != nil:
+		return "_identity_ca"
+	case enrollment != nil && enrollment.Ott != nil, authenticators != nil && authenticators.Cert != nil:
+		return "_identity"
+	default:
+		return "_identity_none"
+	}
+}
 
-	cresp, err := DeleteZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti DELETE Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Deleting Identity", "Could not DELETE Identity, unexpected error: "+err.Error(),
-		)
-		return
+// serviceHostingCostsFromAPI converts the service hosting costs of a fetched
+// identity to the service_hosting_costs attribute; no costs are stored as null.
+func serviceHostingCostsFromAPI(ctx context.Context, costs rest_model.TerminatorCostMap, diags *diag.Diagnostics) types.Map {
+	values := make(map[string]int64, len(costs))
+	for service, cost := range costs {
+		if cost != nil {
+			values[service] = int64(*cost)
+		}
 	}
+	if len(values) == 0 {
+		return types.MapNull(types.Int64Type)
+	}
+	value, d := types.MapValueFrom(ctx, types.Int64Type, values)
+	diags.Append(d...)
+	return value
 }
 
-func (r *identityResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+// serviceHostingPrecedencesFromAPI converts the service hosting precedences of a
+// fetched identity to the service_hosting_precedence attribute; no precedences
+// are stored as null.
+func serviceHostingPrecedencesFromAPI(ctx context.Context, precedences rest_model.TerminatorPrecedenceMap, diags *diag.Diagnostics) types.Map {
+	if len(precedences) == 0 {
+		return types.MapNull(types.StringType)
+	}
+	values := make(map[string]string, len(precedences))
+	for service, precedence := range precedences {
+		values[service] = string(precedence)
+	}
+	value, d := types.MapValueFrom(ctx, types.StringType, values)
+	diags.Append(d...)
+	return value
 }
@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"testing"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccIdentityResource(t *testing.T) {
+	controller := testAccController(t)
+	const resourceName = "ziti_identity.test"
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_identity" "test" {
+  name            = "test-identity"
+  type            = "User"
+  role_attributes = ["clients"]
+}
+`
+	update := testAccProviderConfig(controller, "") + `
+resource "ziti_identity" "test" {
+  name                       = "test-identity"
+  type                       = "User"
+  role_attributes            = ["clients", "admins"]
+  default_hosting_cost       = 10
+  default_hosting_precedence = "required"
+  tags                       = { env = "test" }
+}
+`
+
+	steps := []resource.TestStep{{
+		Config: config,
+		Check: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckEntityExists(controller, mockcontroller.Identities, resourceName),
+			resource.TestCheckResourceAttr(resourceName, "name", "test-identity"),
+			resource.TestCheckResourceAttr(resourceName, "type", "User"),
+			resource.TestCheckResourceAttr(resourceName, "auth_policy_id", mockcontroller.DefaultAuthPolicyID),
+			resource.TestCheckResourceAttrSet(resourceName, "enrollment_token"),
+		),
+	}}
+	steps = append(steps, testAccImportSteps(resourceName)...)
+	steps = append(steps, testAccUpdateStep(resourceName, update,
+		resource.TestCheckResourceAttr(resourceName, "role_attributes.#", "2"),
+		resource.TestCheckResourceAttr(resourceName, "default_hosting_cost", "10"),
+		resource.TestCheckResourceAttr(resourceName, "default_hosting_precedence", "required"),
+		resource.TestCheckResourceAttrSet(resourceName, "enrollment_token"),
+	))
+	steps = append(steps, testAccOutOfBandSteps(t, controller, mockcontroller.Identities, "test-identity", resourceName, update,
+		map[string]interface{}{"isAdmin": true})...)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    steps,
+	})
+}
@@ -2,15 +2,11 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
-	"fmt"
-	"net/url"
-	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
-	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
@@ -22,10 +18,8 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/openziti/edge-api/rest_management_api_client/identity"
 	"github.com/openziti/edge-api/rest_model"
-	"github.com/rs/zerolog/log"
-	"github.com/tidwall/gjson"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -37,56 +31,52 @@ var (
 
 // NewIdentityUpdbResource is a helper function to simplify the provider implementation.
 func NewIdentityUpdbResource() resource.Resource {
-	return &identityUpdbResource{}
+	return &identityUpdbResource{newZitiResource(zitiResourceSpec[identityUpdbResourceModel, *rest_model.IdentityDetail]{
+		typeName:      "_identity_updb",
+		label:         "Identity",
+		create:        identityUpdbCreate,
+		read:          identityUpdbRead,
+		readData:      identityUpdbReadData,
+		update:        identityUpdbUpdate,
+		delete:        identityUpdbDelete,
+		enrollmentJwt: identityUpdbEnrollmentJwt,
+		list:          identityList,
+		entityID: func(detail *rest_model.IdentityDetail) string {
+			return types.StringPointerValue(detail.ID).ValueString()
+		},
+		exports: func(detail *rest_model.IdentityDetail) bool { return identityExportType(detail) == "_identity_updb" },
+	})}
 }
 
 // identityUpdbResource is the resource implementation.
 type identityUpdbResource struct {
-	resourceConfig *zitiData
-}
-
-// Configure adds the provider configured client to the resource.
-func (r *identityUpdbResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	// Add a nil check when handling ProviderData because Terraform
-	// sets that data after it calls the ConfigureProvider RPC.
-	if req.ProviderData == nil {
-		return
-	}
-
-	resourceConfig := req.ProviderData.(*zitiData)
-	r.resourceConfig = resourceConfig
-
-	fmt.Printf("Using API Token to create resource: %s\n", r.resourceConfig.apiToken)
-	fmt.Printf("Using domain to create resource: %s\n", r.resourceConfig.host)
-}
-
-// Metadata returns the resource type name.
-func (r *identityUpdbResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
-	resp.TypeName = req.ProviderTypeName + "_identity_updb"
+	zitiResource[identityUpdbResourceModel, *rest_model.IdentityDetail]
 }
 
 // identityUpdbResourceModel maps the resource schema data.
 type identityUpdbResourceModel struct {
-	ID                       types.String `tfsdk:"id"`
-	Name                     types.String `tfsdk:"name"`
-	RoleAttributes           types.Set    `tfsdk:"role_attributes"`
-	AuthPolicyID             types.String `tfsdk:"auth_policy_id"`
-	ExternalID               types.String `tfsdk:"external_id"`
-	IsAdmin                  types.Bool   `tfsdk:"is_admin"`
-	DefaultHostingCost       types.Int64  `tfsdk:"default_hosting_cost"`
-	DefaultHostingPrecedence types.String `tfsdk:"default_hosting_precedence"`
-	ServiceHostingCosts      types.Map    `tfsdk:"service_hosting_costs"`
-	ServiceHostingPrecedence types.Map    `tfsdk:"service_hosting_precedence"`
-	Tags                     types.Map    `tfsdk:"tags"`
-	AppData                  types.Map    `tfsdk:"app_data"`
-	Type                     types.String `tfsdk:"type"`
-	LastUpdated              types.String `tfsdk:"last_updated"`
-	UpdbUsername             types.String `tfsdk:"updb_username"`
-	EnrollmentJwt            types.String `tfsdk:"enrollment_token"`
+	ID                       types.String   `tfsdk:"id"`
+	Name                     types.String   `tfsdk:"name"`
+	RoleAttributes           types.Set      `tfsdk:"role_attributes"`
+	AuthPolicyID             types.String   `tfsdk:"auth_policy_id"`
+	ExternalID               types.String   `tfsdk:"external_id"`
+	IsAdmin                  types.Bool     `tfsdk:"is_admin"`
+	DefaultHostingCost       types.Int64    `tfsdk:"default_hosting_cost"`
+	DefaultHostingPrecedence types.String   `tfsdk:"default_hosting_precedence"`
+	ServiceHostingCosts      types.Map      `tfsdk:"service_hosting_costs"`
+	ServiceHostingPrecedence types.Map      `tfsdk:"service_hosting_precedence"`
+	Tags                     types.Map      `tfsdk:"tags"`
+	TagsAll                  types.Map      `tfsdk:"tags_all"`
+	AppData                  types.Map      `tfsdk:"app_data"`
+	Type                     types.String   `tfsdk:"type"`
+	LastUpdated              types.String   `tfsdk:"last_updated"`
+	UpdbUsername             types.String   `tfsdk:"updb_username"`
+	EnrollmentJwt            types.String   `tfsdk:"enrollment_token"`
+	Timeouts                 timeouts.Value `tfsdk:"timeouts"`
 }
 
 // Schema defines the schema for the resource.
-func (r *identityUpdbResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (r *identityUpdbResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		MarkdownDescription: "Ziti Identity Resource, Type: updb",
 		Attributes: map[string]schema.Attribute{
@@ -167,6 +157,7 @@ func (r *identityUpdbResource) Schema(_ context.Context, _ resource.SchemaReques
 				Default:             mapdefault.StaticValue(types.MapNull(types.StringType)),
 				MarkdownDescription: "Identity Tags",
 			},
+			"tags_all": tagsAllAttribute(),
 			"app_data": schema.MapAttribute{
 				Computed:            true,
 				ElementType:         types.StringType,
@@ -200,19 +191,15 @@ func (r *identityUpdbResource) Schema(_ context.Context, _ resource.SchemaReques
 				MarkdownDescription: "UPDB Username.",
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+			}),
+		},
 	}
 }
 
-// Create a new resource.
-func (r *identityUpdbResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	// Retrieve values from plan
-	var eplan identityUpdbResourceModel
-
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
+func identityUpdbCreate(ctx context.Context, client *zitiData, eplan *identityUpdbResourceModel, diags *diag.Diagnostics) (string, error) {
 	var roleAttributes rest_model.Attributes
 	for _, value := range eplan.RoleAttributes.Elements() {
 		if roleAttribute, ok := value.(types.String); ok {
@@ -230,7 +217,6 @@ func (r *identityUpdbResource) Create(ctx context.Context, req resource.CreateRe
 	authPolicyId := eplan.AuthPolicyID.ValueString()
 	defaultHostingCost := rest_model.TerminatorCost(eplan.DefaultHostingCost.ValueInt64())
 	defaultHostingPrecedence := rest_model.TerminatorPrecedence(eplan.DefaultHostingPrecedence.ValueString())
-	externalId := eplan.ExternalID.ValueString()
 	isAdmin := eplan.IsAdmin.ValueBool()
 
 	serviceHostingCosts := make(rest_model.TerminatorCostMap)
@@ -253,7 +239,7 @@ func (r *identityUpdbResource) Create(ctx context.Context, req resource.CreateRe
 		AuthPolicyID:              &authPolicyId,
 		DefaultHostingCost:        &defaultHostingCost,
 		DefaultHostingPrecedence:  defaultHostingPrecedence,
-		ExternalID:                &externalId,
+		ExternalID:                eplan.ExternalID.ValueStringPointer(),
 		IsAdmin:                   &isAdmin,
 		Name:                      &name,
 		RoleAttributes:            &roleAttributes,
@@ -264,206 +250,14 @@ func (r *identityUpdbResource) Create(ctx context.Context, req resource.CreateRe
 		Type:                      &type_,
 	}
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************create resource payload***********************:\n %s\n", jsonData)
-
-	authUrl := fmt.Sprintf("%s/identities", r.resourceConfig.host)
-	cresp, err := CreateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti POST Response: %s", cresp)
-	log.Info().Msg(msg)
+	created, err := client.api.Identity.CreateIdentity(&identity.CreateIdentityParams{Identity: &payload, Context: ctx}, nil)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Creating Identity", "Could not Create Identity, unexpected error: "+err.Error(),
-		)
-		return
-	}
-	fmt.Printf("**********************create response************************:\n %s\n", cresp)
-
-	resourceID := gjson.Get(cresp, "data.id").String()
-
-	// Map response body to schema and populate Computed attribute values
-	eplan.ID = types.StringValue(resourceID)
-
-	// Poll identityUpdb endpoint to get JWT
-	maxRetries := 10
-	var jwtToken string
-
-	for i := 0; i < maxRetries; i++ {
-		time.Sleep(5 * time.Second) // wait between retries
-
-		jwtUrl := fmt.Sprintf("%s/identities/%s", r.resourceConfig.host, resourceID)
-		respBody, err := ReadZitiResource(jwtUrl, r.resourceConfig.apiToken)
-		if err != nil {
-			continue
-		}
-
-		// Use gjson to extract the JWT
-		jwt := gjson.Get(respBody, "data.enrollment.updb.jwt").String()
-		if jwt != "" {
-			jwtToken = jwt
-			break
-		}
-	}
-
-	if jwtToken == "" {
-		resp.Diagnostics.AddError("Error Fetching JWT", "Timeout while waiting for JWT to be available")
-		return
-	}
-
-	eplan.EnrollmentJwt = types.StringValue(jwtToken)
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
-
-	// Set state to fully populated data
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-}
-
-// Read resource information.
-func (r *identityUpdbResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	// Get current state
-	var state identityUpdbResourceModel
-	tflog.Debug(ctx, "Reading Identity")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	authUrl := fmt.Sprintf("%s/identities/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := ReadZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		if errors.Is(err, errNotFound) {
-			msg := fmt.Sprintf("Resource not found in backend; removing from state, id: %s", state.ID.ValueString())
-			log.Info().Msg(msg)
-			resp.State.RemoveResource(ctx)
-			return
-		}
-		resp.Diagnostics.AddError(
-			"Error Reading Identity", "Could not READ Identity, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
-	if err != nil {
-		// Handle error
-		resp.Diagnostics.AddError(
-			"Error Reading Identity", fmt.Sprintf("Could not READ Identity, ERROR %v: ", err.Error()),
-		)
-		return
-	}
-
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	data, ok := jsonBody["data"].(map[string]interface{})
-	if !ok {
-		resp.Diagnostics.AddError("Error: ", "'data' is either missing or not a map[string]interface{}")
-		return
-	}
-
-	// Manually assign individual values from the map to the struct fields
-	state.Name = types.StringValue(data["name"].(string))
-
-	if appData, ok := data["appData"].(map[string]interface{}); ok {
-		if len(appData) != 0 {
-			appData, diag := types.MapValueFrom(ctx, types.StringType, appData)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.AppData = appData
-		} else {
-			state.AppData = types.MapNull(types.StringType)
-		}
-	}
-
-	if authPolicyID, ok := data["authPolicyId"].(string); ok {
-		state.AuthPolicyID = types.StringValue(authPolicyID)
-	}
-
-	if defaultHostingCost, ok := data["defaultHostingCost"].(float64); ok {
-		state.DefaultHostingCost = types.Int64Value(int64(defaultHostingCost))
-	}
-
-	if defaultHostingPrecedence, ok := data["defaultHostingPrecedence"].(string); ok {
-		state.DefaultHostingPrecedence = types.StringValue(defaultHostingPrecedence)
-	}
-
-	if externalID, ok := data["externalId"].(string); ok {
-		state.ExternalID = types.StringValue(externalID)
-	}
-
-	if isAdmin, ok := data["isAdmin"].(bool); ok {
-		state.IsAdmin = types.BoolValue(isAdmin)
-	}
-
-	if roleAttributes, ok := data["roleAttributes"].([]interface{}); ok {
-		roleAttributes, diag := types.SetValueFrom(ctx, types.StringType, roleAttributes)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.RoleAttributes = roleAttributes
-	} else {
-		state.RoleAttributes = types.SetNull(types.StringType)
-	}
-
-	if serviceHostingCosts, ok := data["serviceHostingCosts"].(map[string]interface{}); ok {
-		if len(serviceHostingCosts) > 0 {
-			serviceHostingCosts, diag := types.MapValueFrom(ctx, types.Int64Type, serviceHostingCosts)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.ServiceHostingCosts = serviceHostingCosts
-		} else {
-			state.ServiceHostingCosts = types.MapNull(types.Int64Type)
-		}
-	}
-
-	if serviceHostingPrecedence, ok := data["serviceHostingPrecedences"].(map[string]interface{}); ok {
-		if len(serviceHostingPrecedence) > 0 {
-			serviceHostingPrecedence, diag := types.MapValueFrom(ctx, types.StringType, serviceHostingPrecedence)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.ServiceHostingPrecedence = serviceHostingPrecedence
-		} else {
-			state.ServiceHostingPrecedence = types.MapNull(types.StringType)
-		}
-	}
-
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
-	}
-
-	_type := data["type"].(map[string]interface{})
-	if typeValue, ok := _type["name"].(string); ok {
-		state.Type = types.StringValue(typeValue)
-	}
-
-	// Set refreshed state
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+		return "", err
 	}
+	return createdID(created.Payload)
 }
 
-// Update updates the resource and sets the updated Terraform state on success.
-func (r *identityUpdbResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	// Retrieve values from plan
-	var eplan identityUpdbResourceModel
-	tflog.Debug(ctx, "Updating Identity")
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func identityUpdbUpdate(ctx context.Context, client *zitiData, id string, eplan *identityUpdbResourceModel, diags *diag.Diagnostics) error {
 	var roleAttributes rest_model.Attributes
 	for _, value := range eplan.RoleAttributes.Elements() {
 		if roleAttribute, ok := value.(types.String); ok {
@@ -477,7 +271,6 @@ func (r *identityUpdbResource) Update(ctx context.Context, req resource.UpdateRe
 	authPolicyId := eplan.AuthPolicyID.ValueString()
 	defaultHostingCost := rest_model.TerminatorCost(eplan.DefaultHostingCost.ValueInt64())
 	defaultHostingPrecedence := rest_model.TerminatorPrecedence(eplan.DefaultHostingPrecedence.ValueString())
-	externalId := eplan.ExternalID.ValueString()
 	isAdmin := eplan.IsAdmin.ValueBool()
 
 	serviceHostingCosts := make(rest_model.TerminatorCostMap)
@@ -495,12 +288,12 @@ func (r *identityUpdbResource) Update(ctx context.Context, req resource.UpdateRe
 	}
 	type_ := rest_model.IdentityType(eplan.Type.ValueString())
 
-	payload := rest_model.IdentityCreate{
+	payload := rest_model.IdentityUpdate{
 		AppData:                   appData,
 		AuthPolicyID:              &authPolicyId,
 		DefaultHostingCost:        &defaultHostingCost,
 		DefaultHostingPrecedence:  defaultHostingPrecedence,
-		ExternalID:                &externalId,
+		ExternalID:                eplan.ExternalID.ValueStringPointer(),
 		IsAdmin:                   &isAdmin,
 		Name:                      &name,
 		RoleAttributes:            &roleAttributes,
@@ -510,60 +303,71 @@ func (r *identityUpdbResource) Update(ctx context.Context, req resource.UpdateRe
 		Type:                      &type_,
 	}
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************update resource payload***********************:\n %s\n", jsonData)
+	_, err := client.api.Identity.UpdateIdentity(&identity.UpdateIdentityParams{ID: id, Identity: &payload, Context: ctx}, nil)
+	return err
+}
 
-	authUrl := fmt.Sprintf("%s/identities/%s", r.resourceConfig.host, url.QueryEscape(eplan.ID.ValueString()))
-	cresp, err := UpdateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti PUT Response: %s", cresp)
-	log.Info().Msg(msg)
+func identityUpdbRead(ctx context.Context, client *zitiData, id string) (*rest_model.IdentityDetail, error) {
+	detail, err := client.api.Identity.DetailIdentity(&identity.DetailIdentityParams{ID: id, Context: ctx}, nil)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Updating Identity", "Could not Update Identity, unexpected error: "+err.Error(),
-		)
-		return
+		return nil, err
+	}
+	if detail.Payload == nil || detail.Payload.Data == nil {
+		return nil, errMissingData
 	}
+	return detail.Payload.Data, nil
+}
 
-	// Map response body to schema and populate Computed attribute values
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
+func identityUpdbDelete(ctx context.Context, client *zitiData, id string) error {
+	_, err := client.api.Identity.DeleteIdentity(&identity.DeleteIdentityParams{ID: id, Context: ctx}, nil)
+	return err
+}
 
-	var state identityUpdbResourceModel
-	_ = req.State.Get(ctx, &state)
-	eplan.EnrollmentJwt = state.EnrollmentJwt
+func identityUpdbReadData(ctx context.Context, detail *rest_model.IdentityDetail, state *identityUpdbResourceModel, diags *diag.Diagnostics) {
+	state.Name = types.StringPointerValue(detail.Name)
 
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+	if detail.AppData != nil {
+		state.AppData = tagsFromAPI(ctx, detail.AppData, diags)
 	}
-}
 
-// Delete deletes the resource and removes the Terraform state on success.
-func (r *identityUpdbResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	// Retrieve values from state
-	var state identityUpdbResourceModel
-	tflog.Debug(ctx, "Deleting Identity")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+	if detail.AuthPolicyID != nil {
+		state.AuthPolicyID = types.StringValue(*detail.AuthPolicyID)
 	}
 
-	authUrl := fmt.Sprintf("%s/identities/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
+	if detail.DefaultHostingCost != nil {
+		state.DefaultHostingCost = types.Int64Value(int64(*detail.DefaultHostingCost))
+	}
 
-	cresp, err := DeleteZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti DELETE Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Deleting Identity", "Could not DELETE Identity, unexpected error: "+err.Error(),
-		)
-		return
+	if detail.DefaultHostingPrecedence != "" {
+		state.DefaultHostingPrecedence = types.StringValue(string(detail.DefaultHostingPrecedence))
+	}
+
+	state.ExternalID = nonEmptyStringValue(detail.ExternalID)
+
+	if detail.IsAdmin != nil {
+		state.IsAdmin = types.BoolValue(*detail.IsAdmin)
+	}
+
+	state.RoleAttributes = roleAttributesFromAPI(ctx, detail.RoleAttributes, diags)
+
+	if detail.ServiceHostingCosts != nil {
+		state.ServiceHostingCosts = serviceHostingCostsFromAPI(ctx, detail.ServiceHostingCosts, diags)
 	}
+
+	if detail.ServiceHostingPrecedences != nil {
+		state.ServiceHostingPrecedence = serviceHostingPrecedencesFromAPI(ctx, detail.ServiceHostingPrecedences, diags)
+	}
+
+	if detail.Type != nil {
+		state.Type = types.StringValue(detail.Type.Name)
+	}
+
+	state.Tags = tagsFromAPI(ctx, detail.Tags, diags)
 }
 
-func (r *identityUpdbResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+func identityUpdbEnrollmentJwt(detail *rest_model.IdentityDetail) string {
+	if detail.Enrollment == nil || detail.Enrollment.Updb == nil {
+		return ""
+	}
+	return detail.Enrollment.Updb.JWT
 }
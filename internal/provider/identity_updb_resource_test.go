@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"testing"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccIdentityUpdbResource(t *testing.T) {
+	controller := testAccController(t)
+	const resourceName = "ziti_identity_updb.test"
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_identity_updb" "test" {
+  name          = "test-identity-updb"
+  updb_username = "alice"
+}
+`
+	update := testAccProviderConfig(controller, "") + `
+resource "ziti_identity_updb" "test" {
+  name          = "test-identity-updb"
+  updb_username = "alice"
+  is_admin      = true
+}
+`
+
+	steps := []resource.TestStep{{
+		Config: config,
+		Check: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckEntityExists(controller, mockcontroller.Identities, resourceName),
+			resource.TestCheckResourceAttr(resourceName, "name", "test-identity-updb"),
+			resource.TestCheckResourceAttr(resourceName, "updb_username", "alice"),
+			resource.TestCheckResourceAttrSet(resourceName, "enrollment_token"),
+		),
+	}}
+	// The controller does not return the username of a pending updb
+	// enrollment.
+	steps = append(steps, testAccImportSteps(resourceName, "updb_username")...)
+	steps = append(steps, testAccUpdateStep(resourceName, update,
+		resource.TestCheckResourceAttr(resourceName, "is_admin", "true"),
+		resource.TestCheckResourceAttrSet(resourceName, "enrollment_token"),
+	))
+	steps = append(steps, testAccOutOfBandSteps(t, controller, mockcontroller.Identities, "test-identity-updb", resourceName, update,
+		map[string]interface{}{"isAdmin": false})...)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    steps,
+	})
+}
@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Log subsystems of the provider. Their level can be raised independently with
+// TF_LOG_PROVIDER_ZITI_CLIENT and TF_LOG_PROVIDER_ZITI_CLUSTER.
+const (
+	// logSubsystemClient covers authentication and every request sent to the controller.
+	logSubsystemClient = "client"
+	// logSubsystemCluster covers HA member discovery and failover.
+	logSubsystemCluster = "cluster"
+)
+
+// maskedLogFieldKeys are log field keys whose values are always masked.
+var maskedLogFieldKeys = []string{
+	"token",
+	"zt_session",
+	"password",
+	"jwt",
+	"enrollment_token",
+	"private_key",
+	"key",
+}
+
+// maskedLogRegexes mask secrets wherever they appear in log messages or field
+// values: JWTs (enrollment tokens, ext-jwt tokens), PEM private keys and
+// password fields of JSON payloads.
+var maskedLogRegexes = []*regexp.Regexp{
+	regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`"password"\s*:\s*"[^"]*"`),
+}
+
+// withLogMasking registers the secret masks on the provider root logger.
+func withLogMasking(ctx context.Context, secrets ...string) context.Context {
+	ctx = tflog.MaskFieldValuesWithFieldKeys(ctx, maskedLogFieldKeys...)
+	ctx = tflog.MaskLogRegexes(ctx, maskedLogRegexes...)
+	return tflog.MaskLogStrings(ctx, nonEmpty(secrets)...)
+}
+
+// newLogSubsystem creates a subsystem logger carrying the same masks as the
+// root logger. secrets are additional literal values to mask, such as the
+// current session token.
+func newLogSubsystem(ctx context.Context, subsystem string, secrets ...string) context.Context {
+	ctx = tflog.NewSubsystem(ctx, subsystem, tflog.WithLevelFromEnv("TF_LOG_PROVIDER_ZITI", subsystem))
+	ctx = tflog.SubsystemMaskFieldValuesWithFieldKeys(ctx, subsystem, maskedLogFieldKeys...)
+	ctx = tflog.SubsystemMaskLogRegexes(ctx, subsystem, maskedLogRegexes...)
+	return tflog.SubsystemMaskLogStrings(ctx, subsystem, nonEmpty(secrets)...)
+}
+
+func nonEmpty(values []string) []string {
+	var ret []string
+	for _, v := range values {
+		if v != "" {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
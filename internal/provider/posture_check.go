@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/openziti/edge-api/rest_management_api_client/posture_checks"
+	"github.com/openziti/edge-api/rest_model"
+)
+
+// readPostureCheck fetches a posture check and asserts it is of the detail
+// type D, e.g. *rest_model.PostureCheckDomainDetail.
+func readPostureCheck[D rest_model.PostureCheckDetail](ctx context.Context, client *zitiData, id string) (D, error) {
+	var none D
+	detail, err := client.api.PostureChecks.DetailPostureCheck(&posture_checks.DetailPostureCheckParams{ID: id, Context: ctx}, nil)
+	if err != nil {
+		return none, err
+	}
+	if detail.Payload == nil || detail.Payload.Data() == nil {
+		return none, errMissingData
+	}
+	postureCheck, ok := detail.Payload.Data().(D)
+	if !ok {
+		return none, fmt.Errorf("posture check %s is of type %s, not %T", id, detail.Payload.Data().TypeID(), none)
+	}
+	return postureCheck, nil
+}
+
+// listPostureChecks lists the posture checks matching filter and asserts they
+// are of the detail type D.
+func listPostureChecks[D rest_model.PostureCheckDetail](ctx context.Context, client *zitiData, filter string) ([]D, error) {
+	list, err := client.api.PostureChecks.ListPostureChecks(&posture_checks.ListPostureChecksParams{Filter: optionalFilter(filter), Context: ctx}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if list.Payload == nil {
+		return nil, errMissingData
+	}
+	postureChecks := make([]D, 0, len(list.Payload.Data()))
+	for _, item := range list.Payload.Data() {
+		if item == nil {
+			continue
+		}
+		postureCheck, ok := item.(D)
+		if !ok {
+			var none D
+			return nil, fmt.Errorf("posture check %s is of type %s, not %T", types.StringPointerValue(item.ID()).ValueString(), item.TypeID(), none)
+		}
+		postureChecks = append(postureChecks, postureCheck)
+	}
+	return postureChecks, nil
+}
+
+func deletePostureCheck(ctx context.Context, client *zitiData, id string) error {
+	_, err := client.api.PostureChecks.DeletePostureCheck(&posture_checks.DeletePostureCheckParams{ID: id, Context: ctx}, nil)
+	return err
+}
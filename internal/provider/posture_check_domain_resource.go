@@ -2,13 +2,8 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
-	"fmt"
-	"net/url"
-	"time"
 
-	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapdefault"
@@ -16,10 +11,8 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/openziti/edge-api/rest_management_api_client/posture_checks"
 	"github.com/openziti/edge-api/rest_model"
-	"github.com/rs/zerolog/log"
-	"github.com/tidwall/gjson"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -31,32 +24,25 @@ var (
 
 // NewPostureCheckDomainResource is a helper function to simplify the provider implementation.
 func NewPostureCheckDomainResource() resource.Resource {
-	return &postureCheckDomainResource{}
+	return &postureCheckDomainResource{newZitiResource(zitiResourceSpec[postureCheckDomainResourceModel, *rest_model.PostureCheckDomainDetail]{
+		typeName: "_posture_check_domains",
+		label:    "posture check",
+		create:   postureCheckDomainCreate,
+		read:     readPostureCheck[*rest_model.PostureCheckDomainDetail],
+		readData: postureCheckDomainReadData,
+		update:   postureCheckDomainUpdate,
+		delete:   deletePostureCheck,
+		list:     listPostureChecks[*rest_model.PostureCheckDomainDetail],
+		entityID: func(detail *rest_model.PostureCheckDomainDetail) string {
+			return types.StringPointerValue(detail.ID()).ValueString()
+		},
+		exportFilter: `typeId = "DOMAIN"`,
+	})}
 }
 
 // postureCheckDomainResource is the resource implementation.
 type postureCheckDomainResource struct {
-	resourceConfig *zitiData
-}
-
-// Configure adds the provider configured client to the resource.
-func (r *postureCheckDomainResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	// Add a nil check when handling ProviderData because Terraform
-	// sets that data after it calls the ConfigureProvider RPC.
-	if req.ProviderData == nil {
-		return
-	}
-
-	resourceConfig := req.ProviderData.(*zitiData)
-	r.resourceConfig = resourceConfig
-
-	fmt.Printf("Using API Token to create resource: %s\n", r.resourceConfig.apiToken)
-	fmt.Printf("Using domain to create resource: %s\n", r.resourceConfig.host)
-}
-
-// Metadata returns the resource type name.
-func (r *postureCheckDomainResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
-	resp.TypeName = req.ProviderTypeName + "_posture_check_domains"
+	zitiResource[postureCheckDomainResourceModel, *rest_model.PostureCheckDomainDetail]
 }
 
 // postureCheckDomainResourceModel maps the resource schema data.
@@ -66,17 +52,10 @@ type postureCheckDomainResourceModel struct {
 	RoleAttributes types.Set    `tfsdk:"role_attributes"`
 	Domains        types.List   `tfsdk:"domains"`
 	Tags           types.Map    `tfsdk:"tags"`
+	TagsAll        types.Map    `tfsdk:"tags_all"`
 	LastUpdated    types.String `tfsdk:"last_updated"`
 }
 
-type postureCheckDomainPayload struct {
-	Name           *string                     `json:"name"`
-	RoleAttributes rest_model.Attributes       `json:"roleAttributes,omitempty"`
-	TypeID         rest_model.PostureCheckType `json:"typeId"`
-	Domains        []string                    `json:"domains"`
-	Tags           *rest_model.Tags            `json:"tags,omitempty"`
-}
-
 // Schema defines the schema for the resource.
 func (r *postureCheckDomainResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
@@ -116,21 +95,12 @@ func (r *postureCheckDomainResource) Schema(_ context.Context, _ resource.Schema
 				Default:             mapdefault.StaticValue(types.MapNull(types.StringType)),
 				MarkdownDescription: "Posture Check Tags",
 			},
+			"tags_all": tagsAllAttribute(),
 		},
 	}
 }
 
-// Create a new resource.
-func (r *postureCheckDomainResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	// Retrieve values from plan
-	var eplan postureCheckDomainResourceModel
-
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func postureCheckDomainCreate(ctx context.Context, client *zitiData, eplan *postureCheckDomainResourceModel, diags *diag.Diagnostics) (string, error) {
 	name := eplan.Name.ValueString()
 	tags := TagsFromAttributes(eplan.Tags.Elements())
 
@@ -148,144 +118,21 @@ func (r *postureCheckDomainResource) Create(ctx context.Context, req resource.Cr
 		}
 	}
 
-	payload := postureCheckDomainPayload{
-		Domains:        domainAddresses,
-		Name:           &name,
-		TypeID:         "DOMAIN",
-		RoleAttributes: roleAttributes,
-		Tags:           tags,
+	payload := &rest_model.PostureCheckDomainCreate{
+		Domains: domainAddresses,
 	}
+	payload.SetName(&name)
+	payload.SetRoleAttributes(&roleAttributes)
+	payload.SetTags(tags)
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************create resource payload***********************:\n %s\n", jsonData)
-
-	authUrl := fmt.Sprintf("%s/posture-checks", r.resourceConfig.host)
-	cresp, err := CreateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti POST Response: %s", cresp)
-	log.Info().Msg(msg)
+	created, err := client.api.PostureChecks.CreatePostureCheck(&posture_checks.CreatePostureCheckParams{PostureCheck: payload, Context: ctx}, nil)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Creating posture check", "Could not Create posture check, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	fmt.Printf("**********************create response************************:\n %s\n", cresp)
-	resourceID := gjson.Get(cresp, "data.id").String()
-
-	// Map response body to schema and populate Computed attribute values
-	eplan.ID = types.StringValue(resourceID)
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
-
-	// Set state to fully populated data
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+		return "", err
 	}
+	return createdID(created.Payload)
 }
 
-// Read resource information.
-func (r *postureCheckDomainResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	// Get current state
-	var state postureCheckDomainResourceModel
-	tflog.Debug(ctx, "Reading Posture check")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	authUrl := fmt.Sprintf("%s/posture-checks/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := ReadZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		if errors.Is(err, errNotFound) {
-			msg := fmt.Sprintf("Resource not found in backend; removing from state, id: %s", state.ID.ValueString())
-			log.Info().Msg(msg)
-			resp.State.RemoveResource(ctx)
-			return
-		}
-		resp.Diagnostics.AddError(
-			"Error Reading posture check", "Could not READ posture check, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Reading posture check", fmt.Sprintf("Could not READ posture check, ERROR %v: ", err.Error()),
-		)
-		return
-	}
-
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	data, ok := jsonBody["data"].(map[string]interface{})
-	if !ok {
-		resp.Diagnostics.AddError("Error: ", "'data' is either missing or not a map[string]interface{}")
-		return
-	}
-
-	// Manually assign individual values from the map to the struct fields
-	state.Name = types.StringValue(data["name"].(string))
-
-	if domainAddresses, ok := data["domains"].([]interface{}); ok {
-		domainAddresses, diag := types.ListValueFrom(ctx, types.StringType, domainAddresses)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.Domains = domainAddresses
-	}
-
-	if roleAttributes, ok := data["roleAttributes"].([]interface{}); ok {
-		roleAttributes, diag := types.SetValueFrom(ctx, types.StringType, roleAttributes)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.RoleAttributes = roleAttributes
-	} else {
-		state.RoleAttributes = types.SetNull(types.StringType)
-	}
-
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
-	}
-
-	// Set refreshed state
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-}
-
-// Update updates the resource and sets the updated Terraform state on success.
-func (r *postureCheckDomainResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	// Retrieve values from plan
-	var eplan postureCheckDomainResourceModel
-	tflog.Debug(ctx, "Updating Posture check")
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	// Get current state
-	var state postureCheckDomainResourceModel
-	sdiags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(sdiags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func postureCheckDomainUpdate(ctx context.Context, client *zitiData, id string, eplan *postureCheckDomainResourceModel, diags *diag.Diagnostics) error {
 	name := eplan.Name.ValueString()
 	tags := TagsFromAttributes(eplan.Tags.Elements())
 
@@ -303,63 +150,26 @@ func (r *postureCheckDomainResource) Update(ctx context.Context, req resource.Up
 		}
 	}
 
-	payload := postureCheckDomainPayload{
-		Domains:        domainAddresses,
-		Name:           &name,
-		TypeID:         "DOMAIN",
-		RoleAttributes: roleAttributes,
-		Tags:           tags,
-	}
-
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************update resource payload***********************:\n %s\n", jsonData)
-
-	authUrl := fmt.Sprintf("%s/posture-checks/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := PatchZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti PATCH Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Updating posture check", "Could not Update posture check, unexpected error: "+err.Error(),
-		)
-		return
+	payload := &rest_model.PostureCheckDomainPatch{
+		Domains: domainAddresses,
 	}
+	payload.SetName(name)
+	payload.SetRoleAttributes(&roleAttributes)
+	payload.SetTags(tags)
 
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
-
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
+	_, err := client.api.PostureChecks.PatchPostureCheck(&posture_checks.PatchPostureCheckParams{ID: id, PostureCheck: payload, Context: ctx}, nil)
+	return err
 }
 
-// Delete deletes the resource and removes the Terraform state on success.
-func (r *postureCheckDomainResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	// Retrieve values from state
-	var state postureCheckDomainResourceModel
-	tflog.Debug(ctx, "Deleting Posture check")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	authUrl := fmt.Sprintf("%s/posture-checks/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
+func postureCheckDomainReadData(ctx context.Context, detail *rest_model.PostureCheckDomainDetail, state *postureCheckDomainResourceModel, diags *diag.Diagnostics) {
+	state.Name = types.StringPointerValue(detail.Name())
 
-	cresp, err := DeleteZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti Delete Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Deleting posture check", "Could not DELETE posture check, unexpected error: "+err.Error(),
-		)
-		return
+	if detail.Domains != nil {
+		domainAddresses, diag := types.ListValueFrom(ctx, types.StringType, detail.Domains)
+		diags.Append(diag...)
+		state.Domains = domainAddresses
 	}
-}
 
-func (r *postureCheckDomainResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	state.RoleAttributes = roleAttributesFromAPI(ctx, detail.RoleAttributes(), diags)
+	state.Tags = tagsFromAPI(ctx, detail.Tags(), diags)
 }
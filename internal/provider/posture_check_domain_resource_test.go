@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"testing"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccPostureCheckDomainResource(t *testing.T) {
+	controller := testAccController(t)
+	const resourceName = "ziti_posture_check_domains.test"
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_posture_check_domains" "test" {
+  name    = "test-domains"
+  domains = ["corp.example.com"]
+}
+`
+	update := testAccProviderConfig(controller, "") + `
+resource "ziti_posture_check_domains" "test" {
+  name            = "test-domains"
+  domains         = ["corp.example.com", "lab.example.com"]
+  role_attributes = ["domain-joined"]
+}
+`
+
+	steps := []resource.TestStep{{
+		Config: config,
+		Check: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckEntityExists(controller, mockcontroller.PostureChecks, resourceName),
+			resource.TestCheckResourceAttr(resourceName, "name", "test-domains"),
+			resource.TestCheckResourceAttr(resourceName, "domains.0", "corp.example.com"),
+		),
+	}}
+	steps = append(steps, testAccImportSteps(resourceName)...)
+	steps = append(steps, testAccUpdateStep(resourceName, update,
+		resource.TestCheckResourceAttr(resourceName, "domains.1", "lab.example.com"),
+		resource.TestCheckResourceAttr(resourceName, "role_attributes.#", "1"),
+	))
+	steps = append(steps, testAccOutOfBandSteps(t, controller, mockcontroller.PostureChecks, "test-domains", resourceName, update,
+		map[string]interface{}{"domains": []interface{}{"evil.example.com"}})...)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    steps,
+	})
+}
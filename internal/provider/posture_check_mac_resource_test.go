@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"testing"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccPostureCheckMacResource(t *testing.T) {
+	controller := testAccController(t)
+	const resourceName = "ziti_posture_check_mac_addresses.test"
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_posture_check_mac_addresses" "test" {
+  name          = "test-mac-addresses"
+  mac_addresses = ["00:1a:2b:3c:4d:5e"]
+}
+`
+	update := testAccProviderConfig(controller, "") + `
+resource "ziti_posture_check_mac_addresses" "test" {
+  name          = "test-mac-addresses"
+  mac_addresses = ["00:1a:2b:3c:4d:5e", "00:1a:2b:3c:4d:5f"]
+  tags          = { env = "test" }
+}
+`
+
+	steps := []resource.TestStep{{
+		Config: config,
+		Check: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckEntityExists(controller, mockcontroller.PostureChecks, resourceName),
+			resource.TestCheckResourceAttr(resourceName, "name", "test-mac-addresses"),
+			resource.TestCheckResourceAttr(resourceName, "mac_addresses.0", "00:1a:2b:3c:4d:5e"),
+		),
+	}}
+	steps = append(steps, testAccImportSteps(resourceName)...)
+	steps = append(steps, testAccUpdateStep(resourceName, update,
+		resource.TestCheckResourceAttr(resourceName, "mac_addresses.#", "2"),
+		resource.TestCheckResourceAttr(resourceName, "tags.env", "test"),
+	))
+	steps = append(steps, testAccOutOfBandSteps(t, controller, mockcontroller.PostureChecks, "test-mac-addresses", resourceName, update,
+		map[string]interface{}{"macAddresses": []interface{}{"00:00:00:00:00:00"}})...)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    steps,
+	})
+}
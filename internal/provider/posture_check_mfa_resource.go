@@ -2,14 +2,9 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
-	"fmt"
-	"net/url"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
-	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
@@ -20,10 +15,8 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/openziti/edge-api/rest_management_api_client/posture_checks"
 	"github.com/openziti/edge-api/rest_model"
-	"github.com/rs/zerolog/log"
-	"github.com/tidwall/gjson"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -35,32 +28,25 @@ var (
 
 // NewPostureCheckMFAResource is a helper function to simplify the provider implementation.
 func NewPostureCheckMFAResource() resource.Resource {
-	return &postureCheckMFAResource{}
+	return &postureCheckMFAResource{newZitiResource(zitiResourceSpec[postureCheckMFAResourceModel, *rest_model.PostureCheckMfaDetail]{
+		typeName: "_posture_check_mfa",
+		label:    "posture check",
+		create:   postureCheckMFACreate,
+		read:     readPostureCheck[*rest_model.PostureCheckMfaDetail],
+		readData: postureCheckMFAReadData,
+		update:   postureCheckMFAUpdate,
+		delete:   deletePostureCheck,
+		list:     listPostureChecks[*rest_model.PostureCheckMfaDetail],
+		entityID: func(detail *rest_model.PostureCheckMfaDetail) string {
+			return types.StringPointerValue(detail.ID()).ValueString()
+		},
+		exportFilter: `typeId = "MFA"`,
+	})}
 }
 
 // postureCheckMFAResource is the resource implementation.
 type postureCheckMFAResource struct {
-	resourceConfig *zitiData
-}
-
-// Configure adds the provider configured client to the resource.
-func (r *postureCheckMFAResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	// Add a nil check when handling ProviderData because Terraform
-	// sets that data after it calls the ConfigureProvider RPC.
-	if req.ProviderData == nil {
-		return
-	}
-
-	resourceConfig := req.ProviderData.(*zitiData)
-	r.resourceConfig = resourceConfig
-
-	fmt.Printf("Using API Token to create resource: %s\n", r.resourceConfig.apiToken)
-	fmt.Printf("Using domain to create resource: %s\n", r.resourceConfig.host)
-}
-
-// Metadata returns the resource type name.
-func (r *postureCheckMFAResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
-	resp.TypeName = req.ProviderTypeName + "_posture_check_mfa"
+	zitiResource[postureCheckMFAResourceModel, *rest_model.PostureCheckMfaDetail]
 }
 
 // postureCheckMFAResourceModel maps the resource schema data.
@@ -72,19 +58,10 @@ type postureCheckMFAResourceModel struct {
 	PromptOnWake   types.Bool   `tfsdk:"prompt_on_wake"`
 	TimeoutSeconds types.Int64  `tfsdk:"timeout_seconds"`
 	Tags           types.Map    `tfsdk:"tags"`
+	TagsAll        types.Map    `tfsdk:"tags_all"`
 	LastUpdated    types.String `tfsdk:"last_updated"`
 }
 
-type postureCheckMFAPayload struct {
-	Name           *string                     `json:"name"`
-	RoleAttributes rest_model.Attributes       `json:"roleAttributes,omitempty"`
-	TypeID         rest_model.PostureCheckType `json:"typeId"`
-	Tags           *rest_model.Tags            `json:"tags,omitempty"`
-	PromptOnUnlock bool                        `json:"promptOnUnlock,omitempty"`
-	PromptOnWake   bool                        `json:"promptOnWake,omitempty"`
-	TimeoutSeconds int64                       `json:"timeoutSeconds,omitempty"`
-}
-
 // Schema defines the schema for the resource.
 func (r *postureCheckMFAResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
@@ -140,21 +117,12 @@ func (r *postureCheckMFAResource) Schema(_ context.Context, _ resource.SchemaReq
 				Default:             mapdefault.StaticValue(types.MapNull(types.StringType)),
 				MarkdownDescription: "Posture Check Tags",
 			},
+			"tags_all": tagsAllAttribute(),
 		},
 	}
 }
 
-// Create a new resource.
-func (r *postureCheckMFAResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	// Retrieve values from plan
-	var eplan postureCheckMFAResourceModel
-
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func postureCheckMFACreate(ctx context.Context, client *zitiData, eplan *postureCheckMFAResourceModel, diags *diag.Diagnostics) (string, error) {
 	name := eplan.Name.ValueString()
 	tags := TagsFromAttributes(eplan.Tags.Elements())
 
@@ -169,156 +137,25 @@ func (r *postureCheckMFAResource) Create(ctx context.Context, req resource.Creat
 		}
 	}
 
-	payload := postureCheckMFAPayload{
-		Name:           &name,
-		TypeID:         "MFA",
-		RoleAttributes: roleAttributes,
-		Tags:           tags,
-		PromptOnUnlock: promptOnUnlock,
-		PromptOnWake:   promptOnWake,
-		TimeoutSeconds: timeoutSeconds,
-	}
-
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************create resource payload***********************:\n %s\n", jsonData)
-
-	authUrl := fmt.Sprintf("%s/posture-checks", r.resourceConfig.host)
-	cresp, err := CreateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti POST Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Creating posture check", "Could not Create posture check, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	fmt.Printf("**********************create response************************:\n %s\n", cresp)
-	resourceID := gjson.Get(cresp, "data.id").String()
-
-	// Map response body to schema and populate Computed attribute values
-	eplan.ID = types.StringValue(resourceID)
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
-
-	// Set state to fully populated data
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-}
-
-// Read resource information.
-func (r *postureCheckMFAResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	// Get current state
-	var state postureCheckMFAResourceModel
-	tflog.Debug(ctx, "Reading Posture check")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	authUrl := fmt.Sprintf("%s/posture-checks/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := ReadZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		if errors.Is(err, errNotFound) {
-			msg := fmt.Sprintf("Resource not found in backend; removing from state, id: %s", state.ID.ValueString())
-			log.Info().Msg(msg)
-			resp.State.RemoveResource(ctx)
-			return
-		}
-		resp.Diagnostics.AddError(
-			"Error Reading posture check", "Could not READ posture check, unexpected error: "+err.Error(),
-		)
-		return
+	payload := &rest_model.PostureCheckMfaCreate{
+		PostureCheckMfaProperties: rest_model.PostureCheckMfaProperties{
+			PromptOnUnlock: promptOnUnlock,
+			PromptOnWake:   promptOnWake,
+			TimeoutSeconds: timeoutSeconds,
+		},
 	}
+	payload.SetName(&name)
+	payload.SetRoleAttributes(&roleAttributes)
+	payload.SetTags(tags)
 
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
+	created, err := client.api.PostureChecks.CreatePostureCheck(&posture_checks.CreatePostureCheckParams{PostureCheck: payload, Context: ctx}, nil)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Reading posture check", fmt.Sprintf("Could not READ posture check, ERROR %v: ", err.Error()),
-		)
-		return
-	}
-
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	data, ok := jsonBody["data"].(map[string]interface{})
-	if !ok {
-		resp.Diagnostics.AddError("Error: ", "'data' is either missing or not a map[string]interface{}")
-		return
-	}
-
-	// Manually assign individual values from the map to the struct fields
-	state.Name = types.StringValue(data["name"].(string))
-
-	if timeoutSeconds, ok := data["timeoutSeconds"].(float64); ok {
-		state.TimeoutSeconds = types.Int64Value(int64(timeoutSeconds))
-	}
-
-	if promptOnWake, ok := data["promptOnWake"].(bool); ok {
-		state.PromptOnWake = types.BoolValue(promptOnWake)
-	} else {
-		state.PromptOnWake = types.BoolValue(false)
-	}
-
-	if promptOnUnlock, ok := data["promptOnUnlock"].(bool); ok {
-		state.PromptOnUnlock = types.BoolValue(promptOnUnlock)
-	} else {
-		state.PromptOnUnlock = types.BoolValue(false)
-	}
-
-	if roleAttributes, ok := data["roleAttributes"].([]interface{}); ok {
-		roleAttributes, diag := types.SetValueFrom(ctx, types.StringType, roleAttributes)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.RoleAttributes = roleAttributes
-	} else {
-		state.RoleAttributes = types.SetNull(types.StringType)
-	}
-
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
-	}
-
-	// Set refreshed state
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+		return "", err
 	}
+	return createdID(created.Payload)
 }
 
-// Update updates the resource and sets the updated Terraform state on success.
-func (r *postureCheckMFAResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	// Retrieve values from plan
-	var eplan postureCheckMFAResourceModel
-	tflog.Debug(ctx, "Updating Posture check")
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	// Get current state
-	var state postureCheckMFAResourceModel
-	sdiags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(sdiags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func postureCheckMFAUpdate(ctx context.Context, client *zitiData, id string, eplan *postureCheckMFAResourceModel, diags *diag.Diagnostics) error {
 	name := eplan.Name.ValueString()
 	tags := TagsFromAttributes(eplan.Tags.Elements())
 
@@ -333,65 +170,26 @@ func (r *postureCheckMFAResource) Update(ctx context.Context, req resource.Updat
 		}
 	}
 
-	payload := postureCheckMFAPayload{
-		Name:           &name,
-		TypeID:         "MFA",
-		RoleAttributes: roleAttributes,
-		Tags:           tags,
-		PromptOnUnlock: promptOnUnlock,
-		PromptOnWake:   promptOnWake,
-		TimeoutSeconds: timeoutSeconds,
-	}
-
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************update resource payload***********************:\n %s\n", jsonData)
-
-	authUrl := fmt.Sprintf("%s/posture-checks/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := PatchZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti PATCH Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Updating posture check", "Could not Update posture check, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
-
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-}
-
-// Delete deletes the resource and removes the Terraform state on success.
-func (r *postureCheckMFAResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	// Retrieve values from state
-	var state postureCheckMFAResourceModel
-	tflog.Debug(ctx, "Deleting Posture check")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+	payload := &rest_model.PostureCheckMfaPatch{
+		PostureCheckMfaPropertiesPatch: rest_model.PostureCheckMfaPropertiesPatch{
+			PromptOnUnlock: &promptOnUnlock,
+			PromptOnWake:   &promptOnWake,
+			TimeoutSeconds: &timeoutSeconds,
+		},
 	}
+	payload.SetName(name)
+	payload.SetRoleAttributes(&roleAttributes)
+	payload.SetTags(tags)
 
-	authUrl := fmt.Sprintf("%s/posture-checks/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-
-	cresp, err := DeleteZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti Delete Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Deleting posture check", "Could not DELETE posture check, unexpected error: "+err.Error(),
-		)
-		return
-	}
+	_, err := client.api.PostureChecks.PatchPostureCheck(&posture_checks.PatchPostureCheckParams{ID: id, PostureCheck: payload, Context: ctx}, nil)
+	return err
 }
 
-func (r *postureCheckMFAResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+func postureCheckMFAReadData(ctx context.Context, detail *rest_model.PostureCheckMfaDetail, state *postureCheckMFAResourceModel, diags *diag.Diagnostics) {
+	state.Name = types.StringPointerValue(detail.Name())
+	state.TimeoutSeconds = types.Int64Value(detail.TimeoutSeconds)
+	state.PromptOnWake = types.BoolValue(detail.PromptOnWake)
+	state.PromptOnUnlock = types.BoolValue(detail.PromptOnUnlock)
+	state.RoleAttributes = roleAttributesFromAPI(ctx, detail.RoleAttributes(), diags)
+	state.Tags = tagsFromAPI(ctx, detail.Tags(), diags)
 }
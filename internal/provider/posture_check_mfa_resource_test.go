@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"testing"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccPostureCheckMfaResource(t *testing.T) {
+	controller := testAccController(t)
+	const resourceName = "ziti_posture_check_mfa.test"
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_posture_check_mfa" "test" {
+  name            = "test-mfa"
+  timeout_seconds = 300
+}
+`
+	update := testAccProviderConfig(controller, "") + `
+resource "ziti_posture_check_mfa" "test" {
+  name             = "test-mfa"
+  timeout_seconds  = 600
+  prompt_on_unlock = true
+  prompt_on_wake   = true
+}
+`
+
+	steps := []resource.TestStep{{
+		Config: config,
+		Check: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckEntityExists(controller, mockcontroller.PostureChecks, resourceName),
+			resource.TestCheckResourceAttr(resourceName, "name", "test-mfa"),
+			resource.TestCheckResourceAttr(resourceName, "timeout_seconds", "300"),
+		),
+	}}
+	steps = append(steps, testAccImportSteps(resourceName)...)
+	steps = append(steps, testAccUpdateStep(resourceName, update,
+		resource.TestCheckResourceAttr(resourceName, "timeout_seconds", "600"),
+		resource.TestCheckResourceAttr(resourceName, "prompt_on_unlock", "true"),
+		resource.TestCheckResourceAttr(resourceName, "prompt_on_wake", "true"),
+	))
+	steps = append(steps, testAccOutOfBandSteps(t, controller, mockcontroller.PostureChecks, "test-mfa", resourceName, update,
+		map[string]interface{}{"timeoutSeconds": -1})...)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    steps,
+	})
+}
@@ -2,14 +2,11 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 
-	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/rs/zerolog/log"
+	"github.com/openziti/edge-api/rest_model"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -38,9 +35,6 @@ func (r *postureCheckMultiProcessDataSource) Configure(_ context.Context, req da
 
 	datasourceConfig := req.ProviderData.(*zitiData)
 	r.datasourceConfig = datasourceConfig
-
-	fmt.Printf("Using API Token to create datasource: %s\n", r.datasourceConfig.apiToken)
-	fmt.Printf("Using domain to create datasource: %s\n", r.datasourceConfig.host)
 }
 
 // Metadata returns the datasource type name.
@@ -127,126 +121,22 @@ func (r *postureCheckMultiProcessDataSource) Read(ctx context.Context, req datas
 		return
 	}
 
-	filter := ""
-	if state.Name.ValueString() != "" {
-		filter = "filter=name=\"" + state.Name.ValueString() + "\""
-	}
-	if state.ID.ValueString() != "" {
-		filter = "filter=id=\"" + state.ID.ValueString() + "\""
-	}
-
-	authUrl := fmt.Sprintf("%s/posture-checks?%s", r.datasourceConfig.host, filter)
-	cresp, err := ReadZitiResource(authUrl, r.datasourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Reading posture check", "Could not READ posture check, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
-	if err != nil {
-		log.Error().Msgf("Error unmarshalling JSON response from Ziti DataSource Response: %v", err)
-		fmt.Println("Error unmarshalling JSON:", err)
+	filter := dataSourceFilter(state.ID, state.Name)
+	detail, ok := lookupDataSourceEntity(ctx, r.datasourceConfig, &resp.Diagnostics, "posture check", filter, listPostureChecks[*rest_model.PostureCheckProcessMultiDetail])
+	if !ok {
 		return
 	}
 
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	service := jsonBody["data"].([]interface{})
+	state.ID = types.StringPointerValue(detail.ID())
+	state.Name = types.StringPointerValue(detail.Name())
 
-	if len(service) > 1 {
-		resp.Diagnostics.AddError(
-			"Multiple items returned from API upon filter execution!", "Try to narrow down the filter expression"+filter,
-		)
-	}
-	if len(service) == 0 {
-		resp.Diagnostics.AddError(
-			"No items returned from API upon filter execution!", "Try to relax the filter expression: "+filter,
-		)
-	}
-	if resp.Diagnostics.HasError() {
-		return
+	if detail.Semantic != nil {
+		state.Semantic = types.StringValue(string(*detail.Semantic))
 	}
 
-	data := service[0].(map[string]interface{})
-
-	state.Name = types.StringValue(data["name"].(string))
-	state.ID = types.StringValue(data["id"].(string))
-
-	if semanticValue, ok := data["semantic"].(string); ok {
-		state.Semantic = types.StringValue(semanticValue)
-	}
-
-	if procs, ok := data["processes"].([]interface{}); ok && len(procs) > 0 {
-		var processValues []attr.Value
-
-		for _, p := range procs {
-			proc, ok := p.(map[string]interface{})
-			if !ok {
-				continue
-			}
-			attrTypes := MultiProcessModel.AttrTypes
-			values := make(map[string]attr.Value)
-
-			if v, ok := proc["path"].(string); ok {
-				values["path"] = types.StringValue(v)
-			}
-
-			if v, ok := proc["osType"].(string); ok {
-				values["os_type"] = types.StringValue(v)
-			}
-
-			if fps, ok := proc["signerFingerprints"].([]interface{}); ok && len(fps) > 0 {
-				list, diag := types.ListValueFrom(ctx, types.StringType, fps)
-				resp.Diagnostics.Append(diag...)
-				values["signer_fingerprints"] = list
-			} else {
-				values["signer_fingerprints"] = types.ListNull(types.StringType)
-			}
-
-			if hashes, ok := proc["hashes"].([]interface{}); ok && len(hashes) > 0 {
-				list, diag := types.ListValueFrom(ctx, types.StringType, hashes)
-				resp.Diagnostics.Append(diag...)
-				values["hashes"] = list
-			} else {
-				values["hashes"] = types.ListNull(types.StringType)
-			}
-
-			obj, diag := types.ObjectValue(attrTypes, values)
-			resp.Diagnostics.Append(diag...)
-			processValues = append(processValues, obj)
-		}
-
-		list, diag := types.SetValue(types.ObjectType{AttrTypes: MultiProcessModel.AttrTypes}, processValues)
-		resp.Diagnostics.Append(diag...)
-
-		state.Processes = list
-	} else {
-		state.Processes = types.SetNull(types.ObjectType{AttrTypes: MultiProcessModel.AttrTypes})
-	}
-
-	if roleAttributes, ok := data["roleAttributes"].([]interface{}); ok {
-		roleAttributes, diag := types.SetValueFrom(ctx, types.StringType, roleAttributes)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.RoleAttributes = roleAttributes
-	} else {
-		state.RoleAttributes = types.SetNull(types.StringType)
-	}
-
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
-	}
+	state.Processes = multiProcessesFromAPI(ctx, detail.Processes, &resp.Diagnostics)
+	state.RoleAttributes = roleAttributesFromAPI(ctx, detail.RoleAttributes(), &resp.Diagnostics)
+	state.Tags = tagsFromAPI(ctx, detail.Tags(), &resp.Diagnostics)
 
 	// Set refreshed state
 	diags = resp.State.Set(ctx, &state)
@@ -2,15 +2,10 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
-	"fmt"
-	"net/url"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
-	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapdefault"
@@ -20,10 +15,8 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/openziti/edge-api/rest_management_api_client/posture_checks"
 	"github.com/openziti/edge-api/rest_model"
-	"github.com/rs/zerolog/log"
-	"github.com/tidwall/gjson"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -35,32 +28,25 @@ var (
 
 // NewPostureCheckMultiProcessResource is a helper function to simplify the provider implementation.
 func NewPostureCheckMultiProcessResource() resource.Resource {
-	return &postureCheckMultiProcessResource{}
+	return &postureCheckMultiProcessResource{newZitiResource(zitiResourceSpec[postureCheckMultiProcessResourceModel, *rest_model.PostureCheckProcessMultiDetail]{
+		typeName: "_posture_check_multi_process",
+		label:    "posture check",
+		create:   postureCheckMultiProcessCreate,
+		read:     readPostureCheck[*rest_model.PostureCheckProcessMultiDetail],
+		readData: postureCheckMultiProcessReadData,
+		update:   postureCheckMultiProcessUpdate,
+		delete:   deletePostureCheck,
+		list:     listPostureChecks[*rest_model.PostureCheckProcessMultiDetail],
+		entityID: func(detail *rest_model.PostureCheckProcessMultiDetail) string {
+			return types.StringPointerValue(detail.ID()).ValueString()
+		},
+		exportFilter: `typeId = "PROCESS_MULTI"`,
+	})}
 }
 
 // postureCheckMultiProcessResource is the resource implementation.
 type postureCheckMultiProcessResource struct {
-	resourceConfig *zitiData
-}
-
-// Configure adds the provider configured client to the resource.
-func (r *postureCheckMultiProcessResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	// Add a nil check when handling ProviderData because Terraform
-	// sets that data after it calls the ConfigureProvider RPC.
-	if req.ProviderData == nil {
-		return
-	}
-
-	resourceConfig := req.ProviderData.(*zitiData)
-	r.resourceConfig = resourceConfig
-
-	fmt.Printf("Using API Token to create resource: %s\n", r.resourceConfig.apiToken)
-	fmt.Printf("Using domain to create resource: %s\n", r.resourceConfig.host)
-}
-
-// Metadata returns the resource type name.
-func (r *postureCheckMultiProcessResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
-	resp.TypeName = req.ProviderTypeName + "_posture_check_multi_process"
+	zitiResource[postureCheckMultiProcessResourceModel, *rest_model.PostureCheckProcessMultiDetail]
 }
 
 var MultiProcessModel = types.ObjectType{
@@ -80,25 +66,10 @@ type postureCheckMultiProcessResourceModel struct {
 	Semantic       types.String `tfsdk:"semantic"`
 	Processes      types.Set    `tfsdk:"processes"`
 	Tags           types.Map    `tfsdk:"tags"`
+	TagsAll        types.Map    `tfsdk:"tags_all"`
 	LastUpdated    types.String `tfsdk:"last_updated"`
 }
 
-type postureCheckMultiProcessAddressPayload struct {
-	Name           *string                     `json:"name"`
-	RoleAttributes rest_model.Attributes       `json:"roleAttributes,omitempty"`
-	TypeID         rest_model.PostureCheckType `json:"typeId"`
-	Semantic       *rest_model.Semantic        `json:"semantic"`
-	Processes      []MultiProcessDTO           `json:"processes"`
-	Tags           *rest_model.Tags            `json:"tags,omitempty"`
-}
-
-type MultiProcessDTO struct {
-	Path               string    `json:"path"`
-	OsType             string    `json:"osType"`
-	Hashes             *[]string `json:"hashes,omitempty"`
-	SignerFingerprints *[]string `json:"signerFingerprints,omitempty"`
-}
-
 // Schema defines the schema for the resource.
 func (r *postureCheckMultiProcessResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
@@ -170,38 +141,29 @@ func (r *postureCheckMultiProcessResource) Schema(_ context.Context, _ resource.
 				Default:             mapdefault.StaticValue(types.MapNull(types.StringType)),
 				MarkdownDescription: "Posture Check Tags",
 			},
+			"tags_all": tagsAllAttribute(),
 		},
 	}
 }
 
-// Create a new resource.
-func (r *postureCheckMultiProcessResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	// Retrieve values from plan
-	var eplan postureCheckMultiProcessResourceModel
-
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func postureCheckMultiProcessCreate(ctx context.Context, client *zitiData, eplan *postureCheckMultiProcessResourceModel, diags *diag.Diagnostics) (string, error) {
 	name := eplan.Name.ValueString()
 	tags := TagsFromAttributes(eplan.Tags.Elements())
 	semantic := rest_model.Semantic(eplan.Semantic.ValueString())
 
-	var processes []MultiProcessDTO
+	var processes []*rest_model.ProcessMulti
 	if !eplan.Processes.IsNull() && !eplan.Processes.IsUnknown() {
 		for _, v := range eplan.Processes.Elements() {
 			if obj, ok := v.(types.Object); ok {
 				attrs := obj.Attributes()
-				dto := MultiProcessDTO{}
+				process := &rest_model.ProcessMulti{}
 
 				if v, ok := attrs["path"].(types.String); ok && !v.IsNull() && !v.IsUnknown() {
-					dto.Path = v.ValueString()
+					process.Path = v.ValueStringPointer()
 				}
 
 				if v, ok := attrs["os_type"].(types.String); ok && !v.IsNull() && !v.IsUnknown() {
-					dto.OsType = v.ValueString()
+					process.OsType = rest_model.NewOsType(rest_model.OsType(v.ValueString()))
 				}
 
 				if v, ok := attrs["hashes"].(types.List); ok && !v.IsNull() && !v.IsUnknown() {
@@ -211,7 +173,7 @@ func (r *postureCheckMultiProcessResource) Create(ctx context.Context, req resou
 							hashes = append(hashes, s.ValueString())
 						}
 					}
-					dto.Hashes = &hashes
+					process.Hashes = hashes
 				}
 
 				if v, ok := attrs["signer_fingerprints"].(types.List); ok && !v.IsNull() && !v.IsUnknown() {
@@ -221,21 +183,19 @@ func (r *postureCheckMultiProcessResource) Create(ctx context.Context, req resou
 							fps = append(fps, s.ValueString())
 						}
 					}
-					dto.SignerFingerprints = &fps
+					process.SignerFingerprints = fps
 				}
-				processes = append(processes, dto)
+				processes = append(processes, process)
 			}
 		}
 	}
 
 	for i := range processes {
 		if processes[i].Hashes == nil {
-			empty := []string{}
-			processes[i].Hashes = &empty
+			processes[i].Hashes = []string{}
 		}
 		if processes[i].SignerFingerprints == nil {
-			signerEmpty := []string{}
-			processes[i].SignerFingerprints = &signerEmpty
+			processes[i].SignerFingerprints = []string{}
 		}
 	}
 
@@ -246,207 +206,39 @@ func (r *postureCheckMultiProcessResource) Create(ctx context.Context, req resou
 		}
 	}
 
-	payload := postureCheckMultiProcessAddressPayload{
-		Processes:      processes,
-		Name:           &name,
-		TypeID:         "PROCESS_MULTI",
-		RoleAttributes: roleAttributes,
-		Semantic:       &semantic,
-		Tags:           tags,
-	}
-
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************create resource payload***********************:\n %s\n", jsonData)
-
-	authUrl := fmt.Sprintf("%s/posture-checks", r.resourceConfig.host)
-	cresp, err := CreateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti POST Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Creating posture check", "Could not Create posture check, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	fmt.Printf("**********************create response************************:\n %s\n", cresp)
-	resourceID := gjson.Get(cresp, "data.id").String()
-
-	// Map response body to schema and populate Computed attribute values
-	eplan.ID = types.StringValue(resourceID)
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
-
-	// Set state to fully populated data
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-}
-
-// Read resource information.
-func (r *postureCheckMultiProcessResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	// Get current state
-	var state postureCheckMultiProcessResourceModel
-	tflog.Debug(ctx, "Reading Posture check")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	authUrl := fmt.Sprintf("%s/posture-checks/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := ReadZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		if errors.Is(err, errNotFound) {
-			msg := fmt.Sprintf("Resource not found in backend; removing from state, id: %s", state.ID.ValueString())
-			log.Info().Msg(msg)
-			resp.State.RemoveResource(ctx)
-			return
-		}
-		resp.Diagnostics.AddError(
-			"Error Reading posture check", "Could not READ posture check, unexpected error: "+err.Error(),
-		)
-		return
+	payload := &rest_model.PostureCheckProcessMultiCreate{
+		Processes: processes,
+		Semantic:  &semantic,
 	}
+	payload.SetName(&name)
+	payload.SetRoleAttributes(&roleAttributes)
+	payload.SetTags(tags)
 
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
+	created, err := client.api.PostureChecks.CreatePostureCheck(&posture_checks.CreatePostureCheckParams{PostureCheck: payload, Context: ctx}, nil)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Reading posture check", fmt.Sprintf("Could not READ posture check, ERROR %v: ", err.Error()),
-		)
-		return
-	}
-
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	data, ok := jsonBody["data"].(map[string]interface{})
-	if !ok {
-		resp.Diagnostics.AddError("Error: ", "'data' is either missing or not a map[string]interface{}")
-		return
-	}
-
-	// Manually assign individual values from the map to the struct fields
-	state.Name = types.StringValue(data["name"].(string))
-	if semanticValue, ok := data["semantic"].(string); ok {
-		state.Semantic = types.StringValue(semanticValue)
-	}
-
-	if procs, ok := data["processes"].([]interface{}); ok && len(procs) > 0 {
-		var processValues []attr.Value
-
-		for _, p := range procs {
-			proc, ok := p.(map[string]interface{})
-			if !ok {
-				continue
-			}
-			attrTypes := MultiProcessModel.AttrTypes
-			values := make(map[string]attr.Value)
-
-			if v, ok := proc["path"].(string); ok {
-				values["path"] = types.StringValue(v)
-			}
-
-			if v, ok := proc["osType"].(string); ok {
-				values["os_type"] = types.StringValue(v)
-			}
-
-			if fps, ok := proc["signerFingerprints"].([]interface{}); ok && len(fps) > 0 {
-				list, diag := types.ListValueFrom(ctx, types.StringType, fps)
-				resp.Diagnostics.Append(diag...)
-				values["signer_fingerprints"] = list
-			} else {
-				values["signer_fingerprints"] = types.ListNull(types.StringType)
-			}
-
-			if hashes, ok := proc["hashes"].([]interface{}); ok && len(hashes) > 0 {
-				list, diag := types.ListValueFrom(ctx, types.StringType, hashes)
-				resp.Diagnostics.Append(diag...)
-				values["hashes"] = list
-			} else {
-				values["hashes"] = types.ListNull(types.StringType)
-			}
-
-			obj, diag := types.ObjectValue(attrTypes, values)
-			resp.Diagnostics.Append(diag...)
-			processValues = append(processValues, obj)
-		}
-
-		list, diag := types.SetValue(types.ObjectType{AttrTypes: MultiProcessModel.AttrTypes}, processValues)
-		resp.Diagnostics.Append(diag...)
-
-		state.Processes = list
-	} else {
-		state.Processes = types.SetNull(types.ObjectType{AttrTypes: MultiProcessModel.AttrTypes})
-	}
-
-	if roleAttributes, ok := data["roleAttributes"].([]interface{}); ok {
-		roleAttributes, diag := types.SetValueFrom(ctx, types.StringType, roleAttributes)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.RoleAttributes = roleAttributes
-	} else {
-		state.RoleAttributes = types.SetNull(types.StringType)
-	}
-
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
-	}
-
-	// Set refreshed state
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+		return "", err
 	}
+	return createdID(created.Payload)
 }
 
-// Update updates the resource and sets the updated Terraform state on success.
-func (r *postureCheckMultiProcessResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	// Retrieve values from plan
-	var eplan postureCheckMultiProcessResourceModel
-	tflog.Debug(ctx, "Updating Posture check")
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	// Get current state
-	var state postureCheckMultiProcessResourceModel
-	sdiags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(sdiags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func postureCheckMultiProcessUpdate(ctx context.Context, client *zitiData, id string, eplan *postureCheckMultiProcessResourceModel, diags *diag.Diagnostics) error {
 	name := eplan.Name.ValueString()
 	tags := TagsFromAttributes(eplan.Tags.Elements())
 	semantic := rest_model.Semantic(eplan.Semantic.ValueString())
 
-	var processes []MultiProcessDTO
+	var processes []*rest_model.ProcessMulti
 	if !eplan.Processes.IsNull() && !eplan.Processes.IsUnknown() {
 		for _, v := range eplan.Processes.Elements() {
 			if obj, ok := v.(types.Object); ok {
 				attrs := obj.Attributes()
-				dto := MultiProcessDTO{}
+				process := &rest_model.ProcessMulti{}
 
 				if v, ok := attrs["path"].(types.String); ok && !v.IsNull() && !v.IsUnknown() {
-					dto.Path = v.ValueString()
+					process.Path = v.ValueStringPointer()
 				}
 
 				if v, ok := attrs["os_type"].(types.String); ok && !v.IsNull() && !v.IsUnknown() {
-					dto.OsType = v.ValueString()
+					process.OsType = rest_model.NewOsType(rest_model.OsType(v.ValueString()))
 				}
 
 				if v, ok := attrs["hashes"].(types.List); ok && !v.IsNull() && !v.IsUnknown() {
@@ -456,7 +248,7 @@ func (r *postureCheckMultiProcessResource) Update(ctx context.Context, req resou
 							hashes = append(hashes, s.ValueString())
 						}
 					}
-					dto.Hashes = &hashes
+					process.Hashes = hashes
 				}
 
 				if v, ok := attrs["signer_fingerprints"].(types.List); ok && !v.IsNull() && !v.IsUnknown() {
@@ -466,21 +258,19 @@ func (r *postureCheckMultiProcessResource) Update(ctx context.Context, req resou
 							fps = append(fps, s.ValueString())
 						}
 					}
-					dto.SignerFingerprints = &fps
+					process.SignerFingerprints = fps
 				}
-				processes = append(processes, dto)
+				processes = append(processes, process)
 			}
 		}
 	}
 
 	for i := range processes {
 		if processes[i].Hashes == nil {
-			empty := []string{}
-			processes[i].Hashes = &empty
+			processes[i].Hashes = []string{}
 		}
 		if processes[i].SignerFingerprints == nil {
-			signerEmpty := []string{}
-			processes[i].SignerFingerprints = &signerEmpty
+			processes[i].SignerFingerprints = []string{}
 		}
 	}
 
@@ -491,64 +281,73 @@ func (r *postureCheckMultiProcessResource) Update(ctx context.Context, req resou
 		}
 	}
 
-	payload := postureCheckMultiProcessAddressPayload{
-		Processes:      processes,
-		Name:           &name,
-		TypeID:         "PROCESS_MULTI",
-		RoleAttributes: roleAttributes,
-		Semantic:       &semantic,
-		Tags:           tags,
+	payload := &rest_model.PostureCheckProcessMultiPatch{
+		Processes: processes,
+		Semantic:  semantic,
 	}
+	payload.SetName(name)
+	payload.SetRoleAttributes(&roleAttributes)
+	payload.SetTags(tags)
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************update resource payload***********************:\n %s\n", jsonData)
+	_, err := client.api.PostureChecks.PatchPostureCheck(&posture_checks.PatchPostureCheckParams{ID: id, PostureCheck: payload, Context: ctx}, nil)
+	return err
+}
 
-	authUrl := fmt.Sprintf("%s/posture-checks/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := PatchZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti PATCH Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Updating posture check", "Could not Update posture check, unexpected error: "+err.Error(),
-		)
-		return
+func postureCheckMultiProcessReadData(ctx context.Context, detail *rest_model.PostureCheckProcessMultiDetail, state *postureCheckMultiProcessResourceModel, diags *diag.Diagnostics) {
+	state.Name = types.StringPointerValue(detail.Name())
+	if detail.Semantic != nil {
+		state.Semantic = types.StringValue(string(*detail.Semantic))
 	}
 
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
-
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
+	state.Processes = multiProcessesFromAPI(ctx, detail.Processes, diags)
+	state.RoleAttributes = roleAttributesFromAPI(ctx, detail.RoleAttributes(), diags)
+	state.Tags = tagsFromAPI(ctx, detail.Tags(), diags)
 }
 
-// Delete deletes the resource and removes the Terraform state on success.
-func (r *postureCheckMultiProcessResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	// Retrieve values from state
-	var state postureCheckMultiProcessResourceModel
-	tflog.Debug(ctx, "Deleting Posture check")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+// multiProcessesFromAPI converts the processes of a fetched multi process
+// posture check to the processes attribute.
+func multiProcessesFromAPI(ctx context.Context, processes []*rest_model.ProcessMulti, diags *diag.Diagnostics) types.Set {
+	if len(processes) == 0 {
+		return types.SetNull(types.ObjectType{AttrTypes: MultiProcessModel.AttrTypes})
 	}
 
-	authUrl := fmt.Sprintf("%s/posture-checks/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
+	var processValues []attr.Value
+	for _, proc := range processes {
+		if proc == nil {
+			continue
+		}
+		values := make(map[string]attr.Value)
 
-	cresp, err := DeleteZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti Delete Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Deleting posture check", "Could not DELETE posture check, unexpected error: "+err.Error(),
-		)
-		return
+		values["path"] = types.StringPointerValue(proc.Path)
+
+		if proc.OsType != nil {
+			values["os_type"] = types.StringValue(string(*proc.OsType))
+		} else {
+			values["os_type"] = types.StringNull()
+		}
+
+		if len(proc.SignerFingerprints) > 0 {
+			list, diag := types.ListValueFrom(ctx, types.StringType, proc.SignerFingerprints)
+			diags.Append(diag...)
+			values["signer_fingerprints"] = list
+		} else {
+			values["signer_fingerprints"] = types.ListNull(types.StringType)
+		}
+
+		if len(proc.Hashes) > 0 {
+			list, diag := types.ListValueFrom(ctx, types.StringType, proc.Hashes)
+			diags.Append(diag...)
+			values["hashes"] = list
+		} else {
+			values["hashes"] = types.ListNull(types.StringType)
+		}
+
+		obj, diag := types.ObjectValue(MultiProcessModel.AttrTypes, values)
+		diags.Append(diag...)
+		processValues = append(processValues, obj)
 	}
-}
 
-func (r *postureCheckMultiProcessResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	list, diag := types.SetValue(types.ObjectType{AttrTypes: MultiProcessModel.AttrTypes}, processValues)
+	diags.Append(diag...)
+	return list
 }
@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"testing"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccPostureCheckMultiProcessResource(t *testing.T) {
+	controller := testAccController(t)
+	const resourceName = "ziti_posture_check_multi_process.test"
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_posture_check_multi_process" "test" {
+  name      = "test-multi-process"
+  semantic  = "AnyOf"
+  processes = [{ path = "/usr/bin/agent", os_type = "Linux" }]
+}
+`
+	update := testAccProviderConfig(controller, "") + `
+resource "ziti_posture_check_multi_process" "test" {
+  name     = "test-multi-process"
+  semantic = "AllOf"
+  processes = [
+    { path = "/usr/bin/agent", os_type = "Linux" },
+    { path = "/Applications/Agent.app", os_type = "macOS", hashes = ["abc123"] },
+  ]
+}
+`
+
+	steps := []resource.TestStep{{
+		Config: config,
+		Check: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckEntityExists(controller, mockcontroller.PostureChecks, resourceName),
+			resource.TestCheckResourceAttr(resourceName, "name", "test-multi-process"),
+			resource.TestCheckResourceAttr(resourceName, "processes.#", "1"),
+			resource.TestCheckTypeSetElemNestedAttrs(resourceName, "processes.*", map[string]string{"path": "/usr/bin/agent", "os_type": "Linux"}),
+		),
+	}}
+	steps = append(steps, testAccImportSteps(resourceName)...)
+	steps = append(steps, testAccUpdateStep(resourceName, update,
+		resource.TestCheckResourceAttr(resourceName, "semantic", "AllOf"),
+		resource.TestCheckResourceAttr(resourceName, "processes.#", "2"),
+	))
+	steps = append(steps, testAccOutOfBandSteps(t, controller, mockcontroller.PostureChecks, "test-multi-process", resourceName, update,
+		map[string]interface{}{"semantic": "AnyOf"})...)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    steps,
+	})
+}
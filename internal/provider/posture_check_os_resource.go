@@ -2,19 +2,14 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
-	"fmt"
-	"net/url"
 	"regexp"
 	"sort"
 	"strings"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
-	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapdefault"
@@ -24,10 +19,8 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
-	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/openziti/edge-api/rest_management_api_client/posture_checks"
 	"github.com/openziti/edge-api/rest_model"
-	"github.com/rs/zerolog/log"
-	"github.com/tidwall/gjson"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -39,32 +32,25 @@ var (
 
 // NewPostureCheckOSResource is a helper function to simplify the provider implementation.
 func NewPostureCheckOSResource() resource.Resource {
-	return &postureCheckOSResource{}
+	return &postureCheckOSResource{newZitiResource(zitiResourceSpec[postureCheckOSResourceModel, *rest_model.PostureCheckOperatingSystemDetail]{
+		typeName: "_posture_check_os",
+		label:    "posture check",
+		create:   postureCheckOSCreate,
+		read:     readPostureCheck[*rest_model.PostureCheckOperatingSystemDetail],
+		readData: postureCheckOSReadData,
+		update:   postureCheckOSUpdate,
+		delete:   deletePostureCheck,
+		list:     listPostureChecks[*rest_model.PostureCheckOperatingSystemDetail],
+		entityID: func(detail *rest_model.PostureCheckOperatingSystemDetail) string {
+			return types.StringPointerValue(detail.ID()).ValueString()
+		},
+		exportFilter: `typeId = "OS"`,
+	})}
 }
 
 // postureCheckOSResource is the resource implementation.
 type postureCheckOSResource struct {
-	resourceConfig *zitiData
-}
-
-// Configure adds the provider configured client to the resource.
-func (r *postureCheckOSResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	// Add a nil check when handling ProviderData because Terraform
-	// sets that data after it calls the ConfigureProvider RPC.
-	if req.ProviderData == nil {
-		return
-	}
-
-	resourceConfig := req.ProviderData.(*zitiData)
-	r.resourceConfig = resourceConfig
-
-	fmt.Printf("Using API Token to create resource: %s\n", r.resourceConfig.apiToken)
-	fmt.Printf("Using domain to create resource: %s\n", r.resourceConfig.host)
-}
-
-// Metadata returns the resource type name.
-func (r *postureCheckOSResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
-	resp.TypeName = req.ProviderTypeName + "_posture_check_os"
+	zitiResource[postureCheckOSResourceModel, *rest_model.PostureCheckOperatingSystemDetail]
 }
 
 var OperatingSystemModel = types.ObjectType{
@@ -81,17 +67,10 @@ type postureCheckOSResourceModel struct {
 	RoleAttributes   types.Set    `tfsdk:"role_attributes"`
 	OperatingSystems types.Set    `tfsdk:"operating_systems"`
 	Tags             types.Map    `tfsdk:"tags"`
+	TagsAll          types.Map    `tfsdk:"tags_all"`
 	LastUpdated      types.String `tfsdk:"last_updated"`
 }
 
-type postureCheckOSPayload struct {
-	Name             *string                       `json:"name"`
-	RoleAttributes   rest_model.Attributes         `json:"roleAttributes,omitempty"`
-	TypeID           rest_model.PostureCheckType   `json:"typeId"`
-	Tags             *rest_model.Tags              `json:"tags,omitempty"`
-	OperatingSystems []*rest_model.OperatingSystem `json:"operatingSystems"`
-}
-
 // Schema defines the schema for the resource.
 func (r *postureCheckOSResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
@@ -154,21 +133,12 @@ func (r *postureCheckOSResource) Schema(_ context.Context, _ resource.SchemaRequ
 				Default:             mapdefault.StaticValue(types.MapNull(types.StringType)),
 				MarkdownDescription: "Posture Check Tags",
 			},
+			"tags_all": tagsAllAttribute(),
 		},
 	}
 }
 
-// Create a new resource.
-func (r *postureCheckOSResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	// Retrieve values from plan
-	var eplan postureCheckOSResourceModel
-
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func postureCheckOSCreate(ctx context.Context, client *zitiData, eplan *postureCheckOSResourceModel, diags *diag.Diagnostics) (string, error) {
 	name := eplan.Name.ValueString()
 	tags := TagsFromAttributes(eplan.Tags.Elements())
 	operatingSystems := ElementsToListOfStructsPointers[rest_model.OperatingSystem](ctx, eplan.OperatingSystems.Elements())
@@ -180,176 +150,21 @@ func (r *postureCheckOSResource) Create(ctx context.Context, req resource.Create
 		}
 	}
 
-	payload := postureCheckOSPayload{
-		Name:             &name,
-		TypeID:           "OS",
-		RoleAttributes:   roleAttributes,
+	payload := &rest_model.PostureCheckOperatingSystemCreate{
 		OperatingSystems: operatingSystems,
-		Tags:             tags,
 	}
+	payload.SetName(&name)
+	payload.SetRoleAttributes(&roleAttributes)
+	payload.SetTags(tags)
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************create resource payload***********************:\n %s\n", jsonData)
-
-	authUrl := fmt.Sprintf("%s/posture-checks", r.resourceConfig.host)
-	cresp, err := CreateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti POST Response: %s", cresp)
-	log.Info().Msg(msg)
+	created, err := client.api.PostureChecks.CreatePostureCheck(&posture_checks.CreatePostureCheckParams{PostureCheck: payload, Context: ctx}, nil)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Creating posture check", "Could not Create posture check, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	fmt.Printf("**********************create response************************:\n %s\n", cresp)
-	resourceID := gjson.Get(cresp, "data.id").String()
-
-	// Map response body to schema and populate Computed attribute values
-	eplan.ID = types.StringValue(resourceID)
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
-
-	// Set state to fully populated data
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-}
-
-// Read resource information.
-func (r *postureCheckOSResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	// Get current state
-	var state postureCheckOSResourceModel
-	tflog.Debug(ctx, "Reading Posture check")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	authUrl := fmt.Sprintf("%s/posture-checks/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := ReadZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		if errors.Is(err, errNotFound) {
-			msg := fmt.Sprintf("Resource not found in backend; removing from state, id: %s", state.ID.ValueString())
-			log.Info().Msg(msg)
-			resp.State.RemoveResource(ctx)
-			return
-		}
-		resp.Diagnostics.AddError(
-			"Error Reading posture check", "Could not READ posture check, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Reading posture check", fmt.Sprintf("Could not READ posture check, ERROR %v: ", err.Error()),
-		)
-		return
-	}
-
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	data, ok := jsonBody["data"].(map[string]interface{})
-	if !ok {
-		resp.Diagnostics.AddError("Error: ", "'data' is either missing or not a map[string]interface{}")
-		return
-	}
-
-	// Manually assign individual values from the map to the struct fields
-	state.Name = types.StringValue(data["name"].(string))
-
-	if osList, ok := data["operatingSystems"].([]interface{}); ok && len(osList) > 0 {
-		objects := make([]attr.Value, 0, len(osList))
-
-		for _, osItem := range osList {
-			osMap := osItem.(map[string]interface{})
-
-			versionStrs := []string{}
-			if vList, ok := osMap["versions"].([]interface{}); ok && len(vList) > 0 {
-				for _, v := range vList {
-					versionStrs = append(versionStrs, strings.TrimSpace(fmt.Sprintf("%v", v)))
-				}
-				sort.Strings(versionStrs)
-			}
-
-			var versionsList types.List
-			if len(versionStrs) == 0 {
-				versionsList = types.ListNull(types.StringType)
-			} else {
-				versionsList, _ = types.ListValueFrom(ctx, types.StringType, versionStrs)
-			}
-
-			osType := strings.TrimSpace(osMap["type"].(string))
-
-			objectMap := map[string]attr.Value{
-				"type":     types.StringValue(osType),
-				"versions": versionsList,
-			}
-
-			obj, _ := basetypes.NewObjectValue(OperatingSystemModel.AttrTypes, objectMap)
-			objects = append(objects, obj)
-		}
-
-		osSetValue, _ := types.SetValueFrom(ctx, OperatingSystemModel, objects)
-		state.OperatingSystems = osSetValue
-	} else {
-		state.OperatingSystems = types.SetNull(OperatingSystemModel)
-	}
-
-	if roleAttributes, ok := data["roleAttributes"].([]interface{}); ok {
-		roleAttributes, diag := types.SetValueFrom(ctx, types.StringType, roleAttributes)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.RoleAttributes = roleAttributes
-	} else {
-		state.RoleAttributes = types.SetNull(types.StringType)
-	}
-
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
-	}
-
-	// Set refreshed state
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+		return "", err
 	}
+	return createdID(created.Payload)
 }
 
-// Update updates the resource and sets the updated Terraform state on success.
-func (r *postureCheckOSResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	// Retrieve values from plan
-	var eplan postureCheckOSResourceModel
-	tflog.Debug(ctx, "Updating Posture check")
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	// Get current state
-	var state postureCheckOSResourceModel
-	sdiags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(sdiags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func postureCheckOSUpdate(ctx context.Context, client *zitiData, id string, eplan *postureCheckOSResourceModel, diags *diag.Diagnostics) error {
 	name := eplan.Name.ValueString()
 	tags := TagsFromAttributes(eplan.Tags.Elements())
 	operatingSystems := ElementsToListOfStructsPointers[rest_model.OperatingSystem](ctx, eplan.OperatingSystems.Elements())
@@ -361,63 +176,68 @@ func (r *postureCheckOSResource) Update(ctx context.Context, req resource.Update
 		}
 	}
 
-	payload := postureCheckOSPayload{
-		Name:             &name,
-		TypeID:           "OS",
-		RoleAttributes:   roleAttributes,
+	payload := &rest_model.PostureCheckOperatingSystemPatch{
 		OperatingSystems: operatingSystems,
-		Tags:             tags,
 	}
+	payload.SetName(name)
+	payload.SetRoleAttributes(&roleAttributes)
+	payload.SetTags(tags)
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************update resource payload***********************:\n %s\n", jsonData)
+	_, err := client.api.PostureChecks.PatchPostureCheck(&posture_checks.PatchPostureCheckParams{ID: id, PostureCheck: payload, Context: ctx}, nil)
+	return err
+}
 
-	authUrl := fmt.Sprintf("%s/posture-checks/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := PatchZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti PATCH Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Updating posture check", "Could not Update posture check, unexpected error: "+err.Error(),
-		)
-		return
+func postureCheckOSReadData(ctx context.Context, detail *rest_model.PostureCheckOperatingSystemDetail, state *postureCheckOSResourceModel, diags *diag.Diagnostics) {
+	state.Name = types.StringPointerValue(detail.Name())
+	state.OperatingSystems = operatingSystemsFromAPI(ctx, detail.OperatingSystems, diags)
+	state.RoleAttributes = roleAttributesFromAPI(ctx, detail.RoleAttributes(), diags)
+	state.Tags = tagsFromAPI(ctx, detail.Tags(), diags)
+}
+
+// operatingSystemsFromAPI converts the operating systems of a fetched OS
+// posture check to the operating_systems attribute.
+func operatingSystemsFromAPI(ctx context.Context, operatingSystems []*rest_model.OperatingSystem, diags *diag.Diagnostics) types.Set {
+	if len(operatingSystems) == 0 {
+		return types.SetNull(OperatingSystemModel)
 	}
 
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
+	objects := make([]attr.Value, 0, len(operatingSystems))
+	for _, operatingSystem := range operatingSystems {
+		if operatingSystem == nil {
+			continue
+		}
 
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-}
+		versionStrs := []string{}
+		for _, v := range operatingSystem.Versions {
+			versionStrs = append(versionStrs, strings.TrimSpace(v))
+		}
+		sort.Strings(versionStrs)
 
-// Delete deletes the resource and removes the Terraform state on success.
-func (r *postureCheckOSResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	// Retrieve values from state
-	var state postureCheckOSResourceModel
-	tflog.Debug(ctx, "Deleting Posture check")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
+		var versionsList types.List
+		if len(versionStrs) == 0 {
+			versionsList = types.ListNull(types.StringType)
+		} else {
+			list, diag := types.ListValueFrom(ctx, types.StringType, versionStrs)
+			diags.Append(diag...)
+			versionsList = list
+		}
+
+		var osType string
+		if operatingSystem.Type != nil {
+			osType = strings.TrimSpace(string(*operatingSystem.Type))
+		}
 
-	authUrl := fmt.Sprintf("%s/posture-checks/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
+		objectMap := map[string]attr.Value{
+			"type":     types.StringValue(osType),
+			"versions": versionsList,
+		}
 
-	cresp, err := DeleteZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti Delete Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Deleting posture check", "Could not DELETE posture check, unexpected error: "+err.Error(),
-		)
-		return
+		obj, diag := basetypes.NewObjectValue(OperatingSystemModel.AttrTypes, objectMap)
+		diags.Append(diag...)
+		objects = append(objects, obj)
 	}
-}
 
-func (r *postureCheckOSResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	osSetValue, diag := types.SetValueFrom(ctx, OperatingSystemModel, objects)
+	diags.Append(diag...)
+	return osSetValue
 }
@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"testing"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccPostureCheckOsResource(t *testing.T) {
+	controller := testAccController(t)
+	const resourceName = "ziti_posture_check_os.test"
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_posture_check_os" "test" {
+  name              = "test-os"
+  operating_systems = [{ type = "Linux", versions = [">=5.0.0"] }]
+}
+`
+	update := testAccProviderConfig(controller, "") + `
+resource "ziti_posture_check_os" "test" {
+  name            = "test-os"
+  role_attributes = ["managed"]
+  operating_systems = [
+    { type = "Linux", versions = [">=5.0.0"] },
+    { type = "Windows", versions = [">=10.0.19041"] },
+  ]
+}
+`
+
+	steps := []resource.TestStep{{
+		Config: config,
+		Check: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckEntityExists(controller, mockcontroller.PostureChecks, resourceName),
+			resource.TestCheckResourceAttr(resourceName, "name", "test-os"),
+			resource.TestCheckTypeSetElemNestedAttrs(resourceName, "operating_systems.*", map[string]string{"type": "Linux", "versions.0": ">=5.0.0"}),
+		),
+	}}
+	steps = append(steps, testAccImportSteps(resourceName)...)
+	steps = append(steps, testAccUpdateStep(resourceName, update,
+		resource.TestCheckResourceAttr(resourceName, "role_attributes.#", "1"),
+		resource.TestCheckResourceAttr(resourceName, "operating_systems.#", "2"),
+	))
+	steps = append(steps, testAccOutOfBandSteps(t, controller, mockcontroller.PostureChecks, "test-os", resourceName, update,
+		map[string]interface{}{"operatingSystems": []interface{}{
+			map[string]interface{}{"type": "Linux", "versions": []interface{}{">=4.0.0"}},
+		}})...)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    steps,
+	})
+}
@@ -2,15 +2,10 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
-	"fmt"
-	"net/url"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
-	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapdefault"
@@ -19,10 +14,8 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/openziti/edge-api/rest_management_api_client/posture_checks"
 	"github.com/openziti/edge-api/rest_model"
-	"github.com/rs/zerolog/log"
-	"github.com/tidwall/gjson"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -34,32 +27,25 @@ var (
 
 // NewPostureCheckProcessResource is a helper function to simplify the provider implementation.
 func NewPostureCheckProcessResource() resource.Resource {
-	return &postureCheckProcessResource{}
+	return &postureCheckProcessResource{newZitiResource(zitiResourceSpec[postureCheckProcessResourceModel, *rest_model.PostureCheckProcessDetail]{
+		typeName: "_posture_check_process",
+		label:    "posture check",
+		create:   postureCheckProcessCreate,
+		read:     readPostureCheck[*rest_model.PostureCheckProcessDetail],
+		readData: postureCheckProcessReadData,
+		update:   postureCheckProcessUpdate,
+		delete:   deletePostureCheck,
+		list:     listPostureChecks[*rest_model.PostureCheckProcessDetail],
+		entityID: func(detail *rest_model.PostureCheckProcessDetail) string {
+			return types.StringPointerValue(detail.ID()).ValueString()
+		},
+		exportFilter: `typeId = "PROCESS"`,
+	})}
 }
 
 // postureCheckProcessResource is the resource implementation.
 type postureCheckProcessResource struct {
-	resourceConfig *zitiData
-}
-
-// Configure adds the provider configured client to the resource.
-func (r *postureCheckProcessResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	// Add a nil check when handling ProviderData because Terraform
-	// sets that data after it calls the ConfigureProvider RPC.
-	if req.ProviderData == nil {
-		return
-	}
-
-	resourceConfig := req.ProviderData.(*zitiData)
-	r.resourceConfig = resourceConfig
-
-	fmt.Printf("Using API Token to create resource: %s\n", r.resourceConfig.apiToken)
-	fmt.Printf("Using domain to create resource: %s\n", r.resourceConfig.host)
-}
-
-// Metadata returns the resource type name.
-func (r *postureCheckProcessResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
-	resp.TypeName = req.ProviderTypeName + "_posture_check_process"
+	zitiResource[postureCheckProcessResourceModel, *rest_model.PostureCheckProcessDetail]
 }
 
 var ProcessModel = types.ObjectType{
@@ -78,24 +64,10 @@ type postureCheckProcessResourceModel struct {
 	RoleAttributes types.Set    `tfsdk:"role_attributes"`
 	Process        types.Object `tfsdk:"process"`
 	Tags           types.Map    `tfsdk:"tags"`
+	TagsAll        types.Map    `tfsdk:"tags_all"`
 	LastUpdated    types.String `tfsdk:"last_updated"`
 }
 
-type postureCheckProcessAddressPayload struct {
-	Name           *string                     `json:"name"`
-	RoleAttributes rest_model.Attributes       `json:"roleAttributes,omitempty"`
-	TypeID         rest_model.PostureCheckType `json:"typeId"`
-	Process        *ProcessDTO                 `json:"process"`
-	Tags           *rest_model.Tags            `json:"tags,omitempty"`
-}
-
-type ProcessDTO struct {
-	Path              string    `json:"path"`
-	OsType            string    `json:"osType"`
-	Hashes            *[]string `json:"hashes,omitempty"`
-	SignerFingerprint *string   `json:"signerFingerprint,omitempty"`
-}
-
 // Schema defines the schema for the resource.
 func (r *postureCheckProcessResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
@@ -155,30 +127,20 @@ func (r *postureCheckProcessResource) Schema(_ context.Context, _ resource.Schem
 				Default:             mapdefault.StaticValue(types.MapNull(types.StringType)),
 				MarkdownDescription: "Posture Check Tags",
 			},
+			"tags_all": tagsAllAttribute(),
 		},
 	}
 }
 
-// Create a new resource.
-func (r *postureCheckProcessResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	// Retrieve values from plan
-	var eplan postureCheckProcessResourceModel
-
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func postureCheckProcessCreate(ctx context.Context, client *zitiData, eplan *postureCheckProcessResourceModel, diags *diag.Diagnostics) (string, error) {
 	name := eplan.Name.ValueString()
 	tags := TagsFromAttributes(eplan.Tags.Elements())
 
-	var process ProcessDTO
+	var process rest_model.Process
 	GenericFromObject(convertKeysToCamel(AttributesToNativeTypes(ctx, eplan.Process.Attributes())), &process)
 
 	if process.Hashes == nil {
-		emptyHashes := []string{}
-		process.Hashes = &emptyHashes
+		process.Hashes = []string{}
 	}
 	var roleAttributes rest_model.Attributes
 	for _, value := range eplan.RoleAttributes.Elements() {
@@ -187,179 +149,28 @@ func (r *postureCheckProcessResource) Create(ctx context.Context, req resource.C
 		}
 	}
 
-	payload := postureCheckProcessAddressPayload{
-		Process:        &process,
-		Name:           &name,
-		TypeID:         "PROCESS",
-		RoleAttributes: roleAttributes,
-		Tags:           tags,
+	payload := &rest_model.PostureCheckProcessCreate{
+		Process: &process,
 	}
+	payload.SetName(&name)
+	payload.SetRoleAttributes(&roleAttributes)
+	payload.SetTags(tags)
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************create resource payload***********************:\n %s\n", jsonData)
-
-	authUrl := fmt.Sprintf("%s/posture-checks", r.resourceConfig.host)
-	cresp, err := CreateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti POST Response: %s", cresp)
-	log.Info().Msg(msg)
+	created, err := client.api.PostureChecks.CreatePostureCheck(&posture_checks.CreatePostureCheckParams{PostureCheck: payload, Context: ctx}, nil)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Creating posture check", "Could not Create posture check, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	fmt.Printf("**********************create response************************:\n %s\n", cresp)
-	resourceID := gjson.Get(cresp, "data.id").String()
-
-	// Map response body to schema and populate Computed attribute values
-	eplan.ID = types.StringValue(resourceID)
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
-
-	// Set state to fully populated data
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-}
-
-// Read resource information.
-func (r *postureCheckProcessResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	// Get current state
-	var state postureCheckProcessResourceModel
-	tflog.Debug(ctx, "Reading Posture check")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	authUrl := fmt.Sprintf("%s/posture-checks/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := ReadZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		if errors.Is(err, errNotFound) {
-			msg := fmt.Sprintf("Resource not found in backend; removing from state, id: %s", state.ID.ValueString())
-			log.Info().Msg(msg)
-			resp.State.RemoveResource(ctx)
-			return
-		}
-		resp.Diagnostics.AddError(
-			"Error Reading posture check", "Could not READ posture check, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Reading posture check", fmt.Sprintf("Could not READ posture check, ERROR %v: ", err.Error()),
-		)
-		return
-	}
-
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	data, ok := jsonBody["data"].(map[string]interface{})
-	if !ok {
-		resp.Diagnostics.AddError("Error: ", "'data' is either missing or not a map[string]interface{}")
-		return
-	}
-
-	// Manually assign individual values from the map to the struct fields
-	state.Name = types.StringValue(data["name"].(string))
-
-	if proc, ok := data["process"].(map[string]interface{}); ok {
-		attrTypes := ProcessModel.AttrTypes
-		values := make(map[string]attr.Value)
-
-		if v, ok := proc["path"].(string); ok {
-			values["path"] = types.StringValue(v)
-		}
-
-		if v, ok := proc["osType"].(string); ok {
-			values["os_type"] = types.StringValue(v)
-		}
-
-		if v, ok := proc["signerFingerprint"].(string); ok && v != "" {
-			values["signer_fingerprint"] = types.StringValue(v)
-		} else {
-			values["signer_fingerprint"] = types.StringNull()
-		}
-
-		if hashes, ok := proc["hashes"].([]interface{}); ok && len(hashes) > 0 {
-			list, diag := types.ListValueFrom(ctx, types.StringType, hashes)
-			resp.Diagnostics.Append(diag...)
-			values["hashes"] = list
-		} else {
-			values["hashes"] = types.ListNull(types.StringType)
-		}
-
-		obj, _ := types.ObjectValue(attrTypes, values)
-		state.Process = obj
-
-	} else {
-		state.Process = types.ObjectNull(ProcessModel.AttrTypes)
-	}
-
-	if roleAttributes, ok := data["roleAttributes"].([]interface{}); ok {
-		roleAttributes, diag := types.SetValueFrom(ctx, types.StringType, roleAttributes)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.RoleAttributes = roleAttributes
-	} else {
-		state.RoleAttributes = types.SetNull(types.StringType)
-	}
-
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
-	}
-
-	// Set refreshed state
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+		return "", err
 	}
+	return createdID(created.Payload)
 }
 
-// Update updates the resource and sets the updated Terraform state on success.
-func (r *postureCheckProcessResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	// Retrieve values from plan
-	var eplan postureCheckProcessResourceModel
-	tflog.Debug(ctx, "Updating Posture check")
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	// Get current state
-	var state postureCheckProcessResourceModel
-	sdiags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(sdiags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func postureCheckProcessUpdate(ctx context.Context, client *zitiData, id string, eplan *postureCheckProcessResourceModel, diags *diag.Diagnostics) error {
 	name := eplan.Name.ValueString()
 	tags := TagsFromAttributes(eplan.Tags.Elements())
 
-	var process ProcessDTO
+	var process rest_model.Process
 	GenericFromObject(convertKeysToCamel(AttributesToNativeTypes(ctx, eplan.Process.Attributes())), &process)
 	if process.Hashes == nil {
-		emptyHashes := []string{}
-		process.Hashes = &emptyHashes
+		process.Hashes = []string{}
 	}
 
 	var roleAttributes rest_model.Attributes
@@ -369,63 +180,55 @@ func (r *postureCheckProcessResource) Update(ctx context.Context, req resource.U
 		}
 	}
 
-	payload := postureCheckProcessAddressPayload{
-		Process:        &process,
-		Name:           &name,
-		TypeID:         "PROCESS",
-		RoleAttributes: roleAttributes,
-		Tags:           tags,
+	payload := &rest_model.PostureCheckProcessPatch{
+		Process: &process,
 	}
+	payload.SetName(name)
+	payload.SetRoleAttributes(&roleAttributes)
+	payload.SetTags(tags)
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************update resource payload***********************:\n %s\n", jsonData)
+	_, err := client.api.PostureChecks.PatchPostureCheck(&posture_checks.PatchPostureCheckParams{ID: id, PostureCheck: payload, Context: ctx}, nil)
+	return err
+}
 
-	authUrl := fmt.Sprintf("%s/posture-checks/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := PatchZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti PATCH Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Updating posture check", "Could not Update posture check, unexpected error: "+err.Error(),
-		)
-		return
+func postureCheckProcessReadData(ctx context.Context, detail *rest_model.PostureCheckProcessDetail, state *postureCheckProcessResourceModel, diags *diag.Diagnostics) {
+	state.Name = types.StringPointerValue(detail.Name())
+	state.Process = processFromAPI(ctx, detail.Process, diags)
+	state.RoleAttributes = roleAttributesFromAPI(ctx, detail.RoleAttributes(), diags)
+	state.Tags = tagsFromAPI(ctx, detail.Tags(), diags)
+}
+
+// processFromAPI converts the process of a fetched process posture check to the
+// process attribute.
+func processFromAPI(ctx context.Context, proc *rest_model.Process, diags *diag.Diagnostics) types.Object {
+	if proc == nil {
+		return types.ObjectNull(ProcessModel.AttrTypes)
 	}
+	values := make(map[string]attr.Value)
 
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
+	values["path"] = types.StringPointerValue(proc.Path)
 
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+	if proc.OsType != nil {
+		values["os_type"] = types.StringValue(string(*proc.OsType))
+	} else {
+		values["os_type"] = types.StringNull()
 	}
-}
 
-// Delete deletes the resource and removes the Terraform state on success.
-func (r *postureCheckProcessResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	// Retrieve values from state
-	var state postureCheckProcessResourceModel
-	tflog.Debug(ctx, "Deleting Posture check")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+	if proc.SignerFingerprint != "" {
+		values["signer_fingerprint"] = types.StringValue(proc.SignerFingerprint)
+	} else {
+		values["signer_fingerprint"] = types.StringNull()
 	}
 
-	authUrl := fmt.Sprintf("%s/posture-checks/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-
-	cresp, err := DeleteZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti Delete Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Deleting posture check", "Could not DELETE posture check, unexpected error: "+err.Error(),
-		)
-		return
+	if len(proc.Hashes) > 0 {
+		list, diag := types.ListValueFrom(ctx, types.StringType, proc.Hashes)
+		diags.Append(diag...)
+		values["hashes"] = list
+	} else {
+		values["hashes"] = types.ListNull(types.StringType)
 	}
-}
 
-func (r *postureCheckProcessResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	obj, diag := types.ObjectValue(ProcessModel.AttrTypes, values)
+	diags.Append(diag...)
+	return obj
 }
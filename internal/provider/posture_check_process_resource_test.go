@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"testing"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccPostureCheckProcessResource(t *testing.T) {
+	controller := testAccController(t)
+	const resourceName = "ziti_posture_check_process.test"
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_posture_check_process" "test" {
+  name    = "test-process"
+  process = { path = "/usr/bin/agent", os_type = "Linux" }
+}
+`
+	update := testAccProviderConfig(controller, "") + `
+resource "ziti_posture_check_process" "test" {
+  name = "test-process"
+  process = {
+    path               = "/usr/bin/agent"
+    os_type            = "Linux"
+    hashes             = ["abc123"]
+    signer_fingerprint = "def456"
+  }
+}
+`
+
+	steps := []resource.TestStep{{
+		Config: config,
+		Check: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckEntityExists(controller, mockcontroller.PostureChecks, resourceName),
+			resource.TestCheckResourceAttr(resourceName, "name", "test-process"),
+			resource.TestCheckResourceAttr(resourceName, "process.path", "/usr/bin/agent"),
+			resource.TestCheckResourceAttr(resourceName, "process.os_type", "Linux"),
+		),
+	}}
+	steps = append(steps, testAccImportSteps(resourceName)...)
+	steps = append(steps, testAccUpdateStep(resourceName, update,
+		resource.TestCheckResourceAttr(resourceName, "process.hashes.0", "abc123"),
+		resource.TestCheckResourceAttr(resourceName, "process.signer_fingerprint", "def456"),
+	))
+	steps = append(steps, testAccOutOfBandSteps(t, controller, mockcontroller.PostureChecks, "test-process", resourceName, update,
+		map[string]interface{}{"process": map[string]interface{}{"path": "/usr/bin/other", "osType": "Linux"}})...)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    steps,
+	})
+}
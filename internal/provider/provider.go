@@ -3,32 +3,45 @@ package provider
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/rs/zerolog/log"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/openziti/edge-api/rest_management_api_client"
 	"github.com/tidwall/gjson"
+	"go.mozilla.org/pkcs7"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ provider.Provider = &zitiProvider{}
+	_ provider.Provider                       = &zitiProvider{}
+	_ provider.ProviderWithEphemeralResources = &zitiProvider{}
 )
 
 // New is a helper function to simplify provider server and testing implementation.
@@ -54,9 +67,39 @@ func (p *zitiProvider) Metadata(_ context.Context, _ provider.MetadataRequest, r
 	resp.Version = p.version
 }
 
+// zitiData is the provider client shared by every resource and data source.
+// The session may be replaced concurrently when it expires or the controller
+// fails over, so activeHost and apiToken must only be accessed through session,
+// reauthenticate and failover.
 type zitiData struct {
-	apiToken string
-	host     string
+	// host is the controller URL resources build request URLs from. Requests
+	// are sent to activeHost instead once an HA failover has happened.
+	host string
+
+	// httpClient is the pooled, retrying client used for every call to the
+	// controller. Its transport carries the verified TLS configuration.
+	httpClient *retryablehttp.Client
+
+	// authenticate runs the auth flow selected in Configure against a controller.
+	authenticate func(host string) (string, error)
+
+	// router follows the leader of an HA cluster; nil for a single controller.
+	router *clusterRouter
+
+	// api is the typed management API client. Its requests are sent through
+	// httpClient with the current session, like every other request.
+	api *rest_management_api_client.ZitiEdgeManagement
+
+	// tags holds the default_tags and ignore_tags of the provider.
+	tags tagsConfig
+
+	// failoverMu serializes failovers, so parallel requests that fail on the
+	// same controller share one. It is never held together with mu.
+	failoverMu sync.Mutex
+
+	mu         sync.RWMutex
+	activeHost string
+	apiToken   string
 }
 
 // Schema defines the provider-level schema for configuration data.
@@ -71,7 +114,7 @@ func (p *zitiProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp
 			"hosts": schema.ListAttribute{
 				Optional:            true,
 				ElementType:         types.StringType,
-				MarkdownDescription: "List of Ziti controller Host/Domain URLs for HA failover. First successful authentication wins. Finds and prefers the leader",
+				MarkdownDescription: "List of Ziti controller Host/Domain URLs for HA failover. First successful authentication wins. Finds and prefers the leader, and follows it when leadership moves during a run.",
 			},
 			"username": schema.StringAttribute{
 				Optional:            true,
@@ -82,6 +125,15 @@ func (p *zitiProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp
 				Sensitive:           true,
 				MarkdownDescription: "Ziti Session password (password auth). Env: ZITI_API_PASSWORD.",
 			},
+			"jwt": schema.StringAttribute{
+				Optional:            true,
+				Sensitive:           true,
+				MarkdownDescription: "JWT issued by an identity provider trusted through an external JWT signer (ext-jwt auth). Env: ZITI_API_JWT.",
+			},
+			"jwt_file": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Path to a file containing a JWT for ext-jwt auth. The file is re-read whenever the session is renewed, so rotated workload identity tokens are picked up. Conflicts with `jwt`. Env: ZITI_API_JWT_FILE.",
+			},
 			"identity_file": schema.StringAttribute{
 				Optional:            true,
 				MarkdownDescription: "Path to a Ziti identity JSON file containing cert/key/ca PEM material for mTLS authentication. Env: ZITI_API_IDENTITY_FILE.",
@@ -103,40 +155,152 @@ func (p *zitiProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp
 			},
 			"ca": schema.StringAttribute{
 				Optional:            true,
-				MarkdownDescription: "PEM-encoded CA certificate used to verify the Ziti controller's server certificate.",
+				MarkdownDescription: "PEM-encoded CA certificate bundle used to verify the Ziti controller's server certificate. Overrides the CA from the identity JSON.",
+			},
+			"ca_file": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Path to a PEM-encoded CA certificate bundle used to verify the Ziti controller's server certificate. Conflicts with `ca`. Env: ZITI_API_CA_FILE.",
+			},
+			"bootstrap_ca": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "When no `ca` is configured, fetch the controller's CA bundle from `/.well-known/est/cacerts` on first contact and trust only that bundle for the rest of the run. Env: ZITI_API_BOOTSTRAP_CA.",
+			},
+			"ca_fingerprint": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Expected SHA-256 fingerprint (hex, colons optional) of a certificate in the bootstrapped CA bundle. The bundle is rejected when no certificate matches; otherwise only that certificate, and the CAs of the bundle it signed, are trusted. Env: ZITI_API_CA_FINGERPRINT.",
+			},
+			"max_retries": schema.Int64Attribute{
+				Optional:            true,
+				Validators:          []validator.Int64{int64validator.AtLeast(0)},
+				MarkdownDescription: "Maximum number of retries for a controller request that failed with a connection error, 429, 502, 503 or 504. Defaults to `4`.",
+			},
+			"retry_wait_min": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Minimum time to wait before retrying a controller request, as a Go duration (e.g. `500ms`). Defaults to `1s`. A `Retry-After` header on 429 and 503 responses takes precedence.",
+			},
+			"retry_wait_max": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Maximum time to wait between retries of a controller request, as a Go duration (e.g. `30s`). Defaults to `30s`.",
+			},
+			"request_timeout": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Timeout for a single controller request attempt, as a Go duration (e.g. `1m`). Defaults to `30s`.",
+			},
+			"max_concurrent_requests": schema.Int64Attribute{
+				Optional:            true,
+				Validators:          []validator.Int64{int64validator.AtLeast(0)},
+				MarkdownDescription: "Maximum number of requests in flight against the controller, across all resources. `0` (default) means unlimited.",
+			},
+			"requests_per_second": schema.Float64Attribute{
+				Optional:            true,
+				Validators:          []validator.Float64{float64validator.AtLeast(0)},
+				MarkdownDescription: "Maximum rate of requests sent to the controller, across all resources. `0` (default) means unlimited.",
+			},
+			"insecure": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Skip verification of the Ziti controller's server certificate. Not recommended; credentials are sent over unverified TLS. Env: ZITI_API_INSECURE.",
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"default_tags": schema.SingleNestedBlock{
+				MarkdownDescription: "Tags set on every resource that has `tags`. A resource's own `tags` override a default tag with the same key; `tags_all` holds the merged tags.",
+				Attributes: map[string]schema.Attribute{
+					"tags": schema.MapAttribute{
+						Optional:            true,
+						ElementType:         types.StringType,
+						MarkdownDescription: "Tags to set on every resource, e.g. `{ managed_by = \"terraform\" }`.",
+					},
+				},
+			},
+			"ignore_tags": schema.SingleNestedBlock{
+				MarkdownDescription: "Tags managed by other tooling. They are left out of `tags` and `tags_all`, so they cause no drift, and kept on updates. Tags set in `tags` or `default_tags` are managed even if they match.",
+				Attributes: map[string]schema.Attribute{
+					"keys": schema.SetAttribute{
+						Optional:            true,
+						ElementType:         types.StringType,
+						MarkdownDescription: "Tag keys to ignore.",
+					},
+					"key_prefixes": schema.SetAttribute{
+						Optional:            true,
+						ElementType:         types.StringType,
+						MarkdownDescription: "Tag key prefixes to ignore; every tag whose key starts with one of them is ignored.",
+					},
+				},
 			},
 		},
 	}
 }
 
+// authMethod identifies how the provider authenticates against the controller.
+type authMethod int
+
+const (
+	authMethodPassword authMethod = iota
+	authMethodCert
+	authMethodJWT
+)
+
 // zitiProviderModel maps provider schema data to a Go type.
 type zitiProviderModel struct {
-	Host         types.String `tfsdk:"host"`
-	Hosts        types.List   `tfsdk:"hosts"`
-	Username     types.String `tfsdk:"username"`
-	Password     types.String `tfsdk:"password"`
-	IdentityFile types.String `tfsdk:"identity_file"`
-	IdentityJSON types.String `tfsdk:"identity_json"`
-	Cert         types.String `tfsdk:"cert"`
-	Key          types.String `tfsdk:"key"`
-	CA           types.String `tfsdk:"ca"`
+	Host                  types.String      `tfsdk:"host"`
+	Hosts                 types.List        `tfsdk:"hosts"`
+	Username              types.String      `tfsdk:"username"`
+	Password              types.String      `tfsdk:"password"`
+	JWT                   types.String      `tfsdk:"jwt"`
+	JWTFile               types.String      `tfsdk:"jwt_file"`
+	IdentityFile          types.String      `tfsdk:"identity_file"`
+	IdentityJSON          types.String      `tfsdk:"identity_json"`
+	Cert                  types.String      `tfsdk:"cert"`
+	Key                   types.String      `tfsdk:"key"`
+	CA                    types.String      `tfsdk:"ca"`
+	CAFile                types.String      `tfsdk:"ca_file"`
+	BootstrapCA           types.Bool        `tfsdk:"bootstrap_ca"`
+	CAFingerprint         types.String      `tfsdk:"ca_fingerprint"`
+	Insecure              types.Bool        `tfsdk:"insecure"`
+	MaxRetries            types.Int64       `tfsdk:"max_retries"`
+	RetryWaitMin          types.String      `tfsdk:"retry_wait_min"`
+	RetryWaitMax          types.String      `tfsdk:"retry_wait_max"`
+	RequestTimeout        types.String      `tfsdk:"request_timeout"`
+	MaxConcurrentRequests types.Int64       `tfsdk:"max_concurrent_requests"`
+	RequestsPerSecond     types.Float64     `tfsdk:"requests_per_second"`
+	DefaultTags           *defaultTagsModel `tfsdk:"default_tags"`
+	IgnoreTags            *ignoreTagsModel  `tfsdk:"ignore_tags"`
+}
+
+// buildTLSConfig returns the TLS configuration used for every call to the
+// controller. The server certificate is verified against caPEM when set, or the
+// system trust store otherwise, unless insecure is true. When certPEM and keyPEM
+// are set the client certificate is presented for mTLS.
+func buildTLSConfig(caPEM, certPEM, keyPEM string, insecure bool) (*tls.Config, error) {
+	tlsCfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: insecure,
+	}
+	if certPEM != "" && keyPEM != "" {
+		clientCert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse client certificate/key: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{clientCert}
+	}
+	if caPEM != "" && !insecure {
+		pool := x509.NewCertPool()
+		if ok := pool.AppendCertsFromPEM([]byte(caPEM)); !ok {
+			return nil, fmt.Errorf("no valid PEM certificates found in CA bundle")
+		}
+		tlsCfg.RootCAs = pool
+	}
+	return tlsCfg, nil
 }
 
 // tryAuthenticate authenticates against a single Ziti controller and returns the session token.
-func tryAuthenticate(host, username, password string) (string, error) {
+func tryAuthenticate(httpClient *http.Client, host, username, password string) (string, error) {
 	payload := map[string]interface{}{
 		"username": username,
 		"password": password,
 	}
 	jsonData, _ := json.Marshal(payload)
 	authUrl := fmt.Sprintf("%s/authenticate?method=password", host)
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-	}
-	httpClient := &http.Client{
-		Transport: transport,
-		Timeout:   15 * time.Second,
-	}
 	creq, err := http.NewRequest("POST", authUrl, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", fmt.Errorf("failed to build auth request: %w", err)
@@ -162,27 +326,9 @@ func tryAuthenticate(host, username, password string) (string, error) {
 }
 
 // tryAuthenticateCert authenticates using an mTLS client certificate (?method=cert)
-// and returns the session token. When caPEM is non-empty the server certificate is
-// verified against that CA; otherwise InsecureSkipVerify is used (consistent with
-// the existing password-auth behaviour).
-func tryAuthenticateCert(host, certPEM, keyPEM, caPEM string) (string, error) {
-	clientCert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
-	if err != nil {
-		return "", fmt.Errorf("failed to parse client certificate/key: %w", err)
-	}
-	tlsCfg := &tls.Config{
-		Certificates:       []tls.Certificate{clientCert},
-		InsecureSkipVerify: true,
-	}
-	if caPEM != "" {
-		pool := x509.NewCertPool()
-		if ok := pool.AppendCertsFromPEM([]byte(caPEM)); ok {
-			tlsCfg.RootCAs = pool
-			tlsCfg.InsecureSkipVerify = false
-		}
-	}
-	transport := &http.Transport{TLSClientConfig: tlsCfg}
-	httpClient := &http.Client{Transport: transport, Timeout: 15 * time.Second}
+// and returns the session token. The client certificate is presented by the TLS
+// configuration of httpClient, as built by buildTLSConfig.
+func tryAuthenticateCert(httpClient *http.Client, host string) (string, error) {
 
 	authURL := fmt.Sprintf("%s/authenticate?method=cert", host)
 	creq, err := http.NewRequest("POST", authURL, bytes.NewBufferString("{}"))
@@ -209,6 +355,36 @@ func tryAuthenticateCert(host, certPEM, keyPEM, caPEM string) (string, error) {
 	return token, nil
 }
 
+// tryAuthenticateJWT authenticates with a JWT from an identity provider trusted by
+// an external JWT signer (?method=ext-jwt) and returns the session token.
+func tryAuthenticateJWT(httpClient *http.Client, host, jwt string) (string, error) {
+
+	authURL := fmt.Sprintf("%s/authenticate?method=ext-jwt", host)
+	creq, err := http.NewRequest("POST", authURL, bytes.NewBufferString("{}"))
+	if err != nil {
+		return "", fmt.Errorf("failed to build ext-jwt auth request: %w", err)
+	}
+	creq.Header.Add("Content-Type", "application/json")
+	creq.Header.Add("Authorization", "Bearer "+jwt)
+	cresp, err := httpClient.Do(creq)
+	if err != nil {
+		return "", fmt.Errorf("ext-jwt auth request failed: %w", err)
+	}
+	body, err := io.ReadAll(cresp.Body)
+	defer cresp.Body.Close()
+	if err != nil {
+		return "", fmt.Errorf("error reading ext-jwt auth response: %w", err)
+	}
+	if cresp.StatusCode != http.StatusOK && cresp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("status %d: %s", cresp.StatusCode, string(body))
+	}
+	token := gjson.GetBytes(body, "data.token").String()
+	if token == "" {
+		return "", fmt.Errorf("no token returned in ext-jwt auth response")
+	}
+	return token, nil
+}
+
 // parsePemFromZitiIdentity extracts cert, key, and ca PEM strings from a Ziti
 // identity JSON file. The file stores each PEM blob prefixed with "pem:", which
 // is stripped before returning.
@@ -222,65 +398,134 @@ func parsePemFromZitiIdentity(jsonData string) (cert, key, ca string, err error)
 	return cert, key, ca, nil
 }
 
-type clusterMember struct {
-	Address   string `json:"address"`
-	Connected bool   `json:"connected"`
-	ID        string `json:"id"`
-	Leader    bool   `json:"leader"`
-	ReadOnly  bool   `json:"readOnly"`
-	Version   string `json:"version"`
-	Voter     bool   `json:"voter"`
-}
-
-// fetchClusterMembers calls /fabric/v1/cluster/list-members and returns the member list.
-func fetchClusterMembers(activeHost, token string) ([]clusterMember, error) {
-	u, err := url.Parse(activeHost)
+// fetchWellKnownCA downloads the controller's CA bundle from
+// /.well-known/est/cacerts (base64 PKCS#7) with httpClient and returns it PEM
+// encoded. The endpoint is read without verification, so the bundle is only
+// accepted when it validates the certificate the controller presented.
+//
+// When expectedFingerprint is set, the certificate with that SHA-256
+// fingerprint is the only root: the controller's chain must end at it, and the
+// other certificates of the bundle are kept only if they chain to it. A bundle
+// that merely contains the pinned CA next to another root is not trusted.
+func fetchWellKnownCA(httpClient *http.Client, host, expectedFingerprint string) (string, error) {
+	u, err := url.Parse(host)
 	if err != nil {
-		return nil, fmt.Errorf("invalid host URL: %w", err)
-	}
-	clusterURL := fmt.Sprintf("%s://%s/fabric/v1/cluster/list-members", u.Scheme, u.Host)
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		return "", fmt.Errorf("invalid host URL: %w", err)
 	}
-	httpClient := &http.Client{Transport: transport, Timeout: 15 * time.Second}
-	req, err := http.NewRequest("GET", clusterURL, nil)
+	caURL := fmt.Sprintf("%s://%s/.well-known/est/cacerts", u.Scheme, u.Host)
+	req, err := http.NewRequest("GET", caURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build cluster request: %w", err)
+		return "", fmt.Errorf("failed to build CA bundle request: %w", err)
 	}
-	req.Header.Set("zt-session", token)
+	req.Header.Set("Accept", "application/pkcs7-mime")
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("cluster request failed: %w", err)
+		return "", fmt.Errorf("CA bundle request failed: %w", err)
 	}
 	body, err := io.ReadAll(resp.Body)
 	defer resp.Body.Close()
 	if err != nil {
-		return nil, fmt.Errorf("error reading cluster response: %w", err)
+		return "", fmt.Errorf("error reading CA bundle response: %w", err)
 	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("cluster list-members status %d: %s", resp.StatusCode, string(body))
+		return "", fmt.Errorf("CA bundle status %d: %s", resp.StatusCode, string(body))
+	}
+
+	certificates, err := parseCABundle(body)
+	if err != nil {
+		return "", err
+	}
+
+	if expectedFingerprint != "" {
+		certificates, err = pinnedCertificates(certificates, expectedFingerprint)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	pool := x509.NewCertPool()
+	var bundle strings.Builder
+	for _, cert := range certificates {
+		pool.AddCert(cert)
+		_ = pem.Encode(&bundle, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	}
+
+	if resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
+		return "", fmt.Errorf("controller did not present a TLS certificate")
 	}
-	var result struct {
-		Data []clusterMember `json:"data"`
+	intermediates := x509.NewCertPool()
+	for _, cert := range resp.TLS.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
 	}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("error parsing cluster response: %w", err)
+	if _, err := resp.TLS.PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName:       u.Hostname(),
+		Roots:         pool,
+		Intermediates: intermediates,
+	}); err != nil {
+		return "", fmt.Errorf("controller certificate is not trusted by its CA bundle: %w", err)
 	}
-	return result.Data, nil
+
+	return bundle.String(), nil
 }
 
-// memberToHost converts a cluster member tls address (e.g. "tls:HOST:PORT") to
-// an https URL preserving the base path of the original host.
-func memberToHost(address, originalHost string) (string, error) {
-	addr := strings.TrimPrefix(address, "tls:")
-	u, err := url.Parse(originalHost)
+// pinnedCertificates returns the certificate of certificates with the SHA-256
+// fingerprint expectedFingerprint, followed by the CA certificates that chain
+// to it. The others are dropped.
+func pinnedCertificates(certificates []*x509.Certificate, expectedFingerprint string) ([]*x509.Certificate, error) {
+	want := strings.ToLower(strings.ReplaceAll(expectedFingerprint, ":", ""))
+	var pinned *x509.Certificate
+	for _, cert := range certificates {
+		sum := sha256.Sum256(cert.Raw)
+		if hex.EncodeToString(sum[:]) == want {
+			pinned = cert
+			break
+		}
+	}
+	if pinned == nil {
+		return nil, fmt.Errorf("no certificate in CA bundle matches fingerprint %s", expectedFingerprint)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(pinned)
+	intermediates := x509.NewCertPool()
+	for _, cert := range certificates {
+		intermediates.AddCert(cert)
+	}
+	trusted := []*x509.Certificate{pinned}
+	for _, cert := range certificates {
+		if cert == pinned || !cert.IsCA {
+			continue
+		}
+		if _, err := cert.Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		}); err == nil {
+			trusted = append(trusted, cert)
+		}
+	}
+	return trusted, nil
+}
+
+// parseCABundle decodes a CA bundle served by /.well-known/est/cacerts, base64
+// encoded PKCS#7, into its certificates.
+func parseCABundle(body []byte) ([]*x509.Certificate, error) {
+	der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(body)), ""))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding CA bundle: %w", err)
+	}
+	p7, err := pkcs7.Parse(der)
 	if err != nil {
-		return "", fmt.Errorf("invalid original host: %w", err)
+		return nil, fmt.Errorf("error parsing CA bundle: %w", err)
+	}
+	if len(p7.Certificates) == 0 {
+		return nil, fmt.Errorf("CA bundle contains no certificates")
 	}
-	return fmt.Sprintf("https://%s%s", addr, u.Path), nil
+	return p7.Certificates, nil
 }
 
 func (p *zitiProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	ctx = withLogMasking(ctx)
 	tflog.Info(ctx, "Configuring ziti client")
 	var config zitiProviderModel
 	diags := req.Config.Get(ctx, &config)
@@ -288,6 +533,15 @@ func (p *zitiProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx = withLogMasking(ctx,
+		config.Password.ValueString(),
+		config.JWT.ValueString(),
+		config.Key.ValueString(),
+		os.Getenv("ZITI_API_PASSWORD"),
+		os.Getenv("ZITI_API_JWT"),
+	)
+	ctx = newLogSubsystem(ctx, logSubsystemClient)
+	ctx = newLogSubsystem(ctx, logSubsystemCluster)
 
 	for _, attr := range []struct {
 		unknown bool
@@ -298,11 +552,25 @@ func (p *zitiProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 		{config.Hosts.IsUnknown(), "hosts", "Unknown ziti API Hosts"},
 		{config.Username.IsUnknown(), "username", "Unknown ziti API Username"},
 		{config.Password.IsUnknown(), "password", "Unknown ziti API Password"},
+		{config.JWT.IsUnknown(), "jwt", "Unknown ziti API JWT"},
+		{config.JWTFile.IsUnknown(), "jwt_file", "Unknown ziti API JWT file"},
 		{config.IdentityFile.IsUnknown(), "identity_file", "Unknown ziti identity_file"},
 		{config.IdentityJSON.IsUnknown(), "identity_json", "Unknown ziti identity_json"},
 		{config.Cert.IsUnknown(), "cert", "Unknown ziti cert"},
 		{config.Key.IsUnknown(), "key", "Unknown ziti key"},
 		{config.CA.IsUnknown(), "ca", "Unknown ziti ca"},
+		{config.CAFile.IsUnknown(), "ca_file", "Unknown ziti ca_file"},
+		{config.BootstrapCA.IsUnknown(), "bootstrap_ca", "Unknown ziti bootstrap_ca"},
+		{config.CAFingerprint.IsUnknown(), "ca_fingerprint", "Unknown ziti ca_fingerprint"},
+		{config.Insecure.IsUnknown(), "insecure", "Unknown ziti insecure"},
+		{config.MaxRetries.IsUnknown(), "max_retries", "Unknown ziti max_retries"},
+		{config.RetryWaitMin.IsUnknown(), "retry_wait_min", "Unknown ziti retry_wait_min"},
+		{config.RetryWaitMax.IsUnknown(), "retry_wait_max", "Unknown ziti retry_wait_max"},
+		{config.RequestTimeout.IsUnknown(), "request_timeout", "Unknown ziti request_timeout"},
+		{config.MaxConcurrentRequests.IsUnknown(), "max_concurrent_requests", "Unknown ziti max_concurrent_requests"},
+		{config.RequestsPerSecond.IsUnknown(), "requests_per_second", "Unknown ziti requests_per_second"},
+		{config.DefaultTags != nil && !mapKnown(config.DefaultTags.Tags), "default_tags", "Unknown ziti default_tags"},
+		{config.IgnoreTags != nil && (!setKnown(config.IgnoreTags.Keys) || !setKnown(config.IgnoreTags.KeyPrefixes)), "ignore_tags", "Unknown ziti ignore_tags"},
 	} {
 		if attr.unknown {
 			resp.Diagnostics.AddAttributeError(
@@ -315,6 +583,10 @@ func (p *zitiProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	tags := newTagsConfig(ctx, config.DefaultTags, config.IgnoreTags, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	// --- Resolve cert material -------------------------------------------------
 	// Resolution order: identity_file > identity_json > explicit cert/key/ca fields.
@@ -360,11 +632,129 @@ func (p *zitiProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 		caPEM = config.CA.ValueString()
 	}
 
-	useCertAuth := certPEM != "" && keyPEM != ""
+	// --- Resolve the controller trust store ------------------------------------
+	// 'ca' and 'ca_file' (or ZITI_API_CA_FILE) override the CA from the identity
+	// JSON. Verification is only skipped when 'insecure' (or ZITI_API_INSECURE)
+	// is set explicitly.
+	caFile := os.Getenv("ZITI_API_CA_FILE")
+	if !config.CAFile.IsNull() {
+		if !config.CA.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("ca_file"),
+				"Conflicting ziti CA configuration",
+				"Only one of 'ca' and 'ca_file' may be set.",
+			)
+			return
+		}
+		caFile = config.CAFile.ValueString()
+	}
+	if caFile != "" && config.CA.IsNull() {
+		data, err := os.ReadFile(caFile)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("ca_file"),
+				"Failed to read CA file",
+				err.Error(),
+			)
+			return
+		}
+		caPEM = string(data)
+	}
+
+	insecure := false
+	if v := os.Getenv("ZITI_API_INSECURE"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("insecure"),
+				"Invalid ZITI_API_INSECURE value",
+				err.Error(),
+			)
+			return
+		}
+		insecure = parsed
+	}
+	if !config.Insecure.IsNull() {
+		insecure = config.Insecure.ValueBool()
+	}
+	if insecure {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("insecure"),
+			"Ziti controller TLS verification is disabled",
+			"The provider will not verify the controller's server certificate. Configure 'ca', 'ca_file' or 'bootstrap_ca' instead.",
+		)
+	}
+
+	bootstrapCA := false
+	if v := os.Getenv("ZITI_API_BOOTSTRAP_CA"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("bootstrap_ca"),
+				"Invalid ZITI_API_BOOTSTRAP_CA value",
+				err.Error(),
+			)
+			return
+		}
+		bootstrapCA = parsed
+	}
+	if !config.BootstrapCA.IsNull() {
+		bootstrapCA = config.BootstrapCA.ValueBool()
+	}
+	caFingerprint := os.Getenv("ZITI_API_CA_FINGERPRINT")
+	if !config.CAFingerprint.IsNull() {
+		caFingerprint = config.CAFingerprint.ValueString()
+	}
+
+	// --- Resolve the ext-jwt token ---------------------------------------------
+	// 'jwt' (or ZITI_API_JWT) is used as is; 'jwt_file' (or ZITI_API_JWT_FILE) is
+	// read on every authentication so that rotated tokens are picked up.
+	jwt := os.Getenv("ZITI_API_JWT")
+	jwtFile := os.Getenv("ZITI_API_JWT_FILE")
+	if !config.JWT.IsNull() {
+		if !config.JWTFile.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("jwt_file"),
+				"Conflicting ziti JWT configuration",
+				"Only one of 'jwt' and 'jwt_file' may be set.",
+			)
+			return
+		}
+		jwt = config.JWT.ValueString()
+		jwtFile = ""
+	}
+	if !config.JWTFile.IsNull() {
+		jwtFile = config.JWTFile.ValueString()
+		jwt = ""
+	}
+	readJWT := func() (string, error) {
+		if jwtFile == "" {
+			return jwt, nil
+		}
+		data, err := os.ReadFile(jwtFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read JWT file: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	// --- Select the authentication method --------------------------------------
+	// Selection order: certificate (mTLS) > ext-jwt > username/password.
+	authMethod := authMethodPassword
+	switch {
+	case certPEM != "" && keyPEM != "":
+		authMethod = authMethodCert
+	case jwt != "" || jwtFile != "":
+		authMethod = authMethodJWT
+		if _, err := readJWT(); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("jwt_file"), "Failed to read JWT file", err.Error())
+			return
+		}
+	}
 
-	// --- Resolve username/password (only required when not using cert auth) ----
+	// --- Resolve username/password (only required for password auth) ----------
 	var username, password string
-	if !useCertAuth {
+	if authMethod == authMethodPassword {
 		username = os.Getenv("ZITI_API_USERNAME")
 		password = os.Getenv("ZITI_API_PASSWORD")
 		if !config.Username.IsNull() {
@@ -378,7 +768,7 @@ func (p *zitiProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 				path.Root("username"),
 				"Missing ziti API Username",
 				"Provide 'username' (or ZITI_API_USERNAME) when not using certificate authentication "+
-					"(identity_file, identity_json, or cert/key).",
+					"(identity_file, identity_json, or cert/key) or JWT authentication (jwt or jwt_file).",
 			)
 		}
 		if password == "" {
@@ -386,7 +776,7 @@ func (p *zitiProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 				path.Root("password"),
 				"Missing ziti API Password",
 				"Provide 'password' (or ZITI_API_PASSWORD) when not using certificate authentication "+
-					"(identity_file, identity_json, or cert/key).",
+					"(identity_file, identity_json, or cert/key) or JWT authentication (jwt or jwt_file).",
 			)
 		}
 		if resp.Diagnostics.HasError() {
@@ -439,19 +829,123 @@ func (p *zitiProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 		return
 	}
 
-	if useCertAuth {
+	// --- Options of the shared HTTP client -------------------------------------
+	clientOpts := httpClientOptions{
+		maxRetries:     4,
+		retryWaitMin:   1 * time.Second,
+		retryWaitMax:   30 * time.Second,
+		requestTimeout: 30 * time.Second,
+	}
+	if !config.MaxRetries.IsNull() {
+		clientOpts.maxRetries = int(config.MaxRetries.ValueInt64())
+	}
+	if !config.MaxConcurrentRequests.IsNull() {
+		clientOpts.maxConcurrentRequests = int(config.MaxConcurrentRequests.ValueInt64())
+	}
+	if !config.RequestsPerSecond.IsNull() {
+		clientOpts.requestsPerSecond = config.RequestsPerSecond.ValueFloat64()
+	}
+	for _, d := range []struct {
+		value types.String
+		name  string
+		dst   *time.Duration
+	}{
+		{config.RetryWaitMin, "retry_wait_min", &clientOpts.retryWaitMin},
+		{config.RetryWaitMax, "retry_wait_max", &clientOpts.retryWaitMax},
+		{config.RequestTimeout, "request_timeout", &clientOpts.requestTimeout},
+	} {
+		if d.value.IsNull() {
+			continue
+		}
+		parsed, err := time.ParseDuration(d.value.ValueString())
+		if err != nil || parsed < 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root(d.name),
+				"Invalid ziti "+d.name,
+				fmt.Sprintf("Expected a non-negative duration such as \"30s\", got %q.", d.value.ValueString()),
+			)
+			continue
+		}
+		*d.dst = parsed
+	}
+	if clientOpts.retryWaitMin > clientOpts.retryWaitMax {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry_wait_min"),
+			"Invalid ziti retry_wait_min",
+			"'retry_wait_min' must not be greater than 'retry_wait_max'.",
+		)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// --- Bootstrap the trust store from the controller when requested ---------
+	// The well-known CA bundle is fetched once, from the first controller that
+	// answers, and pinned for every later call.
+	if bootstrapCA && caPEM == "" && !insecure {
+		// The bundle is read before the controller can be verified, so the
+		// request skips verification; fetchWellKnownCA checks the presented
+		// chain against the bundle instead.
+		bootstrapTLS, err := buildTLSConfig("", certPEM, keyPEM, true)
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to build ziti TLS configuration", err.Error())
+			return
+		}
+		bootstrapClient := newHTTPClient(bootstrapTLS, clientOpts).StandardClient()
+		var bootstrapErrs []string
+		for _, h := range allHosts {
+			bundle, err := fetchWellKnownCA(bootstrapClient, h, caFingerprint)
+			if err != nil {
+				tflog.SubsystemWarn(ctx, logSubsystemClient, "Failed to bootstrap CA bundle from Ziti controller", map[string]any{"host": h, "error": err.Error()})
+				bootstrapErrs = append(bootstrapErrs, fmt.Sprintf("%s: %v", h, err))
+				continue
+			}
+			caPEM = bundle
+			break
+		}
+		if caPEM == "" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("bootstrap_ca"),
+				"Failed to bootstrap the Ziti controller CA bundle",
+				strings.Join(bootstrapErrs, "; "),
+			)
+			return
+		}
+	}
+
+	tlsCfg, err := buildTLSConfig(caPEM, certPEM, keyPEM, insecure)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to build ziti TLS configuration", err.Error())
+		return
+	}
+
+	sharedClient := newHTTPClient(tlsCfg, clientOpts)
+	authClient := sharedClient.StandardClient()
+
+	switch authMethod {
+	case authMethodCert:
 		tflog.Debug(ctx, "Creating ziti client using certificate (mTLS) authentication")
-	} else {
+	case authMethodJWT:
+		tflog.Debug(ctx, "Creating ziti client using external JWT authentication")
+	default:
 		ctx = tflog.SetField(ctx, "ziti_username", username)
 		tflog.Debug(ctx, "Creating ziti client using password authentication")
 	}
 
 	// authenticate is a unified wrapper that selects the right auth method.
 	authenticate := func(h string) (string, error) {
-		if useCertAuth {
-			return tryAuthenticateCert(h, certPEM, keyPEM, caPEM)
+		switch authMethod {
+		case authMethodCert:
+			return tryAuthenticateCert(authClient, h)
+		case authMethodJWT:
+			token, err := readJWT()
+			if err != nil {
+				return "", err
+			}
+			return tryAuthenticateJWT(authClient, h, token)
+		default:
+			return tryAuthenticate(authClient, h, username, password)
 		}
-		return tryAuthenticate(h, username, password)
 	}
 
 	// Try each controller in order; use the first one that authenticates.
@@ -460,7 +954,7 @@ func (p *zitiProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 	for _, h := range allHosts {
 		token, err := authenticate(h)
 		if err != nil {
-			log.Warn().Msgf("Failed to authenticate with Ziti controller %s: %v", h, err)
+			tflog.SubsystemWarn(ctx, logSubsystemClient, "Failed to authenticate with Ziti controller", map[string]any{"host": h, "error": err.Error()})
 			authErrs = append(authErrs, fmt.Sprintf("%s: %v", h, err))
 			continue
 		}
@@ -478,36 +972,21 @@ func (p *zitiProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 	}
 
 	// When multiple hosts are configured, discover cluster members and re-authenticate
-	// against each one, preferring the leader.
+	// against each one, preferring the leader. The member list is kept for the rest
+	// of the run so that requests can follow the leader and fail over.
+	var router *clusterRouter
 	if multiHost {
-		members, err := fetchClusterMembers(activeHost, zitiToken)
-		if err != nil {
-			log.Warn().Msgf("Could not fetch cluster members from %s: %v", activeHost, err)
+		router = newClusterRouter(authClient, allHosts)
+		if err := router.refresh(activeHost, zitiToken); err != nil {
+			tflog.SubsystemWarn(ctx, logSubsystemCluster, "Could not fetch cluster members", map[string]any{"host": activeHost, "error": err.Error()})
 		} else {
-			// Sort so the leader is tried first.
-			leaderFirst := make([]clusterMember, 0, len(members))
-			for _, m := range members {
-				if m.Leader {
-					leaderFirst = append([]clusterMember{m}, leaderFirst...)
-				} else {
-					leaderFirst = append(leaderFirst, m)
-				}
-			}
-			for _, m := range leaderFirst {
-				if !m.Connected {
-					continue
-				}
-				memberHost, err := memberToHost(m.Address, activeHost)
-				if err != nil {
-					log.Warn().Msgf("Skipping cluster member %s (bad address %q): %v", m.ID, m.Address, err)
-					continue
-				}
+			for _, memberHost := range router.candidates(ctx, activeHost, "") {
 				token, err := authenticate(memberHost)
 				if err != nil {
-					log.Warn().Msgf("Auth failed for cluster member %s (%s): %v", m.ID, memberHost, err)
+					tflog.SubsystemWarn(ctx, logSubsystemCluster, "Auth failed for cluster member", map[string]any{"host": memberHost, "error": err.Error()})
 					continue
 				}
-				log.Info().Msgf("Using cluster member %s (leader=%v) at %s", m.ID, m.Leader, memberHost)
+				tflog.SubsystemInfo(ctx, logSubsystemCluster, "Using cluster member", map[string]any{"host": memberHost, "leader": memberHost == router.leaderHost(activeHost)})
 				activeHost = memberHost
 				zitiToken = token
 				break
@@ -515,15 +994,24 @@ func (p *zitiProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 		}
 	}
 
-	fmt.Printf("Using zitiToken: %s\n", zitiToken)
-
 	resourceData := zitiData{
-		apiToken: zitiToken,
-		host:     activeHost,
+		host:         activeHost,
+		httpClient:   sharedClient,
+		authenticate: authenticate,
+		router:       router,
+		activeHost:   activeHost,
+		apiToken:     zitiToken,
+		tags:         tags,
+	}
+	resourceData.api, err = newManagementClient(&resourceData)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to build the Ziti management API client", err.Error())
+		return
 	}
 
 	resp.DataSourceData = &resourceData
 	resp.ResourceData = &resourceData
+	resp.EphemeralResourceData = &resourceData
 
 	tflog.Info(ctx, "Configured ziti client", map[string]any{"success": true, "host": activeHost})
 }
@@ -537,6 +1025,15 @@ func (p *zitiProvider) DataSources(_ context.Context) []func() datasource.DataSo
 		NewEdgeRouterDataSource,
 		NewServiceDataSource,
 		NewIdentityDataSource,
+		NewIdentitiesDataSource,
+		NewServicesDataSource,
+		NewServicePoliciesDataSource,
+		NewEdgeRoutersDataSource,
+		NewConfigsDataSource,
+		NewIdentityRoleAttributesDataSource,
+		NewServiceRoleAttributesDataSource,
+		NewEdgeRouterRoleAttributesDataSource,
+		NewPostureCheckRoleAttributesDataSource,
 		NewInterceptV1ConfigDataSource,
 		NewHostV1ConfigDataSource,
 		NewHostV2ConfigDataSource,
@@ -552,6 +1049,13 @@ func (p *zitiProvider) DataSources(_ context.Context) []func() datasource.DataSo
 	}
 }
 
+// EphemeralResources defines the ephemeral resources implemented in the provider.
+func (p *zitiProvider) EphemeralResources(_ context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		NewEnrollmentTokenEphemeralResource,
+	}
+}
+
 // Resources defines the resources implemented in the provider.
 func (p *zitiProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
@@ -560,6 +1064,7 @@ func (p *zitiProvider) Resources(_ context.Context) []func() resource.Resource {
 		NewIdentityUpdbResource,
 		NewIdentityCaResource,
 		NewIdentityNoneResource,
+		NewIdentityEnrollmentResource,
 		NewServicePolicyResource,
 		NewServiceEdgeRouterPolicyResource,
 		NewEdgeRouterResource,
@@ -0,0 +1,179 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+// testAccProtoV6ProviderFactories are used to instantiate the provider during
+// acceptance testing. The factory is called for every Terraform command, which
+// then reattaches to the provider server it creates.
+var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"ziti": providerserver.NewProtocol6WithError(New("test")()),
+}
+
+// testAccController starts a fake controller for an acceptance test. The test
+// is skipped unless TF_ACC is set, as it runs Terraform; use make testacc.
+func testAccController(t *testing.T) *mockcontroller.Server {
+	t.Helper()
+	if os.Getenv(resource.EnvTfAcc) == "" {
+		t.Skipf("acceptance tests are skipped unless %s is set", resource.EnvTfAcc)
+	}
+	return mockcontroller.New(t)
+}
+
+// testAccProviderConfig configures the provider against controller. arguments
+// are added to the provider block, e.g. a default_tags block.
+func testAccProviderConfig(controller *mockcontroller.Server, arguments string) string {
+	return fmt.Sprintf(`
+provider "ziti" {
+  host     = %q
+  username = %q
+  password = %q
+%s
+}
+`, controller.ManagementURL(), mockcontroller.DefaultUsername, mockcontroller.DefaultPassword, arguments)
+}
+
+// testAccImportSteps import resourceName by id, by "name=<name>" and by bare
+// name, and verify the imported state against the applied one. ignore lists
+// attributes the controller does not return, so they cannot be imported.
+func testAccImportSteps(resourceName string, ignore ...string) []resource.TestStep {
+	ignore = append([]string{"last_updated"}, ignore...)
+	steps := []resource.TestStep{{
+		ResourceName:            resourceName,
+		ImportState:             true,
+		ImportStateVerify:       true,
+		ImportStateVerifyIgnore: ignore,
+	}}
+	for _, prefix := range []string{"name=", ""} {
+		steps = append(steps, resource.TestStep{
+			ResourceName:            resourceName,
+			ImportState:             true,
+			ImportStateIdFunc:       testAccImportStateIDByName(resourceName, prefix),
+			ImportStateVerify:       true,
+			ImportStateVerifyIgnore: ignore,
+		})
+	}
+	return steps
+}
+
+// testAccImportStateIDByName returns the name of resourceName, after prefix,
+// as the import ID.
+func testAccImportStateIDByName(resourceName, prefix string) resource.ImportStateIdFunc {
+	return func(s *terraform.State) (string, error) {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return "", fmt.Errorf("%s is not in state", resourceName)
+		}
+		return prefix + rs.Primary.Attributes["name"], nil
+	}
+}
+
+// testAccUpdateStep applies config, expecting it to update resourceName in
+// place rather than replace it.
+func testAccUpdateStep(resourceName, config string, checks ...resource.TestCheckFunc) resource.TestStep {
+	return resource.TestStep{
+		Config: config,
+		ConfigPlanChecks: resource.ConfigPlanChecks{
+			PreApply: []plancheck.PlanCheck{plancheck.ExpectResourceAction(resourceName, plancheck.ResourceActionUpdate)},
+		},
+		Check: resource.ComposeAggregateTestCheckFunc(checks...),
+	}
+}
+
+// testAccOutOfBandSteps patch the entity named name out of band, expecting
+// config to update resourceName in place, then delete it, expecting config to
+// create it again.
+func testAccOutOfBandSteps(t *testing.T, controller *mockcontroller.Server, collection, name, resourceName, config string, patch map[string]interface{}) []resource.TestStep {
+	return []resource.TestStep{
+		{
+			PreConfig: func() {
+				controller.PatchEntity(collection, testAccEntityID(t, controller, collection, name), patch)
+			},
+			Config: config,
+			ConfigPlanChecks: resource.ConfigPlanChecks{
+				PreApply: []plancheck.PlanCheck{plancheck.ExpectResourceAction(resourceName, plancheck.ResourceActionUpdate)},
+			},
+		},
+		{
+			PreConfig: func() {
+				controller.DeleteEntity(collection, testAccEntityID(t, controller, collection, name))
+			},
+			Config: config,
+			ConfigPlanChecks: resource.ConfigPlanChecks{
+				PreApply: []plancheck.PlanCheck{plancheck.ExpectResourceAction(resourceName, plancheck.ResourceActionCreate)},
+			},
+		},
+	}
+}
+
+// testAccEntityID returns the id of the entity named name.
+func testAccEntityID(t *testing.T, controller *mockcontroller.Server, collection, name string) string {
+	t.Helper()
+	id, ok := controller.EntityIDByName(collection, name)
+	if !ok {
+		t.Fatalf("%s %q does not exist in the controller", collection, name)
+	}
+	return id
+}
+
+// testAccCheckEntityExists checks that the controller stores the entity with
+// the id of resourceName.
+func testAccCheckEntityExists(controller *mockcontroller.Server, collection, resourceName string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("%s is not in state", resourceName)
+		}
+		if _, ok := controller.Entity(collection, rs.Primary.ID); !ok {
+			return fmt.Errorf("%s %q does not exist in the controller", resourceName, rs.Primary.ID)
+		}
+		return nil
+	}
+}
+
+// testProviderData configures the provider against controller and returns the
+// client it passes to resources, for tests that call them directly.
+func testProviderData(t *testing.T, controller *mockcontroller.Server) *zitiData {
+	t.Helper()
+	ctx := context.Background()
+	p := New("test")()
+	var schemaResp provider.SchemaResponse
+	p.Schema(ctx, provider.SchemaRequest{}, &schemaResp)
+	config := fmt.Sprintf(`{"host": %q, "username": %q, "password": %q}`, controller.ManagementURL(), mockcontroller.DefaultUsername, mockcontroller.DefaultPassword)
+	var resp provider.ConfigureResponse
+	p.Configure(ctx, provider.ConfigureRequest{
+		TerraformVersion: "1.10.0",
+		Config:           tfsdk.Config{Schema: schemaResp.Schema, Raw: testValue(t, schemaResp.Schema.Type(), config)},
+	}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("configuring the provider: %v", resp.Diagnostics)
+	}
+	return resp.ResourceData.(*zitiData)
+}
+
+// testValue decodes a value of typ written in Terraform's JSON syntax.
+// Attributes that are left out of an object are null.
+func testValue(t *testing.T, typ attr.Type, value string) tftypes.Value {
+	t.Helper()
+	decoded, err := tftypes.ValueFromJSON([]byte(value), typ.TerraformType(context.Background()))
+	if err != nil {
+		t.Fatalf("decoding %s: %v", value, err)
+	}
+	return decoded
+}
@@ -0,0 +1,152 @@
+package provider
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mozilla.org/pkcs7"
+)
+
+// testCA is a CA, or a server certificate when it is issued with a host.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCertificate issues a certificate signed by parent, or a self-signed
+// root if parent is nil. It is a CA unless it is issued for ip.
+func newTestCertificate(t *testing.T, name string, parent *testCA, ip net.IP) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating a key: %v", err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+	}
+	if ip == nil {
+		template.IsCA = true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		template.IPAddresses = []net.IP{ip}
+		template.KeyUsage = x509.KeyUsageDigitalSignature
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	}
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("creating the certificate %s: %v", name, err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key}
+}
+
+func fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// newCABundleServer serves bundle at /.well-known/est/cacerts over TLS with
+// the server certificate chain.
+func newCABundleServer(t *testing.T, chain []*testCA, bundle ...*x509.Certificate) *httptest.Server {
+	t.Helper()
+	var der []byte
+	for _, cert := range bundle {
+		der = append(der, cert.Raw...)
+	}
+	p7, err := pkcs7.DegenerateCertificate(der)
+	if err != nil {
+		t.Fatalf("encoding the CA bundle: %v", err)
+	}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, base64.StdEncoding.EncodeToString(p7))
+	}))
+	certificate := tls.Certificate{PrivateKey: chain[0].key}
+	for _, c := range chain {
+		certificate.Certificate = append(certificate.Certificate, c.cert.Raw)
+	}
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{certificate}}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func bootstrapClient(t *testing.T) *http.Client {
+	t.Helper()
+	tlsCfg, err := buildTLSConfig("", "", "", true)
+	if err != nil {
+		t.Fatalf("building the TLS configuration: %v", err)
+	}
+	return newHTTPClient(tlsCfg, httpClientOptions{requestTimeout: 5 * time.Second}).StandardClient()
+}
+
+func TestFetchWellKnownCA(t *testing.T) {
+	t.Parallel()
+	localhost := net.ParseIP("127.0.0.1")
+	root := newTestCertificate(t, "root", nil, nil)
+	intermediate := newTestCertificate(t, "intermediate", root, nil)
+	server := newTestCertificate(t, "controller", intermediate, localhost)
+	controller := newCABundleServer(t, []*testCA{server, intermediate}, root.cert, intermediate.cert)
+
+	bundle, err := fetchWellKnownCA(bootstrapClient(t), controller.URL+"/edge/management/v1", strings.ToUpper(fingerprint(root.cert)))
+	if err != nil {
+		t.Fatalf("fetchWellKnownCA: %v", err)
+	}
+	if got := strings.Count(bundle, "BEGIN CERTIFICATE"); got != 2 {
+		t.Errorf("the bundle has %d certificates, want the root and its intermediate", got)
+	}
+
+	if _, err := fetchWellKnownCA(bootstrapClient(t), controller.URL, fingerprint(server.cert)); err == nil {
+		t.Error("a bundle without the pinned certificate is accepted")
+	}
+}
+
+// A man in the middle can serve the public pinned CA next to its own root; the
+// bundle must not be trusted, nor the attacker root kept.
+func TestFetchWellKnownCAPinnedRootOnly(t *testing.T) {
+	t.Parallel()
+	localhost := net.ParseIP("127.0.0.1")
+	pinned := newTestCertificate(t, "pinned", nil, nil)
+	attacker := newTestCertificate(t, "attacker", nil, nil)
+	impostor := newTestCertificate(t, "controller", attacker, localhost)
+	mitm := newCABundleServer(t, []*testCA{impostor}, pinned.cert, attacker.cert)
+
+	if bundle, err := fetchWellKnownCA(bootstrapClient(t), mitm.URL, fingerprint(pinned.cert)); err == nil {
+		t.Errorf("a controller signed by another root in the bundle is trusted: %s", bundle)
+	}
+
+	// Without the attacker root in the chain, the extra root is dropped.
+	controller := newCABundleServer(t, []*testCA{newTestCertificate(t, "controller", pinned, localhost)}, attacker.cert, pinned.cert)
+	bundle, err := fetchWellKnownCA(bootstrapClient(t), controller.URL, fingerprint(pinned.cert))
+	if err != nil {
+		t.Fatalf("fetchWellKnownCA: %v", err)
+	}
+	block, rest := pem.Decode([]byte(bundle))
+	if block == nil || len(strings.TrimSpace(string(rest))) != 0 || string(block.Bytes) != string(pinned.cert.Raw) {
+		t.Errorf("the bundle is not just the pinned root: %s", bundle)
+	}
+}
@@ -0,0 +1,177 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/openziti/edge-api/rest_management_api_client/role_attributes"
+	"github.com/openziti/edge-api/rest_model"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &roleAttributesDataSource{}
+	_ datasource.DataSourceWithConfigure = &roleAttributesDataSource{}
+)
+
+// roleAttributesPage lists a page of the role attributes matching filter.
+type roleAttributesPage func(ctx context.Context, client *zitiData, filter string, offset, limit int64) (*rest_model.ListRoleAttributesEnvelope, error)
+
+// NewIdentityRoleAttributesDataSource is a helper function to simplify the provider implementation.
+func NewIdentityRoleAttributesDataSource() datasource.DataSource {
+	return &roleAttributesDataSource{typeName: "_identity_role_attributes", label: "identities", page: func(ctx context.Context, client *zitiData, filter string, offset, limit int64) (*rest_model.ListRoleAttributesEnvelope, error) {
+		list, err := client.api.RoleAttributes.ListIdentityRoleAttributes(&role_attributes.ListIdentityRoleAttributesParams{Filter: optionalFilter(filter), Offset: &offset, Limit: &limit, Context: ctx}, nil)
+		if err != nil {
+			return nil, err
+		}
+		return list.Payload, nil
+	}}
+}
+
+// NewServiceRoleAttributesDataSource is a helper function to simplify the provider implementation.
+func NewServiceRoleAttributesDataSource() datasource.DataSource {
+	return &roleAttributesDataSource{typeName: "_service_role_attributes", label: "services", page: func(ctx context.Context, client *zitiData, filter string, offset, limit int64) (*rest_model.ListRoleAttributesEnvelope, error) {
+		list, err := client.api.RoleAttributes.ListServiceRoleAttributes(&role_attributes.ListServiceRoleAttributesParams{Filter: optionalFilter(filter), Offset: &offset, Limit: &limit, Context: ctx}, nil)
+		if err != nil {
+			return nil, err
+		}
+		return list.Payload, nil
+	}}
+}
+
+// NewEdgeRouterRoleAttributesDataSource is a helper function to simplify the provider implementation.
+func NewEdgeRouterRoleAttributesDataSource() datasource.DataSource {
+	return &roleAttributesDataSource{typeName: "_edge_router_role_attributes", label: "edge routers", page: func(ctx context.Context, client *zitiData, filter string, offset, limit int64) (*rest_model.ListRoleAttributesEnvelope, error) {
+		list, err := client.api.RoleAttributes.ListEdgeRouterRoleAttributes(&role_attributes.ListEdgeRouterRoleAttributesParams{Filter: optionalFilter(filter), Offset: &offset, Limit: &limit, Context: ctx}, nil)
+		if err != nil {
+			return nil, err
+		}
+		return list.Payload, nil
+	}}
+}
+
+// NewPostureCheckRoleAttributesDataSource is a helper function to simplify the provider implementation.
+func NewPostureCheckRoleAttributesDataSource() datasource.DataSource {
+	return &roleAttributesDataSource{typeName: "_posture_check_role_attributes", label: "posture checks", page: func(ctx context.Context, client *zitiData, filter string, offset, limit int64) (*rest_model.ListRoleAttributesEnvelope, error) {
+		list, err := client.api.RoleAttributes.ListPostureCheckRoleAttributes(&role_attributes.ListPostureCheckRoleAttributesParams{Filter: optionalFilter(filter), Offset: &offset, Limit: &limit, Context: ctx}, nil)
+		if err != nil {
+			return nil, err
+		}
+		return list.Payload, nil
+	}}
+}
+
+// roleAttributesDataSource lists the role attributes in use by one type of
+// entity, e.g. every attribute set on an identity.
+type roleAttributesDataSource struct {
+	typeName         string
+	label            string
+	page             roleAttributesPage
+	datasourceConfig *zitiData
+}
+
+// roleAttributesDataSourceModel maps the datasource schema data.
+type roleAttributesDataSourceModel struct {
+	Filter         types.String `tfsdk:"filter"`
+	Contains       types.String `tfsdk:"contains"`
+	StartsWith     types.String `tfsdk:"starts_with"`
+	RoleAttributes types.Set    `tfsdk:"role_attributes"`
+}
+
+// Configure adds the provider configured client to the datasource.
+func (r *roleAttributesDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	r.datasourceConfig = req.ProviderData.(*zitiData)
+}
+
+// Metadata returns the datasource type name.
+func (r *roleAttributesDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + r.typeName
+}
+
+// Schema defines the schema for the datasource.
+func (r *roleAttributesDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists the role attributes set on any of the Ziti " + r.label + ".",
+		Attributes: map[string]schema.Attribute{
+			"filter": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Ziti filter expression the role attributes must match. An attribute is addressed as `id`, e.g. `id contains \"prod\"`.",
+			},
+			"contains": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Text the role attributes must contain.",
+			},
+			"starts_with": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Text the role attributes must start with.",
+			},
+			"role_attributes": schema.SetAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "The matching role attributes, without a leading `#`.",
+			},
+		},
+	}
+}
+
+// Read datasource information.
+func (r *roleAttributesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state roleAttributesDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filter := newFilter().Expression(state.Filter.ValueString())
+	if !state.Contains.IsNull() {
+		filter.Contains("id", state.Contains.ValueString())
+	}
+	if !state.StartsWith.IsNull() {
+		filter.StartsWith("id", state.StartsWith.ValueString())
+	}
+	expression, err := filter.SortBy("id").Build()
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid Filter", "Could not build the filter of the role attributes: "+err.Error())
+		return
+	}
+
+	attributes := []string{}
+	for offset := int64(0); ; {
+		page, err := r.page(ctx, r.datasourceConfig, expression, offset, listPageSize)
+		if err == nil && page == nil {
+			err = errMissingData
+		}
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Reading Role Attributes", "Could not list the role attributes of "+r.label+", unexpected error: "+zitiAPIErrorFrom(err).Error(),
+			)
+			return
+		}
+		attributes = append(attributes, page.Data...)
+		offset += int64(len(page.Data))
+		pagination := paginationOf(page.Meta)
+		if len(page.Data) == 0 || pagination == nil || pagination.TotalCount == nil || offset >= *pagination.TotalCount {
+			break
+		}
+	}
+
+	roleAttributes, diags := types.SetValueFrom(ctx, types.StringType, attributes)
+	resp.Diagnostics.Append(diags...)
+	state.RoleAttributes = roleAttributes
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
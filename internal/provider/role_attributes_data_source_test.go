@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccRoleAttributesDataSources(t *testing.T) {
+	controller := testAccController(t)
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_identity" "alice" {
+  name            = "alice"
+  role_attributes = ["clients", "payments-clients"]
+}
+
+resource "ziti_identity" "bob" {
+  name            = "bob"
+  role_attributes = ["clients", "ops"]
+}
+
+resource "ziti_service" "web" {
+  name            = "web"
+  role_attributes = ["web"]
+}
+
+resource "ziti_posture_check_domains" "corp" {
+  name            = "corp"
+  domains         = ["corp.example"]
+  role_attributes = ["corp"]
+}
+
+locals {
+  entities = [ziti_identity.alice, ziti_identity.bob, ziti_service.web, ziti_posture_check_domains.corp]
+}
+
+data "ziti_identity_role_attributes" "all" {
+  depends_on = [local.entities]
+}
+
+data "ziti_identity_role_attributes" "payments" {
+  contains    = "client"
+  starts_with = "pay"
+  depends_on  = [local.entities]
+}
+
+data "ziti_identity_role_attributes" "filtered" {
+  filter     = "id = \"ops\" or id = \"clients\""
+  depends_on = [local.entities]
+}
+
+data "ziti_service_role_attributes" "all" {
+  depends_on = [local.entities]
+}
+
+data "ziti_edge_router_role_attributes" "all" {
+  depends_on = [local.entities]
+}
+
+data "ziti_posture_check_role_attributes" "all" {
+  depends_on = [local.entities]
+}
+`
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{{
+			Config: config,
+			Check: resource.ComposeAggregateTestCheckFunc(
+				resource.TestCheckResourceAttr("data.ziti_identity_role_attributes.all", "role_attributes.#", "3"),
+				resource.TestCheckTypeSetElemAttr("data.ziti_identity_role_attributes.all", "role_attributes.*", "clients"),
+				resource.TestCheckTypeSetElemAttr("data.ziti_identity_role_attributes.all", "role_attributes.*", "ops"),
+				resource.TestCheckTypeSetElemAttr("data.ziti_identity_role_attributes.all", "role_attributes.*", "payments-clients"),
+				resource.TestCheckResourceAttr("data.ziti_identity_role_attributes.payments", "role_attributes.#", "1"),
+				resource.TestCheckTypeSetElemAttr("data.ziti_identity_role_attributes.payments", "role_attributes.*", "payments-clients"),
+				resource.TestCheckResourceAttr("data.ziti_identity_role_attributes.filtered", "role_attributes.#", "2"),
+				resource.TestCheckResourceAttr("data.ziti_service_role_attributes.all", "role_attributes.#", "1"),
+				resource.TestCheckTypeSetElemAttr("data.ziti_service_role_attributes.all", "role_attributes.*", "web"),
+				resource.TestCheckResourceAttr("data.ziti_edge_router_role_attributes.all", "role_attributes.#", "0"),
+				resource.TestCheckResourceAttr("data.ziti_posture_check_role_attributes.all", "role_attributes.#", "1"),
+				resource.TestCheckTypeSetElemAttr("data.ziti_posture_check_role_attributes.all", "role_attributes.*", "corp"),
+			),
+		}},
+	})
+}
@@ -0,0 +1,147 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// allRoles is the role that selects every entity.
+const allRoles = "#all"
+
+// roleKind is the form of a policy role.
+type roleKind int
+
+const (
+	// roleAll is #all.
+	roleAll roleKind = iota
+	// roleAttribute is #attribute, the entities with the role attribute.
+	roleAttribute
+	// roleEntity is @id or @name, a single entity.
+	roleEntity
+)
+
+// parseRole parses a role of a policy: #all, #attribute, or @ followed by
+// the ID or name of an entity. It returns the kind of the role and the
+// attribute, ID or name it refers to.
+func parseRole(role string) (roleKind, string, error) {
+	if role == allRoles {
+		return roleAll, "", nil
+	}
+	if role == "" {
+		return 0, "", fmt.Errorf("a role can not be empty; use #attribute, @id, @name or #all")
+	}
+	kind := roleAttribute
+	switch role[0] {
+	case '#':
+	case '@':
+		kind = roleEntity
+	default:
+		return 0, "", fmt.Errorf("%q does not start with # or @; use #attribute, @id, @name or #all", role)
+	}
+	value := role[1:]
+	if value == "" {
+		return 0, "", fmt.Errorf("%q has nothing after %c", role, role[0])
+	}
+	if strings.TrimFunc(value, unicode.IsSpace) != value {
+		return 0, "", fmt.Errorf("%q has leading or trailing whitespace", role)
+	}
+	return kind, value, nil
+}
+
+// rolesValidator validates a set of policy roles. Each role has to parse,
+// and #all can not be combined with other roles, as it would make them
+// meaningless.
+type rolesValidator struct{}
+
+// validRoles returns a validator for the roles of a policy.
+func validRoles() rolesValidator {
+	return rolesValidator{}
+}
+
+func (v rolesValidator) Description(_ context.Context) string {
+	return "each role must be #attribute, @id, @name or #all, and #all must be the only role"
+}
+
+func (v rolesValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v rolesValidator) ValidateSet(_ context.Context, req validator.SetRequest, resp *validator.SetResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	validateRoles(req.Path, req.ConfigValue.Elements(), &resp.Diagnostics)
+}
+
+func validateRoles(p path.Path, roles []attr.Value, diags *diag.Diagnostics) {
+	all := false
+	for _, value := range roles {
+		role, ok := value.(types.String)
+		if !ok || role.IsNull() || role.IsUnknown() {
+			continue
+		}
+		kind, _, err := parseRole(role.ValueString())
+		if err != nil {
+			diags.AddAttributeError(p.AtSetValue(value), "Invalid Role", err.Error())
+			continue
+		}
+		all = all || kind == roleAll
+	}
+	if all && len(roles) > 1 {
+		diags.AddAttributeError(p, "Invalid Roles", "#all selects every entity and can not be combined with other roles; remove #all or the other roles.")
+	}
+}
+
+// allOfEntityRolesValidator warns about a policy whose semantic is AllOf and
+// that selects more than one entity by @id or @name in a role set. An entity
+// would have to be several entities at once, so the list matches nothing.
+type allOfEntityRolesValidator struct {
+	// roles are the role set attributes of the policy.
+	roles []string
+}
+
+var _ resource.ConfigValidator = allOfEntityRolesValidator{}
+
+func (v allOfEntityRolesValidator) Description(_ context.Context) string {
+	return "with semantic AllOf, a role set must not select more than one entity by @id or @name"
+}
+
+func (v allOfEntityRolesValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v allOfEntityRolesValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var semantic types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("semantic"), &semantic)...)
+	// A policy without a semantic defaults to AllOf.
+	if semantic.IsUnknown() || (!semantic.IsNull() && semantic.ValueString() != "AllOf") {
+		return
+	}
+	for _, name := range v.roles {
+		var roles types.Set
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root(name), &roles)...)
+		entities := 0
+		for _, value := range roles.Elements() {
+			role, ok := value.(types.String)
+			if !ok || role.IsNull() || role.IsUnknown() {
+				continue
+			}
+			if kind, _, err := parseRole(role.ValueString()); err == nil && kind == roleEntity {
+				entities++
+			}
+		}
+		if entities > 1 {
+			resp.Diagnostics.AddAttributeWarning(path.Root(name), "Roles Can Never Match",
+				fmt.Sprintf("%s selects %d entities by @id or @name, but with semantic AllOf an entity has to match every role, and no entity is several entities at once. Set semantic to AnyOf to select each of them.", name, entities))
+		}
+	}
+}
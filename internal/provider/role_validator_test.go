@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestParseRole(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		role  string
+		kind  roleKind
+		value string
+	}{
+		{"#all", roleAll, ""},
+		{"#web", roleAttribute, "web"},
+		{"#web servers", roleAttribute, "web servers"},
+		{"@4gSZs8e8", roleEntity, "4gSZs8e8"},
+		{"@Web Client", roleEntity, "Web Client"},
+	} {
+		kind, value, err := parseRole(tc.role)
+		if err != nil || kind != tc.kind || value != tc.value {
+			t.Errorf("parseRole(%q) = %v, %q, %v, want %v, %q", tc.role, kind, value, err, tc.kind, tc.value)
+		}
+	}
+	for _, role := range []string{"", "#", "@", "web", "all", "#all ", " #all", "# web", "@id "} {
+		if _, _, err := parseRole(role); err == nil {
+			t.Errorf("parseRole(%q) succeeded, want an error", role)
+		}
+	}
+}
+
+func TestAccPolicyRoleValidation(t *testing.T) {
+	controller := testAccController(t)
+	var steps []resource.TestStep
+	for _, tc := range []struct {
+		typeName  string
+		arguments string
+		// want is the summary of the error, or "" when there is none.
+		want string
+	}{
+		{"ziti_service_policy", `identityroles = ["#clients", "@abc"]` + "\n" + `serviceroles = ["#all"]`, ""},
+		{"ziti_service_policy", `identityroles = ["#all "]`, "Invalid Role"},
+		{"ziti_service_policy", `posturecheckroles = ["@"]`, "Invalid Role"},
+		{"ziti_edge_router_policy", `edgerouterroles = ["public"]`, "Invalid Role"},
+		{"ziti_service_edge_router_policy", `serviceroles = ["#all", "#web"]`, "Invalid Roles"},
+	} {
+		step := resource.TestStep{
+			Config: testAccProviderConfig(controller, "") + fmt.Sprintf(`
+resource %q "test" {
+  name = "p"
+  %s
+}
+`, tc.typeName, tc.arguments),
+			PlanOnly: true,
+		}
+		if tc.want == "" {
+			step.ExpectNonEmptyPlan = true
+		} else {
+			step.ExpectError = regexp.MustCompile(tc.want)
+		}
+		steps = append(steps, step)
+	}
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    steps,
+	})
+}
+
+func TestPolicyRoleWarnings(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	for _, tc := range []struct {
+		newResource func() fwresource.Resource
+		config      string
+		warn        bool
+	}{
+		{NewServicePolicyResource, `{"name": "p", "identityroles": ["@a", "@b"]}`, true},
+		{NewServicePolicyResource, `{"name": "p", "identityroles": ["@a", "#b"]}`, false},
+		{NewEdgeRouterPolicyResource, `{"name": "p", "semantic": "AllOf", "identityroles": ["@a", "#x", "@b"]}`, true},
+		{NewServiceEdgeRouterPolicyResource, `{"name": "p", "semantic": "AnyOf", "serviceroles": ["@a", "@b"]}`, false},
+	} {
+		r := tc.newResource().(fwresource.ResourceWithConfigValidators)
+		var schemaResp fwresource.SchemaResponse
+		r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+		req := fwresource.ValidateConfigRequest{
+			Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: testValue(t, schemaResp.Schema.Type(), tc.config)},
+		}
+		var resp fwresource.ValidateConfigResponse
+		for _, v := range r.ConfigValidators(ctx) {
+			v.ValidateResource(ctx, req, &resp)
+		}
+		var got []string
+		for _, d := range resp.Diagnostics {
+			got = append(got, d.Summary())
+		}
+		want := 0
+		if tc.warn {
+			want = 1
+		}
+		if resp.Diagnostics.HasError() || resp.Diagnostics.WarningsCount() != want ||
+			tc.warn && resp.Diagnostics.Warnings()[0].Summary() != "Roles Can Never Match" {
+			t.Errorf("%s: got diagnostics [%s], want %d Roles Can Never Match warnings", tc.config, strings.Join(got, ", "), want)
+		}
+	}
+}
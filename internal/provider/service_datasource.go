@@ -2,13 +2,13 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/rs/zerolog/log"
+	"github.com/openziti/edge-api/rest_management_api_client/service"
+	"github.com/openziti/edge-api/rest_model"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -37,9 +37,6 @@ func (r *serviceDataSource) Configure(_ context.Context, req datasource.Configur
 
 	datasourceConfig := req.ProviderData.(*zitiData)
 	r.datasourceConfig = datasourceConfig
-
-	fmt.Printf("Using API Token to create datasource: %s\n", r.datasourceConfig.apiToken)
-	fmt.Printf("Using domain to create datasource: %s\n", r.datasourceConfig.host)
 }
 
 // Metadata returns the datasource type name.
@@ -115,99 +112,77 @@ func (r *serviceDataSource) Read(ctx context.Context, req datasource.ReadRequest
 		return
 	}
 
-	filter := ""
-	if state.Name.ValueString() != "" {
-		filter = "filter=name=\"" + state.Name.ValueString() + "\""
-	}
-	if state.ID.ValueString() != "" {
-		filter = "filter=id=\"" + state.ID.ValueString() + "\""
-	}
-
-	authUrl := fmt.Sprintf("%s/services?%s", r.datasourceConfig.host, filter)
-	cresp, err := ReadZitiResource(authUrl, r.datasourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Reading services", "Could not READ services, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
-	if err != nil {
-		log.Error().Msgf("Error unmarshalling JSON response from Ziti DataSource Response: %v", err)
-		fmt.Println("Error unmarshalling JSON:", err)
+	filter := dataSourceFilter(state.ID, state.Name)
+	detail, ok := lookupDataSourceEntity(ctx, r.datasourceConfig, &resp.Diagnostics, "services", filter, serviceList)
+	if !ok {
 		return
 	}
 
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	service := jsonBody["data"].([]interface{})
+	serviceDataSourceState(ctx, detail, &state, &resp.Diagnostics)
 
-	if len(service) > 1 {
-		resp.Diagnostics.AddError(
-			"Multiple items returned from API upon filter execution!", "Try to narrow down the filter expression"+filter,
-		)
-	}
-	if len(service) == 0 {
-		resp.Diagnostics.AddError(
-			"No items returned from API upon filter execution!", "Try to relax the filter expression: "+filter,
-		)
-	}
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+}
 
-	data := service[0].(map[string]interface{})
-
-	state.Name = types.StringValue(data["name"].(string))
-	state.ID = types.StringValue(data["id"].(string))
+// serviceDataSourceState copies a service fetched by the data source into state.
+func serviceDataSourceState(ctx context.Context, detail *rest_model.ServiceDetail, state *serviceDataSourceModel, diags *diag.Diagnostics) {
+	state.ID = types.StringPointerValue(detail.ID)
+	state.Name = types.StringPointerValue(detail.Name)
 
-	if terminatorStrategy, ok := data["terminatorStrategy"].(string); ok {
-		state.TerminatorStrategy = types.StringValue(terminatorStrategy)
+	if detail.TerminatorStrategy != nil {
+		state.TerminatorStrategy = types.StringValue(*detail.TerminatorStrategy)
 	}
 
-	if maxIdleTimeMilliseconds, ok := data["maxIdleTimeMillis"].(float64); ok {
-		state.MaxIdleTimeMilliseconds = types.Int64Value(int64(maxIdleTimeMilliseconds))
+	if detail.MaxIdleTimeMillis != nil {
+		state.MaxIdleTimeMilliseconds = types.Int64Value(*detail.MaxIdleTimeMillis)
 	}
 
-	if encryptionRequired, ok := data["encryptionRequired"].(bool); ok {
-		state.EncryptionRequired = types.BoolValue(encryptionRequired)
+	if detail.EncryptionRequired != nil {
+		state.EncryptionRequired = types.BoolValue(*detail.EncryptionRequired)
 	}
 
-	if configs, ok := data["configs"].([]interface{}); ok {
-		configs, diag := types.SetValueFrom(ctx, types.StringType, configs)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.Configs = configs
-	} else {
-		state.Configs = types.SetNull(types.StringType)
-	}
+	state.Configs = stringSetFromAPI(ctx, detail.Configs, diags)
+	state.RoleAttributes = roleAttributesFromAPI(ctx, detail.RoleAttributes, diags)
+	state.Tags = tagsFromAPI(ctx, detail.Tags, diags)
+}
 
-	if roleAttributes, ok := data["roleAttributes"].([]interface{}); ok {
-		roleAttributes, diag := types.SetValueFrom(ctx, types.StringType, roleAttributes)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.RoleAttributes = roleAttributes
-	} else {
-		state.RoleAttributes = types.SetNull(types.StringType)
+// serviceList lists the services matching filter.
+func serviceList(ctx context.Context, client *zitiData, filter string) ([]*rest_model.ServiceDetail, error) {
+	list, err := client.api.Service.ListServices(&service.ListServicesParams{Filter: optionalFilter(filter), Context: ctx}, nil)
+	if err != nil {
+		return nil, err
 	}
-
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
+	if list.Payload == nil {
+		return nil, errMissingData
 	}
+	return withoutNil(list.Payload.Data), nil
+}
 
-	// Set refreshed state
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+// servicePage lists a page of the services matching filter.
+func servicePage(ctx context.Context, client *zitiData, filter string, offset, limit int64) ([]*rest_model.ServiceDetail, *rest_model.Pagination, error) {
+	list, err := client.api.Service.ListServices(&service.ListServicesParams{Filter: optionalFilter(filter), Offset: &offset, Limit: &limit, Context: ctx}, nil)
+	if err != nil {
+		return nil, nil, err
 	}
+	if list.Payload == nil {
+		return nil, nil, errMissingData
+	}
+	return withoutNil(list.Payload.Data), paginationOf(list.Payload.Meta), nil
+}
+
+// NewServicesDataSource is a helper function to simplify the provider implementation.
+func NewServicesDataSource() datasource.DataSource {
+	return &zitiListDataSource[*rest_model.ServiceDetail, serviceDataSourceModel]{spec: zitiListDataSourceSpec[*rest_model.ServiceDetail, serviceDataSourceModel]{
+		typeName:       "_services",
+		label:          "services",
+		items:          "services",
+		itemAttributes: listItemAttributes(dataSourceAttributes(NewServiceDataSource())),
+		roleAttributes: true,
+		page:           servicePage,
+		item:           serviceDataSourceState,
+	}}
 }
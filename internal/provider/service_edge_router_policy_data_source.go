@@ -2,13 +2,12 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/rs/zerolog/log"
+	"github.com/openziti/edge-api/rest_management_api_client/service_edge_router_policy"
+	"github.com/openziti/edge-api/rest_model"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -37,9 +36,6 @@ func (r *serviceEdgeRouterPolicyDataSource) Configure(_ context.Context, req dat
 
 	datasourceConfig := req.ProviderData.(*zitiData)
 	r.datasourceConfig = datasourceConfig
-
-	fmt.Printf("Using API Token to create datasource: %s\n", r.datasourceConfig.apiToken)
-	fmt.Printf("Using domain to create datasource: %s\n", r.datasourceConfig.host)
 }
 
 // Metadata returns the datasource type name.
@@ -52,8 +48,8 @@ type serviceEdgeRouterPolicyDataSourceModel struct {
 	ID              types.String `tfsdk:"id"`
 	Name            types.String `tfsdk:"name"`
 	Semantic        types.String `tfsdk:"semantic"`
-	ServiceRoles    types.List   `tfsdk:"serviceroles"`
-	EdgeRouterRoles types.List   `tfsdk:"edgerouterroles"`
+	ServiceRoles    types.Set    `tfsdk:"serviceroles"`
+	EdgeRouterRoles types.Set    `tfsdk:"edgerouterroles"`
 	Tags            types.Map    `tfsdk:"tags"`
 }
 
@@ -76,12 +72,12 @@ func (r *serviceEdgeRouterPolicyDataSource) Schema(_ context.Context, _ datasour
 				Computed:            true,
 				MarkdownDescription: "Semantic Value",
 			},
-			"serviceroles": schema.ListAttribute{
+			"serviceroles": schema.SetAttribute{
 				ElementType:         types.StringType,
 				Computed:            true,
 				MarkdownDescription: "Service Roles",
 			},
-			"edgerouterroles": schema.ListAttribute{
+			"edgerouterroles": schema.SetAttribute{
 				ElementType:         types.StringType,
 				Computed:            true,
 				MarkdownDescription: "Edge Router Roles",
@@ -105,86 +101,22 @@ func (r *serviceEdgeRouterPolicyDataSource) Read(ctx context.Context, req dataso
 		return
 	}
 
-	filter := ""
-	if state.Name.ValueString() != "" {
-		filter = "filter=name=\"" + state.Name.ValueString() + "\""
-	}
-	if state.ID.ValueString() != "" {
-		filter = "filter=id=\"" + state.ID.ValueString() + "\""
-	}
-
-	authUrl := fmt.Sprintf("%s/service-edge-router-policies?%s", r.datasourceConfig.host, filter)
-	cresp, err := ReadZitiResource(authUrl, r.datasourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Reading service-edge-router-policies", "Could not READ service-edge-router-policies, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
-	if err != nil {
-		log.Error().Msgf("Error unmarshalling JSON response from Ziti DataSource Response: %v", err)
-		fmt.Println("Error unmarshalling JSON:", err)
-		return
-	}
-
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	serviceEdgeRouterPolicies := jsonBody["data"].([]interface{})
-
-	if len(serviceEdgeRouterPolicies) > 1 {
-		resp.Diagnostics.AddError(
-			"Multiple items returned from API upon filter execution!", "Try to narrow down the filter expression"+filter,
-		)
-	}
-	if len(serviceEdgeRouterPolicies) == 0 {
-		resp.Diagnostics.AddError(
-			"No items returned from API upon filter execution!", "Try to relax the filter expression: "+filter,
-		)
-	}
-	if resp.Diagnostics.HasError() {
+	filter := dataSourceFilter(state.ID, state.Name)
+	detail, ok := lookupDataSourceEntity(ctx, r.datasourceConfig, &resp.Diagnostics, "service-edge-router-policies", filter, serviceEdgeRouterPolicyList)
+	if !ok {
 		return
 	}
 
-	data := serviceEdgeRouterPolicies[0].(map[string]interface{})
-
-	state.Name = types.StringValue(data["name"].(string))
-	state.ID = types.StringValue(data["id"].(string))
-
-	if semanticValue, ok := data["semantic"].(string); ok {
-		state.Semantic = types.StringValue(semanticValue)
-	}
-
-	if edgeRouterRoles, ok := data["edgeRouterRoles"].([]interface{}); ok {
-		edgeRouterRoles, diag := types.ListValueFrom(ctx, types.StringType, edgeRouterRoles)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.EdgeRouterRoles = edgeRouterRoles
-	} else {
-		state.EdgeRouterRoles = types.ListNull(types.StringType)
-	}
+	state.ID = types.StringPointerValue(detail.ID)
+	state.Name = types.StringPointerValue(detail.Name)
 
-	if serviceRoles, ok := data["serviceRoles"].([]interface{}); ok {
-		serviceRoles, diag := types.ListValueFrom(ctx, types.StringType, serviceRoles)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.ServiceRoles = serviceRoles
-	} else {
-		state.ServiceRoles = types.ListNull(types.StringType)
+	if detail.Semantic != nil {
+		state.Semantic = types.StringValue(string(*detail.Semantic))
 	}
 
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
-	}
+	state.ServiceRoles = stringSetFromAPI(ctx, detail.ServiceRoles, &resp.Diagnostics)
+	state.EdgeRouterRoles = stringSetFromAPI(ctx, detail.EdgeRouterRoles, &resp.Diagnostics)
+	state.Tags = tagsFromAPI(ctx, detail.Tags, &resp.Diagnostics)
 
 	// Set refreshed state
 	diags = resp.State.Set(ctx, &state)
@@ -193,3 +125,15 @@ func (r *serviceEdgeRouterPolicyDataSource) Read(ctx context.Context, req dataso
 		return
 	}
 }
+
+// serviceEdgeRouterPolicyList lists the service edge router policies matching filter.
+func serviceEdgeRouterPolicyList(ctx context.Context, client *zitiData, filter string) ([]*rest_model.ServiceEdgeRouterPolicyDetail, error) {
+	list, err := client.api.ServiceEdgeRouterPolicy.ListServiceEdgeRouterPolicies(&service_edge_router_policy.ListServiceEdgeRouterPoliciesParams{Filter: optionalFilter(filter), Context: ctx}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if list.Payload == nil {
+		return nil, errMissingData
+	}
+	return withoutNil(list.Payload.Data), nil
+}
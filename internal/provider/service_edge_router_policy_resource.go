@@ -2,64 +2,51 @@ package provider
 
 import (
 	"context"
-	"errors"
-	"encoding/json"
-	"fmt"
-	"net/url"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
-	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
-	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/openziti/edge-api/rest_management_api_client/service_edge_router_policy"
 	"github.com/openziti/edge-api/rest_model"
-	"github.com/rs/zerolog/log"
-	"github.com/tidwall/gjson"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource                = &serviceEdgeRouterPolicyResource{}
-	_ resource.ResourceWithConfigure   = &serviceEdgeRouterPolicyResource{}
-	_ resource.ResourceWithImportState = &serviceEdgeRouterPolicyResource{}
+	_ resource.Resource                     = &serviceEdgeRouterPolicyResource{}
+	_ resource.ResourceWithConfigure        = &serviceEdgeRouterPolicyResource{}
+	_ resource.ResourceWithImportState      = &serviceEdgeRouterPolicyResource{}
+	_ resource.ResourceWithUpgradeState     = &serviceEdgeRouterPolicyResource{}
+	_ resource.ResourceWithConfigValidators = &serviceEdgeRouterPolicyResource{}
 )
 
 // NewServiceEdgeRouterPolicyResource is a helper function to simplify the provider implementation.
 func NewServiceEdgeRouterPolicyResource() resource.Resource {
-	return &serviceEdgeRouterPolicyResource{}
+	return &serviceEdgeRouterPolicyResource{newZitiResource(zitiResourceSpec[serviceEdgeRouterPolicyResourceModel, *rest_model.ServiceEdgeRouterPolicyDetail]{
+		typeName: "_service_edge_router_policy",
+		label:    "service-edge-router-policies",
+		create:   serviceEdgeRouterPolicyCreate,
+		read:     serviceEdgeRouterPolicyRead,
+		readData: serviceEdgeRouterPolicyReadData,
+		update:   serviceEdgeRouterPolicyUpdate,
+		delete:   serviceEdgeRouterPolicyDelete,
+		list:     serviceEdgeRouterPolicyList,
+		entityID: func(detail *rest_model.ServiceEdgeRouterPolicyDetail) string {
+			return types.StringPointerValue(detail.ID).ValueString()
+		},
+	})}
 }
 
 // serviceEdgeRouterPolicyResource is the resource implementation.
 type serviceEdgeRouterPolicyResource struct {
-	resourceConfig *zitiData
-}
-
-// Configure adds the provider configured client to the resource.
-func (r *serviceEdgeRouterPolicyResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	// Add a nil check when handling ProviderData because Terraform
-	// sets that data after it calls the ConfigureProvider RPC.
-	if req.ProviderData == nil {
-		return
-	}
-
-	resourceConfig := req.ProviderData.(*zitiData)
-	r.resourceConfig = resourceConfig
-
-	fmt.Printf("Using API Token to create resource: %s\n", r.resourceConfig.apiToken)
-	fmt.Printf("Using domain to create resource: %s\n", r.resourceConfig.host)
-}
-
-// Metadata returns the resource type name.
-func (r *serviceEdgeRouterPolicyResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
-	resp.TypeName = req.ProviderTypeName + "_service_edge_router_policy"
+	zitiResource[serviceEdgeRouterPolicyResourceModel, *rest_model.ServiceEdgeRouterPolicyDetail]
 }
 
 // serviceEdgeRouterPolicyResourceModel maps the resource schema data.
@@ -67,15 +54,33 @@ type serviceEdgeRouterPolicyResourceModel struct {
 	ID              types.String `tfsdk:"id"`
 	Name            types.String `tfsdk:"name"`
 	Semantic        types.String `tfsdk:"semantic"`
-	ServiceRoles    types.List   `tfsdk:"serviceroles"`
-	EdgeRouterRoles types.List   `tfsdk:"edgerouterroles"`
+	ServiceRoles    types.Set    `tfsdk:"serviceroles"`
+	EdgeRouterRoles types.Set    `tfsdk:"edgerouterroles"`
 	Tags            types.Map    `tfsdk:"tags"`
+	TagsAll         types.Map    `tfsdk:"tags_all"`
 	LastUpdated     types.String `tfsdk:"last_updated"`
 }
 
+// ConfigValidators warns about roles that can never match.
+func (r *serviceEdgeRouterPolicyResource) ConfigValidators(_ context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		allOfEntityRolesValidator{roles: []string{"serviceroles", "edgerouterroles"}},
+	}
+}
+
+// UpgradeState migrates the state of schema version 0, whose roles were lists.
+func (r *serviceEdgeRouterPolicyResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	var resp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &resp)
+	return map[int64]resource.StateUpgrader{
+		0: roleSetsUpgrader(resp.Schema, "serviceroles", "edgerouterroles"),
+	}
+}
+
 // Schema defines the schema for the resource.
 func (r *serviceEdgeRouterPolicyResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version:             1,
 		MarkdownDescription: "Ziti Service Edge Router Policy Resource",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -102,18 +107,24 @@ func (r *serviceEdgeRouterPolicyResource) Schema(_ context.Context, _ resource.S
 				},
 				MarkdownDescription: "Semantic Value",
 			},
-			"serviceroles": schema.ListAttribute{
-				ElementType:         types.StringType,
-				Optional:            true,
-				Computed:            true,
-				Default:             listdefault.StaticValue(types.ListNull(types.StringType)),
+			"serviceroles": schema.SetAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Computed:    true,
+				Default:     setdefault.StaticValue(types.SetNull(types.StringType)),
+				Validators: []validator.Set{
+					validRoles(),
+				},
 				MarkdownDescription: "Service Roles",
 			},
-			"edgerouterroles": schema.ListAttribute{
-				ElementType:         types.StringType,
-				Optional:            true,
-				Computed:            true,
-				Default:             listdefault.StaticValue(types.ListNull(types.StringType)),
+			"edgerouterroles": schema.SetAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Computed:    true,
+				Default:     setdefault.StaticValue(types.SetNull(types.StringType)),
+				Validators: []validator.Set{
+					validRoles(),
+				},
 				MarkdownDescription: "Edge Router Roles",
 			},
 			"tags": schema.MapAttribute{
@@ -123,21 +134,12 @@ func (r *serviceEdgeRouterPolicyResource) Schema(_ context.Context, _ resource.S
 				Default:             mapdefault.StaticValue(types.MapNull(types.StringType)),
 				MarkdownDescription: "Service Edge Router Policy Tags",
 			},
+			"tags_all": tagsAllAttribute(),
 		},
 	}
 }
 
-// Create a new resource.
-func (r *serviceEdgeRouterPolicyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	// Retrieve values from plan
-	var eplan serviceEdgeRouterPolicyResourceModel
-
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func serviceEdgeRouterPolicyCreate(ctx context.Context, client *zitiData, eplan *serviceEdgeRouterPolicyResourceModel, diags *diag.Diagnostics) (string, error) {
 	name := eplan.Name.ValueString()
 	semantic := rest_model.Semantic(eplan.Semantic.ValueString())
 	tags := TagsFromAttributes(eplan.Tags.Elements())
@@ -164,143 +166,14 @@ func (r *serviceEdgeRouterPolicyResource) Create(ctx context.Context, req resour
 		Tags:            tags,
 	}
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************create resource payload***********************:\n %s\n", jsonData)
-
-	authUrl := fmt.Sprintf("%s/service-edge-router-policies", r.resourceConfig.host)
-	cresp, err := CreateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti POST Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Creating service-edge-router-policies", "Could not Create service-edge-router-policies, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	fmt.Printf("**********************create response************************:\n %s\n", cresp)
-	resourceID := gjson.Get(cresp, "data.id").String()
-
-	// Map response body to schema and populate Computed attribute values
-	eplan.ID = types.StringValue(resourceID)
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
-
-	// Set state to fully populated data
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-}
-
-// Read resource information.
-func (r *serviceEdgeRouterPolicyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	// Get current state
-	var state serviceEdgeRouterPolicyResourceModel
-	tflog.Debug(ctx, "Reading Service Edge Router Policy")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	authUrl := fmt.Sprintf("%s/service-edge-router-policies/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := ReadZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
+	created, err := client.api.ServiceEdgeRouterPolicy.CreateServiceEdgeRouterPolicy(&service_edge_router_policy.CreateServiceEdgeRouterPolicyParams{Policy: &payload, Context: ctx}, nil)
 	if err != nil {
-		if errors.Is(err, errNotFound) {
-			msg := fmt.Sprintf("Resource not found in backend; removing from state, id: %s", state.ID.ValueString())
-			log.Info().Msg(msg)
-			resp.State.RemoveResource(ctx)
-			return
-		}
-		resp.Diagnostics.AddError(
-			"Error Reading service-edge-router-policies", "Could not READ service-edge-router-policies, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
-	if err != nil {
-		// Handle error
-		resp.Diagnostics.AddError(
-			"Error Reading service-edge-router-policies", fmt.Sprintf("Could not READ service-edge-router-policies, ERROR %v: ", err.Error()),
-		)
-		return
-	}
-
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	data, ok := jsonBody["data"].(map[string]interface{})
-	if !ok {
-		resp.Diagnostics.AddError("Error: ", "'data' is either missing or not a map[string]interface{}")
-		return
-	}
-
-	// Manually assign individual values from the map to the struct fields
-	state.Name = types.StringValue(data["name"].(string))
-
-	if semanticValue, ok := data["semantic"].(string); ok {
-		state.Semantic = types.StringValue(semanticValue)
-	}
-
-	if edgeRouterRoles, ok := data["edgeRouterRoles"].([]interface{}); ok {
-		edgeRouterRoles, diag := types.ListValueFrom(ctx, types.StringType, edgeRouterRoles)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.EdgeRouterRoles = edgeRouterRoles
-	} else {
-		state.EdgeRouterRoles = types.ListNull(types.StringType)
-	}
-
-	if serviceRoles, ok := data["serviceRoles"].([]interface{}); ok {
-		serviceRoles, diag := types.ListValueFrom(ctx, types.StringType, serviceRoles)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.ServiceRoles = serviceRoles
-	} else {
-		state.ServiceRoles = types.ListNull(types.StringType)
-	}
-
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
-	}
-
-	// Set refreshed state
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+		return "", err
 	}
+	return createdID(created.Payload)
 }
 
-// Update updates the resource and sets the updated Terraform state on success.
-func (r *serviceEdgeRouterPolicyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	// Retrieve values from plan
-	var eplan serviceEdgeRouterPolicyResourceModel
-	tflog.Debug(ctx, "Updating Service Edge Router Policy")
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	// Get current state
-	var state serviceEdgeRouterPolicyResourceModel
-	sdiags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(sdiags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func serviceEdgeRouterPolicyUpdate(ctx context.Context, client *zitiData, id string, eplan *serviceEdgeRouterPolicyResourceModel, diags *diag.Diagnostics) error {
 	name := eplan.Name.ValueString()
 	semantic := rest_model.Semantic(eplan.Semantic.ValueString())
 	tags := TagsFromAttributes(eplan.Tags.Elements())
@@ -327,55 +200,34 @@ func (r *serviceEdgeRouterPolicyResource) Update(ctx context.Context, req resour
 		Tags:            tags,
 	}
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************update resource payload***********************:\n %s\n", jsonData)
+	_, err := client.api.ServiceEdgeRouterPolicy.UpdateServiceEdgeRouterPolicy(&service_edge_router_policy.UpdateServiceEdgeRouterPolicyParams{ID: id, Policy: &payload, Context: ctx}, nil)
+	return err
+}
 
-	authUrl := fmt.Sprintf("%s/service-edge-router-policies/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := UpdateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti PUT Response: %s", cresp)
-	log.Info().Msg(msg)
+func serviceEdgeRouterPolicyRead(ctx context.Context, client *zitiData, id string) (*rest_model.ServiceEdgeRouterPolicyDetail, error) {
+	detail, err := client.api.ServiceEdgeRouterPolicy.DetailServiceEdgeRouterPolicy(&service_edge_router_policy.DetailServiceEdgeRouterPolicyParams{ID: id, Context: ctx}, nil)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Updating service-edge-router-policies", "Could not Update service-edge-router-policies, unexpected error: "+err.Error(),
-		)
-		return
+		return nil, err
 	}
-
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
-
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+	if detail.Payload == nil || detail.Payload.Data == nil {
+		return nil, errMissingData
 	}
+	return detail.Payload.Data, nil
 }
 
-// Delete deletes the resource and removes the Terraform state on success.
-func (r *serviceEdgeRouterPolicyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	// Retrieve values from state
-	var state serviceEdgeRouterPolicyResourceModel
-	tflog.Debug(ctx, "Deleting Service Edge Router Policy")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
+func serviceEdgeRouterPolicyDelete(ctx context.Context, client *zitiData, id string) error {
+	_, err := client.api.ServiceEdgeRouterPolicy.DeleteServiceEdgeRouterPolicy(&service_edge_router_policy.DeleteServiceEdgeRouterPolicyParams{ID: id, Context: ctx}, nil)
+	return err
+}
 
-	authUrl := fmt.Sprintf("%s/service-edge-router-policies/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
+func serviceEdgeRouterPolicyReadData(ctx context.Context, detail *rest_model.ServiceEdgeRouterPolicyDetail, state *serviceEdgeRouterPolicyResourceModel, diags *diag.Diagnostics) {
+	state.Name = types.StringPointerValue(detail.Name)
 
-	cresp, err := DeleteZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti Delete Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Deleting service-edge-router-policies", "Could not DELETE service-edge-router-policies, unexpected error: "+err.Error(),
-		)
-		return
+	if detail.Semantic != nil {
+		state.Semantic = types.StringValue(string(*detail.Semantic))
 	}
-}
 
-func (r *serviceEdgeRouterPolicyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	state.EdgeRouterRoles = stringSetFromAPI(ctx, detail.EdgeRouterRoles, diags)
+	state.ServiceRoles = stringSetFromAPI(ctx, detail.ServiceRoles, diags)
+	state.Tags = tagsFromAPI(ctx, detail.Tags, diags)
 }
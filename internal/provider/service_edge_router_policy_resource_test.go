@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"testing"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccServiceEdgeRouterPolicyResource(t *testing.T) {
+	controller := testAccController(t)
+	const resourceName = "ziti_service_edge_router_policy.test"
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_service_edge_router_policy" "test" {
+  name            = "test-service-edge-router-policy"
+  semantic        = "AnyOf"
+  edgerouterroles = ["#public"]
+  serviceroles    = ["#web", "#api"]
+}
+`
+	update := testAccProviderConfig(controller, "") + `
+resource "ziti_service_edge_router_policy" "test" {
+  name            = "test-service-edge-router-policy"
+  semantic        = "AnyOf"
+  edgerouterroles = ["#public", "#private"]
+  serviceroles    = ["#web", "#api"]
+  tags            = { env = "test" }
+}
+`
+
+	steps := []resource.TestStep{{
+		Config: config,
+		Check: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckEntityExists(controller, mockcontroller.ServiceEdgeRouterPolicies, resourceName),
+			resource.TestCheckResourceAttr(resourceName, "name", "test-service-edge-router-policy"),
+			resource.TestCheckTypeSetElemAttr(resourceName, "serviceroles.*", "#web"),
+			resource.TestCheckTypeSetElemAttr(resourceName, "serviceroles.*", "#api"),
+		),
+	}}
+	steps = append(steps, testAccImportSteps(resourceName)...)
+	steps = append(steps, testAccUpdateStep(resourceName, update,
+		resource.TestCheckTypeSetElemAttr(resourceName, "edgerouterroles.*", "#private"),
+		resource.TestCheckResourceAttr(resourceName, "tags.env", "test"),
+	))
+	steps = append(steps, testAccOutOfBandSteps(t, controller, mockcontroller.ServiceEdgeRouterPolicies, "test-service-edge-router-policy", resourceName, update,
+		map[string]interface{}{"serviceRoles": []interface{}{"#all"}})...)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    steps,
+	})
+}
@@ -2,13 +2,13 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/rs/zerolog/log"
+	"github.com/openziti/edge-api/rest_management_api_client/service_policy"
+	"github.com/openziti/edge-api/rest_model"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -37,9 +37,6 @@ func (r *servicePolicyDataSource) Configure(_ context.Context, req datasource.Co
 
 	datasourceConfig := req.ProviderData.(*zitiData)
 	r.datasourceConfig = datasourceConfig
-
-	fmt.Printf("Using API Token to create datasource: %s\n", r.datasourceConfig.apiToken)
-	fmt.Printf("Using domain to create datasource: %s\n", r.datasourceConfig.host)
 }
 
 // Metadata returns the datasource type name.
@@ -52,9 +49,9 @@ type servicePolicyDataSourceModel struct {
 	ID                types.String `tfsdk:"id"`
 	Name              types.String `tfsdk:"name"`
 	Semantic          types.String `tfsdk:"semantic"`
-	ServiceRoles      types.List   `tfsdk:"serviceroles"`
-	IdentityRoles     types.List   `tfsdk:"identityroles"`
-	PostureCheckRoles types.List   `tfsdk:"posturecheckroles"`
+	ServiceRoles      types.Set    `tfsdk:"serviceroles"`
+	IdentityRoles     types.Set    `tfsdk:"identityroles"`
+	PostureCheckRoles types.Set    `tfsdk:"posturecheckroles"`
 	Tags              types.Map    `tfsdk:"tags"`
 	Type              types.String `tfsdk:"type"`
 }
@@ -78,17 +75,17 @@ func (r *servicePolicyDataSource) Schema(_ context.Context, _ datasource.SchemaR
 				Computed:            true,
 				MarkdownDescription: "Semantic Value",
 			},
-			"serviceroles": schema.ListAttribute{
+			"serviceroles": schema.SetAttribute{
 				ElementType:         types.StringType,
 				Computed:            true,
 				MarkdownDescription: "Service Roles",
 			},
-			"identityroles": schema.ListAttribute{
+			"identityroles": schema.SetAttribute{
 				ElementType:         types.StringType,
 				Computed:            true,
 				MarkdownDescription: "Identity Roles",
 			},
-			"posturecheckroles": schema.ListAttribute{
+			"posturecheckroles": schema.SetAttribute{
 				ElementType:         types.StringType,
 				Computed:            true,
 				MarkdownDescription: "Posture Check Roles",
@@ -116,103 +113,73 @@ func (r *servicePolicyDataSource) Read(ctx context.Context, req datasource.ReadR
 		return
 	}
 
-	filter := ""
-	if state.Name.ValueString() != "" {
-		filter = "filter=name=\"" + state.Name.ValueString() + "\""
-	}
-	if state.ID.ValueString() != "" {
-		filter = "filter=id=\"" + state.ID.ValueString() + "\""
-	}
-
-	authUrl := fmt.Sprintf("%s/service-policies?%s", r.datasourceConfig.host, filter)
-	cresp, err := ReadZitiResource(authUrl, r.datasourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Reading service-policies", "Could not READ service-policies, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
-	if err != nil {
-		log.Error().Msgf("Error unmarshalling JSON response from Ziti DataSource Response: %v", err)
-		fmt.Println("Error unmarshalling JSON:", err)
+	filter := dataSourceFilter(state.ID, state.Name)
+	detail, ok := lookupDataSourceEntity(ctx, r.datasourceConfig, &resp.Diagnostics, "service-policies", filter, servicePolicyList)
+	if !ok {
 		return
 	}
 
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	servicePolicies := jsonBody["data"].([]interface{})
+	servicePolicyDataSourceState(ctx, detail, &state, &resp.Diagnostics)
 
-	if len(servicePolicies) > 1 {
-		resp.Diagnostics.AddError(
-			"Multiple items returned from API upon filter execution!", "Try to narrow down the filter expression"+filter,
-		)
-	}
-	if len(servicePolicies) == 0 {
-		resp.Diagnostics.AddError(
-			"No items returned from API upon filter execution!", "Try to relax the filter expression: "+filter,
-		)
-	}
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+}
 
-	data := servicePolicies[0].(map[string]interface{})
-
-	state.Name = types.StringValue(data["name"].(string))
-	state.ID = types.StringValue(data["id"].(string))
+// servicePolicyDataSourceState copies a service policy fetched by the data source into state.
+func servicePolicyDataSourceState(ctx context.Context, detail *rest_model.ServicePolicyDetail, state *servicePolicyDataSourceModel, diags *diag.Diagnostics) {
+	state.ID = types.StringPointerValue(detail.ID)
+	state.Name = types.StringPointerValue(detail.Name)
 
-	if semanticValue, ok := data["semantic"].(string); ok {
-		state.Semantic = types.StringValue(semanticValue)
+	if detail.Semantic != nil {
+		state.Semantic = types.StringValue(string(*detail.Semantic))
 	}
 
-	if typeValue, ok := data["type"].(string); ok {
-		state.Type = types.StringValue(typeValue)
+	if detail.Type != nil {
+		state.Type = types.StringValue(string(*detail.Type))
 	}
 
-	if identityRoles, ok := data["identityRoles"].([]interface{}); ok {
-		identityRoles, diag := types.ListValueFrom(ctx, types.StringType, identityRoles)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.IdentityRoles = identityRoles
-	} else {
-		state.IdentityRoles = types.ListNull(types.StringType)
-	}
+	state.IdentityRoles = stringSetFromAPI(ctx, detail.IdentityRoles, diags)
+	state.ServiceRoles = stringSetFromAPI(ctx, detail.ServiceRoles, diags)
+	state.PostureCheckRoles = stringSetFromAPI(ctx, detail.PostureCheckRoles, diags)
+	state.Tags = tagsFromAPI(ctx, detail.Tags, diags)
+}
 
-	if serviceRoles, ok := data["serviceRoles"].([]interface{}); ok {
-		serviceRoles, diag := types.ListValueFrom(ctx, types.StringType, serviceRoles)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.ServiceRoles = serviceRoles
-	} else {
-		state.ServiceRoles = types.ListNull(types.StringType)
+// servicePolicyList lists the service policies matching filter.
+func servicePolicyList(ctx context.Context, client *zitiData, filter string) ([]*rest_model.ServicePolicyDetail, error) {
+	list, err := client.api.ServicePolicy.ListServicePolicies(&service_policy.ListServicePoliciesParams{Filter: optionalFilter(filter), Context: ctx}, nil)
+	if err != nil {
+		return nil, err
 	}
-
-	if postureCheckRoles, ok := data["postureCheckRoles"].([]interface{}); ok {
-		postureCheckRoles, diag := types.ListValueFrom(ctx, types.StringType, postureCheckRoles)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.PostureCheckRoles = postureCheckRoles
-	} else {
-		state.PostureCheckRoles = types.ListNull(types.StringType)
+	if list.Payload == nil {
+		return nil, errMissingData
 	}
+	return withoutNil(list.Payload.Data), nil
+}
 
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
+// servicePolicyPage lists a page of the service policies matching filter.
+func servicePolicyPage(ctx context.Context, client *zitiData, filter string, offset, limit int64) ([]*rest_model.ServicePolicyDetail, *rest_model.Pagination, error) {
+	list, err := client.api.ServicePolicy.ListServicePolicies(&service_policy.ListServicePoliciesParams{Filter: optionalFilter(filter), Offset: &offset, Limit: &limit, Context: ctx}, nil)
+	if err != nil {
+		return nil, nil, err
 	}
-
-	// Set refreshed state
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+	if list.Payload == nil {
+		return nil, nil, errMissingData
 	}
+	return withoutNil(list.Payload.Data), paginationOf(list.Payload.Meta), nil
+}
+
+// NewServicePoliciesDataSource is a helper function to simplify the provider implementation.
+func NewServicePoliciesDataSource() datasource.DataSource {
+	return &zitiListDataSource[*rest_model.ServicePolicyDetail, servicePolicyDataSourceModel]{spec: zitiListDataSourceSpec[*rest_model.ServicePolicyDetail, servicePolicyDataSourceModel]{
+		typeName:       "_service_policies",
+		label:          "service policies",
+		items:          "service_policies",
+		itemAttributes: listItemAttributes(dataSourceAttributes(NewServicePolicyDataSource())),
+		page:           servicePolicyPage,
+		item:           servicePolicyDataSourceState,
+	}}
 }
@@ -2,64 +2,51 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
-	"fmt"
-	"net/url"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
-	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
-	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/openziti/edge-api/rest_management_api_client/service_policy"
 	"github.com/openziti/edge-api/rest_model"
-	"github.com/rs/zerolog/log"
-	"github.com/tidwall/gjson"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource                = &servicePolicyResource{}
-	_ resource.ResourceWithConfigure   = &servicePolicyResource{}
-	_ resource.ResourceWithImportState = &servicePolicyResource{}
+	_ resource.Resource                     = &servicePolicyResource{}
+	_ resource.ResourceWithConfigure        = &servicePolicyResource{}
+	_ resource.ResourceWithImportState      = &servicePolicyResource{}
+	_ resource.ResourceWithUpgradeState     = &servicePolicyResource{}
+	_ resource.ResourceWithConfigValidators = &servicePolicyResource{}
 )
 
 // NewServicePolicyResource is a helper function to simplify the provider implementation.
 func NewServicePolicyResource() resource.Resource {
-	return &servicePolicyResource{}
+	return &servicePolicyResource{newZitiResource(zitiResourceSpec[servicePolicyResourceModel, *rest_model.ServicePolicyDetail]{
+		typeName: "_service_policy",
+		label:    "service-policies",
+		create:   servicePolicyCreate,
+		read:     servicePolicyRead,
+		readData: servicePolicyReadData,
+		update:   servicePolicyUpdate,
+		delete:   servicePolicyDelete,
+		list:     servicePolicyList,
+		entityID: func(detail *rest_model.ServicePolicyDetail) string {
+			return types.StringPointerValue(detail.ID).ValueString()
+		},
+	})}
 }
 
 // servicePolicyResource is the resource implementation.
 type servicePolicyResource struct {
-	resourceConfig *zitiData
-}
-
-// Configure adds the provider configured client to the resource.
-func (r *servicePolicyResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	// Add a nil check when handling ProviderData because Terraform
-	// sets that data after it calls the ConfigureProvider RPC.
-	if req.ProviderData == nil {
-		return
-	}
-
-	resourceConfig := req.ProviderData.(*zitiData)
-	r.resourceConfig = resourceConfig
-
-	fmt.Printf("Using API Token to create resource: %s\n", r.resourceConfig.apiToken)
-	fmt.Printf("Using domain to create resource: %s\n", r.resourceConfig.host)
-}
-
-// Metadata returns the resource type name.
-func (r *servicePolicyResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
-	resp.TypeName = req.ProviderTypeName + "_service_policy"
+	zitiResource[servicePolicyResourceModel, *rest_model.ServicePolicyDetail]
 }
 
 // servicePolicyResourceModel maps the resource schema data.
@@ -67,17 +54,35 @@ type servicePolicyResourceModel struct {
 	ID                types.String `tfsdk:"id"`
 	Name              types.String `tfsdk:"name"`
 	Semantic          types.String `tfsdk:"semantic"`
-	ServiceRoles      types.List   `tfsdk:"serviceroles"`
-	IdentityRoles     types.List   `tfsdk:"identityroles"`
-	PostureCheckRoles types.List   `tfsdk:"posturecheckroles"`
+	ServiceRoles      types.Set    `tfsdk:"serviceroles"`
+	IdentityRoles     types.Set    `tfsdk:"identityroles"`
+	PostureCheckRoles types.Set    `tfsdk:"posturecheckroles"`
 	Tags              types.Map    `tfsdk:"tags"`
+	TagsAll           types.Map    `tfsdk:"tags_all"`
 	Type              types.String `tfsdk:"type"`
 	LastUpdated       types.String `tfsdk:"last_updated"`
 }
 
+// ConfigValidators warns about roles that can never match.
+func (r *servicePolicyResource) ConfigValidators(_ context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		allOfEntityRolesValidator{roles: []string{"serviceroles", "identityroles", "posturecheckroles"}},
+	}
+}
+
+// UpgradeState migrates the state of schema version 0, whose roles were lists.
+func (r *servicePolicyResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	var resp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &resp)
+	return map[int64]resource.StateUpgrader{
+		0: roleSetsUpgrader(resp.Schema, "serviceroles", "identityroles", "posturecheckroles"),
+	}
+}
+
 // Schema defines the schema for the resource.
 func (r *servicePolicyResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version:             1,
 		MarkdownDescription: "Ziti Service Policy Resource",
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -104,25 +109,34 @@ func (r *servicePolicyResource) Schema(_ context.Context, _ resource.SchemaReque
 				},
 				MarkdownDescription: "Semantic Value",
 			},
-			"serviceroles": schema.ListAttribute{
-				ElementType:         types.StringType,
-				Optional:            true,
-				Computed:            true,
-				Default:             listdefault.StaticValue(types.ListNull(types.StringType)),
+			"serviceroles": schema.SetAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Computed:    true,
+				Default:     setdefault.StaticValue(types.SetNull(types.StringType)),
+				Validators: []validator.Set{
+					validRoles(),
+				},
 				MarkdownDescription: "Service Roles",
 			},
-			"identityroles": schema.ListAttribute{
-				ElementType:         types.StringType,
-				Optional:            true,
-				Computed:            true,
-				Default:             listdefault.StaticValue(types.ListNull(types.StringType)),
+			"identityroles": schema.SetAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Computed:    true,
+				Default:     setdefault.StaticValue(types.SetNull(types.StringType)),
+				Validators: []validator.Set{
+					validRoles(),
+				},
 				MarkdownDescription: "Identity Roles",
 			},
-			"posturecheckroles": schema.ListAttribute{
-				ElementType:         types.StringType,
-				Optional:            true,
-				Computed:            true,
-				Default:             listdefault.StaticValue(types.ListNull(types.StringType)),
+			"posturecheckroles": schema.SetAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Computed:    true,
+				Default:     setdefault.StaticValue(types.SetNull(types.StringType)),
+				Validators: []validator.Set{
+					validRoles(),
+				},
 				MarkdownDescription: "Posture Check Roles",
 			},
 			"tags": schema.MapAttribute{
@@ -132,6 +146,7 @@ func (r *servicePolicyResource) Schema(_ context.Context, _ resource.SchemaReque
 				Default:             mapdefault.StaticValue(types.MapNull(types.StringType)),
 				MarkdownDescription: "Service Policy Tags",
 			},
+			"tags_all": tagsAllAttribute(),
 			"type": schema.StringAttribute{
 				Computed: true,
 				Optional: true,
@@ -145,17 +160,7 @@ func (r *servicePolicyResource) Schema(_ context.Context, _ resource.SchemaReque
 	}
 }
 
-// Create a new resource.
-func (r *servicePolicyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	// Retrieve values from plan
-	var eplan servicePolicyResourceModel
-
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func servicePolicyCreate(ctx context.Context, client *zitiData, eplan *servicePolicyResourceModel, diags *diag.Diagnostics) (string, error) {
 	name := eplan.Name.ValueString()
 	semantic := rest_model.Semantic(eplan.Semantic.ValueString())
 	type_ := rest_model.DialBind(eplan.Type.ValueString())
@@ -192,155 +197,14 @@ func (r *servicePolicyResource) Create(ctx context.Context, req resource.CreateR
 		Type:              &type_,
 	}
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************create resource payload***********************:\n %s\n", jsonData)
-
-	authUrl := fmt.Sprintf("%s/service-policies", r.resourceConfig.host)
-	cresp, err := CreateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti POST Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Creating service-policies", "Could not Create service-policies, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	fmt.Printf("**********************create response************************:\n %s\n", cresp)
-	resourceID := gjson.Get(cresp, "data.id").String()
-
-	// Map response body to schema and populate Computed attribute values
-	eplan.ID = types.StringValue(resourceID)
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
-
-	// Set state to fully populated data
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-}
-
-// Read resource information.
-func (r *servicePolicyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	// Get current state
-	var state servicePolicyResourceModel
-	tflog.Debug(ctx, "Reading Service Policy")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	authUrl := fmt.Sprintf("%s/service-policies/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := ReadZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		if errors.Is(err, errNotFound) {
-			msg := fmt.Sprintf("Resource not found in backend; removing from state, id: %s", state.ID.ValueString())
-			log.Info().Msg(msg)
-			resp.State.RemoveResource(ctx)
-			return
-		}
-		resp.Diagnostics.AddError(
-			"Error Reading service-policies", "Could not READ service-policies, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
+	created, err := client.api.ServicePolicy.CreateServicePolicy(&service_policy.CreateServicePolicyParams{Policy: &payload, Context: ctx}, nil)
 	if err != nil {
-		// Handle error
-		resp.Diagnostics.AddError(
-			"Error Reading service-policies", fmt.Sprintf("Could not READ service-policies, ERROR %v: ", err.Error()),
-		)
-		return
-	}
-
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	data, ok := jsonBody["data"].(map[string]interface{})
-	if !ok {
-		resp.Diagnostics.AddError("Error: ", "'data' is either missing or not a map[string]interface{}")
-		return
-	}
-
-	// Manually assign individual values from the map to the struct fields
-	state.Name = types.StringValue(data["name"].(string))
-
-	if semanticValue, ok := data["semantic"].(string); ok {
-		state.Semantic = types.StringValue(semanticValue)
-	}
-
-	if typeValue, ok := data["type"].(string); ok {
-		state.Type = types.StringValue(typeValue)
-	}
-
-	if identityRoles, ok := data["identityRoles"].([]interface{}); ok {
-		identityRoles, diag := types.ListValueFrom(ctx, types.StringType, identityRoles)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.IdentityRoles = identityRoles
-	} else {
-		state.IdentityRoles = types.ListNull(types.StringType)
-	}
-
-	if serviceRoles, ok := data["serviceRoles"].([]interface{}); ok {
-		serviceRoles, diag := types.ListValueFrom(ctx, types.StringType, serviceRoles)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.ServiceRoles = serviceRoles
-	} else {
-		state.ServiceRoles = types.ListNull(types.StringType)
-	}
-
-	if postureCheckRoles, ok := data["postureCheckRoles"].([]interface{}); ok {
-		postureCheckRoles, diag := types.ListValueFrom(ctx, types.StringType, postureCheckRoles)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.PostureCheckRoles = postureCheckRoles
-	} else {
-		state.PostureCheckRoles = types.ListNull(types.StringType)
-	}
-
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
-	}
-
-	// Set refreshed state
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+		return "", err
 	}
+	return createdID(created.Payload)
 }
 
-// Update updates the resource and sets the updated Terraform state on success.
-func (r *servicePolicyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	// Retrieve values from plan
-	var eplan servicePolicyResourceModel
-	tflog.Debug(ctx, "Updating Service Policy")
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	// Get current state
-	var state servicePolicyResourceModel
-	sdiags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(sdiags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func servicePolicyUpdate(ctx context.Context, client *zitiData, id string, eplan *servicePolicyResourceModel, diags *diag.Diagnostics) error {
 	name := eplan.Name.ValueString()
 	semantic := rest_model.Semantic(eplan.Semantic.ValueString())
 	type_ := rest_model.DialBind(eplan.Type.ValueString())
@@ -377,55 +241,39 @@ func (r *servicePolicyResource) Update(ctx context.Context, req resource.UpdateR
 		Type:              &type_,
 	}
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************update resource payload***********************:\n %s\n", jsonData)
+	_, err := client.api.ServicePolicy.UpdateServicePolicy(&service_policy.UpdateServicePolicyParams{ID: id, Policy: &payload, Context: ctx}, nil)
+	return err
+}
 
-	authUrl := fmt.Sprintf("%s/service-policies/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := UpdateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti PUT Response: %s", cresp)
-	log.Info().Msg(msg)
+func servicePolicyRead(ctx context.Context, client *zitiData, id string) (*rest_model.ServicePolicyDetail, error) {
+	detail, err := client.api.ServicePolicy.DetailServicePolicy(&service_policy.DetailServicePolicyParams{ID: id, Context: ctx}, nil)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Updating service-policies", "Could not Update service-policies, unexpected error: "+err.Error(),
-		)
-		return
+		return nil, err
 	}
-
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
-
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+	if detail.Payload == nil || detail.Payload.Data == nil {
+		return nil, errMissingData
 	}
+	return detail.Payload.Data, nil
 }
 
-// Delete deletes the resource and removes the Terraform state on success.
-func (r *servicePolicyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	// Retrieve values from state
-	var state servicePolicyResourceModel
-	tflog.Debug(ctx, "Deleting Service Policy")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
+func servicePolicyDelete(ctx context.Context, client *zitiData, id string) error {
+	_, err := client.api.ServicePolicy.DeleteServicePolicy(&service_policy.DeleteServicePolicyParams{ID: id, Context: ctx}, nil)
+	return err
+}
 
-	authUrl := fmt.Sprintf("%s/service-policies/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
+func servicePolicyReadData(ctx context.Context, detail *rest_model.ServicePolicyDetail, state *servicePolicyResourceModel, diags *diag.Diagnostics) {
+	state.Name = types.StringPointerValue(detail.Name)
 
-	cresp, err := DeleteZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti Delete Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Deleting service-policies", "Could not DELETE service-policies, unexpected error: "+err.Error(),
-		)
-		return
+	if detail.Semantic != nil {
+		state.Semantic = types.StringValue(string(*detail.Semantic))
+	}
+
+	if detail.Type != nil {
+		state.Type = types.StringValue(string(*detail.Type))
 	}
-}
 
-func (r *servicePolicyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	state.IdentityRoles = stringSetFromAPI(ctx, detail.IdentityRoles, diags)
+	state.ServiceRoles = stringSetFromAPI(ctx, detail.ServiceRoles, diags)
+	state.PostureCheckRoles = stringSetFromAPI(ctx, detail.PostureCheckRoles, diags)
+	state.Tags = tagsFromAPI(ctx, detail.Tags, diags)
 }
@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"testing"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccServicePolicyResource(t *testing.T) {
+	controller := testAccController(t)
+	const resourceName = "ziti_service_policy.test"
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_service_policy" "test" {
+  name          = "test-service-policy"
+  semantic      = "AnyOf"
+  type          = "Dial"
+  identityroles = ["#clients", "#admins"]
+  serviceroles  = ["#web"]
+}
+`
+	update := testAccProviderConfig(controller, "") + `
+resource "ziti_service_policy" "test" {
+  name              = "test-service-policy"
+  semantic          = "AnyOf"
+  type              = "Dial"
+  identityroles     = ["#clients", "#admins"]
+  serviceroles      = ["#web", "#api"]
+  posturecheckroles = ["#mfa"]
+}
+`
+
+	steps := []resource.TestStep{{
+		Config: config,
+		Check: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckEntityExists(controller, mockcontroller.ServicePolicies, resourceName),
+			resource.TestCheckResourceAttr(resourceName, "name", "test-service-policy"),
+			resource.TestCheckResourceAttr(resourceName, "type", "Dial"),
+			resource.TestCheckTypeSetElemAttr(resourceName, "identityroles.*", "#clients"),
+			resource.TestCheckTypeSetElemAttr(resourceName, "identityroles.*", "#admins"),
+		),
+	}}
+	steps = append(steps, testAccImportSteps(resourceName)...)
+	steps = append(steps,
+		testAccUpdateStep(resourceName, update,
+			resource.TestCheckResourceAttr(resourceName, "serviceroles.#", "2"),
+			resource.TestCheckTypeSetElemAttr(resourceName, "posturecheckroles.*", "#mfa"),
+		),
+		// The controller may return the roles in any order.
+		resource.TestStep{
+			PreConfig: func() {
+				id := testAccEntityID(t, controller, mockcontroller.ServicePolicies, "test-service-policy")
+				controller.PatchEntity(mockcontroller.ServicePolicies, id, map[string]interface{}{"identityRoles": []interface{}{"#admins", "#clients"}})
+			},
+			Config:   update,
+			PlanOnly: true,
+		},
+	)
+	steps = append(steps, testAccOutOfBandSteps(t, controller, mockcontroller.ServicePolicies, "test-service-policy", resourceName, update,
+		map[string]interface{}{"type": "Bind"})...)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    steps,
+	})
+}
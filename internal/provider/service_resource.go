@@ -2,14 +2,9 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
-	"fmt"
-	"net/url"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
-	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
@@ -21,10 +16,8 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/openziti/edge-api/rest_management_api_client/service"
 	"github.com/openziti/edge-api/rest_model"
-	"github.com/rs/zerolog/log"
-	"github.com/tidwall/gjson"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -36,32 +29,24 @@ var (
 
 // NewServiceResource is a helper function to simplify the provider implementation.
 func NewServiceResource() resource.Resource {
-	return &serviceResource{}
+	return &serviceResource{newZitiResource(zitiResourceSpec[serviceResourceModel, *rest_model.ServiceDetail]{
+		typeName: "_service",
+		label:    "services",
+		create:   serviceCreate,
+		read:     serviceRead,
+		readData: serviceReadData,
+		update:   serviceUpdate,
+		delete:   serviceDelete,
+		list:     serviceList,
+		entityID: func(detail *rest_model.ServiceDetail) string {
+			return types.StringPointerValue(detail.ID).ValueString()
+		},
+	})}
 }
 
 // serviceResource is the resource implementation.
 type serviceResource struct {
-	resourceConfig *zitiData
-}
-
-// Configure adds the provider configured client to the resource.
-func (r *serviceResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	// Add a nil check when handling ProviderData because Terraform
-	// sets that data after it calls the ConfigureProvider RPC.
-	if req.ProviderData == nil {
-		return
-	}
-
-	resourceConfig := req.ProviderData.(*zitiData)
-	r.resourceConfig = resourceConfig
-
-	fmt.Printf("Using API Token to create resource: %s\n", r.resourceConfig.apiToken)
-	fmt.Printf("Using domain to create resource: %s\n", r.resourceConfig.host)
-}
-
-// Metadata returns the resource type name.
-func (r *serviceResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
-	resp.TypeName = req.ProviderTypeName + "_service"
+	zitiResource[serviceResourceModel, *rest_model.ServiceDetail]
 }
 
 // serviceResourceModel maps the resource schema data.
@@ -74,6 +59,7 @@ type serviceResourceModel struct {
 	RoleAttributes          types.Set    `tfsdk:"role_attributes"`
 	TerminatorStrategy      types.String `tfsdk:"terminator_strategy"`
 	Tags                    types.Map    `tfsdk:"tags"`
+	TagsAll                 types.Map    `tfsdk:"tags_all"`
 	LastUpdated             types.String `tfsdk:"last_updated"`
 }
 
@@ -139,21 +125,12 @@ func (r *serviceResource) Schema(_ context.Context, _ resource.SchemaRequest, re
 				Default:             mapdefault.StaticValue(types.MapNull(types.StringType)),
 				MarkdownDescription: "Service Tags",
 			},
+			"tags_all": tagsAllAttribute(),
 		},
 	}
 }
 
-// Create a new resource.
-func (r *serviceResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	// Retrieve values from plan
-	var eplan serviceResourceModel
-
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func serviceCreate(ctx context.Context, client *zitiData, eplan *serviceResourceModel, diags *diag.Diagnostics) (string, error) {
 	name := eplan.Name.ValueString()
 	encryptionRequired := eplan.EncryptionRequired.ValueBool()
 	maxIdleMilliseconds := eplan.MaxIdleTimeMilliseconds.ValueInt64()
@@ -184,151 +161,14 @@ func (r *serviceResource) Create(ctx context.Context, req resource.CreateRequest
 		Tags:               tags,
 	}
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************create resource payload***********************:\n %s\n", jsonData)
-
-	authUrl := fmt.Sprintf("%s/services", r.resourceConfig.host)
-	cresp, err := CreateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti POST Response: %s", cresp)
-	log.Info().Msg(msg)
+	created, err := client.api.Service.CreateService(&service.CreateServiceParams{Service: &payload, Context: ctx}, nil)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Creating services", "Could not Create services, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	fmt.Printf("**********************create response************************:\n %s\n", cresp)
-	resourceID := gjson.Get(cresp, "data.id").String()
-
-	// Map response body to schema and populate Computed attribute values
-	eplan.ID = types.StringValue(resourceID)
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
-
-	// Set state to fully populated data
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-}
-
-// Read resource information.
-func (r *serviceResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	// Get current state
-	var state serviceResourceModel
-	tflog.Debug(ctx, "Reading Service")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	authUrl := fmt.Sprintf("%s/services/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := ReadZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti GET Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		if errors.Is(err, errNotFound) {
-			msg := fmt.Sprintf("Resource not found in backend; removing from state, id: %s", state.ID.ValueString())
-			log.Info().Msg(msg)
-			resp.State.RemoveResource(ctx)
-			return
-		}
-		resp.Diagnostics.AddError(
-			"Error Reading services", "Could not READ services, unexpected error: "+err.Error(),
-		)
-		return
-	}
-
-	var jsonBody map[string]interface{}
-	err = json.Unmarshal([]byte(cresp), &jsonBody)
-	if err != nil {
-		// Handle error
-		resp.Diagnostics.AddError(
-			"Error Reading services", fmt.Sprintf("Could not READ services, ERROR %v: ", err.Error()),
-		)
-		return
-	}
-
-	stringBody := string(cresp)
-	fmt.Printf("**********************read response************************:\n %s\n", stringBody)
-
-	data, ok := jsonBody["data"].(map[string]interface{})
-	if !ok {
-		resp.Diagnostics.AddError("Error: ", "'data' is either missing or not a map[string]interface{}")
-		return
-	}
-
-	// Manually assign individual values from the map to the struct fields
-	state.Name = types.StringValue(data["name"].(string))
-
-	if terminatorStrategy, ok := data["terminatorStrategy"].(string); ok {
-		state.TerminatorStrategy = types.StringValue(terminatorStrategy)
-	}
-
-	if maxIdleTimeMilliseconds, ok := data["maxIdleTimeMillis"].(float64); ok {
-		state.MaxIdleTimeMilliseconds = types.Int64Value(int64(maxIdleTimeMilliseconds))
-	}
-
-	if encryptionRequired, ok := data["encryptionRequired"].(bool); ok {
-		state.EncryptionRequired = types.BoolValue(encryptionRequired)
-	}
-
-	if configs, ok := data["configs"].([]interface{}); ok {
-		configs, diag := types.SetValueFrom(ctx, types.StringType, configs)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.Configs = configs
-	} else {
-		state.Configs = types.SetNull(types.StringType)
-	}
-
-	if roleAttributes, ok := data["roleAttributes"].([]interface{}); ok {
-		roleAttributes, diag := types.SetValueFrom(ctx, types.StringType, roleAttributes)
-		resp.Diagnostics = append(resp.Diagnostics, diag...)
-		state.RoleAttributes = roleAttributes
-	} else {
-		state.RoleAttributes = types.SetNull(types.StringType)
-	}
-
-	if _tags, ok := data["tags"].(map[string]interface{}); ok {
-		if len(_tags) != 0 {
-			_tags, diag := types.MapValueFrom(ctx, types.StringType, _tags)
-			resp.Diagnostics = append(resp.Diagnostics, diag...)
-			state.Tags = _tags
-		} else {
-			state.Tags = types.MapNull(types.StringType)
-		}
-	}
-
-	// Set refreshed state
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+		return "", err
 	}
+	return createdID(created.Payload)
 }
 
-// Update updates the resource and sets the updated Terraform state on success.
-func (r *serviceResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	// Retrieve values from plan
-	var eplan serviceResourceModel
-	tflog.Debug(ctx, "Updating Service")
-	diags := req.Plan.Get(ctx, &eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	// Get current state
-	var state serviceResourceModel
-	sdiags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(sdiags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
+func serviceUpdate(ctx context.Context, client *zitiData, id string, eplan *serviceResourceModel, diags *diag.Diagnostics) error {
 	name := eplan.Name.ValueString()
 	encryptionRequired := eplan.EncryptionRequired.ValueBool()
 	maxIdleMilliseconds := eplan.MaxIdleTimeMilliseconds.ValueInt64()
@@ -359,55 +199,42 @@ func (r *serviceResource) Update(ctx context.Context, req resource.UpdateRequest
 		Tags:               tags,
 	}
 
-	// Convert the payload to JSON
-	jsonData, _ := json.Marshal(payload)
-	fmt.Printf("**********************update resource payload***********************:\n %s\n", jsonData)
+	_, err := client.api.Service.UpdateService(&service.UpdateServiceParams{ID: id, Service: &payload, Context: ctx}, nil)
+	return err
+}
 
-	authUrl := fmt.Sprintf("%s/services/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
-	cresp, err := UpdateZitiResource(authUrl, r.resourceConfig.apiToken, jsonData)
-	msg := fmt.Sprintf("Ziti PUT Response: %s", cresp)
-	log.Info().Msg(msg)
+func serviceRead(ctx context.Context, client *zitiData, id string) (*rest_model.ServiceDetail, error) {
+	detail, err := client.api.Service.DetailService(&service.DetailServiceParams{ID: id, Context: ctx}, nil)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Updating services", "Could not Update services, unexpected error: "+err.Error(),
-		)
-		return
+		return nil, err
 	}
-
-	eplan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
-
-	diags = resp.State.Set(ctx, eplan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+	if detail.Payload == nil || detail.Payload.Data == nil {
+		return nil, errMissingData
 	}
+	return detail.Payload.Data, nil
+}
+
+func serviceDelete(ctx context.Context, client *zitiData, id string) error {
+	_, err := client.api.Service.DeleteService(&service.DeleteServiceParams{ID: id, Context: ctx}, nil)
+	return err
 }
 
-// Delete deletes the resource and removes the Terraform state on success.
-func (r *serviceResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	// Retrieve values from state
-	var state serviceResourceModel
-	tflog.Debug(ctx, "Deleting Service")
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+func serviceReadData(ctx context.Context, detail *rest_model.ServiceDetail, state *serviceResourceModel, diags *diag.Diagnostics) {
+	state.Name = types.StringPointerValue(detail.Name)
+
+	if detail.TerminatorStrategy != nil {
+		state.TerminatorStrategy = types.StringValue(*detail.TerminatorStrategy)
 	}
 
-	authUrl := fmt.Sprintf("%s/services/%s", r.resourceConfig.host, url.QueryEscape(state.ID.ValueString()))
+	if detail.MaxIdleTimeMillis != nil {
+		state.MaxIdleTimeMilliseconds = types.Int64Value(*detail.MaxIdleTimeMillis)
+	}
 
-	cresp, err := DeleteZitiResource(authUrl, r.resourceConfig.apiToken)
-	msg := fmt.Sprintf("Ziti Delete Response: %s", cresp)
-	log.Info().Msg(msg)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error Deleting services", "Could not DELETE services, unexpected error: "+err.Error(),
-		)
-		return
+	if detail.EncryptionRequired != nil {
+		state.EncryptionRequired = types.BoolValue(*detail.EncryptionRequired)
 	}
-}
 
-func (r *serviceResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	state.Configs = stringSetFromAPI(ctx, detail.Configs, diags)
+	state.RoleAttributes = roleAttributesFromAPI(ctx, detail.RoleAttributes, diags)
+	state.Tags = tagsFromAPI(ctx, detail.Tags, diags)
 }
@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"testing"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccServiceResource(t *testing.T) {
+	controller := testAccController(t)
+	const resourceName = "ziti_service.test"
+	intercept := testAccProviderConfig(controller, "") + `
+resource "ziti_intercept_v1_config" "test" {
+  name        = "test-service.intercept.v1"
+  addresses   = ["web.ziti"]
+  protocols   = ["tcp"]
+  port_ranges = [{ low = 443, high = 443 }]
+}
+`
+	config := intercept + `
+resource "ziti_service" "test" {
+  name            = "test-service"
+  role_attributes = ["web"]
+}
+`
+	update := intercept + `
+resource "ziti_service" "test" {
+  name                = "test-service"
+  role_attributes     = ["web", "public"]
+  configs             = [ziti_intercept_v1_config.test.id]
+  terminator_strategy = "weighted"
+}
+`
+
+	steps := []resource.TestStep{{
+		Config: config,
+		Check: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckEntityExists(controller, mockcontroller.Services, resourceName),
+			resource.TestCheckResourceAttr(resourceName, "name", "test-service"),
+			resource.TestCheckResourceAttr(resourceName, "encryption_required", "true"),
+			resource.TestCheckResourceAttr(resourceName, "role_attributes.#", "1"),
+		),
+	}}
+	steps = append(steps, testAccImportSteps(resourceName)...)
+	steps = append(steps, testAccUpdateStep(resourceName, update,
+		resource.TestCheckResourceAttr(resourceName, "role_attributes.#", "2"),
+		resource.TestCheckTypeSetElemAttrPair(resourceName, "configs.*", "ziti_intercept_v1_config.test", "id"),
+		resource.TestCheckResourceAttr(resourceName, "terminator_strategy", "weighted"),
+	))
+	steps = append(steps, testAccOutOfBandSteps(t, controller, mockcontroller.Services, "test-service", resourceName, update,
+		map[string]interface{}{"encryptionRequired": false})...)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    steps,
+	})
+}
@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"context"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// roleSetsUpgrader returns the state upgrader from schema version 0, in which
+// the role attributes of a resource were lists, to current, in which they are
+// sets. The roles keep their values; duplicates are dropped.
+func roleSetsUpgrader(current schema.Schema, roles ...string) resource.StateUpgrader {
+	prior := current
+	prior.Version = 0
+	prior.Attributes = make(map[string]schema.Attribute, len(current.Attributes))
+	for name, attribute := range current.Attributes {
+		prior.Attributes[name] = attribute
+	}
+	for _, name := range roles {
+		set := current.Attributes[name].(schema.SetAttribute)
+		prior.Attributes[name] = schema.ListAttribute{
+			ElementType: set.ElementType,
+			Optional:    set.Optional,
+			Computed:    set.Computed,
+		}
+	}
+
+	return resource.StateUpgrader{
+		PriorSchema: &prior,
+		StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+			var attributes map[string]tftypes.Value
+			if err := req.State.Raw.As(&attributes); err != nil {
+				resp.Diagnostics.AddError("Error Upgrading State", "Could not read the prior state: "+err.Error())
+				return
+			}
+			for _, name := range roles {
+				attributes[name] = listToSet(attributes[name])
+			}
+			resp.State.Raw = tftypes.NewValue(current.Type().TerraformType(ctx), attributes)
+		},
+	}
+}
+
+// listToSet converts a list of strings in a prior state to a set.
+func listToSet(list tftypes.Value) tftypes.Value {
+	setType := tftypes.Set{ElementType: tftypes.String}
+	if !list.IsKnown() {
+		return tftypes.NewValue(setType, tftypes.UnknownValue)
+	}
+	if list.IsNull() {
+		return tftypes.NewValue(setType, nil)
+	}
+	var elements []tftypes.Value
+	_ = list.As(&elements)
+	seen := map[string]bool{}
+	var values []string
+	for _, element := range elements {
+		var value string
+		if err := element.As(&value); err != nil || seen[value] {
+			continue
+		}
+		seen[value] = true
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	set := make([]tftypes.Value, len(values))
+	for i, value := range values {
+		set[i] = tftypes.NewValue(tftypes.String, value)
+	}
+	return tftypes.NewValue(setType, set)
+}
@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestRoleSetsUpgrade(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	r := NewServicePolicyResource()
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+	upgrader := r.(fwresource.ResourceWithUpgradeState).UpgradeState(ctx)[0]
+
+	// The state of version 0 held the roles as lists, in the order of the
+	// configuration.
+	prior := tfsdk.State{Schema: upgrader.PriorSchema, Raw: testValue(t, upgrader.PriorSchema.Type(), `{
+  "id": "policy1",
+  "name": "web-dial",
+  "semantic": "AnyOf",
+  "type": "Dial",
+  "identityroles": ["#clients", "#admins", "#clients"],
+  "serviceroles": ["#web"],
+  "last_updated": "Mon, 02 Jan 2006 15:04:05 MST"
+}`)}
+	resp := fwresource.UpgradeStateResponse{
+		State: tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)},
+	}
+	upgrader.StateUpgrader(ctx, fwresource.UpgradeStateRequest{State: &prior}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("upgrading the state: %v", resp.Diagnostics)
+	}
+
+	var id types.String
+	var identityRoles, serviceRoles, postureCheckRoles types.Set
+	resp.Diagnostics.Append(resp.State.GetAttribute(ctx, path.Root("id"), &id)...)
+	resp.Diagnostics.Append(resp.State.GetAttribute(ctx, path.Root("identityroles"), &identityRoles)...)
+	resp.Diagnostics.Append(resp.State.GetAttribute(ctx, path.Root("serviceroles"), &serviceRoles)...)
+	resp.Diagnostics.Append(resp.State.GetAttribute(ctx, path.Root("posturecheckroles"), &postureCheckRoles)...)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("reading the upgraded state: %v", resp.Diagnostics)
+	}
+	if id.ValueString() != "policy1" {
+		t.Errorf("id = %s, want policy1", id)
+	}
+	if len(identityRoles.Elements()) != 2 || len(serviceRoles.Elements()) != 1 {
+		t.Errorf("identityroles = %s, serviceroles = %s, want the roles without duplicates", identityRoles, serviceRoles)
+	}
+	if !postureCheckRoles.IsNull() {
+		t.Errorf("posturecheckroles = %s, want null", postureCheckRoles)
+	}
+}
@@ -0,0 +1,215 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// tagsConfig is the tag configuration of the provider: default_tags, which
+// every resource carries unless its own tags override them, and ignore_tags,
+// the tags managed by other tooling, which the provider neither reports nor
+// removes.
+type tagsConfig struct {
+	defaults       map[string]string
+	ignoreKeys     map[string]bool
+	ignorePrefixes []string
+}
+
+// defaultTagsModel maps the default_tags block of the provider.
+type defaultTagsModel struct {
+	Tags types.Map `tfsdk:"tags"`
+}
+
+// ignoreTagsModel maps the ignore_tags block of the provider.
+type ignoreTagsModel struct {
+	Keys        types.Set `tfsdk:"keys"`
+	KeyPrefixes types.Set `tfsdk:"key_prefixes"`
+}
+
+// newTagsConfig returns the tag configuration of the default_tags and
+// ignore_tags blocks; either may be nil when the block is not set.
+func newTagsConfig(ctx context.Context, defaultTags *defaultTagsModel, ignoreTags *ignoreTagsModel, diags *diag.Diagnostics) tagsConfig {
+	config := tagsConfig{defaults: map[string]string{}, ignoreKeys: map[string]bool{}}
+	if defaultTags != nil && !defaultTags.Tags.IsNull() {
+		diags.Append(defaultTags.Tags.ElementsAs(ctx, &config.defaults, false)...)
+	}
+	if ignoreTags != nil {
+		var keys []string
+		if !ignoreTags.Keys.IsNull() {
+			diags.Append(ignoreTags.Keys.ElementsAs(ctx, &keys, false)...)
+		}
+		for _, key := range keys {
+			config.ignoreKeys[key] = true
+		}
+		if !ignoreTags.KeyPrefixes.IsNull() {
+			diags.Append(ignoreTags.KeyPrefixes.ElementsAs(ctx, &config.ignorePrefixes, false)...)
+		}
+	}
+	config.warnIgnored(config.defaults, path.Root("default_tags").AtName("tags"), diags)
+	return config
+}
+
+// ignoresAny reports whether ignore_tags is set.
+func (c tagsConfig) ignoresAny() bool {
+	return len(c.ignoreKeys) > 0 || len(c.ignorePrefixes) > 0
+}
+
+// ignored reports whether the tag key is left to other tooling.
+func (c tagsConfig) ignored(key string) bool {
+	if c.ignoreKeys[key] {
+		return true
+	}
+	for _, prefix := range c.ignorePrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// merge returns the default tags with tags, the tags of a resource, set over
+// them.
+func (c tagsConfig) merge(tags map[string]string) map[string]string {
+	merged := make(map[string]string, len(c.defaults)+len(tags))
+	for key, value := range c.defaults {
+		merged[key] = value
+	}
+	for key, value := range tags {
+		merged[key] = value
+	}
+	return merged
+}
+
+// split splits the tags read from the controller into tags_all, every tag but
+// the ignored ones, and tags, which leaves out the tags equal to a default tag
+// unless configured, the tags in the configuration of the resource, sets them
+// too. A configured or default tag is kept even if ignore_tags matches it, as
+// it would otherwise be planned on every run.
+func (c tagsConfig) split(read, configured map[string]string) (tags, all map[string]string) {
+	tags, all = map[string]string{}, map[string]string{}
+	for key, value := range read {
+		_, isConfigured := configured[key]
+		defaultValue, isDefault := c.defaults[key]
+		if c.ignored(key) && !isConfigured && !isDefault {
+			continue
+		}
+		all[key] = value
+		if isDefault && defaultValue == value && !isConfigured {
+			continue
+		}
+		tags[key] = value
+	}
+	return tags, all
+}
+
+// warnIgnored warns about the tags that ignore_tags matches but that are kept
+// as they are set in the configuration at path.
+func (c tagsConfig) warnIgnored(tags map[string]string, at path.Path, diags *diag.Diagnostics) {
+	for key := range tags {
+		if c.ignored(key) {
+			diags.AddAttributeWarning(at.AtMapKey(key), "Ignored Tag Is Configured",
+				fmt.Sprintf("The tag %q matches ignore_tags of the provider, but it is managed as it is set in the configuration. Remove it from the configuration or from ignore_tags.", key))
+		}
+	}
+}
+
+// tagsAllAttribute is the tags_all attribute of a resource.
+func tagsAllAttribute() schema.MapAttribute {
+	return schema.MapAttribute{
+		Computed:            true,
+		ElementType:         types.StringType,
+		MarkdownDescription: "All tags of the resource: `tags` merged over the `default_tags` of the provider, without the tags matched by `ignore_tags`.",
+	}
+}
+
+// knownTags returns the values of a tags attribute. It reports false when the
+// map or one of its values is unknown.
+func knownTags(tags types.Map) (map[string]string, bool) {
+	if tags.IsUnknown() {
+		return nil, false
+	}
+	values := make(map[string]string, len(tags.Elements()))
+	for key, element := range tags.Elements() {
+		value, ok := element.(types.String)
+		if !ok || value.IsUnknown() {
+			return nil, false
+		}
+		if !value.IsNull() {
+			values[key] = value.ValueString()
+		}
+	}
+	return values, true
+}
+
+// mapKnown reports whether a map of strings and its values are known.
+func mapKnown(tags types.Map) bool {
+	_, ok := knownTags(tags)
+	return ok
+}
+
+// setKnown reports whether a set and its elements are known.
+func setKnown(set types.Set) bool {
+	if set.IsUnknown() {
+		return false
+	}
+	for _, element := range set.Elements() {
+		if element.IsUnknown() {
+			return false
+		}
+	}
+	return true
+}
+
+// tagsValue converts tags to a tags attribute; no tags are stored as null, like
+// tagsFromAPI.
+func tagsValue(ctx context.Context, tags map[string]string, diags *diag.Diagnostics) types.Map {
+	if len(tags) == 0 {
+		return types.MapNull(types.StringType)
+	}
+	value, d := types.MapValueFrom(ctx, types.StringType, tags)
+	diags.Append(d...)
+	return value
+}
+
+// ignoredTagsOf returns the ignored tags of a fetched resource, with the
+// values stringified as in tagsFromAPI.
+func (c tagsConfig) ignoredTagsOf(detail any) (map[string]string, error) {
+	// Every detail model of the management API carries its tags in a tags
+	// field, so they are picked from its JSON encoding.
+	encoded, err := json.Marshal(detail)
+	if err != nil {
+		return nil, err
+	}
+	var entity struct {
+		Tags map[string]interface{} `json:"tags"`
+	}
+	if err := json.Unmarshal(encoded, &entity); err != nil {
+		return nil, err
+	}
+	ignored := map[string]string{}
+	for key, value := range entity.Tags {
+		if !c.ignored(key) {
+			continue
+		}
+		if s, ok := value.(string); ok {
+			ignored[key] = s
+			continue
+		}
+		encoded, _ := json.Marshal(value)
+		ignored[key] = string(encoded)
+	}
+	return ignored, nil
+}
+
+// tagsPath and tagsAllPath are the tag attributes every resource has.
+var (
+	tagsPath    = path.Root("tags")
+	tagsAllPath = path.Root("tags_all")
+)
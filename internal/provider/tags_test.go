@@ -0,0 +1,189 @@
+package provider
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"terraform-provider-ziti/internal/mockcontroller"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestTagsConfigSplit(t *testing.T) {
+	t.Parallel()
+	c := tagsConfig{
+		defaults:       map[string]string{"owner": "platform", "managed_by": "terraform"},
+		ignoreKeys:     map[string]bool{"scanner": true},
+		ignorePrefixes: []string{"external:"},
+	}
+	read := map[string]string{
+		"owner":         "payments",
+		"managed_by":    "terraform",
+		"env":           "dev",
+		"scanner":       "2024-01-01",
+		"external:team": "x",
+	}
+	tags, all := c.split(read, map[string]string{"env": "dev", "owner": "payments"})
+	if want := map[string]string{"owner": "payments", "env": "dev"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("tags = %v, want %v", tags, want)
+	}
+	if want := map[string]string{"owner": "payments", "managed_by": "terraform", "env": "dev"}; !reflect.DeepEqual(all, want) {
+		t.Errorf("tags_all = %v, want %v", all, want)
+	}
+
+	// A tag equal to its default stays in tags when it is configured.
+	tags, _ = c.split(map[string]string{"managed_by": "terraform"}, map[string]string{"managed_by": "terraform"})
+	if want := map[string]string{"managed_by": "terraform"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("tags = %v, want %v", tags, want)
+	}
+
+	// A configured tag is kept even if ignore_tags matches it.
+	tags, all = c.split(map[string]string{"scanner": "manual", "external:team": "x"}, map[string]string{"scanner": "manual"})
+	if want := map[string]string{"scanner": "manual"}; !reflect.DeepEqual(tags, want) || !reflect.DeepEqual(all, want) {
+		t.Errorf("tags = %v, tags_all = %v, want %v in both", tags, all, want)
+	}
+	var diags diag.Diagnostics
+	c.warnIgnored(map[string]string{"scanner": "manual", "env": "dev"}, tagsPath, &diags)
+	if diags.WarningsCount() != 1 || diags.HasError() {
+		t.Errorf("got %v, want a warning about the scanner tag", diags)
+	}
+}
+
+func TestAccConfiguredTagMatchingIgnoreTags(t *testing.T) {
+	controller := testAccController(t)
+	const resourceName = "ziti_service.web"
+	config := testAccProviderConfig(controller, `
+  ignore_tags {
+    keys = ["scanner"]
+  }`) + `
+resource "ziti_service" "web" {
+  name = "web"
+  tags = { scanner = "manual" }
+}
+`
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{{
+			Config: config,
+			Check: resource.ComposeAggregateTestCheckFunc(
+				resource.TestCheckResourceAttr(resourceName, "tags.scanner", "manual"),
+				resource.TestCheckResourceAttr(resourceName, "tags_all.scanner", "manual"),
+			),
+		}},
+	})
+}
+
+func TestAccDefaultAndIgnoredTags(t *testing.T) {
+	controller := testAccController(t)
+	const resourceName = "ziti_service.web"
+	provider := testAccProviderConfig(controller, `
+  default_tags {
+    tags = { owner = "platform", cost_center = "cc-42", managed_by = "terraform" }
+  }
+  ignore_tags {
+    keys         = ["scanner"]
+    key_prefixes = ["external:"]
+  }`)
+	config := provider + `
+resource "ziti_service" "web" {
+  name = "web"
+  tags = { env = "dev", owner = "payments" }
+}
+`
+	update := provider + `
+resource "ziti_service" "web" {
+  name = "web"
+  tags = { env = "prod" }
+}
+`
+	// patchTags changes the tags the controller stores for the service.
+	patchTags := func(patch func(tags map[string]interface{})) func() {
+		return func() {
+			id := testAccEntityID(t, controller, mockcontroller.Services, "web")
+			entity, _ := controller.Entity(mockcontroller.Services, id)
+			tags := entity["tags"].(map[string]interface{})
+			patch(tags)
+			controller.PatchEntity(mockcontroller.Services, id, map[string]interface{}{"tags": tags})
+		}
+	}
+	updatedTags := map[string]interface{}{
+		"env": "prod", "owner": "platform", "cost_center": "cc-42", "managed_by": "terraform",
+		"scanner": "2024-01-01", "external:team": "x",
+	}
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "tags.%", "2"),
+					resource.TestCheckResourceAttr(resourceName, "tags_all.%", "4"),
+					resource.TestCheckResourceAttr(resourceName, "tags_all.owner", "payments"),
+					resource.TestCheckResourceAttr(resourceName, "tags_all.managed_by", "terraform"),
+					testAccCheckServiceTags(controller, resourceName, map[string]interface{}{"env": "dev", "owner": "payments", "cost_center": "cc-42", "managed_by": "terraform"}),
+				),
+			},
+			// Tags set by other tooling cause no drift and survive an update.
+			{
+				PreConfig: patchTags(func(tags map[string]interface{}) {
+					tags["scanner"] = "2024-01-01"
+					tags["external:team"] = "x"
+				}),
+				Config:   config,
+				PlanOnly: true,
+			},
+			{
+				Config: update,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "tags.%", "1"),
+					resource.TestCheckResourceAttr(resourceName, "tags_all.%", "4"),
+					resource.TestCheckResourceAttr(resourceName, "tags_all.owner", "platform"),
+					testAccCheckServiceTags(controller, resourceName, updatedTags),
+				),
+			},
+			// A default tag removed out of band is put back.
+			{
+				PreConfig: patchTags(func(tags map[string]interface{}) {
+					delete(tags, "cost_center")
+				}),
+				Config: update,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{plancheck.ExpectResourceAction(resourceName, plancheck.ResourceActionUpdate)},
+				},
+				Check: testAccCheckServiceTags(controller, resourceName, updatedTags),
+			},
+			// An import leaves the default tags out of tags.
+			{
+				ResourceName:            resourceName,
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"last_updated"},
+			},
+		},
+	})
+}
+
+// testAccCheckServiceTags checks the tags the controller stores for the
+// service of resourceName.
+func testAccCheckServiceTags(controller *mockcontroller.Server, resourceName string, want map[string]interface{}) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[resourceName]
+		if !ok {
+			return fmt.Errorf("%s is not in state", resourceName)
+		}
+		entity, ok := controller.Entity(mockcontroller.Services, rs.Primary.ID)
+		if !ok {
+			return fmt.Errorf("service %s does not exist in the controller", rs.Primary.ID)
+		}
+		if got := entity["tags"]; !reflect.DeepEqual(got, want) {
+			return fmt.Errorf("controller tags = %v, want %v", got, want)
+		}
+		return nil
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"reflect"
-	"sort"
 	"strings"
 
 	"encoding/json"
@@ -44,26 +43,6 @@ func FormatMaybeRawMAC(s string) string {
 	return strings.ToLower(s)
 }
 
-func sortAttributes(ctx context.Context, listItems types.List) types.List {
-	// Get the elements from the attributes
-	var attrStrings []string
-	for _, elem := range listItems.Elements() {
-		if strElem, ok := elem.(types.String); ok {
-			attrStrings = append(attrStrings, strElem.ValueString())
-		}
-	}
-
-	// Sort the role attributes alphabetically
-	sort.Strings(attrStrings)
-
-	attributes, err := types.ListValueFrom(ctx, types.StringType, attrStrings)
-	if err != nil {
-		fmt.Printf("Error converting string list to types.list:\n %s\n", err)
-	}
-
-	return attributes
-}
-
 func JsonStructToObject(ctx context.Context, s interface{}, makeZeroNil bool, ignoreZero bool) (map[string]interface{}, error) {
 	result := make(map[string]interface{})
 
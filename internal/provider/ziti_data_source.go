@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// dataSourceFilter builds the filter a data source looks its entity up by: the
+// id when it is set, the name otherwise.
+func dataSourceFilter(id, name types.String) string {
+	filter := newFilter()
+	if id.ValueString() != "" {
+		filter.Equals("id", id.ValueString())
+	} else if name.ValueString() != "" {
+		filter.Equals("name", name.ValueString())
+	}
+	// Only a field or tag key that is not valid in a filter fails the build.
+	expression, _ := filter.Build()
+	return expression
+}
+
+// optionalFilter returns filter as the filter parameter of a list request; an
+// empty filter lists every entity.
+func optionalFilter(filter string) *string {
+	if filter == "" {
+		return nil
+	}
+	return &filter
+}
+
+// lookupDataSourceEntity lists the entities matching filter and returns the
+// only one. A failed request, or a filter that does not match exactly one
+// entity, is reported to diags and returns false. list must not return nil
+// entities.
+func lookupDataSourceEntity[D any](ctx context.Context, client *zitiData, diags *diag.Diagnostics, label, filter string, list func(ctx context.Context, client *zitiData, filter string) ([]D, error)) (D, bool) {
+	var none D
+	entities, err := list(ctx, client, filter)
+	if err != nil {
+		diags.AddError(
+			"Error Reading "+label, "Could not READ "+label+", unexpected error: "+zitiAPIErrorFrom(err).Error(),
+		)
+		return none, false
+	}
+
+	if len(entities) > 1 {
+		diags.AddError(
+			"Multiple items returned from API upon filter execution!", "Try to narrow down the filter expression: "+filter,
+		)
+		return none, false
+	}
+	if len(entities) == 0 {
+		diags.AddError(
+			"No items returned from API upon filter execution!", "Try to relax the filter expression: "+filter,
+		)
+		return none, false
+	}
+	return entities[0], true
+}
+
+// withoutNil drops the nil entries of a decoded list.
+func withoutNil[T any](items []*T) []*T {
+	kept := make([]*T, 0, len(items))
+	for _, item := range items {
+		if item != nil {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
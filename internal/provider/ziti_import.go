@@ -0,0 +1,146 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ImportState imports a resource by its ID or name. The import ID is either
+//   - a list of key=value pairs separated by commas, e.g. "name=web", where
+//     the keys are id, name and the importChecks keys of the resource, or
+//   - a bare ID, or the name of the resource when no resource has it as ID.
+func (r *zitiResource[M, D]) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	keys, err := parseImportID(req.ID, r.importKeys())
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid Import ID", err.Error())
+		return
+	}
+
+	detail, ok := r.resolveImport(ctx, keys, &resp.Diagnostics)
+	if !ok {
+		return
+	}
+	for key, value := range keys {
+		if check := r.spec.importChecks[key]; check != nil {
+			if err := check(detail, value); err != nil {
+				resp.Diagnostics.AddError("Error Importing "+r.spec.label, fmt.Sprintf("Could not import %q: %s", req.ID, err))
+				return
+			}
+		}
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), r.spec.entityID(detail))...)
+}
+
+// importKeys returns the keys a composite import ID of the resource may set.
+func (r *zitiResource[M, D]) importKeys() []string {
+	keys := []string{"id", "name"}
+	for key := range r.spec.importChecks {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys[2:])
+	return keys
+}
+
+// resolveImport fetches the resource a parsed import ID refers to. A failed
+// request, or a name that does not match exactly one resource, is reported to
+// diags and returns false.
+func (r *zitiResource[M, D]) resolveImport(ctx context.Context, keys map[string]string, diags *diag.Diagnostics) (D, bool) {
+	var none D
+	id, byID := keys["id"]
+	name, byName := keys["name"]
+	bare, isBare := keys[""]
+	switch {
+	case byID && byName:
+		diags.AddError("Invalid Import ID", "Set either id or name, not both.")
+		return none, false
+	case !byID && !byName && !isBare:
+		diags.AddError("Invalid Import ID", "Set id or name to select the "+r.spec.label+" to import.")
+		return none, false
+	case isBare:
+		id = bare
+	}
+
+	if !byName {
+		detail, err := r.spec.read(ctx, r.resourceConfig, id)
+		if err == nil {
+			return detail, true
+		}
+		err = zitiAPIErrorFrom(err)
+		if !errors.Is(err, errNotFound) || byID {
+			diags.AddError("Error Importing "+r.spec.label, "Could not READ "+r.spec.label+" "+id+", unexpected error: "+err.Error())
+			return none, false
+		}
+		name = bare
+	}
+
+	filter := dataSourceFilter(types.StringNull(), types.StringValue(name))
+	entities, err := r.spec.list(ctx, r.resourceConfig, filter)
+	if err != nil {
+		diags.AddError("Error Importing "+r.spec.label, "Could not list "+r.spec.label+" named "+name+", unexpected error: "+zitiAPIErrorFrom(err).Error())
+		return none, false
+	}
+	switch len(entities) {
+	case 0:
+		if isBare {
+			diags.AddError("Error Importing "+r.spec.label, fmt.Sprintf("No %s found with the ID or name %q.", r.spec.label, name))
+		} else {
+			diags.AddError("Error Importing "+r.spec.label, fmt.Sprintf("No %s found with the name %q.", r.spec.label, name))
+		}
+		return none, false
+	case 1:
+		return entities[0], true
+	default:
+		diags.AddError("Error Importing "+r.spec.label, fmt.Sprintf("%d %s found with the name %q; import one of them by ID.", len(entities), r.spec.label, name))
+		return none, false
+	}
+}
+
+// parseImportID splits a composite import ID, e.g. "name=web,config_type=host.v1",
+// into its key=value pairs. A comma only separates two pairs when it is
+// followed by one of keys and "=", so values may contain commas. An import ID
+// that does not start with one of keys and "=" is returned under the key "".
+func parseImportID(importID string, keys []string) (map[string]string, error) {
+	if importID == "" {
+		return nil, errors.New("the import ID is empty")
+	}
+	keyAt := func(s string) string {
+		for _, key := range keys {
+			if strings.HasPrefix(s, key+"=") {
+				return key
+			}
+		}
+		return ""
+	}
+
+	parts := strings.Split(importID, ",")
+	if keyAt(parts[0]) == "" {
+		return map[string]string{"": importID}, nil
+	}
+	pairs := map[string]string{}
+	var key string
+	for _, part := range parts {
+		if next := keyAt(part); next != "" {
+			if _, ok := pairs[next]; ok {
+				return nil, fmt.Errorf("%s is set more than once in the import ID %q", next, importID)
+			}
+			key = next
+			pairs[key] = part[len(key)+1:]
+			continue
+		}
+		pairs[key] += "," + part
+	}
+	for key, value := range pairs {
+		if value == "" {
+			return nil, fmt.Errorf("%s is empty in the import ID %q", key, importID)
+		}
+	}
+	return pairs, nil
+}
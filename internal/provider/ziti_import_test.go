@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestParseImportID(t *testing.T) {
+	t.Parallel()
+	keys := []string{"id", "name", "config_type"}
+	tests := []struct {
+		importID string
+		want     map[string]string
+		wantErr  bool
+	}{
+		{"3xmPLFvRr", map[string]string{"": "3xmPLFvRr"}, false},
+		{"my service", map[string]string{"": "my service"}, false},
+		{"a=b", map[string]string{"": "a=b"}, false},
+		{"name=web", map[string]string{"name": "web"}, false},
+		{"id=3xmPLFvRr", map[string]string{"id": "3xmPLFvRr"}, false},
+		{"name=web,config_type=host.v1", map[string]string{"name": "web", "config_type": "host.v1"}, false},
+		{"config_type=host.v1,name=a,b=c", map[string]string{"name": "a,b=c", "config_type": "host.v1"}, false},
+		{"name=web,name=db", nil, true},
+		{"name=", nil, true},
+		{"", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseImportID(tt.importID, keys)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseImportID(%q): error = %v, want error %v", tt.importID, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseImportID(%q) = %v, want %v", tt.importID, got, tt.want)
+		}
+	}
+}
+
+func TestAccImportErrors(t *testing.T) {
+	controller := testAccController(t)
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_host_v1_config" "web" {
+  name     = "web"
+  address  = "localhost"
+  port     = 80
+  protocol = "tcp"
+}
+`
+	steps := []resource.TestStep{
+		{Config: config},
+		{
+			ResourceName:            "ziti_host_v1_config.web",
+			ImportState:             true,
+			ImportStateId:           "name=web,config_type=host.v1",
+			ImportStateVerify:       true,
+			ImportStateVerifyIgnore: []string{"last_updated"},
+		},
+	}
+	for _, tt := range []struct {
+		importID string
+		wantErr  string
+	}{
+		{"config_type=intercept.v1,name=web", "is of type host.v1"},
+		{"missing", "No configs found with the ID or name"},
+		{"name=missing", "No configs found with the name"},
+		{"id=web", "Could not READ"},
+		{"id=x,name=web", "Set either id or name"},
+		{"config_type=host.v1", "Set id or name"},
+	} {
+		steps = append(steps, resource.TestStep{
+			ResourceName:  "ziti_host_v1_config.web",
+			ImportState:   true,
+			ImportStateId: tt.importID,
+			// Terraform wraps long diagnostics.
+			ExpectError: regexp.MustCompile(strings.ReplaceAll(regexp.QuoteMeta(tt.wantErr), " ", `\s+`)),
+		})
+	}
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps:                    steps,
+	})
+}
@@ -0,0 +1,243 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/openziti/edge-api/rest_model"
+)
+
+// listPageSize is the number of entities requested per page, the most the
+// controller returns at once.
+const listPageSize = 500
+
+// sortPattern matches the sort argument of a list data source, e.g.
+// "name desc" or "cost asc, name".
+var sortPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.]*( (?i:asc|desc))?(, *[A-Za-z][A-Za-z0-9_.]*( (?i:asc|desc))?)*$`)
+
+// zitiListDataSourceSpec describes how a plural data source lists its
+// entities. D is the detail model the controller returns and I the Terraform
+// model of one item.
+type zitiListDataSourceSpec[D any, I any] struct {
+	// typeName is appended to the provider type name, e.g. "_identities".
+	typeName string
+	// label names the entities in diagnostics and descriptions.
+	label string
+	// items is the name of the attribute the entities are stored in, e.g.
+	// "identities".
+	items string
+	// itemAttributes are the attributes of an item.
+	itemAttributes map[string]schema.Attribute
+	// roleAttributes is whether the entities have role attributes to match.
+	roleAttributes bool
+
+	// page lists a page of the entities matching a filter.
+	page func(ctx context.Context, client *zitiData, filter string, offset, limit int64) ([]D, *rest_model.Pagination, error)
+	// item converts a fetched entity to an item.
+	item func(ctx context.Context, detail D, item *I, diags *diag.Diagnostics)
+}
+
+// zitiListDataSource implements a data source that lists every entity
+// matching a filter from its zitiListDataSourceSpec.
+type zitiListDataSource[D any, I any] struct {
+	spec             zitiListDataSourceSpec[D, I]
+	datasourceConfig *zitiData
+}
+
+// zitiListDataSourceModel maps the arguments shared by the list data sources.
+type zitiListDataSourceModel struct {
+	Filter         types.String `tfsdk:"filter"`
+	RoleAttributes types.Set    `tfsdk:"role_attributes"`
+	Tags           types.Map    `tfsdk:"tags"`
+	Sort           types.String `tfsdk:"sort"`
+	Limit          types.Int64  `tfsdk:"limit"`
+}
+
+// Configure adds the provider configured client to the datasource.
+func (r *zitiListDataSource[D, I]) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	r.datasourceConfig = req.ProviderData.(*zitiData)
+}
+
+// Metadata returns the datasource type name.
+func (r *zitiListDataSource[D, I]) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + r.spec.typeName
+}
+
+// Schema defines the schema for the datasource.
+func (r *zitiListDataSource[D, I]) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	attributes := map[string]schema.Attribute{
+		"filter": schema.StringAttribute{
+			Optional:            true,
+			MarkdownDescription: "Ziti filter expression the " + r.spec.label + " must match, e.g. `name contains \"web\"`. Use `sort` and `limit` rather than `sort by`, `limit` and `skip` clauses.",
+		},
+		"tags": schema.MapAttribute{
+			Optional:    true,
+			ElementType: types.StringType,
+			Validators: []validator.Map{
+				mapvalidator.KeysAre(stringvalidator.RegexMatches(tagKeyPattern, "must be letters, digits and underscores to be matched by a filter")),
+			},
+			MarkdownDescription: "Tags the " + r.spec.label + " must have, with these values.",
+		},
+		"sort": schema.StringAttribute{
+			Optional: true,
+			Validators: []validator.String{
+				stringvalidator.RegexMatches(sortPattern, "must be a list of fields, each optionally followed by asc or desc"),
+			},
+			MarkdownDescription: "Order of the " + r.spec.label + ", e.g. `name desc`.",
+		},
+		"limit": schema.Int64Attribute{
+			Optional: true,
+			Validators: []validator.Int64{
+				int64validator.AtLeast(1),
+			},
+			MarkdownDescription: "Maximum number of " + r.spec.label + " to return. Every match is returned when unset.",
+		},
+		r.spec.items: schema.ListNestedAttribute{
+			Computed: true,
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: r.spec.itemAttributes,
+			},
+			MarkdownDescription: "The matching " + r.spec.label + ".",
+		},
+	}
+	if r.spec.roleAttributes {
+		attributes["role_attributes"] = schema.SetAttribute{
+			Optional:            true,
+			ElementType:         types.StringType,
+			MarkdownDescription: "Role attributes the " + r.spec.label + " must all have.",
+		}
+	}
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists the Ziti " + r.spec.label + " matching a filter.",
+		Attributes:          attributes,
+	}
+}
+
+// Read datasource information.
+func (r *zitiListDataSource[D, I]) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config zitiListDataSourceModel
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("filter"), &config.Filter)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("tags"), &config.Tags)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("sort"), &config.Sort)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("limit"), &config.Limit)...)
+	if r.spec.roleAttributes {
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("role_attributes"), &config.RoleAttributes)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filter, err := listFilter(config)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid Filter", "Could not build the filter of "+r.spec.label+": "+err.Error())
+		return
+	}
+	limit := config.Limit.ValueInt64()
+	var details []D
+	for offset := int64(0); limit == 0 || int64(len(details)) < limit; {
+		pageSize := int64(listPageSize)
+		if limit != 0 {
+			pageSize = min(pageSize, limit-int64(len(details)))
+		}
+		page, pagination, err := r.spec.page(ctx, r.datasourceConfig, filter, offset, pageSize)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Reading "+r.spec.label, "Could not list "+r.spec.label+", unexpected error: "+zitiAPIErrorFrom(err).Error(),
+			)
+			return
+		}
+		details = append(details, page...)
+		offset += int64(len(page))
+		if len(page) == 0 || pagination == nil || pagination.TotalCount == nil || offset >= *pagination.TotalCount {
+			break
+		}
+	}
+
+	items := make([]I, len(details))
+	for i, detail := range details {
+		r.spec.item(ctx, detail, &items[i], &resp.Diagnostics)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root(r.spec.items), items)...)
+}
+
+// listFilter builds the filter of a list data source from its arguments.
+func listFilter(config zitiListDataSourceModel) (string, error) {
+	filter := newFilter().Expression(config.Filter.ValueString())
+	for _, value := range config.RoleAttributes.Elements() {
+		if roleAttribute, ok := value.(types.String); ok {
+			filter.AnyOf("roleAttributes", roleAttribute.ValueString())
+		}
+	}
+	tags := config.Tags.Elements()
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if value, ok := tags[key].(types.String); ok {
+			filter.Tag(key, value.ValueString())
+		}
+	}
+	return filter.SortBy(config.Sort.ValueString()).Build()
+}
+
+// paginationOf returns the pagination of a list response.
+func paginationOf(meta *rest_model.Meta) *rest_model.Pagination {
+	if meta == nil {
+		return nil
+	}
+	return meta.Pagination
+}
+
+// listItemAttributes returns the attributes of a single entity data source as
+// computed attributes of a list item.
+func listItemAttributes(attributes map[string]schema.Attribute) map[string]schema.Attribute {
+	items := make(map[string]schema.Attribute, len(attributes))
+	for name, a := range attributes {
+		switch a := a.(type) {
+		case schema.StringAttribute:
+			items[name] = schema.StringAttribute{Computed: true, MarkdownDescription: a.MarkdownDescription}
+		case schema.BoolAttribute:
+			items[name] = schema.BoolAttribute{Computed: true, MarkdownDescription: a.MarkdownDescription}
+		case schema.Int64Attribute:
+			items[name] = schema.Int64Attribute{Computed: true, MarkdownDescription: a.MarkdownDescription}
+		case schema.SetAttribute:
+			items[name] = schema.SetAttribute{Computed: true, ElementType: a.ElementType, MarkdownDescription: a.MarkdownDescription}
+		case schema.ListAttribute:
+			items[name] = schema.ListAttribute{Computed: true, ElementType: a.ElementType, MarkdownDescription: a.MarkdownDescription}
+		case schema.MapAttribute:
+			items[name] = schema.MapAttribute{Computed: true, ElementType: a.ElementType, MarkdownDescription: a.MarkdownDescription}
+		default:
+			panic(fmt.Sprintf("listItemAttributes: unsupported attribute %s of type %T", name, a))
+		}
+	}
+	return items
+}
+
+// dataSourceAttributes returns the schema attributes of a data source.
+func dataSourceAttributes(d datasource.DataSource) map[string]schema.Attribute {
+	var resp datasource.SchemaResponse
+	d.Schema(context.Background(), datasource.SchemaRequest{}, &resp)
+	return resp.Schema.Attributes
+}
@@ -0,0 +1,211 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccIdentitiesDataSource(t *testing.T) {
+	controller := testAccController(t)
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_identity" "payments_api" {
+  name            = "payments-api"
+  role_attributes = ["api"]
+  tags            = { team = "payments" }
+}
+
+resource "ziti_identity" "payments_worker" {
+  name            = "payments-worker"
+  role_attributes = ["worker"]
+  tags            = { team = "payments" }
+}
+
+resource "ziti_identity" "ledger_api" {
+  name            = "ledger-api"
+  role_attributes = ["api"]
+  tags            = { team = "ledger" }
+}
+
+locals {
+  identities = [ziti_identity.payments_api, ziti_identity.payments_worker, ziti_identity.ledger_api]
+}
+
+data "ziti_identities" "tags" {
+  tags       = { team = "payments" }
+  sort       = "name"
+  depends_on = [local.identities]
+}
+
+data "ziti_identities" "role_attributes" {
+  role_attributes = ["api"]
+  sort            = "name"
+  depends_on      = [local.identities]
+}
+
+data "ziti_identities" "tags_and_role_attributes" {
+  role_attributes = ["api"]
+  tags            = { team = "payments" }
+  depends_on      = [local.identities]
+}
+
+data "ziti_identities" "filter" {
+  filter     = "name startsWith \"payments\" or name = \"ledger-api\""
+  tags       = { team = "ledger" }
+  depends_on = [local.identities]
+}
+
+data "ziti_identities" "sort_and_limit" {
+  filter     = "name contains \"api\""
+  sort       = "name desc"
+  limit      = 1
+  depends_on = [local.identities]
+}
+
+data "ziti_identities" "no_match" {
+  tags       = { team = "nobody" }
+  depends_on = [local.identities]
+}
+`
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{{
+			Config: config,
+			Check: resource.ComposeAggregateTestCheckFunc(
+				testAccCheckListNames("data.ziti_identities.tags", "identities", "payments-api", "payments-worker"),
+				testAccCheckListNames("data.ziti_identities.role_attributes", "identities", "ledger-api", "payments-api"),
+				testAccCheckListNames("data.ziti_identities.tags_and_role_attributes", "identities", "payments-api"),
+				testAccCheckListNames("data.ziti_identities.filter", "identities", "ledger-api"),
+				testAccCheckListNames("data.ziti_identities.sort_and_limit", "identities", "payments-api"),
+				testAccCheckListNames("data.ziti_identities.no_match", "identities"),
+			),
+		}},
+	})
+}
+
+func TestAccServicesDataSourcePaging(t *testing.T) {
+	controller := testAccController(t)
+	count := listPageSize + 2
+	config := testAccProviderConfig(controller, "") + fmt.Sprintf(`
+resource "ziti_service" "paged" {
+  count           = %d
+  name            = format("svc-%%04d", count.index)
+  role_attributes = ["paged"]
+}
+
+data "ziti_services" "paged" {
+  role_attributes = ["paged"]
+  depends_on      = [ziti_service.paged]
+}
+
+data "ziti_services" "limited" {
+  sort       = "name"
+  limit      = %d
+  depends_on = [ziti_service.paged]
+}
+`, count, listPageSize+1)
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{{
+			Config: config,
+			Check: resource.ComposeAggregateTestCheckFunc(
+				resource.TestCheckResourceAttr("data.ziti_services.paged", "services.#", strconv.Itoa(count)),
+				resource.TestCheckResourceAttr("data.ziti_services.limited", "services.#", strconv.Itoa(listPageSize+1)),
+				resource.TestCheckResourceAttr("data.ziti_services.limited", fmt.Sprintf("services.%d.name", listPageSize), fmt.Sprintf("svc-%04d", listPageSize)),
+			),
+		}},
+	})
+}
+
+func TestAccConfigsDataSource(t *testing.T) {
+	controller := testAccController(t)
+	config := testAccProviderConfig(controller, "") + `
+resource "ziti_host_v1_config" "web" {
+  name     = "web.host.v1"
+  address  = "localhost"
+  port     = 8080
+  protocol = "tcp"
+  tags     = { app = "web" }
+}
+
+resource "ziti_intercept_v1_config" "web" {
+  name        = "web.intercept.v1"
+  addresses   = ["web.ziti"]
+  protocols   = ["tcp"]
+  port_ranges = [{ low = 80, high = 80 }]
+}
+
+data "ziti_configs" "tagged" {
+  tags       = { app = "web" }
+  depends_on = [ziti_host_v1_config.web, ziti_intercept_v1_config.web]
+}
+
+data "ziti_configs" "sorted" {
+  sort       = "name"
+  depends_on = [ziti_host_v1_config.web, ziti_intercept_v1_config.web]
+}
+`
+
+	resource.ParallelTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{{
+			Config: config,
+			Check: resource.ComposeAggregateTestCheckFunc(
+				resource.TestCheckResourceAttr("data.ziti_configs.tagged", "configs.#", "1"),
+				resource.TestCheckResourceAttr("data.ziti_configs.tagged", "configs.0.name", "web.host.v1"),
+				resource.TestCheckResourceAttr("data.ziti_configs.tagged", "configs.0.config_type_name", "host.v1"),
+				resource.TestCheckResourceAttrSet("data.ziti_configs.tagged", "configs.0.config_type_id"),
+				resource.TestCheckResourceAttr("data.ziti_configs.tagged", "configs.0.tags.app", "web"),
+				resource.TestMatchResourceAttr("data.ziti_configs.tagged", "configs.0.data", regexp.MustCompile(`"address":"localhost"`)),
+				resource.TestCheckResourceAttr("data.ziti_configs.sorted", "configs.#", "2"),
+				resource.TestCheckResourceAttr("data.ziti_configs.sorted", "configs.1.name", "web.intercept.v1"),
+			),
+		}},
+	})
+}
+
+// testAccCheckListNames checks that the list attribute of a data source holds
+// entities with an id named names, in order.
+func testAccCheckListNames(dataSource, list string, names ...string) resource.TestCheckFunc {
+	checks := []resource.TestCheckFunc{
+		resource.TestCheckResourceAttr(dataSource, list+".#", strconv.Itoa(len(names))),
+	}
+	for i, name := range names {
+		checks = append(checks,
+			resource.TestCheckResourceAttr(dataSource, fmt.Sprintf("%s.%d.name", list, i), name),
+			resource.TestCheckResourceAttrSet(dataSource, fmt.Sprintf("%s.%d.id", list, i)),
+		)
+	}
+	return resource.ComposeAggregateTestCheckFunc(checks...)
+}
+
+func TestListFilter(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		config zitiListDataSourceModel
+		want   string
+	}{
+		{zitiListDataSourceModel{}, ""},
+		{zitiListDataSourceModel{Sort: types.StringValue("name desc")}, "true sort by name desc"},
+		{
+			zitiListDataSourceModel{
+				Filter:         types.StringValue(`name contains "a" or name contains "b"`),
+				RoleAttributes: types.SetValueMust(types.StringType, []attr.Value{types.StringValue(`say "hi"`)}),
+				Tags:           types.MapValueMust(types.StringType, map[string]attr.Value{"team": types.StringValue("payments"), "env": types.StringValue(`c:\tmp`)}),
+				Sort:           types.StringValue("name"),
+			},
+			`(name contains "a" or name contains "b") and anyOf(roleAttributes) = "say \"hi\"" and tags.env = "c:\\tmp" and tags.team = "payments" sort by name`,
+		},
+	} {
+		if got, err := listFilter(tc.config); err != nil || got != tc.want {
+			t.Errorf("listFilter = %s, %v, want %s", got, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,460 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/openziti/edge-api/rest_model"
+)
+
+const (
+	// defaultEnrollmentJwtTimeout is how long Create waits for an enrollment
+	// JWT unless timeouts.create is set.
+	defaultEnrollmentJwtTimeout = 2 * time.Minute
+	// enrollmentJwtPollMin and enrollmentJwtPollMax bound the wait between
+	// two checks for an enrollment JWT.
+	enrollmentJwtPollMin = 250 * time.Millisecond
+	enrollmentJwtPollMax = 10 * time.Second
+)
+
+// errMissingData is returned when a controller response has no data object.
+var errMissingData = errors.New("the controller response has no data")
+
+// zitiResourceSpec describes how a resource type maps onto the typed
+// management API. M is the Terraform model of the resource and D the detail
+// model the controller returns for it.
+type zitiResourceSpec[M any, D any] struct {
+	// typeName is appended to the provider type name, e.g. "_service".
+	typeName string
+	// label names the resource in diagnostics.
+	label string
+
+	// create creates the resource from the plan and returns its ID.
+	create func(ctx context.Context, client *zitiData, plan *M, diags *diag.Diagnostics) (string, error)
+	// read fetches the resource.
+	read func(ctx context.Context, client *zitiData, id string) (D, error)
+	// readData copies a fetched resource into state. The id and last_updated
+	// attributes are handled by zitiResource.
+	readData func(ctx context.Context, detail D, state *M, diags *diag.Diagnostics)
+	// update replaces the resource with the plan.
+	update func(ctx context.Context, client *zitiData, id string, plan *M, diags *diag.Diagnostics) error
+	// delete deletes the resource.
+	delete func(ctx context.Context, client *zitiData, id string) error
+
+	// list lists the resources matching a filter. Import looks names up
+	// through it.
+	list func(ctx context.Context, client *zitiData, filter string) ([]D, error)
+	// entityID returns the ID of a fetched resource.
+	entityID func(detail D) string
+	// importChecks are the keys an import ID may set besides id and name, e.g.
+	// config_type. Each returns an error if the imported resource does not
+	// match the value of its key.
+	importChecks map[string]func(detail D, value string) error
+
+	// exportFilter is the filter the exporter lists the resources by, e.g.
+	// `typeId = "DOMAIN"`; empty lists every resource.
+	exportFilter string
+	// exports, when set, selects the listed resources the exporter writes as
+	// this resource type, e.g. the identities with a updb enrollment.
+	exports func(detail D) bool
+
+	// enrollmentJwt, when set, returns the enrollment JWT of a fetched
+	// resource. Create then waits for the JWT, up to the create timeout of the
+	// timeouts block, and stores it in enrollment_token, and update carries
+	// enrollment_token over from state.
+	enrollmentJwt func(detail D) string
+}
+
+// zitiResource implements the Configure, Metadata, CRUD and import methods of a
+// resource from its zitiResourceSpec. Resources embed it and add their Schema.
+type zitiResource[M any, D any] struct {
+	spec           zitiResourceSpec[M, D]
+	resourceConfig *zitiData
+}
+
+func newZitiResource[M any, D any](spec zitiResourceSpec[M, D]) zitiResource[M, D] {
+	return zitiResource[M, D]{spec: spec}
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *zitiResource[M, D]) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Add a nil check when handling ProviderData because Terraform
+	// sets that data after it calls the ConfigureProvider RPC.
+	if req.ProviderData == nil {
+		return
+	}
+
+	r.resourceConfig = req.ProviderData.(*zitiData)
+}
+
+// Metadata returns the resource type name.
+func (r *zitiResource[M, D]) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + r.spec.typeName
+}
+
+// Create a new resource.
+func (r *zitiResource[M, D]) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	plan, tags, tagsAll := r.payload(ctx, req.Plan, nil, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resourceID, err := r.spec.create(ctx, r.resourceConfig, &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err != nil {
+		addZitiErrorDiagnostic(ctx, &resp.Diagnostics, req.Plan.Schema, "Error Creating "+r.spec.label, "Could not Create "+r.spec.label+", unexpected error: ", zitiAPIErrorFrom(err))
+		return
+	}
+
+	// Record the resource before waiting on anything else, so it is tracked
+	// (and tainted) even if a later step fails.
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, tagsPath, tags)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, tagsAllPath, tagsAll)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), resourceID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("last_updated"), lastUpdated())...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if r.spec.enrollmentJwt != nil {
+		var createTimeouts timeouts.Value
+		resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("timeouts"), &createTimeouts)...)
+		createTimeout, d := createTimeouts.Create(ctx, defaultEnrollmentJwtTimeout)
+		resp.Diagnostics.Append(d...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		jwt, err := r.waitForEnrollmentJwt(ctx, resourceID, createTimeout)
+		if err != nil {
+			resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("enrollment_token"), types.StringNull())...)
+			if errors.Is(err, context.DeadlineExceeded) {
+				resp.Diagnostics.AddError("Error Fetching JWT", fmt.Sprintf("Timeout after %s while waiting for JWT to be available; increase timeouts.create to wait longer", createTimeout))
+			} else {
+				resp.Diagnostics.AddError("Error Fetching JWT", "Could not read the JWT of the "+r.spec.label+": "+err.Error())
+			}
+			return
+		}
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("enrollment_token"), jwt)...)
+	}
+}
+
+// waitForEnrollmentJwt polls the resource until the controller has issued its
+// enrollment JWT. The first check is made right away, so a JWT issued with the
+// resource is used without waiting; later ones back off exponentially. A read
+// error that will not clear up, such as a 404 or 403, is returned at once;
+// otherwise the error of ctx is returned once ctx is done or timeout has
+// passed.
+func (r *zitiResource[M, D]) waitForEnrollmentJwt(ctx context.Context, id string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	wait := enrollmentJwtPollMin
+	for {
+		detail, err := r.spec.read(ctx, r.resourceConfig, id)
+		if err == nil {
+			if jwt := r.spec.enrollmentJwt(detail); jwt != "" {
+				return jwt, nil
+			}
+		} else if err = zitiAPIErrorFrom(err); !isTransientAPIError(err) {
+			return "", err
+		}
+		tflog.Debug(ctx, "Waiting for the enrollment JWT", map[string]any{"id": id, "wait": wait.String()})
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return "", ctx.Err()
+		case <-timer.C:
+		}
+		wait = min(2*wait, enrollmentJwtPollMax)
+	}
+}
+
+// isTransientAPIError reports whether err may clear up on its own: a transport
+// error, or a response of the controller that is worth retrying.
+func isTransientAPIError(err error) bool {
+	var apiErr *ZitiAPIError
+	if !errors.As(err, &apiErr) {
+		return true
+	}
+	switch apiErr.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	}
+	return apiErr.StatusCode >= http.StatusInternalServerError
+}
+
+// Read resource information.
+func (r *zitiResource[M, D]) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state M
+	tflog.Debug(ctx, "Reading "+r.spec.label)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	var id types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("id"), &id)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	detail, err := r.spec.read(ctx, r.resourceConfig, id.ValueString())
+	if err != nil {
+		err = zitiAPIErrorFrom(err)
+		if errors.Is(err, errNotFound) {
+			tflog.Info(ctx, "Resource not found in backend; removing from state", map[string]any{"id": id.ValueString()})
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Reading "+r.spec.label, "Could not READ "+r.spec.label+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	r.spec.readData(ctx, detail, &state, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Set refreshed state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+	var configured types.Map
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, tagsPath, &configured)...)
+	resp.Diagnostics.Append(r.refreshTags(ctx, &resp.State, configured)...)
+
+	// The controller drops the enrollment JWT once it has been used, so only a
+	// pending one is copied; it restores enrollment_token on import.
+	if r.spec.enrollmentJwt != nil {
+		if jwt := r.spec.enrollmentJwt(detail); jwt != "" {
+			resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("enrollment_token"), jwt)...)
+		}
+	}
+}
+
+// Update updates the resource and sets the updated Terraform state on success.
+func (r *zitiResource[M, D]) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	tflog.Debug(ctx, "Updating "+r.spec.label)
+	var id types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("id"), &id)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The update replaces every tag, so the ignored tags other tooling has
+	// set are sent back as they are.
+	var ignoredTags map[string]string
+	if r.resourceConfig.tags.ignoresAny() {
+		detail, err := r.spec.read(ctx, r.resourceConfig, id.ValueString())
+		if err == nil {
+			ignoredTags, err = r.resourceConfig.tags.ignoredTagsOf(detail)
+		}
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Updating "+r.spec.label, "Could not read the tags of "+r.spec.label+", unexpected error: "+zitiAPIErrorFrom(err).Error(),
+			)
+			return
+		}
+	}
+	plan, tags, tagsAll := r.payload(ctx, req.Plan, ignoredTags, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.spec.update(ctx, r.resourceConfig, id.ValueString(), &plan, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if err != nil {
+		addZitiErrorDiagnostic(ctx, &resp.Diagnostics, req.Plan.Schema, "Error Updating "+r.spec.label, "Could not Update "+r.spec.label+", unexpected error: ", zitiAPIErrorFrom(err))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, tagsPath, tags)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, tagsAllPath, tagsAll)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("last_updated"), lastUpdated())...)
+
+	if r.spec.enrollmentJwt != nil {
+		var enrollmentToken types.String
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("enrollment_token"), &enrollmentToken)...)
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("enrollment_token"), enrollmentToken)...)
+	}
+}
+
+// ModifyPlan plans tags_all, the tags of the resource merged over the
+// default_tags of the provider, so a change of the default tags updates the
+// resource.
+func (r *zitiResource[M, D]) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.resourceConfig == nil {
+		return
+	}
+
+	var configured types.Map
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, tagsPath, &configured)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tagsAll := types.MapUnknown(types.StringType)
+	if tags, ok := knownTags(configured); ok {
+		r.resourceConfig.tags.warnIgnored(tags, tagsPath, &resp.Diagnostics)
+		tagsAll = tagsValue(ctx, r.resourceConfig.tags.merge(tags), &resp.Diagnostics)
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, tagsAllPath, tagsAll)...)
+
+	if req.State.Raw.IsNull() {
+		return
+	}
+	var prior types.Map
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, tagsAllPath, &prior)...)
+	if !prior.Equal(tagsAll) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("last_updated"), types.StringUnknown())...)
+	}
+}
+
+// payload returns the model a create or update payload is built from: plan
+// with the default tags merged into tags, and ignored, the ignored tags the
+// resource has on the controller, added to them. It also returns the tags
+// and tags_all to store in state.
+func (r *zitiResource[M, D]) payload(ctx context.Context, plan tfsdk.Plan, ignored map[string]string, diags *diag.Diagnostics) (M, types.Map, types.Map) {
+	var payload M
+	var configured types.Map
+	diags.Append(plan.GetAttribute(ctx, tagsPath, &configured)...)
+	tags, _ := knownTags(configured)
+	all := r.resourceConfig.tags.merge(tags)
+
+	sent := make(map[string]string, len(ignored)+len(all))
+	for key, value := range ignored {
+		sent[key] = value
+	}
+	for key, value := range all {
+		sent[key] = value
+	}
+	diags.Append(plan.SetAttribute(ctx, tagsPath, tagsValue(ctx, sent, diags))...)
+	diags.Append(plan.Get(ctx, &payload)...)
+	return payload, configured, tagsValue(ctx, all, diags)
+}
+
+// refreshTags splits the tags read into state into tags_all and tags, which
+// leaves out the default tags unless configured, the prior tags, sets them.
+func (r *zitiResource[M, D]) refreshTags(ctx context.Context, state *tfsdk.State, configured types.Map) diag.Diagnostics {
+	var diags diag.Diagnostics
+	var read types.Map
+	diags.Append(state.GetAttribute(ctx, tagsPath, &read)...)
+	readTags, _ := knownTags(read)
+	priorTags, _ := knownTags(configured)
+	tags, all := r.resourceConfig.tags.split(readTags, priorTags)
+	diags.Append(state.SetAttribute(ctx, tagsPath, tagsValue(ctx, tags, &diags))...)
+	diags.Append(state.SetAttribute(ctx, tagsAllPath, tagsValue(ctx, all, &diags))...)
+	return diags
+}
+
+// Delete deletes the resource and removes the Terraform state on success.
+func (r *zitiResource[M, D]) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var id types.String
+	tflog.Debug(ctx, "Deleting "+r.spec.label)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("id"), &id)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.spec.delete(ctx, r.resourceConfig, id.ValueString())
+	if err != nil {
+		err = zitiAPIErrorFrom(err)
+		if errors.Is(err, errNotFound) {
+			tflog.Info(ctx, "Resource already deleted in backend", map[string]any{"id": id.ValueString()})
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Error Deleting "+r.spec.label, "Could not DELETE "+r.spec.label+", unexpected error: "+err.Error(),
+		)
+		return
+	}
+}
+
+// lastUpdated is the value of the last_updated attribute for a change made now.
+func lastUpdated() string {
+	return time.Now().Format(time.RFC850)
+}
+
+// createdID returns the ID of a created resource.
+func createdID(envelope *rest_model.CreateEnvelope) (string, error) {
+	if envelope == nil || envelope.Data == nil {
+		return "", errMissingData
+	}
+	return envelope.Data.ID, nil
+}
+
+// tagsFromAPI converts the tags of a fetched resource to the tags attribute; no
+// tags are stored as null so an unset tags argument shows no drift.
+func tagsFromAPI(ctx context.Context, tags *rest_model.Tags, diags *diag.Diagnostics) types.Map {
+	if tags == nil || len(tags.SubTags) == 0 {
+		return types.MapNull(types.StringType)
+	}
+	values := make(map[string]string, len(tags.SubTags))
+	for key, value := range tags.SubTags {
+		if s, ok := value.(string); ok {
+			values[key] = s
+			continue
+		}
+		encoded, _ := json.Marshal(value)
+		values[key] = string(encoded)
+	}
+	value, d := types.MapValueFrom(ctx, types.StringType, values)
+	diags.Append(d...)
+	return value
+}
+
+// stringSetFromAPI converts a list of a fetched resource to a set attribute; a
+// missing or empty list is stored as null, as the controller returns an empty
+// list for an unset argument.
+func stringSetFromAPI(ctx context.Context, values []string, diags *diag.Diagnostics) types.Set {
+	if len(values) == 0 {
+		return types.SetNull(types.StringType)
+	}
+	value, d := types.SetValueFrom(ctx, types.StringType, values)
+	diags.Append(d...)
+	return value
+}
+
+// stringListFromAPI converts a list of a fetched resource to a list attribute;
+// a missing or empty list is stored as null, like stringSetFromAPI.
+func stringListFromAPI(ctx context.Context, values []string, diags *diag.Diagnostics) types.List {
+	if len(values) == 0 {
+		return types.ListNull(types.StringType)
+	}
+	value, d := types.ListValueFrom(ctx, types.StringType, values)
+	diags.Append(d...)
+	return value
+}
+
+// nonEmptyStringValue converts an optional string of a fetched resource to a
+// string attribute; a missing or empty string is stored as null.
+func nonEmptyStringValue(value *string) types.String {
+	if value == nil || *value == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(*value)
+}
+
+// roleAttributesFromAPI converts the role attributes of a fetched resource to
+// the role_attributes attribute.
+func roleAttributesFromAPI(ctx context.Context, attributes *rest_model.Attributes, diags *diag.Diagnostics) types.Set {
+	if attributes == nil {
+		return types.SetNull(types.StringType)
+	}
+	return stringSetFromAPI(ctx, *attributes, diags)
+}
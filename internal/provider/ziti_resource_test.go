@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// jwtAfterReads returns a resource whose enrollment JWT is issued on the n-th
+// read, and a counter of the reads made.
+func jwtAfterReads(n int) (*zitiResource[struct{}, int], *int) {
+	reads := 0
+	r := newZitiResource(zitiResourceSpec[struct{}, int]{
+		read: func(context.Context, *zitiData, string) (int, error) {
+			reads++
+			return reads, nil
+		},
+		enrollmentJwt: func(read int) string {
+			if n > 0 && read >= n {
+				return "jwt"
+			}
+			return ""
+		},
+	})
+	return &r, &reads
+}
+
+func TestWaitForEnrollmentJwt(t *testing.T) {
+	t.Parallel()
+
+	t.Run("issued on create", func(t *testing.T) {
+		r, reads := jwtAfterReads(1)
+		start := time.Now()
+		jwt, err := r.waitForEnrollmentJwt(context.Background(), "id", time.Minute)
+		if err != nil || jwt != "jwt" {
+			t.Fatalf("got %q, %v; want the JWT", jwt, err)
+		}
+		if *reads != 1 || time.Since(start) >= enrollmentJwtPollMin {
+			t.Errorf("waited %s over %d reads for a JWT issued on create", time.Since(start), *reads)
+		}
+	})
+
+	t.Run("issued later", func(t *testing.T) {
+		r, reads := jwtAfterReads(3)
+		jwt, err := r.waitForEnrollmentJwt(context.Background(), "id", time.Minute)
+		if err != nil || jwt != "jwt" {
+			t.Fatalf("got %q, %v; want the JWT", jwt, err)
+		}
+		if *reads != 3 {
+			t.Errorf("got %d reads, want 3", *reads)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		r, _ := jwtAfterReads(0)
+		_, err := r.waitForEnrollmentJwt(context.Background(), "id", 100*time.Millisecond)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got %v, want a deadline error", err)
+		}
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		for status, transient := range map[int]bool{
+			http.StatusNotFound:           false,
+			http.StatusForbidden:          false,
+			http.StatusTooManyRequests:    true,
+			http.StatusServiceUnavailable: true,
+		} {
+			reads := 0
+			r := newZitiResource(zitiResourceSpec[struct{}, int]{
+				read: func(context.Context, *zitiData, string) (int, error) {
+					reads++
+					return 0, &ZitiAPIError{StatusCode: status}
+				},
+				enrollmentJwt: func(int) string { return "" },
+			})
+			start := time.Now()
+			_, err := r.waitForEnrollmentJwt(context.Background(), "id", 300*time.Millisecond)
+			if transient {
+				if !errors.Is(err, context.DeadlineExceeded) || reads < 2 {
+					t.Errorf("%d: got %v after %d reads, want the reads retried until the timeout", status, err, reads)
+				}
+				continue
+			}
+			var apiErr *ZitiAPIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != status || reads != 1 || time.Since(start) >= enrollmentJwtPollMin {
+				t.Errorf("%d: got %v after %d reads, want the error of the first read", status, err, reads)
+			}
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		r, _ := jwtAfterReads(0)
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+		start := time.Now()
+		_, err := r.waitForEnrollmentJwt(ctx, "id", time.Minute)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want a cancellation error", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("returned %s after cancellation", elapsed)
+		}
+	})
+}
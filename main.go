@@ -3,7 +3,9 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log"
+	"os"
 
 	"terraform-provider-ziti/internal/provider"
 
@@ -20,6 +22,13 @@ var (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		if err := export(os.Args[2:]); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+
 	var debug bool
 
 	flag.BoolVar(&debug, "debug", false, "set to true to run the provider with support for debuggers like delve")
@@ -41,3 +50,32 @@ func main() {
 		log.Fatal(err.Error())
 	}
 }
+
+// export runs the export subcommand, which writes the resources and import
+// blocks of every entity of a controller. The controller is configured through
+// the same ZITI_API_* environment variables as the provider.
+func export(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s export [-out file]\n\n", os.Args[0])
+		fmt.Fprintln(flags.Output(), "Writes ziti_* resources and import blocks for the entities of the controller")
+		fmt.Fprintln(flags.Output(), "the ZITI_API_* environment variables point at.")
+		fmt.Fprintln(flags.Output())
+		flags.PrintDefaults()
+	}
+	out := flags.String("out", "-", "file to write the configuration to; - writes to stdout")
+	_ = flags.Parse(args)
+
+	if *out == "-" {
+		return provider.Export(context.Background(), os.Stdout, version)
+	}
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := provider.Export(context.Background(), file, version); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}